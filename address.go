@@ -0,0 +1,226 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// ErrInvalidAddress is returned when an address cannot be parsed.
+var ErrInvalidAddress = errors.New("invalid address")
+
+// Address is an email address with an optional display name, as held by the
+// address fields of Mail.
+type Address struct {
+	// Name is the display name e.g. "Jane Doe", it may contain non-ASCII characters.
+	Name string
+	// Email is the bare address e.g. "jane@example.com".
+	Email string
+}
+
+// String formats the address for a header, quoting the display name when needed
+// and encoding it as an RFC 2047 encoded-word when it contains non-ASCII characters.
+func (a Address) String() string {
+	if a.Name == "" {
+		return a.Email
+	}
+	return (&mail.Address{Name: a.Name, Address: a.Email}).String()
+}
+
+// Addresses returns the bare addresses as Address values, e.g. for the To of a Mail.
+func Addresses(emails ...string) []Address {
+	addrs := make([]Address, 0, len(emails))
+	for _, email := range emails {
+		addrs = append(addrs, Address{Email: email})
+	}
+	return addrs
+}
+
+// joinAddresses formats the addresses as an address list.
+func joinAddresses(addrs []Address) string {
+	return strings.Join(formatAddresses(addrs), ", ")
+}
+
+// formatAddresses formats each of the addresses for a header.
+func formatAddresses(addrs []Address) []string {
+	list := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		list = append(list, addr.String())
+	}
+	return list
+}
+
+// addressEmails returns the bare addresses of addrs.
+func addressEmails(addrs []Address) []string {
+	list := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		list = append(list, addr.Email)
+	}
+	return list
+}
+
+// allRecipients returns the To, Cc then Bcc recipients of the email.
+func allRecipients(msg Mail) []Address {
+	return append(append(append([]Address(nil), msg.To...), msg.Cc...), msg.Bcc...)
+}
+
+// SetFrom parses the sender of the email, e.g. `"Doe, Jane" <jane@example.com>`,
+// its display name being unquoted and decoded from RFC 2047 encoded-words.
+func (m *Mail) SetFrom(s string) error {
+	addr, err := ParseAddress(s)
+	if err != nil {
+		return err
+	}
+	m.From = addr
+	return nil
+}
+
+// SetTo parses the recipients of the email from a comma separated address list.
+func (m *Mail) SetTo(list string) error {
+	return setAddressList(&m.To, list)
+}
+
+// SetCc parses the cc recipients of the email from a comma separated address list.
+func (m *Mail) SetCc(list string) error {
+	return setAddressList(&m.Cc, list)
+}
+
+// SetBcc parses the bcc recipients of the email from a comma separated address list.
+func (m *Mail) SetBcc(list string) error {
+	return setAddressList(&m.Bcc, list)
+}
+
+// SetReplyTo parses the address replies are sent to.
+func (m *Mail) SetReplyTo(s string) error {
+	addr, err := ParseAddress(s)
+	if err != nil {
+		return err
+	}
+	m.ReplyTo = addr
+	return nil
+}
+
+func setAddressList(field *[]Address, list string) error {
+	addrs, err := ParseAddressList(list)
+	if err != nil {
+		return err
+	}
+	*field = addrs
+	return nil
+}
+
+// toAddress parses an address of an address list, keeping it as the bare address
+// when it cannot be parsed.
+func toAddress(s string) Address {
+	s = strings.TrimSpace(s)
+	if addr, err := mail.ParseAddress(s); err == nil {
+		return Address{Name: addr.Name, Email: addr.Address}
+	}
+	return Address{Email: s}
+}
+
+// toAddresses parses the addresses of a configured list, see toAddress.
+func toAddresses(list []string) []Address {
+	addrs := make([]Address, 0, len(list))
+	for _, s := range list {
+		addrs = append(addrs, toAddress(s))
+	}
+	return addrs
+}
+
+// splitAddressList splits an address list on the commas outside of quoted display
+// names and angle brackets, so that `"Doe, Jane" <jane@example.com>` is one address.
+func splitAddressList(list string) []string {
+	var addrs []string
+	quoted, angle, escaped := false, false, false
+	start := 0
+	for i, r := range list {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quoted:
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == '<' && !quoted:
+			angle = true
+		case r == '>' && !quoted:
+			angle = false
+		case r == ',' && !quoted && !angle:
+			addrs = append(addrs, list[start:i])
+			start = i + 1
+		}
+	}
+	return append(addrs, list[start:])
+}
+
+// ParseAddress parses a single address, e.g. `Jane Doe <jane@example.com>`.
+func ParseAddress(s string) (Address, error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return Address{}, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+	return Address{Name: addr.Name, Email: addr.Address}, nil
+}
+
+// ParseAddressList parses a comma separated address list, e.g.
+// `"Doe, Jane" <jane@example.com>, bob@example.com`.
+func ParseAddressList(s string) ([]Address, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	addrs, err := mail.ParseAddressList(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+	list := make([]Address, 0, len(addrs))
+	for _, addr := range addrs {
+		list = append(list, Address{Name: addr.Name, Email: addr.Address})
+	}
+	return list, nil
+}
+
+// Normalize returns the address trimmed with its domain lowercased. The local part
+// is kept as-is as it may be case sensitive, but its +tag, e.g. "+newsletter" in
+// "jane+newsletter@example.com", is removed when stripTags is true.
+func (a Address) Normalize(stripTags bool) Address {
+	a.Name = strings.TrimSpace(a.Name)
+	a.Email = strings.TrimSpace(a.Email)
+
+	at := strings.LastIndex(a.Email, "@")
+	if at < 0 {
+		return a
+	}
+	local, domain := a.Email[:at], strings.ToLower(a.Email[at+1:])
+	if stripTags {
+		if plus := strings.Index(local, "+"); plus > 0 {
+			local = local[:plus]
+		}
+	}
+	a.Email = local + "@" + domain
+	return a
+}
+
+// dedupeRecipients removes the recipients which appear more than once across To,
+// Cc and Bcc, keeping the first one so that a recipient in To and Bcc stays visible.
+func dedupeRecipients(msg Mail) Mail {
+	seen := make(map[string]bool)
+	dedupe := func(list []Address) []Address {
+		var kept []Address
+		for _, addr := range list {
+			key := strings.ToLower(addr.Normalize(false).Email)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			kept = append(kept, addr)
+		}
+		return kept
+	}
+
+	msg.To = dedupe(msg.To)
+	msg.Cc = dedupe(msg.Cc)
+	msg.Bcc = dedupe(msg.Bcc)
+	return msg
+}
@@ -0,0 +1,96 @@
+package mailer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAddress_String(t *testing.T) {
+	tests := []struct {
+		address  Address
+		expected string
+	}{
+		{Address{Email: "jane@example.com"}, "jane@example.com"},
+		{Address{Name: "Jane Doe", Email: "jane@example.com"}, `"Jane Doe" <jane@example.com>`},
+		{Address{Name: `Doe, "JD" Jane`, Email: "jane@example.com"}, `"Doe, \"JD\" Jane" <jane@example.com>`},
+		{Address{Name: "Zoë", Email: "zoe@example.com"}, "=?utf-8?q?Zo=C3=AB?= <zoe@example.com>"},
+	}
+
+	for _, test := range tests {
+		if got := test.address.String(); got != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, got)
+		}
+	}
+}
+
+func TestMail_SetTo(t *testing.T) {
+	var msg Mail
+	list := `"Doe, Jane" <jane@example.com>, =?utf-8?q?Zo=C3=AB?= <zoe@example.com>, john@example.com`
+	if err := msg.SetTo(list); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []Address{{Name: "Doe, Jane", Email: "jane@example.com"}, {Name: "Zoë", Email: "zoe@example.com"}, {Email: "john@example.com"}}
+	if !reflect.DeepEqual(msg.To, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, msg.To)
+	}
+	if got := joinAddresses(msg.To); got != list {
+		t.Errorf("Expected the recipients to be formatted back as %s, got %s", list, got)
+	}
+
+	if err := msg.SetFrom("Doe, Jane <jane@example.com>"); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("Expected ErrInvalidAddress for an unquoted comma, got %v", err)
+	}
+	if err := msg.SetReplyTo(`"Doe, Jane" <jane@example.com>`); err != nil || msg.ReplyTo != expected[0] {
+		t.Errorf("Expected the reply-to %+v, got %+v, %v", expected[0], msg.ReplyTo, err)
+	}
+}
+
+func TestSendGrid_AddressNames(t *testing.T) {
+	sendgrid := &sendgridMailer{}
+	addresses := sendgrid.getAddresses([]Address{{Name: "Doe, Jane", Email: "jane@example.com"}, {Email: "john@example.com"}})
+	expected := []sendgridAddress{{Email: "jane@example.com", Name: "Doe, Jane"}, {Email: "john@example.com"}}
+	if !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, addresses)
+	}
+}
+
+func TestParseAddressList(t *testing.T) {
+	addrs, err := ParseAddressList(`"Doe, Jane" <jane@example.com>, john@example.com`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []Address{{Name: "Doe, Jane", Email: "jane@example.com"}, {Email: "john@example.com"}}
+	if !reflect.DeepEqual(addrs, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, addrs)
+	}
+
+	if _, err := ParseAddress("not an address"); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("Expected ErrInvalidAddress, got %v", err)
+	}
+}
+
+func TestAddress_Normalize(t *testing.T) {
+	addr := Address{Email: " Jane+News@Example.COM "}
+	if got := addr.Normalize(false).Email; got != "Jane+News@example.com" {
+		t.Errorf("Unexpected normalized address %s", got)
+	}
+	if got := addr.Normalize(true).Email; got != "Jane@example.com" {
+		t.Errorf("Unexpected normalized address without tag %s", got)
+	}
+}
+
+func TestMailer_SendDedupesRecipients(t *testing.T) {
+	sent := make(chan Mail, 1)
+	mailer := NewMailer(MailCfg{mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+		sent <- msg
+		return nil
+	}}})
+	defer mailer.Close()
+
+	mailer.Send(Mail{To: []Address{{Email: "a@test.com"}, {Name: "Jane", Email: "b@TEST.com"}}, Cc: Addresses("b@test.com", "c@test.com"), Bcc: Addresses("A@test.com")})
+	msg := <-sent
+	if joinAddresses(msg.To) != `a@test.com, "Jane" <b@TEST.com>` || joinAddresses(msg.Cc) != "c@test.com" || joinAddresses(msg.Bcc) != "" {
+		t.Errorf("Expected the duplicated recipients to be removed, got %q %q %q", msg.To, msg.Cc, msg.Bcc)
+	}
+}
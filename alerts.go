@@ -0,0 +1,202 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAlertInterval = time.Minute
+	// defaultAlertMinSends is the number of sends during the last minute below
+	// which the rates are not alerted on, a couple of failures out of a handful
+	// of emails not being an incident.
+	defaultAlertMinSends = 20
+)
+
+// AlertKind is the metric an Alert is about.
+type AlertKind string
+
+const (
+	AlertErrorRate  AlertKind = "error_rate"
+	AlertBounceRate AlertKind = "bounce_rate"
+	AlertQueueDepth AlertKind = "queue_depth"
+)
+
+// Alert is raised when a metric of the mailer crosses its threshold, and again
+// with Resolved set once it is back under it.
+type Alert struct {
+	Kind      AlertKind      `json:"kind"`
+	Value     float64        `json:"value"`
+	Threshold float64        `json:"threshold"`
+	Resolved  bool           `json:"resolved"`
+	Provider  APIServiceType `json:"provider,omitempty"`
+	Time      time.Time      `json:"time"`
+}
+
+// String describes the alert for a person, e.g. in a chat message.
+func (a Alert) String() string {
+	format := "%.1f%%"
+	value, threshold := a.Value*100, a.Threshold*100
+	if a.Kind == AlertQueueDepth {
+		format, value, threshold = "%.0f", a.Value, a.Threshold
+	}
+	state := "above"
+	if a.Resolved {
+		state = "back under"
+	}
+	return fmt.Sprintf("[%s mailer] %s is "+format+", %s the threshold of "+format, a.Provider, a.Kind, value, state, threshold)
+}
+
+// AlertSink receives the alerts of the mailer, e.g. a chat channel.
+type AlertSink interface {
+	Alert(ctx context.Context, alert Alert) error
+}
+
+// AlertConfig alerts on the deliverability incidents, the thresholds being
+// disabled when zero. The rates are those of the last minute, see Stats.
+type AlertConfig struct {
+	Sinks []AlertSink
+	// ErrorRate is the ratio of failed sends, between 0 and 1, alerted on.
+	ErrorRate float64
+	// BounceRate is the ratio of bounces to sends, between 0 and 1, alerted on.
+	BounceRate float64
+	// QueueDepth is the number of emails waiting to be sent alerted on.
+	QueueDepth int
+	// MinSends is the number of sends during the last minute under which the
+	// rates are not alerted on. Defaults to 20.
+	MinSends int
+	// Interval is how often the thresholds are checked. Defaults to a minute.
+	Interval time.Duration
+}
+
+// alerter raises the alerts of the thresholds crossed since the last check.
+type alerter struct {
+	cfg AlertConfig
+
+	mu     sync.Mutex
+	firing map[AlertKind]bool
+}
+
+func newAlerter(cfg AlertConfig) *alerter {
+	if cfg.MinSends <= 0 {
+		cfg.MinSends = defaultAlertMinSends
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultAlertInterval
+	}
+	return &alerter{cfg: cfg, firing: make(map[AlertKind]bool)}
+}
+
+// check compares the stats to the thresholds and returns the alerts raised or
+// resolved since the previous check.
+func (a *alerter) check(stats Stats, provider APIServiceType, now time.Time) []Alert {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var alerts []Alert
+	evaluate := func(kind AlertKind, value float64, threshold float64, measured bool) {
+		if threshold <= 0 || !measured {
+			return
+		}
+		firing := value >= threshold
+		if firing == a.firing[kind] {
+			return
+		}
+		a.firing[kind] = firing
+		alerts = append(alerts, Alert{Kind: kind, Value: value, Threshold: threshold, Resolved: !firing, Provider: provider, Time: now})
+	}
+	enoughSends := stats.SendsPerMinute >= a.cfg.MinSends
+	evaluate(AlertErrorRate, stats.ErrorRate, a.cfg.ErrorRate, enoughSends)
+	evaluate(AlertBounceRate, stats.BounceRate, a.cfg.BounceRate, enoughSends)
+	evaluate(AlertQueueDepth, float64(stats.Queued), float64(a.cfg.QueueDepth), true)
+	return alerts
+}
+
+// watchAlerts checks the thresholds every interval until the mailer is closed.
+func (m *Mailer) watchAlerts(a *alerter) {
+	ticker := m.clock.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C():
+			m.sendAlerts(a, a.check(m.Stats(), m.apiService, m.clock.Now()))
+		}
+	}
+}
+
+func (m *Mailer) sendAlerts(a *alerter, alerts []Alert) {
+	for _, alert := range alerts {
+		for _, sink := range a.cfg.Sinks {
+			ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+			err := sink.Alert(ctx, alert)
+			cancel()
+			if err != nil {
+				log.Printf("mailer: failed to send %s alert: %v", alert.Kind, err)
+			}
+		}
+	}
+}
+
+// webhookAlertSink posts the alerts as JSON to a url.
+type webhookAlertSink struct {
+	httpClient *http.Client
+	url        string
+	payload    func(Alert) any
+}
+
+func (s *webhookAlertSink) Alert(ctx context.Context, alert Alert) error {
+	req, err := newJSONRequest(http.MethodPost, s.url, s.payload(alert))
+	if err != nil {
+		return err
+	}
+	return doJSONRequest(s.httpClient, "alert", req.WithContext(ctx), nil)
+}
+
+func newWebhookAlertSink(url string, payload func(Alert) any) AlertSink {
+	return &webhookAlertSink{httpClient: &http.Client{Timeout: webhookTimeout}, url: url, payload: payload}
+}
+
+// NewSlackAlertSink posts the alerts to a Slack incoming webhook.
+func NewSlackAlertSink(webhookURL string) AlertSink {
+	return newWebhookAlertSink(webhookURL, func(alert Alert) any {
+		return map[string]string{"text": alertEmoji(alert) + " " + alert.String()}
+	})
+}
+
+// NewTeamsAlertSink posts the alerts to a Microsoft Teams incoming webhook, as
+// message cards colored by whether the alert is resolved.
+func NewTeamsAlertSink(webhookURL string) AlertSink {
+	return newWebhookAlertSink(webhookURL, func(alert Alert) any {
+		color := "D73A49"
+		if alert.Resolved {
+			color = "28A745"
+		}
+		return map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    string(alert.Kind),
+			"themeColor": color,
+			"text":       alert.String(),
+		}
+	})
+}
+
+// NewWebhookAlertSink posts the alerts as JSON to a url, e.g. of an incident
+// management tool.
+func NewWebhookAlertSink(url string) AlertSink {
+	return newWebhookAlertSink(url, func(alert Alert) any { return alert })
+}
+
+func alertEmoji(alert Alert) string {
+	if alert.Resolved {
+		return ":white_check_mark:"
+	}
+	return ":rotating_light:"
+}
@@ -0,0 +1,162 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingAlertSink struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (s *recordingAlertSink) Alert(ctx context.Context, alert Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts = append(s.alerts, alert)
+	return nil
+}
+
+func (s *recordingAlertSink) received() []Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Alert(nil), s.alerts...)
+}
+
+func TestAlerter_Check(t *testing.T) {
+	a := newAlerter(AlertConfig{ErrorRate: 0.1, BounceRate: 0.05, QueueDepth: 100})
+	now := time.Now()
+
+	testCases := []struct {
+		name     string
+		stats    Stats
+		expected []Alert
+	}{
+		{
+			name:  "Should not alert on the rates of a handful of sends",
+			stats: Stats{SendsPerMinute: 5, ErrorRate: 0.6},
+		},
+		{
+			name:  "Should alert when thresholds are crossed",
+			stats: Stats{SendsPerMinute: 50, ErrorRate: 0.2, BounceRate: 0.01, Queued: 150},
+			expected: []Alert{
+				{Kind: AlertErrorRate, Value: 0.2, Threshold: 0.1, Provider: RESEND, Time: now},
+				{Kind: AlertQueueDepth, Value: 150, Threshold: 100, Provider: RESEND, Time: now},
+			},
+		},
+		{
+			name:  "Should not alert again while the thresholds stay crossed",
+			stats: Stats{SendsPerMinute: 50, ErrorRate: 0.3, Queued: 200},
+		},
+		{
+			name:  "Should resolve the alerts once back under the thresholds",
+			stats: Stats{SendsPerMinute: 50, ErrorRate: 0.3, BounceRate: 0.1, Queued: 10},
+			expected: []Alert{
+				{Kind: AlertBounceRate, Value: 0.1, Threshold: 0.05, Provider: RESEND, Time: now},
+				{Kind: AlertQueueDepth, Value: 10, Threshold: 100, Resolved: true, Provider: RESEND, Time: now},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			alerts := a.check(tc.stats, RESEND, now)
+			if len(alerts) != len(tc.expected) {
+				t.Fatalf("Expected %v, got %v", tc.expected, alerts)
+			}
+			for i := range alerts {
+				if alerts[i] != tc.expected[i] {
+					t.Errorf("Expected %v, got %v", tc.expected[i], alerts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMailer_AlertsOnBounceRate(t *testing.T) {
+	sink := &recordingAlertSink{}
+	mailer := NewMailer(MailCfg{
+		APIService:   RESEND,
+		APIKey:       MailAPIKey,
+		Alerts:       AlertConfig{Sinks: []AlertSink{sink}, BounceRate: 0.1, MinSends: 10, Interval: 5 * time.Millisecond},
+		mailerClient: &mockMailerClient{},
+	})
+	defer mailer.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test", Text: "test"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	mailer.HandleEvents(Event{Type: EventBounced, Recipient: "a@test.com"}, Event{Type: EventBounced, Recipient: "b@test.com"})
+
+	for i := 0; i < 200 && len(sink.received()) == 0; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	alerts := sink.received()
+	if len(alerts) != 1 || alerts[0].Kind != AlertBounceRate || alerts[0].Value != 0.2 {
+		t.Errorf("Expected a bounce rate alert, got %v", alerts)
+	}
+}
+
+func TestAlertSinks(t *testing.T) {
+	alert := Alert{Kind: AlertErrorRate, Value: 0.25, Threshold: 0.1, Provider: SENDGRID}
+
+	testCases := []struct {
+		name     string
+		sink     func(url string) AlertSink
+		expected map[string]any
+	}{
+		{
+			name:     "Should post a Slack message",
+			sink:     NewSlackAlertSink,
+			expected: map[string]any{"text": ":rotating_light: [sendgrid mailer] error_rate is 25.0%, above the threshold of 10.0%"},
+		},
+		{
+			name: "Should post a Teams message card",
+			sink: NewTeamsAlertSink,
+			expected: map[string]any{
+				"@type":      "MessageCard",
+				"@context":   "https://schema.org/extensions",
+				"summary":    "error_rate",
+				"themeColor": "D73A49",
+				"text":       "[sendgrid mailer] error_rate is 25.0%, above the threshold of 10.0%",
+			},
+		},
+		{
+			name:     "Should post the alert as JSON",
+			sink:     NewWebhookAlertSink,
+			expected: map[string]any{"kind": "error_rate", "value": 0.25, "threshold": 0.1, "resolved": false, "provider": "sendgrid", "time": "0001-01-01T00:00:00Z"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []recordedRequest
+			server := newRecordingServer(t, &requests, map[string]string{"POST /hook": "ok"})
+			defer server.Close()
+
+			if err := tc.sink(server.URL+"/hook").Alert(context.Background(), alert); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(requests) != 1 {
+				t.Fatalf("Expected 1 request, got %d", len(requests))
+			}
+			body := requests[0].Body
+			if len(body) != len(tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, body)
+			}
+			for key, value := range tc.expected {
+				if body[key] != value {
+					t.Errorf("Expected %s to be %v, got %v", key, value, body[key])
+				}
+			}
+			if !strings.HasPrefix(requests[0].Header.Get("Content-Type"), "application/json") {
+				t.Errorf("Expected a JSON request, got %s", requests[0].Header.Get("Content-Type"))
+			}
+		})
+	}
+}
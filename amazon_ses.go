@@ -2,9 +2,13 @@ package mailer
 
 import (
 	"context"
+	"encoding/json"
 	"log"
+	"net/http"
+	"sort"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/sesv2"
@@ -19,33 +23,97 @@ type sesMailerClient interface {
 	SendEmail(ctx context.Context, params *sesv2.SendEmailInput, optFns ...func(*sesv2.Options)) (*sesv2.SendEmailOutput, error)
 }
 
+type sesTemplateClient interface {
+	CreateEmailTemplate(ctx context.Context, params *sesv2.CreateEmailTemplateInput, optFns ...func(*sesv2.Options)) (*sesv2.CreateEmailTemplateOutput, error)
+	UpdateEmailTemplate(ctx context.Context, params *sesv2.UpdateEmailTemplateInput, optFns ...func(*sesv2.Options)) (*sesv2.UpdateEmailTemplateOutput, error)
+	DeleteEmailTemplate(ctx context.Context, params *sesv2.DeleteEmailTemplateInput, optFns ...func(*sesv2.Options)) (*sesv2.DeleteEmailTemplateOutput, error)
+}
+
+type sesAccountClient interface {
+	GetAccount(ctx context.Context, params *sesv2.GetAccountInput, optFns ...func(*sesv2.Options)) (*sesv2.GetAccountOutput, error)
+}
+
+type sesIdentityClient interface {
+	PutEmailIdentityMailFromAttributes(ctx context.Context, params *sesv2.PutEmailIdentityMailFromAttributesInput, optFns ...func(*sesv2.Options)) (*sesv2.PutEmailIdentityMailFromAttributesOutput, error)
+}
+
+// SESOptions holds the Amazon SES specific options of an email.
+type SESOptions struct {
+	// ConfigurationSetName overrides the configuration set of the mailer for this email.
+	ConfigurationSetName string
+	// Tags are the message tags used by SES event publishing, keyed by tag name.
+	Tags map[string]string
+}
+
 type sesParams struct {
-	Region string
-	Key    string
-	Secret string
+	Region               string
+	Key                  string
+	Secret               string
+	ConfigurationSetName string
+	// AppID is appended to the user agent of the AWS SDK.
+	AppID      string
+	HTTPClient *http.Client
+	// Regions are the regions to fail over between, Region being ignored when
+	// there are more than one.
+	Regions         []string
+	RegionSelection SESRegionSelection
+	Clock           Clock
 }
 
 type sesMailer struct {
-	sesClient sesMailerClient
+	sesClient            sesMailerClient
+	templateClient       sesTemplateClient
+	accountClient        sesAccountClient
+	configurationSetName string
 }
 
 func newSES(params sesParams) MailerClient {
+	if len(params.Regions) > 1 {
+		regions := newSESRegions(params)
+		return &sesMailer{
+			sesClient:            regions,
+			templateClient:       regions,
+			accountClient:        regions,
+			configurationSetName: params.ConfigurationSetName,
+		}
+	}
+	if len(params.Regions) == 1 {
+		params.Region = params.Regions[0]
+	}
+
+	client := sesv2.NewFromConfig(loadSESConfig(params))
+
+	return &sesMailer{
+		sesClient:            client,
+		templateClient:       client,
+		accountClient:        client,
+		configurationSetName: params.ConfigurationSetName,
+	}
+}
+
+func loadSESConfig(params sesParams) aws.Config {
 	creds := credentials.NewStaticCredentialsProvider(params.Key, params.Secret, "")
-	cfg, err := config.LoadDefaultConfig(
-		context.Background(),
+	options := []func(*config.LoadOptions) error{
 		config.WithRegion(params.Region),
 		config.WithCredentialsProvider(creds),
-	)
+		config.WithAppID(params.AppID),
+	}
+	if params.HTTPClient != nil {
+		options = append(options, config.WithHTTPClient(params.HTTPClient))
+	}
+	cfg, err := config.LoadDefaultConfig(context.Background(), options...)
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	client := sesv2.NewFromConfig(cfg)
-
-	return &sesMailer{sesClient: client}
+	return cfg
 }
 
 func (m *sesMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *sesMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
 	message, _ := buildMessage(msg)
 	mailInput := &sesv2.SendEmailInput{
 		Content: &types.EmailContent{
@@ -55,15 +123,131 @@ func (m *sesMailer) Send(msg Mail) error {
 		},
 	}
 
-	_, err := m.sesClient.SendEmail(context.TODO(), mailInput)
+	configurationSetName := m.configurationSetName
+	if msg.SES != nil && msg.SES.ConfigurationSetName != "" {
+		configurationSetName = msg.SES.ConfigurationSetName
+	}
+	if configurationSetName != "" {
+		mailInput.ConfigurationSetName = aws.String(configurationSetName)
+	}
+	if msg.SES != nil {
+		mailInput.EmailTags = getSESTags(msg.SES.Tags)
+	}
+
+	output, err := m.sesClient.SendEmail(context.TODO(), mailInput)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	response := &ProviderResponse{Provider: AMAZON_SES, StatusCode: http.StatusOK}
+	if output != nil {
+		if requestID, ok := awsmiddleware.GetRequestIDMetadata(output.ResultMetadata); ok {
+			response.RequestID = requestID
+			response.Headers = map[string]string{"X-Amzn-Requestid": requestID}
+		}
+		// The body of the SES API, which the SDK decodes.
+		body, _ := json.Marshal(map[string]*string{"MessageId": output.MessageId})
+		response.Body = string(body)
+	}
+	return response, nil
+}
+
+// SendRaw sends the message as-is, SES adding the headers it requires e.g. the Message-ID.
+func (m *sesMailer) SendRaw(ctx context.Context, from string, rcpts []string, message []byte) error {
+	mailInput := &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(from),
+		Destination:      &types.Destination{ToAddresses: rcpts},
+		Content: &types.EmailContent{
+			Raw: &types.RawMessage{Data: message},
+		},
+	}
+	if m.configurationSetName != "" {
+		mailInput.ConfigurationSetName = aws.String(m.configurationSetName)
+	}
+
+	_, err := m.sesClient.SendEmail(ctx, mailInput)
+	return err
 }
 
 func (m *sesMailer) Close() {
 	// No need to close the connection
 }
+
+// Health verifies the credentials and the region by fetching the SES account.
+func (m *sesMailer) Health(ctx context.Context) error {
+	_, err := m.accountClient.GetAccount(ctx, &sesv2.GetAccountInput{})
+	return err
+}
+
+func (m *sesMailer) CreateTemplate(ctx context.Context, tpl Template) error {
+	_, err := m.templateClient.CreateEmailTemplate(ctx, &sesv2.CreateEmailTemplateInput{
+		TemplateName:    aws.String(tpl.Name),
+		TemplateContent: getSESTemplateContent(tpl),
+	})
+	return err
+}
+
+func (m *sesMailer) UpdateTemplate(ctx context.Context, tpl Template) error {
+	_, err := m.templateClient.UpdateEmailTemplate(ctx, &sesv2.UpdateEmailTemplateInput{
+		TemplateName:    aws.String(tpl.Name),
+		TemplateContent: getSESTemplateContent(tpl),
+	})
+	return err
+}
+
+func (m *sesMailer) DeleteTemplate(ctx context.Context, name string) error {
+	_, err := m.templateClient.DeleteEmailTemplate(ctx, &sesv2.DeleteEmailTemplateInput{
+		TemplateName: aws.String(name),
+	})
+	return err
+}
+
+func getSESTemplateContent(tpl Template) *types.EmailTemplateContent {
+	content := &types.EmailTemplateContent{Subject: aws.String(tpl.Subject)}
+	if tpl.Html != "" {
+		content.Html = aws.String(tpl.Html)
+	}
+	if tpl.Text != "" {
+		content.Text = aws.String(tpl.Text)
+	}
+	return content
+}
+
+// ConfigureSESMailFromDomain sets a custom MAIL FROM domain on an SES identity so
+// that bounces and SPF alignment use a subdomain of the sender domain.
+// The Region, APIKey and APISecret of the configuration are used to connect to SES.
+func ConfigureSESMailFromDomain(ctx context.Context, cfg MailCfg, identity string, mailFromDomain string) error {
+	client := sesv2.NewFromConfig(loadSESConfig(sesParams{
+		Region: cfg.Region,
+		Key:    cfg.APIKey,
+		Secret: cfg.APISecret,
+	}))
+	return configureSESMailFromDomain(ctx, client, identity, mailFromDomain)
+}
+
+func configureSESMailFromDomain(ctx context.Context, client sesIdentityClient, identity string, mailFromDomain string) error {
+	_, err := client.PutEmailIdentityMailFromAttributes(ctx, &sesv2.PutEmailIdentityMailFromAttributesInput{
+		EmailIdentity:       aws.String(identity),
+		MailFromDomain:      aws.String(mailFromDomain),
+		BehaviorOnMxFailure: types.BehaviorOnMxFailureUseDefaultValue,
+	})
+	return err
+}
+
+func getSESTags(tags map[string]string) []types.MessageTag {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var messageTags []types.MessageTag
+	for _, name := range names {
+		messageTags = append(messageTags, types.MessageTag{
+			Name:  aws.String(name),
+			Value: aws.String(tags[name]),
+		})
+	}
+	return messageTags
+}
@@ -0,0 +1,252 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+type snsMessage struct {
+	Type             string `json:"Type"`
+	MessageId        string `json:"MessageId"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	Token            string `json:"Token"`
+	SubscribeURL     string `json:"SubscribeURL"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+}
+
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Mail             struct {
+		MessageId string `json:"messageId"`
+	} `json:"mail"`
+	Bounce *struct {
+		BounceType        string    `json:"bounceType"`
+		BounceSubType     string    `json:"bounceSubType"`
+		Timestamp         time.Time `json:"timestamp"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint *struct {
+		ComplaintFeedbackType string    `json:"complaintFeedbackType"`
+		Timestamp             time.Time `json:"timestamp"`
+		ComplainedRecipients  []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+	} `json:"complaint"`
+	Delivery *struct {
+		Timestamp    time.Time `json:"timestamp"`
+		SmtpResponse string    `json:"smtpResponse"`
+		Recipients   []string  `json:"recipients"`
+	} `json:"delivery"`
+}
+
+// ParseSESNotification parses an SNS notification carrying an Amazon SES bounce,
+// complaint or delivery notification into events, one per recipient.
+// Both SES feedback notifications and configuration set event publishing are supported.
+func ParseSESNotification(body []byte) ([]Event, error) {
+	var envelope snsMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid SNS message: %w", err)
+	}
+	if envelope.Type != "Notification" {
+		return nil, fmt.Errorf("unexpected SNS message type %q", envelope.Type)
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+		return nil, fmt.Errorf("invalid SES notification: %w", err)
+	}
+
+	notificationType := notification.NotificationType
+	if notificationType == "" {
+		notificationType = notification.EventType
+	}
+
+	var events []Event
+	switch notificationType {
+	case "Bounce":
+		if notification.Bounce == nil {
+			return nil, fmt.Errorf("missing bounce details")
+		}
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			events = append(events, Event{
+				Type:      EventBounced,
+				Provider:  AMAZON_SES,
+				MessageID: notification.Mail.MessageId,
+				Recipient: recipient.EmailAddress,
+				Timestamp: notification.Bounce.Timestamp,
+				Reason:    recipient.DiagnosticCode,
+				Permanent: notification.Bounce.BounceType == "Permanent",
+			})
+		}
+	case "Complaint":
+		if notification.Complaint == nil {
+			return nil, fmt.Errorf("missing complaint details")
+		}
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			events = append(events, Event{
+				Type:      EventComplained,
+				Provider:  AMAZON_SES,
+				MessageID: notification.Mail.MessageId,
+				Recipient: recipient.EmailAddress,
+				Timestamp: notification.Complaint.Timestamp,
+				Reason:    notification.Complaint.ComplaintFeedbackType,
+			})
+		}
+	case "Delivery":
+		if notification.Delivery == nil {
+			return nil, fmt.Errorf("missing delivery details")
+		}
+		for _, recipient := range notification.Delivery.Recipients {
+			events = append(events, Event{
+				Type:      EventDelivered,
+				Provider:  AMAZON_SES,
+				MessageID: notification.Mail.MessageId,
+				Recipient: recipient,
+				Timestamp: notification.Delivery.Timestamp,
+				Reason:    notification.Delivery.SmtpResponse,
+			})
+		}
+	}
+
+	return events, nil
+}
+
+// SESEventHandlerOptions configures the handler of NewSESEventHandlerWithOptions.
+type SESEventHandlerOptions struct {
+	// TopicARNs are the SNS topics accepted by the handler, every topic is
+	// accepted when empty.
+	TopicARNs []string
+	// Verifier verifies the signatures of the SNS messages when set.
+	Verifier *SNSVerifier
+	// Replay ignores the SNS messages already handled, by MessageId and Timestamp.
+	Replay *ReplayGuard
+	// HTTPClient confirms the SNS subscriptions. Defaults to a client with a 10 seconds timeout.
+	HTTPClient *http.Client
+}
+
+// NewSESEventHandler returns an http.Handler to mount as the endpoint of an SNS
+// subscription. Every event parsed from the notifications is passed to handle.
+// Subscription requests are confirmed automatically.
+func NewSESEventHandler(handle func(Event) error) http.Handler {
+	return NewSESEventHandlerWithOptions(handle, SESEventHandlerOptions{})
+}
+
+// NewSESEventHandlerWithOptions is like NewSESEventHandler with options.
+func NewSESEventHandlerWithOptions(handle func(Event) error, opts SESEventHandlerOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var envelope snsMessage
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			http.Error(w, fmt.Sprintf("invalid SNS message: %v", err), http.StatusBadRequest)
+			return
+		}
+		if len(opts.TopicARNs) > 0 && !slices.Contains(opts.TopicARNs, envelope.TopicArn) {
+			http.Error(w, fmt.Sprintf("topic %q is not allowed", envelope.TopicArn), http.StatusForbidden)
+			return
+		}
+		if opts.Verifier != nil {
+			if err := opts.Verifier.verify(r.Context(), envelope); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+		}
+
+		switch envelope.Type {
+		case "SubscriptionConfirmation":
+			if err := confirmSNSSubscription(r.Context(), opts.HTTPClient, envelope.SubscribeURL); err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		case "UnsubscribeConfirmation":
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		events, err := ParseSESNotification(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var replayID string
+		if opts.Replay != nil {
+			timestamp, _ := time.Parse(time.RFC3339, envelope.Timestamp)
+			replayID = "sns:" + envelope.MessageId
+			if err := opts.Replay.Check(r.Context(), replayID, timestamp); err != nil {
+				if errors.Is(err, ErrReplayedWebhook) {
+					// Acknowledge replays so that they are not delivered again.
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		for _, event := range events {
+			if err := handle(event); err != nil {
+				if opts.Replay != nil {
+					opts.Replay.Forget(r.Context(), replayID)
+				}
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// confirmSNSSubscription visits the SubscribeURL of a subscription confirmation,
+// which must be an SNS endpoint.
+func confirmSNSSubscription(ctx context.Context, client *http.Client, subscribeURL string) error {
+	u, err := url.Parse(subscribeURL)
+	if err != nil || u.Scheme != "https" || !snsHost.MatchString(u.Host) {
+		return fmt.Errorf("untrusted SNS subscribe url %q", subscribeURL)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, subscribeURL, nil)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to confirm SNS subscription: status code %d", res.StatusCode)
+	}
+	return nil
+}
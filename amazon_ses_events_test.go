@@ -0,0 +1,258 @@
+package mailer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func snsNotification(t *testing.T, message string) string {
+	t.Helper()
+	body, err := json.Marshal(map[string]string{
+		"Type":      "Notification",
+		"MessageId": "sns-id",
+		"TopicArn":  "arn:aws:sns:us-west-2:123456789012:ses-feedback",
+		"Message":   message,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestParseSESNotification(t *testing.T) {
+	testCases := []struct {
+		name      string
+		body      string
+		expected  []Event
+		expectErr bool
+	}{
+		{
+			name: "bounce notification",
+			body: snsNotification(t, `{"notificationType":"Bounce","mail":{"messageId":"ses-1"},"bounce":{"bounceType":"Permanent","timestamp":"2024-05-01T10:00:00Z","bouncedRecipients":[{"emailAddress":"a@test.com","diagnosticCode":"550 user unknown"}]}}`),
+			expected: []Event{
+				{Type: EventBounced, Provider: AMAZON_SES, MessageID: "ses-1", Recipient: "a@test.com", Reason: "550 user unknown", Permanent: true},
+			},
+		},
+		{
+			name: "complaint event publishing",
+			body: snsNotification(t, `{"eventType":"Complaint","mail":{"messageId":"ses-2"},"complaint":{"complaintFeedbackType":"abuse","timestamp":"2024-05-01T10:00:00Z","complainedRecipients":[{"emailAddress":"b@test.com"}]}}`),
+			expected: []Event{
+				{Type: EventComplained, Provider: AMAZON_SES, MessageID: "ses-2", Recipient: "b@test.com", Reason: "abuse"},
+			},
+		},
+		{
+			name: "delivery notification",
+			body: snsNotification(t, `{"notificationType":"Delivery","mail":{"messageId":"ses-3"},"delivery":{"timestamp":"2024-05-01T10:00:00Z","smtpResponse":"250 ok","recipients":["c@test.com","d@test.com"]}}`),
+			expected: []Event{
+				{Type: EventDelivered, Provider: AMAZON_SES, MessageID: "ses-3", Recipient: "c@test.com", Reason: "250 ok"},
+				{Type: EventDelivered, Provider: AMAZON_SES, MessageID: "ses-3", Recipient: "d@test.com", Reason: "250 ok"},
+			},
+		},
+		{
+			name:      "invalid body",
+			body:      "not json",
+			expectErr: true,
+		},
+		{
+			name:      "missing bounce details",
+			body:      snsNotification(t, `{"notificationType":"Bounce","mail":{"messageId":"ses-4"}}`),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			events, err := ParseSESNotification([]byte(tc.body))
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(events) != len(tc.expected) {
+				t.Fatalf("Expected %d events, got %d", len(tc.expected), len(events))
+			}
+			for i, event := range events {
+				expected := tc.expected[i]
+				if event.Type != expected.Type || event.MessageID != expected.MessageID ||
+					event.Recipient != expected.Recipient || event.Reason != expected.Reason ||
+					event.Permanent != expected.Permanent || event.Provider != expected.Provider {
+					t.Errorf("Expected event %+v, got %+v", expected, event)
+				}
+				if event.Timestamp.IsZero() {
+					t.Errorf("Expected event timestamp to be set")
+				}
+			}
+		})
+	}
+}
+
+func TestSESEventHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		handleErr  error
+		statusCode int
+		events     int
+	}{
+		{
+			name:       "handles events",
+			body:       snsNotification(t, `{"notificationType":"Delivery","mail":{"messageId":"ses-1"},"delivery":{"recipients":["c@test.com"]}}`),
+			statusCode: http.StatusOK,
+			events:     1,
+		},
+		{
+			name:       "rejects invalid notifications",
+			body:       "{}",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "reports handler errors",
+			body:       snsNotification(t, `{"notificationType":"Delivery","mail":{"messageId":"ses-1"},"delivery":{"recipients":["c@test.com"]}}`),
+			handleErr:  errors.New("database down"),
+			statusCode: http.StatusInternalServerError,
+			events:     1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var events []Event
+			handler := NewSESEventHandler(func(event Event) error {
+				events = append(events, event)
+				return tc.handleErr
+			})
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ses", strings.NewReader(tc.body)))
+
+			if rec.Code != tc.statusCode {
+				t.Errorf("Expected status %d, got %d", tc.statusCode, rec.Code)
+			}
+			if len(events) != tc.events {
+				t.Errorf("Expected %d events, got %d", tc.events, len(events))
+			}
+		})
+	}
+}
+
+func TestSESEventHandlerReplay(t *testing.T) {
+	var events []Event
+	failing := true
+	handler := NewSESEventHandlerWithOptions(func(event Event) error {
+		events = append(events, event)
+		if failing {
+			return errors.New("database down")
+		}
+		return nil
+	}, SESEventHandlerOptions{Replay: &ReplayGuard{}})
+
+	body := snsNotification(t, `{"notificationType":"Bounce","mail":{"messageId":"ses-1"},"bounce":{"bounceType":"Permanent","bouncedRecipients":[{"emailAddress":"b@test.com"}]}}`)
+	post := func() int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ses", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	if code := post(); code != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500, got %d", code)
+	}
+	failing = false
+	if code := post(); code != http.StatusOK {
+		t.Fatalf("Expected a failed callback to be retried, got %d", code)
+	}
+	if code := post(); code != http.StatusOK {
+		t.Fatalf("Expected the replay to be acknowledged, got %d", code)
+	}
+	if len(events) != 2 {
+		t.Errorf("Expected the replayed callback not to be handled, got %d events", len(events))
+	}
+}
+
+func TestSESEventHandlerSubscription(t *testing.T) {
+	subscribeURL := "https://sns.us-west-2.amazonaws.com/?Action=ConfirmSubscription&Token=abc"
+	confirmed := 0
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != subscribeURL {
+			t.Errorf("Unexpected subscribe URL %s", req.URL)
+		}
+		confirmed++
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})}
+
+	sign, verifier := newTestSNSSigner(t)
+	message := func(topicArn string, subscribeURL string) string {
+		msg := snsMessage{
+			Type:         "SubscriptionConfirmation",
+			MessageId:    "sub-1",
+			TopicArn:     topicArn,
+			Message:      "You have chosen to subscribe to the topic",
+			Timestamp:    "2024-01-01T00:00:00.000Z",
+			Token:        "abc",
+			SubscribeURL: subscribeURL,
+		}
+		sign(&msg)
+		body, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	handler := NewSESEventHandlerWithOptions(func(event Event) error {
+		t.Errorf("Unexpected event %+v", event)
+		return nil
+	}, SESEventHandlerOptions{
+		TopicARNs:  []string{"arn:aws:sns:us-west-2:123456789012:ses-feedback"},
+		Verifier:   verifier,
+		HTTPClient: client,
+	})
+
+	testCases := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "confirms subscriptions",
+			body:       message("arn:aws:sns:us-west-2:123456789012:ses-feedback", subscribeURL),
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "rejects topics not allowed",
+			body:       message("arn:aws:sns:us-west-2:123456789012:other", subscribeURL),
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "rejects unsigned messages",
+			body:       `{"Type":"SubscriptionConfirmation","TopicArn":"arn:aws:sns:us-west-2:123456789012:ses-feedback"}`,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "refuses subscribe urls outside SNS",
+			body:       message("arn:aws:sns:us-west-2:123456789012:ses-feedback", "https://evil.example.com/confirm"),
+			statusCode: http.StatusBadGateway,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ses", strings.NewReader(tc.body)))
+			if rec.Code != tc.statusCode {
+				t.Errorf("Expected status %d, got %d", tc.statusCode, rec.Code)
+			}
+		})
+	}
+
+	if confirmed != 1 {
+		t.Errorf("Expected one subscription confirmation, got %d", confirmed)
+	}
+}
@@ -47,8 +47,8 @@ func TestAWSSes_Send(t *testing.T) {
 			name: "Should send email successfully",
 			payload: Mail{
 				Subject: "test",
-				From:    "info@test.com",
-				To:      "test@gmail.com",
+				From:    Address{Email: "info@test.com"},
+				To:      Addresses("test@gmail.com"),
 				Html:    "<p>test</p>",
 				Text:    "test",
 			},
@@ -95,6 +95,133 @@ func TestAWSSes_Send(t *testing.T) {
 	}
 }
 
+func TestAWSSes_SendWithConfigurationSetAndTags(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		configurationSetName string
+		payload              Mail
+		expectedSet          string
+		expectedTags         int
+	}{
+		{
+			name:                 "Should use the mailer configuration set",
+			configurationSetName: "default-set",
+			payload:              Mail{From: Address{Email: "info@test.com"}, To: Addresses("test@gmail.com"), Text: "test"},
+			expectedSet:          "default-set",
+		},
+		{
+			name:                 "Should override the configuration set and add tags",
+			configurationSetName: "default-set",
+			payload: Mail{
+				From: Address{Email: "info@test.com"},
+				To:   Addresses("test@gmail.com"),
+				Text: "test",
+				SES: &SESOptions{
+					ConfigurationSetName: "marketing-set",
+					Tags:                 map[string]string{"campaign": "launch", "kind": "newsletter"},
+				},
+			},
+			expectedSet:  "marketing-set",
+			expectedTags: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var input *sesv2.SendEmailInput
+			ses := &sesMailer{
+				configurationSetName: tc.configurationSetName,
+				sesClient: &mockSESClient{
+					SendEmailFunc: func(ctx context.Context, params *sesv2.SendEmailInput) (*sesv2.SendEmailOutput, error) {
+						input = params
+						return &sesv2.SendEmailOutput{}, nil
+					},
+				},
+			}
+
+			if err := ses.Send(tc.payload); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if aws.ToString(input.ConfigurationSetName) != tc.expectedSet {
+				t.Errorf("Expected configuration set %q, got %q", tc.expectedSet, aws.ToString(input.ConfigurationSetName))
+			}
+			if len(input.EmailTags) != tc.expectedTags {
+				t.Errorf("Expected %d tags, got %d", tc.expectedTags, len(input.EmailTags))
+			}
+		})
+	}
+}
+
+func TestAWSSes_ConfigureMailFromDomain(t *testing.T) {
+	client := &mockSESIdentityClient{}
+
+	err := configureSESMailFromDomain(context.Background(), client, "example.com", "mail.example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if aws.ToString(client.input.EmailIdentity) != "example.com" {
+		t.Errorf("Expected identity example.com, got %s", aws.ToString(client.input.EmailIdentity))
+	}
+	if aws.ToString(client.input.MailFromDomain) != "mail.example.com" {
+		t.Errorf("Expected MAIL FROM domain mail.example.com, got %s", aws.ToString(client.input.MailFromDomain))
+	}
+}
+
+func TestAWSSes_TemplateManagement(t *testing.T) {
+	client := &mockSESTemplateClient{}
+	ses := &sesMailer{templateClient: client}
+
+	ctx := context.Background()
+	tpl := Template{Name: "welcome", Subject: "Welcome", Html: "<p>Hi</p>"}
+	if err := ses.CreateTemplate(ctx, tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := ses.UpdateTemplate(ctx, tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := ses.DeleteTemplate(ctx, tpl.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"create:welcome", "update:welcome", "delete:welcome"}
+	if len(client.calls) != len(expected) {
+		t.Fatalf("Expected calls %v, got %v", expected, client.calls)
+	}
+	for i := range expected {
+		if client.calls[i] != expected[i] {
+			t.Errorf("Expected calls %v, got %v", expected, client.calls)
+		}
+	}
+}
+
+type mockSESTemplateClient struct {
+	calls []string
+}
+
+func (m *mockSESTemplateClient) CreateEmailTemplate(ctx context.Context, params *sesv2.CreateEmailTemplateInput, optFns ...func(*sesv2.Options)) (*sesv2.CreateEmailTemplateOutput, error) {
+	m.calls = append(m.calls, "create:"+aws.ToString(params.TemplateName))
+	return &sesv2.CreateEmailTemplateOutput{}, nil
+}
+
+func (m *mockSESTemplateClient) UpdateEmailTemplate(ctx context.Context, params *sesv2.UpdateEmailTemplateInput, optFns ...func(*sesv2.Options)) (*sesv2.UpdateEmailTemplateOutput, error) {
+	m.calls = append(m.calls, "update:"+aws.ToString(params.TemplateName))
+	return &sesv2.UpdateEmailTemplateOutput{}, nil
+}
+
+func (m *mockSESTemplateClient) DeleteEmailTemplate(ctx context.Context, params *sesv2.DeleteEmailTemplateInput, optFns ...func(*sesv2.Options)) (*sesv2.DeleteEmailTemplateOutput, error) {
+	m.calls = append(m.calls, "delete:"+aws.ToString(params.TemplateName))
+	return &sesv2.DeleteEmailTemplateOutput{}, nil
+}
+
+type mockSESIdentityClient struct {
+	input *sesv2.PutEmailIdentityMailFromAttributesInput
+}
+
+func (m *mockSESIdentityClient) PutEmailIdentityMailFromAttributes(ctx context.Context, params *sesv2.PutEmailIdentityMailFromAttributesInput, optFns ...func(*sesv2.Options)) (*sesv2.PutEmailIdentityMailFromAttributesOutput, error) {
+	m.input = params
+	return &sesv2.PutEmailIdentityMailFromAttributesOutput{}, nil
+}
+
 type mockSESClient struct {
 	SendEmailFunc func(ctx context.Context, input *sesv2.SendEmailInput) (*sesv2.SendEmailOutput, error)
 }
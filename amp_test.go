@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuildMessage_AMP(t *testing.T) {
+	message, email := buildMessage(Mail{
+		From:    Address{Email: "info@test.com"},
+		To:      Addresses("a@test.com"),
+		Text:    "text",
+		AmpHtml: "<html amp4email>amp</html>",
+		Html:    "<p>html</p>",
+	})
+	if email.Error != nil {
+		t.Fatal(email.Error)
+	}
+
+	text := strings.Index(message, "Content-Type: text/plain")
+	amp := strings.Index(message, "Content-Type: text/x-amp-html")
+	html := strings.Index(message, "Content-Type: text/html")
+	if text < 0 || amp < text || html < amp {
+		t.Errorf("Expected the text, AMP and html parts in order, got %s", message)
+	}
+}
+
+func TestMailer_SendAMPWithoutHtml(t *testing.T) {
+	mailer := NewMailer(MailCfg{mailerClient: &mockMailerClient{}})
+	defer mailer.Close()
+
+	err := mailer.Send(Mail{To: Addresses("a@test.com"), AmpHtml: "<html amp4email></html>"})
+	if !errors.Is(err, ErrAMPWithoutHtml) {
+		t.Errorf("Expected ErrAMPWithoutHtml, got %v", err)
+	}
+}
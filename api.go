@@ -0,0 +1,201 @@
+package mailer
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxAPIMessageSize is the maximum size of the JSON email posted to the HTTP API,
+// which carries no attachments.
+const maxAPIMessageSize = 10 << 20
+
+// apiMail is the JSON representation of an email in the HTTP API. Attachments
+// are not supported, as their paths would be read from the filesystem of the server.
+type apiMail struct {
+	To        string    `json:"to"`
+	From      string    `json:"from"`
+	Html      string    `json:"html"`
+	Text      string    `json:"text"`
+	AmpHtml   string    `json:"amp_html"`
+	Subject   string    `json:"subject"`
+	Preheader string    `json:"preheader"`
+	Cc        string    `json:"cc"`
+	Bcc       string    `json:"bcc"`
+	ReplyTo   string    `json:"reply_to"`
+	Category  string    `json:"category"`
+	Class     string    `json:"class"`
+	Tags      []string  `json:"tags"`
+	TenantID  string    `json:"tenant_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ErrInvalidMail is returned when a JSON encoded email cannot be decoded.
+var ErrInvalidMail = errors.New("invalid mail")
+
+// DecodeMailJSON decodes an email encoded as JSON, as accepted by APIHandler, e.g.
+// to send the emails published on a message broker:
+//
+//	{"to": "a@example.com", "subject": "Hello", "text": "Hello", "tags": ["welcome"]}
+func DecodeMailJSON(data []byte) (Mail, error) {
+	var msg apiMail
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return Mail{}, fmt.Errorf("%w: %v", ErrInvalidMail, err)
+	}
+
+	email := Mail{
+		Html:      msg.Html,
+		Text:      msg.Text,
+		AmpHtml:   msg.AmpHtml,
+		Subject:   msg.Subject,
+		Preheader: msg.Preheader,
+		Category:  msg.Category,
+		Class:     MessageClass(msg.Class),
+		Tags:      msg.Tags,
+		TenantID:  msg.TenantID,
+		ExpiresAt: msg.ExpiresAt,
+	}
+	for _, field := range []struct {
+		value string
+		set   func(string) error
+	}{{msg.To, email.SetTo}, {msg.From, email.SetFrom}, {msg.Cc, email.SetCc}, {msg.Bcc, email.SetBcc}, {msg.ReplyTo, email.SetReplyTo}} {
+		if strings.TrimSpace(field.value) == "" {
+			continue
+		}
+		if err := field.set(field.value); err != nil {
+			return Mail{}, fmt.Errorf("%w: %v", ErrInvalidMail, err)
+		}
+	}
+	if len(allRecipients(email)) == 0 {
+		return Mail{}, fmt.Errorf("%w: no recipients", ErrInvalidMail)
+	}
+	return email, nil
+}
+
+// EncodeMailJSON encodes an email as JSON, as decoded by DecodeMailJSON e.g. to
+// enqueue it on a job queue. Emails with attachments cannot be encoded.
+func EncodeMailJSON(msg Mail) ([]byte, error) {
+	if len(msg.Attachments) > 0 {
+		return nil, fmt.Errorf("%w: attachments cannot be encoded", ErrInvalidMail)
+	}
+	return json.Marshal(apiMail{
+		To:        joinAddresses(msg.To),
+		From:      msg.From.String(),
+		Html:      msg.Html,
+		Text:      msg.Text,
+		AmpHtml:   msg.AmpHtml,
+		Subject:   msg.Subject,
+		Preheader: msg.Preheader,
+		Cc:        joinAddresses(msg.Cc),
+		Bcc:       joinAddresses(msg.Bcc),
+		ReplyTo:   msg.ReplyTo.String(),
+		Category:  msg.Category,
+		Class:     string(msg.Class),
+		Tags:      msg.Tags,
+		TenantID:  msg.TenantID,
+		ExpiresAt: msg.ExpiresAt,
+	})
+}
+
+type apiStatus struct {
+	ID        string             `json:"id"`
+	State     MessageState       `json:"state"`
+	Error     string             `json:"error,omitempty"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Responses []ProviderResponse `json:"responses,omitempty"`
+}
+
+// APIHandler returns an http.Handler exposing the mailer as an internal mail service:
+//
+//	POST /messages              queues an email and responds with its id
+//	GET  /messages/{id}         responds with the status of an email
+//	POST /messages/{id}/cancel  cancels a queued email
+//
+// Requests must carry one of the apiKeys as a bearer token or in the X-API-Key header.
+// The emails posted are limited to 10 MiB.
+func (m *Mailer) APIHandler(apiKeys ...string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /messages", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAPIMessageSize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeAPIError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("message larger than %d bytes", tooLarge.Limit))
+			return
+		}
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		msg, err := DecodeMailJSON(body)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		id, err := m.Enqueue(msg)
+		if err != nil {
+			writeAPIError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeAPIJSON(w, http.StatusAccepted, map[string]string{"id": id})
+	})
+
+	mux.HandleFunc("GET /messages/{id}", func(w http.ResponseWriter, r *http.Request) {
+		status, ok := m.Status(r.PathValue("id"))
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "message not found")
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, apiStatus(status))
+	})
+
+	mux.HandleFunc("POST /messages/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if !m.Cancel(r.PathValue("id")) {
+			writeAPIError(w, http.StatusConflict, "message is not queued")
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, map[string]bool{"canceled": true})
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validAPIKey(r, apiKeys) {
+			writeAPIError(w, http.StatusUnauthorized, "invalid API key")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func validAPIKey(r *http.Request, apiKeys []string) bool {
+	key := r.Header.Get("X-API-Key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		key = bearer
+	}
+	if key == "" {
+		return false
+	}
+
+	valid := false
+	for _, apiKey := range apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+func writeAPIJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeAPIError(w http.ResponseWriter, statusCode int, message string) {
+	writeAPIJSON(w, statusCode, map[string]string{"error": message})
+}
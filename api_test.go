@@ -0,0 +1,99 @@
+package mailer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMailer_APIHandler(t *testing.T) {
+	release := make(chan struct{})
+	mailer := NewMailer(MailCfg{
+		APIService: RESEND,
+		APIKey:     MailAPIKey,
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			<-release
+			return nil
+		}},
+	})
+	defer mailer.Close()
+	defer close(release)
+
+	handler := mailer.APIHandler("secret")
+	do := func(method string, path string, body string, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodPost, "/messages", `{"to":"a@test.com"}`, "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/messages", `{"subject":"no recipients"}`, "secret"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/messages", `{"to":"a@test.com","text":"`+strings.Repeat("a", maxAPIMessageSize)+`"}`, "secret"); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", rec.Code)
+	}
+
+	// The first email blocks the provider, so the second one stays queued.
+	do(http.MethodPost, "/messages", `{"to":"a@test.com","subject":"first"}`, "secret")
+	rec := do(http.MethodPost, "/messages", `{"to":"b@test.com","subject":"second","tags":["welcome"]}`, "secret")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", rec.Code, rec.Body)
+	}
+	var created struct{ ID string }
+	json.NewDecoder(rec.Body).Decode(&created)
+
+	rec = do(http.MethodGet, "/messages/"+created.ID, "", "secret")
+	var status apiStatus
+	json.NewDecoder(rec.Body).Decode(&status)
+	if rec.Code != http.StatusOK || status.State != MessageQueued {
+		t.Errorf("Expected a queued message, got %d %+v", rec.Code, status)
+	}
+
+	if rec := do(http.MethodPost, "/messages/"+created.ID+"/cancel", "", "secret"); rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/messages/"+created.ID+"/cancel", "", "secret"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/messages/unknown", "", "secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestDecodeMailJSON(t *testing.T) {
+	msg, err := DecodeMailJSON([]byte(`{"to":"a@test.com","subject":"Hello","tags":["welcome"],"expires_at":"2030-01-01T00:00:00Z"}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if joinAddresses(msg.To) != "a@test.com" || msg.Subject != "Hello" || len(msg.Tags) != 1 || msg.ExpiresAt.Year() != 2030 {
+		t.Errorf("Unexpected mail %+v", msg)
+	}
+
+	for _, data := range []string{`not json`, `{"subject":"Hello"}`} {
+		if _, err := DecodeMailJSON([]byte(data)); !IsPermanentError(err) {
+			t.Errorf("Expected a permanent ErrInvalidMail for %s, got %v", data, err)
+		}
+	}
+}
+
+func TestMailer_APIHandlerClosed(t *testing.T) {
+	mailer := NewMailer(MailCfg{APIService: RESEND, APIKey: MailAPIKey, mailerClient: &mockMailerClient{}})
+	mailer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/messages", strings.NewReader(`{"to":"a@test.com"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	mailer.APIHandler("secret").ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", rec.Code)
+	}
+}
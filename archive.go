@@ -0,0 +1,86 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// archive copies a sent email to the archive address, writer and store.
+// Archiving failures are logged and never fail the delivery of the email.
+func (m *Mailer) archive(d delivery, msg Mail) {
+	id := d.id
+	if m.archiveAddress != "" {
+		m.journal(d, msg)
+	}
+
+	if m.archiveWriter == nil && m.archiveStore == nil {
+		return
+	}
+
+	message, _ := buildMessage(msg)
+
+	if m.archiveWriter != nil {
+		m.archiveMu.Lock()
+		_, err := fmt.Fprintf(m.archiveWriter, "%s\r\n", message)
+		m.archiveMu.Unlock()
+		if err != nil {
+			log.Printf("mailer: failed to write archive of %s: %v", id, err)
+		}
+	}
+
+	if m.archiveStore != nil {
+		archived := ArchivedMessage{ID: id, Raw: []byte(message), SentAt: m.clock.Now(), Recipients: envelopeRecipients(msg)}
+		retention := m.retention.Archives
+		if m.archiveRetention != nil {
+			if policy := m.archiveRetention(msg); policy > 0 {
+				retention = policy
+			}
+		}
+		if retention > 0 {
+			archived.RetainUntil = archived.SentAt.Add(retention)
+		}
+		if err := m.archiveStore.Put(context.Background(), archived); err != nil {
+			log.Printf("mailer: failed to store archive of %s: %v", id, err)
+		}
+	}
+}
+
+// journal sends the copy of an email to the archive address with the client
+// that sent it, within its concurrency limit and unless it is rate limited. The
+// Cc and Bcc are stripped from the copy, all the envelope recipients are kept
+// in the X-Envelope-To header.
+func (m *Mailer) journal(d delivery, msg Mail) {
+	journal := withHeader(msg, "X-Envelope-To", strings.Join(envelopeRecipients(msg), ", "))
+	journal.To = []Address{toAddress(m.archiveAddress)}
+	journal.Cc = nil
+	journal.Bcc = nil
+
+	if until, ok := m.rateLimits.pausedUntil(d.client, m.clock.Now()); ok {
+		log.Printf("mailer: failed to archive %s to %s: %s is rate limited until %s", d.id, m.archiveAddress, d.provider, until.Format(time.RFC3339))
+		return
+	}
+	release, err := m.sendLimiter.acquire(context.Background(), d.provider)
+	if err != nil {
+		log.Printf("mailer: failed to archive %s to %s: %v", d.id, m.archiveAddress, err)
+		return
+	}
+	err = d.client.Send(journal)
+	release()
+	if err != nil {
+		m.rateLimited(d.client, err)
+		log.Printf("mailer: failed to archive %s to %s: %v", d.id, m.archiveAddress, err)
+	}
+}
+
+// envelopeRecipients returns the lowercased addresses of the To, Cc and Bcc
+// recipients of the email.
+func envelopeRecipients(msg Mail) []string {
+	var recipients []string
+	for _, addr := range allRecipients(msg) {
+		recipients = append(recipients, strings.ToLower(addr.Email))
+	}
+	return recipients
+}
@@ -0,0 +1,119 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// mboxDateLayout is the asctime layout of the date of the "From " separator lines.
+const mboxDateLayout = "Mon Jan _2 15:04:05 2006"
+
+// ExportMbox writes every message of the store to w in the mboxrd format, oldest
+// first, and returns the number of messages written. The lines of the messages
+// starting with "From " are quoted with a ">" so that they are not mistaken for
+// separators, and the line endings are converted to LF as mail clients expect.
+func ExportMbox(ctx context.Context, store ListableArchiveStore, w io.Writer) (int, error) {
+	messages, err := store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, msg := range messages {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		if err := writeMboxMessage(bw, msg); err != nil {
+			return i, err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	return len(messages), nil
+}
+
+func writeMboxMessage(w *bufio.Writer, msg ArchivedMessage) error {
+	if _, err := fmt.Fprintf(w, "From %s %s\n", getArchiveSender(msg.Raw), msg.SentAt.UTC().Format(mboxDateLayout)); err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimRight(toLF(msg.Raw), "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+			line = ">" + line
+		}
+		if _, err := w.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	// A blank line ends the message.
+	_, err := w.WriteString("\n")
+	return err
+}
+
+// getArchiveSender returns the envelope sender of the "From " line, the address of
+// the From header of the message or MAILER-DAEMON when it has none.
+func getArchiveSender(raw []byte) string {
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return "MAILER-DAEMON"
+	}
+	from, err := mail.ParseAddress(parsed.Header.Get("From"))
+	if err != nil {
+		return "MAILER-DAEMON"
+	}
+	return from.Address
+}
+
+// ExportMaildir writes every message of the store to the Maildir at dir, creating
+// it when missing, and returns the number of messages written. The messages are
+// delivered to cur flagged as seen, named after their id so that exporting again
+// overwrites them instead of duplicating them, and dated by their sending time.
+func ExportMaildir(ctx context.Context, store ListableArchiveStore, dir string) (int, error) {
+	messages, err := store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return 0, err
+		}
+	}
+
+	for i, msg := range messages {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		if err := writeMaildirMessage(dir, msg); err != nil {
+			return i, err
+		}
+	}
+	return len(messages), nil
+}
+
+func writeMaildirMessage(dir string, msg ArchivedMessage) error {
+	// Maildir file names must not contain "/" nor ":", which separates the flags.
+	name := fmt.Sprintf("%d.%s.mailer", msg.SentAt.Unix(), strings.NewReplacer("/", "_", ":", "_").Replace(msg.ID))
+
+	// The message is written to tmp then moved, so that a reader never sees a partial file.
+	tmp := filepath.Join(dir, "tmp", name)
+	if err := os.WriteFile(tmp, []byte(toLF(msg.Raw)), 0o600); err != nil {
+		return err
+	}
+	if !msg.SentAt.IsZero() {
+		if err := os.Chtimes(tmp, msg.SentAt, msg.SentAt); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, filepath.Join(dir, "cur", name+":2,S"))
+}
+
+func toLF(raw []byte) string {
+	return strings.ReplaceAll(string(raw), "\r\n", "\n")
+}
@@ -0,0 +1,105 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newExportArchiveStore(t *testing.T) *MemoryArchiveStore {
+	store := NewMemoryArchiveStore()
+	messages := []ArchivedMessage{
+		{
+			ID:     "b",
+			Raw:    []byte("From: Billing <billing@test.com>\r\nTo: a@test.com\r\nSubject: Invoice\r\n\r\nHello\r\nFrom now on, pay online.\r\n>From the team\r\n"),
+			SentAt: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:     "a",
+			Raw:    []byte("To: a@test.com\r\nSubject: Welcome\r\n\r\nWelcome\r\n"),
+			SentAt: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, msg := range messages {
+		if err := store.Put(context.Background(), msg); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	return store
+}
+
+func TestExportMbox(t *testing.T) {
+	store := newExportArchiveStore(t)
+
+	var out bytes.Buffer
+	n, err := ExportMbox(context.Background(), store, &out)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 messages to be exported, got %d", n)
+	}
+
+	expected := "From MAILER-DAEMON Wed May  1 09:30:00 2024\n" +
+		"To: a@test.com\nSubject: Welcome\n\nWelcome\n\n" +
+		"From billing@test.com Thu May  2 10:00:00 2024\n" +
+		"From: Billing <billing@test.com>\nTo: a@test.com\nSubject: Invoice\n\nHello\n>From now on, pay online.\n>>From the team\n\n"
+	if out.String() != expected {
+		t.Errorf("Expected the mbox\n%q\ngot\n%q", expected, out.String())
+	}
+}
+
+func TestExportMaildir(t *testing.T) {
+	store := newExportArchiveStore(t)
+	dir := filepath.Join(t.TempDir(), "Archive")
+
+	for i := 0; i < 2; i++ {
+		n, err := ExportMaildir(context.Background(), store, dir)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if n != 2 {
+			t.Errorf("Expected 2 messages to be exported, got %d", n)
+		}
+	}
+
+	for _, sub := range []string{"tmp", "new"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			t.Fatalf("Expected the %s directory to be created, got %v", sub, err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("Expected %s to be empty, got %d files", sub, len(entries))
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "cur"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected exporting again to overwrite the messages, got %d files", len(entries))
+	}
+
+	name := entries[0].Name()
+	if name != "1714555800.a.mailer:2,S" {
+		t.Errorf("Expected the message to be named after its id and flagged as seen, got %s", name)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "cur", name))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(content) != "To: a@test.com\nSubject: Welcome\n\nWelcome\n" {
+		t.Errorf("Expected the message with LF line endings, got %q", content)
+	}
+	info, err := entries[0].Info()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !info.ModTime().Equal(time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected the message to be dated by its sending time, got %v", info.ModTime())
+	}
+}
@@ -0,0 +1,273 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrArchiveNotFound is returned when no archived message exists for an id.
+var ErrArchiveNotFound = errors.New("archived message not found")
+
+// ArchivedMessage is the rendered .eml of a sent email stored in an ArchiveStore.
+type ArchivedMessage struct {
+	// ID is the id assigned to the email by the mailer.
+	ID string
+	// Raw is the rendered MIME message.
+	Raw []byte
+	// SentAt is the time at which the email was sent.
+	SentAt time.Time
+	// RetainUntil is the time until which the message must be kept, zero when unlimited.
+	RetainUntil time.Time
+	// Recipients are the lowercased addresses of the envelope recipients, the Bcc
+	// recipients included, for the message to be erased with them. The S3 and GCS
+	// stores do not keep them.
+	Recipients []string
+}
+
+// ArchiveStore stores the rendered message of every email sent, keyed by message id,
+// for legal and audit retrieval.
+type ArchiveStore interface {
+	Put(ctx context.Context, msg ArchivedMessage) error
+	Get(ctx context.Context, id string) (ArchivedMessage, error)
+}
+
+// ListableArchiveStore is an ArchiveStore whose messages can be listed, e.g. to
+// export them.
+type ListableArchiveStore interface {
+	ArchiveStore
+	// List returns every archived message, oldest first.
+	List(ctx context.Context) ([]ArchivedMessage, error)
+}
+
+// RetentionPolicy returns how long the archive of an email must be kept.
+// A zero duration keeps it forever.
+type RetentionPolicy func(msg Mail) time.Duration
+
+// MemoryArchiveStore is an ArchiveStore keeping the messages in memory, for tests and development.
+type MemoryArchiveStore struct {
+	mu       sync.RWMutex
+	messages map[string]ArchivedMessage
+}
+
+// NewMemoryArchiveStore creates an empty MemoryArchiveStore.
+func NewMemoryArchiveStore() *MemoryArchiveStore {
+	return &MemoryArchiveStore{messages: make(map[string]ArchivedMessage)}
+}
+
+func (s *MemoryArchiveStore) Put(ctx context.Context, msg ArchivedMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[msg.ID] = msg
+	return nil
+}
+
+func (s *MemoryArchiveStore) Get(ctx context.Context, id string) (ArchivedMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	msg, ok := s.messages[id]
+	if !ok {
+		return ArchivedMessage{}, ErrArchiveNotFound
+	}
+	return msg, nil
+}
+
+func (s *MemoryArchiveStore) List(ctx context.Context) ([]ArchivedMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	messages := make([]ArchivedMessage, 0, len(s.messages))
+	for _, msg := range s.messages {
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].SentAt.Equal(messages[j].SentAt) {
+			return messages[i].SentAt.Before(messages[j].SentAt)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+	return messages, nil
+}
+
+// S3ArchiveClient is the subset of the *s3.Client used by the S3 archive store.
+type S3ArchiveClient interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// S3ArchiveStore stores the archived messages as <prefix><id>.eml objects of an S3 bucket.
+// The retention date is stored in the retain-until metadata so that bucket
+// lifecycle rules or a janitor can enforce it. The store is neither a Pruner nor
+// an Eraser nor a ListableArchiveStore: the expiration is left to the lifecycle
+// rules of the bucket, and Prune and Forget report it as skipped.
+type S3ArchiveStore struct {
+	client S3ArchiveClient
+	bucket string
+	prefix string
+}
+
+// NewS3ArchiveStore creates an S3ArchiveStore, the client is usually an *s3.Client.
+func NewS3ArchiveStore(client S3ArchiveClient, bucket string, prefix string) *S3ArchiveStore {
+	return &S3ArchiveStore{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *S3ArchiveStore) Put(ctx context.Context, msg ArchivedMessage) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.prefix + msg.ID + ".eml"),
+		Body:        bytes.NewReader(msg.Raw),
+		ContentType: aws.String("message/rfc822"),
+		Metadata:    getArchiveMetadata(msg),
+	})
+	return err
+}
+
+func (s *S3ArchiveStore) Get(ctx context.Context, id string) (ArchivedMessage, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + id + ".eml"),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return ArchivedMessage{}, ErrArchiveNotFound
+	}
+	if err != nil {
+		return ArchivedMessage{}, err
+	}
+	defer out.Body.Close()
+
+	raw, err := io.ReadAll(out.Body)
+	if err != nil {
+		return ArchivedMessage{}, err
+	}
+	return parseArchiveMetadata(id, raw, out.Metadata), nil
+}
+
+const gcsBaseURL = "https://storage.googleapis.com"
+
+// GCSArchiveStore stores the archived messages as <prefix><id>.eml objects of a
+// Google Cloud Storage bucket, through the JSON API. Like the S3ArchiveStore, it
+// leaves the expiration to the lifecycle rules of the bucket and is reported as
+// skipped by Prune and Forget.
+type GCSArchiveStore struct {
+	httpClient *http.Client
+	baseURL    string
+	bucket     string
+	prefix     string
+}
+
+// NewGCSArchiveStore creates a GCSArchiveStore. The http client must authenticate
+// the requests, e.g. a client from golang.org/x/oauth2/google.DefaultClient.
+func NewGCSArchiveStore(httpClient *http.Client, bucket string, prefix string) *GCSArchiveStore {
+	return &GCSArchiveStore{httpClient: httpClient, baseURL: gcsBaseURL, bucket: bucket, prefix: prefix}
+}
+
+func (s *GCSArchiveStore) Put(ctx context.Context, msg ArchivedMessage) error {
+	metadata, err := json.Marshal(map[string]any{
+		"name":        s.prefix + msg.ID + ".eml",
+		"contentType": "message/rfc822",
+		"metadata":    getArchiveMetadata(msg),
+	})
+	if err != nil {
+		return err
+	}
+
+	body := &bytes.Buffer{}
+	parts := multipart.NewWriter(body)
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"application/json; charset=UTF-8", metadata},
+		{"message/rfc822", msg.Raw},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(part.content); err != nil {
+			return err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=multipart", s.baseURL, url.PathEscape(s.bucket))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "multipart/related; boundary="+parts.Boundary())
+
+	return doJSONRequest(s.httpClient, "gcs", req, nil)
+}
+
+func (s *GCSArchiveStore) Get(ctx context.Context, id string) (ArchivedMessage, error) {
+	object := fmt.Sprintf("%s/storage/v1/b/%s/o/%s", s.baseURL, url.PathEscape(s.bucket), url.PathEscape(s.prefix+id+".eml"))
+
+	var attrs struct {
+		Metadata map[string]string `json:"metadata"`
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, object, nil)
+	if err != nil {
+		return ArchivedMessage{}, err
+	}
+	if err := doJSONRequest(s.httpClient, "gcs", req, &attrs); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return ArchivedMessage{}, ErrArchiveNotFound
+		}
+		return ArchivedMessage{}, err
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, object+"?alt=media", nil)
+	if err != nil {
+		return ArchivedMessage{}, err
+	}
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return ArchivedMessage{}, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return ArchivedMessage{}, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return ArchivedMessage{}, &APIError{Provider: "gcs", StatusCode: res.StatusCode, Body: string(raw)}
+	}
+	return parseArchiveMetadata(id, raw, attrs.Metadata), nil
+}
+
+func getArchiveMetadata(msg ArchivedMessage) map[string]string {
+	metadata := map[string]string{
+		"message-id": msg.ID,
+		"sent-at":    msg.SentAt.UTC().Format(time.RFC3339),
+	}
+	if !msg.RetainUntil.IsZero() {
+		metadata["retain-until"] = msg.RetainUntil.UTC().Format(time.RFC3339)
+	}
+	return metadata
+}
+
+func parseArchiveMetadata(id string, raw []byte, metadata map[string]string) ArchivedMessage {
+	msg := ArchivedMessage{ID: id, Raw: raw}
+	msg.SentAt, _ = time.Parse(time.RFC3339, metadata["sent-at"])
+	msg.RetainUntil, _ = time.Parse(time.RFC3339, metadata["retain-until"])
+	return msg
+}
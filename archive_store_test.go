@@ -0,0 +1,170 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestMailer_ArchiveStore(t *testing.T) {
+	store := NewMemoryArchiveStore()
+	mailer := NewMailer(MailCfg{
+		APIService:   RESEND,
+		APIKey:       MailAPIKey,
+		ArchiveStore: store,
+		ArchiveRetention: func(msg Mail) time.Duration {
+			return 7 * 365 * 24 * time.Hour
+		},
+		AuditStore:   NewMemoryAuditStore(),
+		mailerClient: &mockMailerClient{},
+	})
+	defer mailer.Close()
+
+	if err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "Invoice", Text: "test"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	records := mailer.auditStore.(*MemoryAuditStore).Records()
+	archived, err := store.Get(context.Background(), records[0].MessageID)
+	if err != nil {
+		t.Fatalf("Expected the message to be archived, got %v", err)
+	}
+	if !bytes.Contains(archived.Raw, []byte("Subject: Invoice")) {
+		t.Errorf("Expected the rendered message to be archived")
+	}
+	if archived.RetainUntil.Sub(archived.SentAt) != 7*365*24*time.Hour {
+		t.Errorf("Expected the retention policy to be applied, got %v", archived.RetainUntil)
+	}
+
+	if _, err := store.Get(context.Background(), "unknown"); !errors.Is(err, ErrArchiveNotFound) {
+		t.Errorf("Expected ErrArchiveNotFound, got %v", err)
+	}
+}
+
+func TestS3ArchiveStore(t *testing.T) {
+	client := &mockS3Client{objects: make(map[string]*s3.PutObjectInput)}
+	store := NewS3ArchiveStore(client, "archive", "mail/")
+
+	sentAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	msg := ArchivedMessage{ID: "abc", Raw: []byte("Subject: test\r\n\r\ntest"), SentAt: sentAt, RetainUntil: sentAt.AddDate(7, 0, 0)}
+	if err := store.Put(context.Background(), msg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	object, ok := client.objects["archive/mail/abc.eml"]
+	if !ok {
+		t.Fatalf("Expected object mail/abc.eml, got %v", client.objects)
+	}
+	if aws.ToString(object.ContentType) != "message/rfc822" {
+		t.Errorf("Expected message/rfc822, got %s", aws.ToString(object.ContentType))
+	}
+
+	archived, err := store.Get(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(archived.Raw) != string(msg.Raw) || !archived.SentAt.Equal(sentAt) || !archived.RetainUntil.Equal(msg.RetainUntil) {
+		t.Errorf("Expected %+v, got %+v", msg, archived)
+	}
+
+	if _, err := store.Get(context.Background(), "unknown"); !errors.Is(err, ErrArchiveNotFound) {
+		t.Errorf("Expected ErrArchiveNotFound, got %v", err)
+	}
+}
+
+func TestGCSArchiveStore(t *testing.T) {
+	objects := make(map[string][]byte)
+	metadata := make(map[string]map[string]string)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/archive/o":
+			_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			parts := multipart.NewReader(r.Body, params["boundary"])
+
+			part, _ := parts.NextPart()
+			var attrs struct {
+				Name     string            `json:"name"`
+				Metadata map[string]string `json:"metadata"`
+			}
+			json.NewDecoder(part).Decode(&attrs)
+
+			part, _ = parts.NextPart()
+			content, _ := io.ReadAll(part)
+			objects[attrs.Name] = content
+			metadata[attrs.Name] = attrs.Metadata
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/storage/v1/b/archive/o/"):
+			name := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/archive/o/")
+			content, ok := objects[name]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			if r.URL.Query().Get("alt") == "media" {
+				w.Write(content)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"metadata": metadata[name]})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	store := NewGCSArchiveStore(server.Client(), "archive", "mail/")
+	store.baseURL = server.URL
+
+	sentAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	msg := ArchivedMessage{ID: "abc", Raw: []byte("Subject: test\r\n\r\ntest"), SentAt: sentAt}
+	if err := store.Put(context.Background(), msg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	archived, err := store.Get(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(archived.Raw) != string(msg.Raw) || !archived.SentAt.Equal(sentAt) || !archived.RetainUntil.IsZero() {
+		t.Errorf("Expected %+v, got %+v", msg, archived)
+	}
+
+	if _, err := store.Get(context.Background(), "unknown"); !errors.Is(err, ErrArchiveNotFound) {
+		t.Errorf("Expected ErrArchiveNotFound, got %v", err)
+	}
+}
+
+type mockS3Client struct {
+	objects map[string]*s3.PutObjectInput
+}
+
+func (m *mockS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	m.objects[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)] = params
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (m *mockS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	object, ok := m.objects[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	body, _ := io.ReadAll(object.Body)
+	object.Body = bytes.NewReader(body)
+	return &s3.GetObjectOutput{
+		Body:     io.NopCloser(bytes.NewReader(body)),
+		Metadata: object.Metadata,
+	}, nil
+}
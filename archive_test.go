@@ -0,0 +1,116 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMailer_Archive(t *testing.T) {
+	testCases := []struct {
+		name       string
+		archiveErr error
+	}{
+		{
+			name: "Should copy the email to the archive",
+		},
+		{
+			name:       "Should deliver the email when archiving fails",
+			archiveErr: errors.New("archive mailbox full"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sent []Mail
+			var archive bytes.Buffer
+			mailer := NewMailer(MailCfg{
+				APIService:     RESEND,
+				APIKey:         MailAPIKey,
+				ArchiveAddress: "archive@test.com",
+				ArchiveWriter:  &archive,
+				mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+					sent = append(sent, msg)
+					if joinAddresses(msg.To) == "archive@test.com" {
+						return tc.archiveErr
+					}
+					return nil
+				}},
+			})
+			defer mailer.Close()
+
+			err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Bcc: Addresses("b@test.com"), Subject: "Invoice", Text: "test"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(sent) != 2 {
+				t.Fatalf("Expected the email and its archive copy, got %d emails", len(sent))
+			}
+			if joinAddresses(sent[1].To) != "archive@test.com" || joinAddresses(sent[1].Bcc) != "" {
+				t.Errorf("Expected the copy to only target the archive, got %+v", sent[1])
+			}
+			if sent[1].Headers["X-Envelope-To"] != "a@test.com, b@test.com" {
+				t.Errorf("Expected the copy to keep the envelope recipients, got %+v", sent[1].Headers)
+			}
+			if !strings.Contains(archive.String(), "Subject: Invoice") {
+				t.Errorf("Expected the raw message to be written to the archive writer")
+			}
+		})
+	}
+}
+
+func TestMailer_ArchiveSkipsFailedSends(t *testing.T) {
+	var archive bytes.Buffer
+	mailer := NewMailer(MailCfg{
+		APIService:    RESEND,
+		APIKey:        MailAPIKey,
+		ArchiveWriter: &archive,
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			return errors.New("provider unavailable")
+		}},
+	})
+	defer mailer.Close()
+
+	if err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Text: "test"}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+	if archive.Len() != 0 {
+		t.Errorf("Expected failed sends not to be archived")
+	}
+}
+
+func TestMailer_ArchiveWithTheTenantClient(t *testing.T) {
+	var defaultSent, tenantSent []Mail
+	mailer := NewMailer(MailCfg{
+		APIService:     RESEND,
+		APIKey:         MailAPIKey,
+		ArchiveAddress: "archive@test.com",
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			defaultSent = append(defaultSent, msg)
+			return nil
+		}},
+		Tenants: []Tenant{{
+			ID:         "acme",
+			FromDomain: "acme.com",
+			From:       "noreply@acme.com",
+			Config: MailCfg{
+				APIService: SENDGRID,
+				APIKey:     "acme-key",
+				mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+					tenantSent = append(tenantSent, msg)
+					return nil
+				}},
+			},
+		}},
+	})
+	defer mailer.Close()
+
+	if err := mailer.Send(Mail{TenantID: "acme", To: Addresses("a@test.com"), Text: "test"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(defaultSent) != 0 || len(tenantSent) != 2 || joinAddresses(tenantSent[1].To) != "archive@test.com" {
+		t.Errorf("Expected the copy to be sent by the tenant, got %+v and %+v", defaultSent, tenantSent)
+	}
+}
@@ -0,0 +1,111 @@
+package mailer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+)
+
+// AuditRecord is an immutable record of a send attempt, kept as proof-of-send.
+type AuditRecord struct {
+	// MessageID is the id assigned to the email by the mailer.
+	MessageID string
+	// Attempt is the number of the attempt, starting at 1.
+	Attempt int
+	// From is the sender of the email.
+	From string
+	// Recipients are the To, Cc and Bcc recipients of the email.
+	Recipients []string
+	// Subject is the subject of the email.
+	Subject string
+	// Tags are the tags of the email.
+	Tags []string
+	// Provider is the API service used for the attempt.
+	Provider APIServiceType
+	// ContentHash is the SHA-256 hash of the email content, see ContentHash.
+	ContentHash string
+	// Time is the time at which the attempt finished.
+	Time time.Time
+	// Error is the error returned by the provider, empty on success.
+	Error string
+}
+
+// AuditStore persists the audit trail of the mailer. Implementations must only
+// append records, never update nor delete them, except to erase the data of an
+// address for Forget.
+type AuditStore interface {
+	Append(ctx context.Context, record AuditRecord) error
+}
+
+// MemoryAuditStore is an AuditStore keeping the records in memory, for tests and development.
+type MemoryAuditStore struct {
+	mu      sync.RWMutex
+	records []AuditRecord
+}
+
+// NewMemoryAuditStore creates an empty MemoryAuditStore.
+func NewMemoryAuditStore() *MemoryAuditStore {
+	return &MemoryAuditStore{}
+}
+
+func (s *MemoryAuditStore) Append(ctx context.Context, record AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record.Recipients = append([]string{}, record.Recipients...)
+	record.Tags = append([]string(nil), record.Tags...)
+	s.records = append(s.records, record)
+	return nil
+}
+
+// Records returns a copy of the records, oldest first.
+func (s *MemoryAuditStore) Records() []AuditRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]AuditRecord{}, s.records...)
+}
+
+// ContentHash returns the hex encoded SHA-256 hash of the addresses, subject,
+// bodies and attachment names of the email.
+func ContentHash(msg Mail) string {
+	hash := sha256.New()
+	for _, field := range []string{msg.From.String(), joinAddresses(msg.To), joinAddresses(msg.Cc), joinAddresses(msg.Bcc), msg.ReplyTo.String(), msg.Subject, msg.Text, msg.Html} {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+	for _, attachment := range msg.Attachments {
+		hash.Write([]byte(attachment.Name))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// audit appends the record of an attempt with the provider to the audit store,
+// the provider of the tenant for its emails. Failures are logged
+// rather than returned so that auditing does not change the outcome of the send.
+func (m *Mailer) audit(id string, attempt int, provider APIServiceType, msg Mail, sendErr error) {
+	if m.auditStore == nil {
+		return
+	}
+
+	record := AuditRecord{
+		MessageID:   id,
+		Attempt:     attempt,
+		From:        msg.From.String(),
+		Recipients:  formatAddresses(allRecipients(msg)),
+		Subject:     msg.Subject,
+		Tags:        msg.Tags,
+		Provider:    provider,
+		ContentHash: ContentHash(msg),
+		Time:        m.clock.Now(),
+	}
+	if sendErr != nil {
+		record.Error = sendErr.Error()
+	}
+
+	if err := m.auditStore.Append(context.Background(), record); err != nil {
+		log.Printf("mailer: failed to append audit record for %s: %v", id, err)
+	}
+}
@@ -0,0 +1,139 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestContentHash(t *testing.T) {
+	msg := Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test", Text: "test"}
+
+	if ContentHash(msg) != ContentHash(msg) {
+		t.Errorf("Expected the hash to be deterministic")
+	}
+
+	changed := msg
+	changed.Text = "changed"
+	if ContentHash(msg) == ContentHash(changed) {
+		t.Errorf("Expected the hash to change with the content")
+	}
+
+	moved := Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.comtest"), Text: "test"}
+	if ContentHash(moved) == ContentHash(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test", Text: "test"}) {
+		t.Errorf("Expected field boundaries to be part of the hash")
+	}
+}
+
+func TestMailer_Audit(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sendErr error
+	}{
+		{
+			name: "Should record successful sends",
+		},
+		{
+			name:    "Should record failed sends",
+			sendErr: errors.New("provider unavailable"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewMemoryAuditStore()
+			mailer := NewMailer(MailCfg{
+				APIService: RESEND,
+				APIKey:     MailAPIKey,
+				AuditStore: store,
+				mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+					return tc.sendErr
+				}},
+			})
+			defer mailer.Close()
+
+			msg := Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Bcc: Addresses("b@test.com"), Subject: "test", Text: "test"}
+			if err := mailer.Send(msg); !errors.Is(err, tc.sendErr) {
+				t.Fatalf("Expected %v, got %v", tc.sendErr, err)
+			}
+
+			records := store.Records()
+			if len(records) != 1 {
+				t.Fatalf("Expected one audit record, got %d", len(records))
+			}
+			record := records[0]
+			if record.MessageID == "" || record.Attempt != 1 || record.Provider != RESEND {
+				t.Errorf("Unexpected record %+v", record)
+			}
+			if len(record.Recipients) != 2 || record.ContentHash != ContentHash(msg) {
+				t.Errorf("Unexpected record %+v", record)
+			}
+			if tc.sendErr != nil && record.Error != tc.sendErr.Error() {
+				t.Errorf("Expected error %q, got %q", tc.sendErr, record.Error)
+			}
+			if tc.sendErr == nil && record.Error != "" {
+				t.Errorf("Expected no error, got %q", record.Error)
+			}
+		})
+	}
+}
+
+func TestMailer_AuditAttempts(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)}
+	store := NewMemoryAuditStore()
+	var sendErr error
+	sent := make(chan error, 4)
+	mailer := NewMailer(MailCfg{
+		Clock:      clock,
+		AuditStore: store,
+		SpoolDir:   t.TempDir(),
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			defer func() { sent <- sendErr }()
+			return sendErr
+		}},
+	})
+	defer mailer.Close()
+
+	// Rate limited, the email is requeued for a second attempt.
+	sendErr = &APIError{Provider: SENDGRID, StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}
+	if _, err := mailer.Enqueue(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test", Text: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	<-sent
+	for clock.pendingTimers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Down, the email is spooled after the second attempt.
+	sendErr = &APIError{Provider: SENDGRID, StatusCode: http.StatusBadGateway}
+	clock.Advance(time.Minute)
+	<-sent
+	for i := 0; i < 200 && len(store.Records()) < 2; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 200; i++ {
+		if status, _ := mailer.Status(store.Records()[0].MessageID); status.State == MessageSpooled {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := mailer.ReplaySpool(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	sendErr = nil
+	if n, err := mailer.ReplaySpool(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Expected the email to be replayed, got %d, %v", n, err)
+	}
+
+	var attempts []int
+	for _, record := range store.Records() {
+		attempts = append(attempts, record.Attempt)
+	}
+	if !slices.Equal(attempts, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected the attempts to be numbered across the requeue and the replays, got %v", attempts)
+	}
+}
@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"bytes"
+	"image/png"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+)
+
+// QRCode returns a PNG attachment of size by size pixels encoding data in a QR
+// code, e.g. a ticket or a 2FA setup URI. The html shows it inline with
+// <img src="cid:name">.
+func QRCode(name string, data string, size int) (Attachment, error) {
+	code, err := qr.Encode(data, qr.M, qr.Auto)
+	if err != nil {
+		return Attachment{}, err
+	}
+	return barcodeAttachment(name, code, size, size)
+}
+
+// Code128 returns a PNG attachment of width by height pixels encoding data in a
+// Code 128 barcode, e.g. an order or a boarding pass number.
+func Code128(name string, data string, width int, height int) (Attachment, error) {
+	code, err := code128.Encode(data)
+	if err != nil {
+		return Attachment{}, err
+	}
+	return barcodeAttachment(name, code, width, height)
+}
+
+func barcodeAttachment(name string, code barcode.Barcode, width int, height int) (Attachment, error) {
+	scaled, err := barcode.Scale(code, width, height)
+	if err != nil {
+		return Attachment{}, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaled); err != nil {
+		return Attachment{}, err
+	}
+	return Attachment{Name: name, Content: buf.Bytes()}, nil
+}
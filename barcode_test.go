@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestQRCode(t *testing.T) {
+	attachment, err := QRCode("ticket.png", "otpauth://totp/Example:ada?secret=JBSWY3DPEHPK3PXP", 256)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(attachment.Content))
+	if err != nil {
+		t.Fatalf("Expected a PNG, got %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 256 || bounds.Dy() != 256 {
+		t.Errorf("Expected a 256x256 image, got %v", bounds)
+	}
+
+	message, email := buildMessage(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Html: `<img src="cid:ticket.png">`, Attachments: []Attachment{attachment}})
+	if email.Error != nil {
+		t.Fatal(email.Error)
+	}
+	if !strings.Contains(strings.ToLower(message), "content-id: <") || strings.Contains(message, "cid:ticket.png") {
+		t.Errorf("Expected the image to be referenced by its content id, got %s", message)
+	}
+}
+
+func TestCode128(t *testing.T) {
+	attachment, err := Code128("order.png", "ORDER-42", 300, 80)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(attachment.Content))
+	if err != nil {
+		t.Fatalf("Expected a PNG, got %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 300 || bounds.Dy() != 80 {
+		t.Errorf("Expected a 300x80 image, got %v", bounds)
+	}
+
+	if _, err := Code128("small.png", "ORDER-42", 10, 80); err == nil {
+		t.Errorf("Expected an error when the width is too small")
+	}
+}
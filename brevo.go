@@ -0,0 +1,204 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const brevoBaseURL = "https://api.brevo.com"
+
+// BrevoOptions holds the Brevo specific options of an email.
+type BrevoOptions struct {
+	// TemplateID is the id of a Brevo template. When set, the template stored in
+	// Brevo is rendered instead of the Subject, Html and Text of the email.
+	TemplateID int64
+	// Params are the values of the template, e.g. {{ params.name }}.
+	Params map[string]any
+}
+
+type brevoParams struct {
+	apiKey         string
+	templateSender string
+	userAgent      string
+	httpClient     *http.Client
+}
+
+type brevoMailer struct {
+	httpClient     *http.Client
+	baseURL        string
+	apiKey         string
+	templateSender string
+	userAgent      string
+}
+
+type brevoAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type brevoAttachment struct {
+	Content string `json:"content"`
+	Name    string `json:"name"`
+}
+
+type brevoEmail struct {
+	Sender      brevoAddress      `json:"sender"`
+	To          []brevoAddress    `json:"to"`
+	Cc          []brevoAddress    `json:"cc,omitempty"`
+	Bcc         []brevoAddress    `json:"bcc,omitempty"`
+	ReplyTo     *brevoAddress     `json:"replyTo,omitempty"`
+	Subject     string            `json:"subject,omitempty"`
+	HtmlContent string            `json:"htmlContent,omitempty"`
+	TextContent string            `json:"textContent,omitempty"`
+	Attachment  []brevoAttachment `json:"attachment,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	TemplateID  int64             `json:"templateId,omitempty"`
+	Params      map[string]any    `json:"params,omitempty"`
+}
+
+type brevoTemplate struct {
+	TemplateName string       `json:"templateName"`
+	Subject      string       `json:"subject"`
+	HtmlContent  string       `json:"htmlContent"`
+	Sender       brevoAddress `json:"sender"`
+	IsActive     bool         `json:"isActive"`
+}
+
+func newBrevo(params brevoParams) MailerClient {
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+
+	return &brevoMailer{
+		httpClient:     params.httpClient,
+		baseURL:        brevoBaseURL,
+		apiKey:         params.apiKey,
+		templateSender: params.templateSender,
+		userAgent:      params.userAgent,
+	}
+}
+
+func (m *brevoMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *brevoMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	payload := brevoEmail{
+		Sender:      brevoAddress{Email: msg.From.Email, Name: msg.From.Name},
+		To:          getBrevoAddresses(msg.To),
+		Cc:          getBrevoAddresses(msg.Cc),
+		Bcc:         getBrevoAddresses(msg.Bcc),
+		Subject:     msg.Subject,
+		HtmlContent: msg.Html,
+		TextContent: msg.Text,
+		Headers:     msg.Headers,
+		Tags:        msg.Tags,
+	}
+	if msg.ReplyTo.Email != "" {
+		replyTo := msg.ReplyTo
+		payload.ReplyTo = &brevoAddress{Email: replyTo.Email, Name: replyTo.Name}
+	}
+	if options := msg.Brevo; options != nil && options.TemplateID != 0 {
+		payload.Subject, payload.HtmlContent, payload.TextContent = "", "", ""
+		payload.TemplateID = options.TemplateID
+		payload.Params = options.Params
+	}
+
+	for _, attachment := range msg.Attachments {
+		content, err := encodeAttachmentBase64(attachment)
+		if err != nil {
+			return nil, err
+		}
+		payload.Attachment = append(payload.Attachment, brevoAttachment{Content: content, Name: attachment.Name})
+	}
+
+	return m.doWithResponse(context.Background(), http.MethodPost, "/v3/smtp/email", payload, nil)
+}
+
+func getBrevoAddresses(addrs []Address) []brevoAddress {
+	var addresses []brevoAddress
+	for _, addr := range addrs {
+		addresses = append(addresses, brevoAddress{Email: addr.Email, Name: addr.Name})
+	}
+	return addresses
+}
+
+func (m *brevoMailer) do(ctx context.Context, method string, path string, payload any, out any) error {
+	_, err := m.doWithResponse(ctx, method, path, payload, out)
+	return err
+}
+
+func (m *brevoMailer) doWithResponse(ctx context.Context, method string, path string, payload any, out any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(method, m.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("api-key", m.apiKey)
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, BREVO, req.WithContext(ctx), out)
+}
+
+// CreateTemplate creates an active template, Brevo requiring a sender for it.
+func (m *brevoMailer) CreateTemplate(ctx context.Context, tpl Template) error {
+	return m.do(ctx, http.MethodPost, "/v3/smtp/templates", m.getTemplate(tpl), nil)
+}
+
+func (m *brevoMailer) UpdateTemplate(ctx context.Context, tpl Template) error {
+	id, err := m.findTemplateID(ctx, tpl.Name)
+	if err != nil {
+		return err
+	}
+	return m.do(ctx, http.MethodPut, "/v3/smtp/templates/"+strconv.FormatInt(id, 10), m.getTemplate(tpl), nil)
+}
+
+func (m *brevoMailer) DeleteTemplate(ctx context.Context, name string) error {
+	id, err := m.findTemplateID(ctx, name)
+	if err != nil {
+		return err
+	}
+	return m.do(ctx, http.MethodDelete, "/v3/smtp/templates/"+strconv.FormatInt(id, 10), nil, nil)
+}
+
+func (m *brevoMailer) getTemplate(tpl Template) brevoTemplate {
+	sender := toAddress(m.templateSender)
+	return brevoTemplate{
+		TemplateName: tpl.Name,
+		Subject:      tpl.Subject,
+		HtmlContent:  tpl.Html,
+		Sender:       brevoAddress{Email: sender.Email, Name: sender.Name},
+		IsActive:     true,
+	}
+}
+
+// findTemplateID looks the template up by name, as Brevo identifies the templates by id.
+func (m *brevoMailer) findTemplateID(ctx context.Context, name string) (int64, error) {
+	var templates struct {
+		Templates []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"templates"`
+	}
+	if err := m.do(ctx, http.MethodGet, "/v3/smtp/templates?limit=1000", nil, &templates); err != nil {
+		return 0, err
+	}
+	for _, template := range templates.Templates {
+		if template.Name == name {
+			return template.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("brevo template %q not found", name)
+}
+
+// Health verifies the API key by fetching the account.
+func (m *brevoMailer) Health(ctx context.Context) error {
+	return m.do(ctx, http.MethodGet, "/v3/account", nil, nil)
+}
+
+func (m *brevoMailer) Close() {
+	// Not implemented because the Brevo API is stateless.
+}
@@ -0,0 +1,104 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestBrevo_Send(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	brevo := newBrevo(brevoParams{apiKey: MailAPIKey}).(*brevoMailer)
+	brevo.baseURL = server.URL
+
+	err := brevo.Send(Mail{
+		From:        Address{Name: "Acme", Email: "info@test.com"},
+		To:          []Address{{Email: "a@test.com"}, {Name: "Bob", Email: "b@test.com"}},
+		Subject:     "test",
+		Html:        "<p>test</p>",
+		Tags:        []string{"welcome"},
+		Attachments: []Attachment{{Name: "a.txt", Content: []byte("hi")}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := requests[0]
+	if req.Path != "/v3/smtp/email" || req.Header.Get("api-key") != MailAPIKey {
+		t.Fatalf("Expected an authenticated request to /v3/smtp/email, got %s %v", req.Path, req.Header)
+	}
+	if sender, _ := req.Body["sender"].(map[string]any); sender["email"] != "info@test.com" || sender["name"] != "Acme" {
+		t.Errorf("Expected the sender with its name, got %v", req.Body["sender"])
+	}
+	if to, _ := req.Body["to"].([]any); len(to) != 2 {
+		t.Errorf("Expected 2 recipients, got %v", req.Body["to"])
+	}
+	if attachments, _ := req.Body["attachment"].([]any); len(attachments) != 1 || attachments[0].(map[string]any)["content"] != "aGk=" {
+		t.Errorf("Expected the base64 attachment, got %v", req.Body["attachment"])
+	}
+	if tags, _ := req.Body["tags"].([]any); len(tags) != 1 || tags[0] != "welcome" {
+		t.Errorf("Expected the tags, got %v", req.Body["tags"])
+	}
+}
+
+func TestBrevo_SendWithTemplate(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	brevo := newBrevo(brevoParams{apiKey: MailAPIKey}).(*brevoMailer)
+	brevo.baseURL = server.URL
+
+	err := brevo.Send(Mail{
+		From:    Address{Email: "info@test.com"},
+		To:      Addresses("a@test.com"),
+		Subject: "ignored",
+		Brevo:   &BrevoOptions{TemplateID: 12, Params: map[string]any{"name": "Ada"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body := requests[0].Body
+	if body["templateId"] != float64(12) || body["params"].(map[string]any)["name"] != "Ada" {
+		t.Errorf("Expected the template and its params, got %v", body)
+	}
+	if _, ok := body["subject"]; ok {
+		t.Errorf("Expected no subject with a template, got %v", body["subject"])
+	}
+}
+
+func TestBrevo_TemplateManagement(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, map[string]string{
+		"GET /v3/smtp/templates": `{"templates":[{"id":3,"name":"welcome"}]}`,
+	})
+	brevo := newBrevo(brevoParams{apiKey: MailAPIKey, templateSender: "info@test.com"}).(*brevoMailer)
+	brevo.baseURL = server.URL
+
+	ctx := context.Background()
+	tpl := Template{Name: "welcome", Subject: "Welcome", Html: "<p>Hi</p>"}
+	if err := brevo.CreateTemplate(ctx, tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := brevo.UpdateTemplate(ctx, tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := brevo.DeleteTemplate(ctx, tpl.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"POST /v3/smtp/templates", "GET /v3/smtp/templates", "PUT /v3/smtp/templates/3", "GET /v3/smtp/templates", "DELETE /v3/smtp/templates/3"}
+	if len(requests) != len(expected) {
+		t.Fatalf("Expected %d requests, got %d", len(expected), len(requests))
+	}
+	for i, req := range expected {
+		if got := requests[i].Method + " " + requests[i].Path; got != req {
+			t.Errorf("Expected %s, got %s", req, got)
+		}
+	}
+	if sender, _ := requests[0].Body["sender"].(map[string]any); sender["email"] != "info@test.com" {
+		t.Errorf("Expected the template sender, got %v", requests[0].Body["sender"])
+	}
+	if requests[0].Method != http.MethodPost || requests[0].Body["templateName"] != "welcome" {
+		t.Errorf("Expected the template name, got %v", requests[0].Body)
+	}
+}
@@ -0,0 +1,89 @@
+package mailer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ViewInBrowserPlaceholder is replaced in the html and text of the emails with
+// the signed URL at which ViewInBrowserHandler serves the sent email, e.g.
+//
+//	<a href="{view_in_browser_url}">View this email in your browser</a>
+const ViewInBrowserPlaceholder = "{view_in_browser_url}"
+
+// ViewInBrowserURL returns the signed URL of the sent email with the id, empty
+// when the mailer has no ViewInBrowserURL.
+func (m *Mailer) ViewInBrowserURL(id string) string {
+	if m.viewInBrowserURL == "" {
+		return ""
+	}
+	return strings.TrimRight(m.viewInBrowserURL, "/") + "/" + url.PathEscape(id) + "?sig=" + m.viewSignature(id)
+}
+
+// ViewInBrowserHandler returns an http.Handler serving the html of the archived
+// emails at GET /{id}, to be mounted at the ViewInBrowserURL. Requests must carry
+// the signature of the URL, so that message ids cannot be guessed.
+func (m *Mailer) ViewInBrowserHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		sig := r.URL.Query().Get("sig")
+		if m.viewInBrowserSecret == "" || !hmac.Equal([]byte(sig), []byte(m.viewSignature(id))) {
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+		if m.archiveStore == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		archived, err := m.archiveStore.Get(r.Context(), id)
+		if errors.Is(err, ErrArchiveNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		msg, err := parseMessage(archived.Raw, true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// The html is sent by the application itself, but must not run scripts
+		// on the domain serving it.
+		w.Header().Set("Content-Security-Policy", "script-src 'none'")
+		if msg.Html == "" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte(msg.Text))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(msg.Html))
+	})
+	return mux
+}
+
+func (m *Mailer) viewSignature(id string) string {
+	mac := hmac.New(sha256.New, []byte(m.viewInBrowserSecret))
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// applyViewInBrowser replaces the ViewInBrowserPlaceholder of the email.
+func (m *Mailer) applyViewInBrowser(id string, msg Mail) Mail {
+	if m.viewInBrowserURL == "" {
+		return msg
+	}
+	link := m.ViewInBrowserURL(id)
+	msg.Html = strings.ReplaceAll(msg.Html, ViewInBrowserPlaceholder, link)
+	msg.Text = strings.ReplaceAll(msg.Text, ViewInBrowserPlaceholder, link)
+	return msg
+}
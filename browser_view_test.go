@@ -0,0 +1,69 @@
+package mailer
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMailer_ViewInBrowser(t *testing.T) {
+	sent := make(chan Mail, 1)
+	mailer := NewMailer(MailCfg{
+		ArchiveStore:        NewMemoryArchiveStore(),
+		ViewInBrowserURL:    "https://example.com/emails",
+		ViewInBrowserSecret: "secret",
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			sent <- msg
+			return nil
+		}},
+	})
+	defer mailer.Close()
+
+	id, _ := mailer.Enqueue(Mail{
+		From:    Address{Email: "info@test.com"},
+		To:      Addresses("a@test.com"),
+		Subject: "Newsletter",
+		Html:    `<p><a href="{view_in_browser_url}">View in browser</a></p><p>News</p>`,
+	})
+	msg := <-sent
+	// Send waits for the email queued before it to be archived.
+	mailer.Send(Mail{To: Addresses("last@test.com")})
+
+	link := mailer.ViewInBrowserURL(id)
+	if !strings.HasPrefix(link, "https://example.com/emails/"+id+"?sig=") || !strings.Contains(msg.Html, link) {
+		t.Fatalf("Expected the placeholder to be replaced with %s, got %s", link, msg.Html)
+	}
+
+	server := httptest.NewServer(http.StripPrefix("/emails", mailer.ViewInBrowserHandler()))
+	defer server.Close()
+
+	testCases := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "Should serve the archived html", path: strings.TrimPrefix(link, "https://example.com"), status: http.StatusOK},
+		{name: "Should reject invalid signatures", path: "/emails/" + id + "?sig=invalid", status: http.StatusForbidden},
+		{name: "Should not find unknown emails", path: strings.TrimPrefix(mailer.ViewInBrowserURL("unknown"), "https://example.com"), status: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("Expected status %d, got %d: %s", tc.status, resp.StatusCode, body)
+			}
+			if tc.status == http.StatusOK && !strings.Contains(string(body), "<p>News</p>") {
+				t.Errorf("Expected the html of the email, got %s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package mailer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrUnsupportedCapability is wrapped by the errors of the emails the provider
+// cannot send, see CapabilityError.
+var ErrUnsupportedCapability = errors.New("unsupported by the provider")
+
+// CapabilityError is returned for an email using a feature the provider does not
+// support, before it is sent.
+type CapabilityError struct {
+	Provider APIServiceType
+	// Capability is the unsupported feature, e.g. "attachments".
+	Capability string
+	// Detail explains why the email is refused, when the feature is not simply missing.
+	Detail string
+}
+
+func (e *CapabilityError) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("%s: %s %s", e.Provider, e.Capability, e.Detail)
+	}
+	return fmt.Sprintf("%s does not support %s", e.Provider, e.Capability)
+}
+
+func (e *CapabilityError) Unwrap() error { return ErrUnsupportedCapability }
+
+// Capabilities describes what the provider of a mailer client supports, so that
+// the emails can be routed to, or validated for, the provider that sends them.
+type Capabilities struct {
+	// Attachments tells whether the attachments of the emails are sent.
+	Attachments bool
+	// AMP tells whether the AmpHtml of the emails is sent, it is ignored otherwise.
+	AMP bool
+	// Tags tells whether the Tags of the emails are sent, they are ignored otherwise.
+	Tags bool
+	// Headers tells whether the custom Headers of the emails are sent.
+	Headers bool
+	// Templates tells whether the provider renders templates stored in it.
+	Templates bool
+	// Scheduling tells whether the delivery of an email can be delayed with the
+	// options of the provider, e.g. MailgunOptions.DeliveryTime.
+	Scheduling bool
+	// Batch tells whether an email to several recipients is sent in one call.
+	Batch bool
+	// MaxRecipients is the number of recipients of a call, 0 when unlimited.
+	// Longer lists are split in several calls, see MailCfg.MaxRecipients.
+	MaxRecipients int
+	// MaxMessageSize is the largest email, in bytes once its attachments are
+	// encoded in base64, 0 when unknown.
+	MaxMessageSize int64
+}
+
+// CapabilityReporter is implemented by the mailer clients that know the
+// capabilities of their provider. The capabilities of the other clients are
+// those of an SMTP server.
+type CapabilityReporter interface {
+	Capabilities() Capabilities
+}
+
+// Capabilities returns the capabilities of the provider of the mailer.
+func (m *Mailer) Capabilities() Capabilities {
+	return CapabilitiesOf(m.mailerClient)
+}
+
+// CapabilitiesOf returns the capabilities of the client.
+func CapabilitiesOf(client MailerClient) Capabilities {
+	caps := Capabilities{Attachments: true, AMP: true, Headers: true}
+	if reporter, ok := client.(CapabilityReporter); ok {
+		caps = reporter.Capabilities()
+	}
+	if _, ok := client.(TemplateManager); ok {
+		caps.Templates = true
+	}
+	if limiter, ok := client.(recipientLimiter); ok && caps.MaxRecipients == 0 {
+		caps.MaxRecipients = limiter.maxRecipients()
+	}
+	caps.Batch = caps.MaxRecipients != 1
+	return caps
+}
+
+// Check returns a *CapabilityError when the email cannot be sent by the provider,
+// because of its attachments or of its size. The missing capabilities the
+// provider ignores, e.g. tags, are not errors.
+func (c Capabilities) Check(provider APIServiceType, msg Mail) error {
+	if len(msg.Attachments) > 0 && !c.Attachments {
+		return &CapabilityError{Provider: provider, Capability: "attachments"}
+	}
+	if c.MaxMessageSize > 0 {
+		if size := messageSize(msg); size > c.MaxMessageSize {
+			return &CapabilityError{
+				Provider:   provider,
+				Capability: "message size",
+				Detail:     fmt.Sprintf("of %d bytes exceeds the limit of %d bytes", size, c.MaxMessageSize),
+			}
+		}
+	}
+	return nil
+}
+
+// messageSize estimates the size of the email, its attachments encoded in base64.
+func messageSize(msg Mail) int64 {
+	size := int64(len(msg.Subject) + len(msg.Html) + len(msg.Text) + len(msg.AmpHtml))
+	for key, value := range msg.Headers {
+		size += int64(len(key) + len(value))
+	}
+	for _, attachment := range msg.Attachments {
+		n := int64(len(attachment.Content))
+		if attachment.Content == nil && attachment.Path != "" {
+			if info, err := os.Stat(attachment.Path); err == nil {
+				n = info.Size()
+			}
+		}
+		size += int64(base64.StdEncoding.EncodedLen(int(n)))
+	}
+	return size
+}
+
+const mb = 1 << 20
+
+func (m *sendgridMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, AMP: true, Tags: true, Headers: true, Templates: true, MaxMessageSize: 30 * mb}
+}
+
+func (m *mailgunMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, AMP: true, Tags: true, Headers: true, Templates: true, Scheduling: true, MaxMessageSize: 25 * mb}
+}
+
+func (m *sesMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, AMP: true, Headers: true, Templates: true, MaxMessageSize: 40 * mb}
+}
+
+func (m *postmarkMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, Tags: true, Headers: true, Templates: true, MaxMessageSize: 10 * mb}
+}
+
+func (m *resendMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, Tags: true, Headers: true, MaxMessageSize: 40 * mb}
+}
+
+func (m *brevoMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, Tags: true, Headers: true, Templates: true, MaxMessageSize: 20 * mb}
+}
+
+func (m *sparkpostMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, AMP: true, Tags: true, Headers: true, Templates: true, MaxMessageSize: 20 * mb}
+}
+
+func (m *mailjetMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, Headers: true, Templates: true, MaxMessageSize: 15 * mb}
+}
+
+func (m *smtp2goMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, Headers: true, Templates: true, MaxMessageSize: 50 * mb}
+}
+
+func (m *elasticEmailMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, Headers: true, Templates: true}
+}
+
+func (m *mandrillMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, Tags: true, Headers: true, Templates: true, MaxMessageSize: 25 * mb}
+}
+
+func (m *scalewayMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, Headers: true, MaxMessageSize: 2 * mb}
+}
+
+// Capabilities of Loops, whose transactional emails are designed in Loops.
+func (m *loopsMailer) Capabilities() Capabilities {
+	return Capabilities{Attachments: true, Templates: true}
+}
+
+func (m *plunkMailer) Capabilities() Capabilities {
+	return Capabilities{Headers: true}
+}
+
+// Capabilities of a JSON API, which sends the fields it maps.
+func (m *jsonAPIMailer) Capabilities() Capabilities {
+	fields := m.config.Fields
+	return Capabilities{Attachments: fields.Attachments != "", Tags: fields.Tags != "", Headers: fields.Headers != ""}
+}
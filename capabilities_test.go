@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type capableClient struct {
+	mockSendFuncClient
+	caps Capabilities
+}
+
+func (c *capableClient) Capabilities() Capabilities { return c.caps }
+
+func TestMailer_Capabilities(t *testing.T) {
+	caps := CapabilitiesOf(newLoops(loopsParams{}))
+	if !caps.Templates || caps.MaxRecipients != 1 || caps.Batch {
+		t.Errorf("Expected Loops templates of a single recipient, got %+v", caps)
+	}
+
+	caps = CapabilitiesOf(newPostmark(postmarkParams{}))
+	if caps.AMP || caps.MaxRecipients != 50 || !caps.Batch || caps.MaxMessageSize != 10*mb {
+		t.Errorf("Expected the Postmark capabilities, got %+v", caps)
+	}
+
+	// A client without capabilities is assumed to be an SMTP server.
+	caps = CapabilitiesOf(&mockMailerClient{})
+	if !caps.Attachments || !caps.Headers || caps.MaxMessageSize != 0 {
+		t.Errorf("Expected the default capabilities, got %+v", caps)
+	}
+}
+
+func TestCapabilities_Check(t *testing.T) {
+	attachment := Mail{Attachments: []Attachment{{Name: "a.txt", Content: []byte("hello")}}}
+
+	err := (Capabilities{}).Check(PLUNK, attachment)
+	var capErr *CapabilityError
+	if !errors.As(err, &capErr) || capErr.Capability != "attachments" || !errors.Is(err, ErrUnsupportedCapability) {
+		t.Errorf("Expected an attachments CapabilityError, got %v", err)
+	}
+
+	if err := (Capabilities{Attachments: true, MaxMessageSize: 100}).Check(SENDGRID, attachment); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	large := Mail{Html: strings.Repeat("a", 80), Attachments: []Attachment{{Name: "a.txt", Content: []byte("hello world")}}}
+	if err := (Capabilities{Attachments: true, MaxMessageSize: 90}).Check(SENDGRID, large); !errors.As(err, &capErr) || capErr.Capability != "message size" {
+		t.Errorf("Expected a message size CapabilityError, got %v", err)
+	}
+}
+
+func TestMailer_SendChecksCapabilities(t *testing.T) {
+	sent := 0
+	client := &capableClient{caps: Capabilities{Headers: true}}
+	client.sendFunc = func(msg Mail) error {
+		sent++
+		return nil
+	}
+	mailer := NewMailer(MailCfg{mailerClient: client})
+	defer mailer.Close()
+
+	err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Attachments: []Attachment{{Name: "a.txt", Content: []byte("hello")}}})
+	if !errors.Is(err, ErrUnsupportedCapability) {
+		t.Errorf("Expected the email to be refused before being sent, got %v", err)
+	}
+	if sent != 0 {
+		t.Errorf("Expected no call to the provider, got %d", sent)
+	}
+}
@@ -0,0 +1,111 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrChaos is the error injected by ChaosMiddleware.
+var ErrChaos = errors.New("chaos: injected failure")
+
+// ChaosConfig is the misbehavior injected by ChaosMiddleware. The rates are
+// between 0 and 1 and are drawn independently for every send.
+type ChaosConfig struct {
+	// Latency is added to every send, plus a random duration up to Jitter.
+	Latency time.Duration
+	Jitter  time.Duration
+	// ErrorRate is the ratio of sends failing with Error without being sent.
+	ErrorRate float64
+	// Error is the error injected, defaults to ErrChaos. Use a *APIError to
+	// simulate a specific status code.
+	Error error
+	// TimeoutRate is the ratio of sends hanging for Timeout, then failing with
+	// an error wrapping context.DeadlineExceeded without being sent.
+	TimeoutRate float64
+	// Timeout defaults to 30 seconds.
+	Timeout time.Duration
+	// PartialFailureRate is the ratio of sends delivered to the first half of
+	// the recipients only, the others being reported in the error. A single
+	// recipient email is sent but fails, as when the response of the provider
+	// is lost, so that retries deliver duplicates.
+	PartialFailureRate float64
+	// Seed makes the injected failures reproducible when not zero.
+	Seed int64
+}
+
+// ChaosMiddleware returns a Middleware injecting latency, errors, timeouts and
+// partial failures in the sends, so that applications can verify their retry
+// and alerting behavior against a misbehaving provider.
+func ChaosMiddleware(cfg ChaosConfig) Middleware {
+	if cfg.Error == nil {
+		cfg.Error = ErrChaos
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	var mu sync.Mutex
+	random := rand.New(rand.NewSource(seed))
+	draw := func(rate float64) bool {
+		if rate <= 0 {
+			return false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return random.Float64() < rate
+	}
+	jitter := func() time.Duration {
+		if cfg.Jitter <= 0 {
+			return 0
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return time.Duration(random.Int63n(int64(cfg.Jitter)))
+	}
+
+	return func(next SendFunc) SendFunc {
+		return func(msg Mail) error {
+			if delay := cfg.Latency + jitter(); delay > 0 {
+				time.Sleep(delay)
+			}
+
+			switch {
+			case draw(cfg.ErrorRate):
+				return cfg.Error
+			case draw(cfg.TimeoutRate):
+				time.Sleep(cfg.Timeout)
+				return fmt.Errorf("%w: %w", ErrChaos, context.DeadlineExceeded)
+			case draw(cfg.PartialFailureRate):
+				return partialFailure(next, msg)
+			}
+			return next(msg)
+		}
+	}
+}
+
+// partialFailure sends the email to the first half of its recipients, failing
+// with the others.
+func partialFailure(next SendFunc, msg Mail) error {
+	recipients := allRecipients(msg)
+	if len(recipients) <= 1 {
+		if err := next(msg); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: response lost", ErrChaos)
+	}
+
+	half := (len(recipients) + 1) / 2
+	delivered := chunkRecipients(msg, half)[0]
+	if err := next(delivered); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: rejected %s", ErrChaos, joinAddresses(recipients[half:]))
+}
@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChaosMiddleware(t *testing.T) {
+	var sent []Mail
+	send := func(msg Mail) error {
+		sent = append(sent, msg)
+		return nil
+	}
+
+	failing := ChaosMiddleware(ChaosConfig{ErrorRate: 1, Error: &APIError{Provider: SENDGRID, StatusCode: 503}})(send)
+	var apiErr *APIError
+	if err := failing(Mail{To: Addresses("a@test.com")}); !errors.As(err, &apiErr) || apiErr.StatusCode != 503 || len(sent) != 0 {
+		t.Errorf("Expected the injected error without sending, got %v and %d sent", err, len(sent))
+	}
+
+	timingOut := ChaosMiddleware(ChaosConfig{TimeoutRate: 1, Timeout: time.Millisecond})(send)
+	if err := timingOut(Mail{To: Addresses("a@test.com")}); !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrChaos) {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+
+	partial := ChaosMiddleware(ChaosConfig{PartialFailureRate: 1})(send)
+	err := partial(Mail{To: Addresses("a@test.com", "b@test.com"), Cc: Addresses("c@test.com")})
+	if err == nil || !strings.Contains(err.Error(), "rejected c@test.com") {
+		t.Errorf("Expected the last recipient to be rejected, got %v", err)
+	}
+	if len(sent) != 1 || joinAddresses(sent[0].To) != "a@test.com, b@test.com" || joinAddresses(sent[0].Cc) != "" {
+		t.Errorf("Expected the email to be sent to the first recipients, got %+v", sent)
+	}
+
+	if err := partial(Mail{To: Addresses("a@test.com")}); !errors.Is(err, ErrChaos) || len(sent) != 2 {
+		t.Errorf("Expected a single recipient email to be sent and fail, got %v", err)
+	}
+
+	slow := ChaosMiddleware(ChaosConfig{Latency: 20 * time.Millisecond})(send)
+	start := time.Now()
+	if err := slow(Mail{To: Addresses("a@test.com")}); err != nil || time.Since(start) < 20*time.Millisecond {
+		t.Errorf("Expected the send to be delayed, got %v after %s", err, time.Since(start))
+	}
+}
+
+func TestChaosMiddleware_ErrorRate(t *testing.T) {
+	send := ChaosMiddleware(ChaosConfig{ErrorRate: 0.3, Seed: 42})(func(msg Mail) error { return nil })
+
+	failed := 0
+	for i := 0; i < 1000; i++ {
+		if send(Mail{To: Addresses("a@test.com")}) != nil {
+			failed++
+		}
+	}
+	if failed < 250 || failed > 350 {
+		t.Errorf("Expected about 300 failures, got %d", failed)
+	}
+}
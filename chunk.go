@@ -0,0 +1,150 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// recipientLimiter is implemented by the clients limiting the number of recipients
+// of a single API call or SMTP transaction.
+type recipientLimiter interface {
+	maxRecipients() int
+}
+
+func (m *sendgridMailer) maxRecipients() int { return 1000 }
+func (m *mailgunMailer) maxRecipients() int  { return 1000 }
+func (m *sesMailer) maxRecipients() int      { return 50 }
+func (m *postmarkMailer) maxRecipients() int { return 50 }
+func (m *resendMailer) maxRecipients() int   { return 50 }
+func (m *brevoMailer) maxRecipients() int    { return 99 }
+func (m *mailjetMailer) maxRecipients() int  { return 50 }
+func (m *smtp2goMailer) maxRecipients() int  { return 100 }
+func (m *mandrillMailer) maxRecipients() int { return 1000 }
+func (m *loopsMailer) maxRecipients() int    { return 1 }
+
+// maxRecipients follows the RFC 5321 minimum of 100 recipients a server must accept.
+func (m *smtpMailer) maxRecipients() int { return 100 }
+
+// envelopeChunker is implemented by the SMTP and MX transports, which split only the
+// envelope of a large recipient list into transactions of the same message, its
+// To and Cc headers listing every recipient for Reply-All to keep working.
+type envelopeChunker interface {
+	sendEnvelopeChunks(msg Mail, limit int) error
+}
+
+// recipientLimit returns the configured MaxRecipients, or the limit of the client.
+func (m *Mailer) recipientLimit(client MailerClient) int {
+	if m.maxRecipients > 0 {
+		return m.maxRecipients
+	}
+	if limiter, ok := client.(recipientLimiter); ok {
+		return limiter.maxRecipients()
+	}
+	return 0
+}
+
+// sendChunks sends the email in as many calls as needed for each to stay within
+// the recipient limit. Every chunk is sent even when one fails, unless the provider
+// is rate limited, and the failures are returned together with the responses of
+// the provider.
+func sendChunks(client MailerClient, msg Mail, limit int) ([]ProviderResponse, error) {
+	if chunker, ok := client.(envelopeChunker); ok {
+		return nil, chunker.sendEnvelopeChunks(msg, limit)
+	}
+	if len(msg.EnvelopeTo) > 0 {
+		msg = onlyRecipients(msg, msg.EnvelopeTo)
+	}
+	chunks := chunkRecipients(msg, limit)
+
+	var responses []ProviderResponse
+	err := eachChunk(len(chunks), func(i int) error {
+		response, err := sendCapturing(client, chunks[i])
+		var apiErr *APIError
+		if response == nil && errors.As(err, &apiErr) {
+			response = apiErr.Response
+		}
+		if response != nil {
+			responses = append(responses, *response)
+		}
+		return err
+	})
+	return responses, err
+}
+
+// eachChunk sends the n chunks in turn, every one even when one fails unless
+// the provider is rate limited, and returns the failures together.
+func eachChunk(n int, send func(i int) error) error {
+	var errs []error
+	for i := 0; i < n; i++ {
+		err := send(i)
+		if err != nil && n > 1 {
+			err = fmt.Errorf("chunk %d of %d: %w", i+1, n, err)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if _, ok := isRateLimited(err); ok {
+			break
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// chunkRecipients splits the recipients of the email into emails of at most limit
+// recipients for the API providers, whose calls list their recipients, filled in the To, Cc then Bcc order so that the Cc and Bcc recipients
+// of a short list stay with the first To recipients.
+func chunkRecipients(msg Mail, limit int) []Mail {
+	if limit <= 0 || len(allRecipients(msg)) <= limit {
+		return []Mail{msg}
+	}
+
+	var chunks []Mail
+	var chunkTo, chunkCc, chunkBcc []Address
+	count := 0
+	flush := func() {
+		chunk := msg
+		chunk.To, chunk.Cc, chunk.Bcc = chunkTo, chunkCc, chunkBcc
+		chunks = append(chunks, chunk)
+		chunkTo, chunkCc, chunkBcc, count = nil, nil, nil, 0
+	}
+	for _, field := range []struct {
+		addrs []Address
+		chunk *[]Address
+	}{{msg.To, &chunkTo}, {msg.Cc, &chunkCc}, {msg.Bcc, &chunkBcc}} {
+		for _, addr := range field.addrs {
+			*field.chunk = append(*field.chunk, addr)
+			if count++; count == limit {
+				flush()
+			}
+		}
+	}
+	if count > 0 {
+		flush()
+	}
+	return chunks
+}
+
+// onlyRecipients drops the recipients missing from rcpts from the To, Cc and Bcc
+// lists of the email, for the API providers to send to rcpts only.
+func onlyRecipients(msg Mail, rcpts []string) Mail {
+	keep := make(map[string]bool, len(rcpts))
+	for _, rcpt := range rcpts {
+		keep[strings.ToLower(toAddress(rcpt).Email)] = true
+	}
+	filter := func(list []Address) []Address {
+		var addrs []Address
+		for _, addr := range list {
+			if keep[strings.ToLower(addr.Email)] {
+				addrs = append(addrs, addr)
+			}
+		}
+		return addrs
+	}
+	msg.To, msg.Cc, msg.Bcc = filter(msg.To), filter(msg.Cc), filter(msg.Bcc)
+	msg.EnvelopeTo = nil
+	return msg
+}
@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestChunkRecipients(t *testing.T) {
+	msg := Mail{To: Addresses("a@test.com", "b@test.com", "c@test.com"), Cc: Addresses("d@test.com"), Bcc: Addresses("e@test.com"), Subject: "test"}
+
+	chunks := chunkRecipients(msg, 2)
+	expected := []Mail{
+		{To: Addresses("a@test.com", "b@test.com"), Subject: "test"},
+		{To: Addresses("c@test.com"), Cc: Addresses("d@test.com"), Subject: "test"},
+		{Bcc: Addresses("e@test.com"), Subject: "test"},
+	}
+	if len(chunks) != len(expected) {
+		t.Fatalf("Expected %d chunks, got %+v", len(expected), chunks)
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(chunks[i].To, expected[i].To) || !reflect.DeepEqual(chunks[i].Cc, expected[i].Cc) || !reflect.DeepEqual(chunks[i].Bcc, expected[i].Bcc) || chunks[i].Subject != "test" {
+			t.Errorf("Expected chunk %+v, got %+v", expected[i], chunks[i])
+		}
+	}
+
+	if chunks := chunkRecipients(msg, 5); len(chunks) != 1 || !reflect.DeepEqual(chunks[0].To, msg.To) {
+		t.Errorf("Expected the email to be left alone within the limit, got %+v", chunks)
+	}
+}
+
+func TestMailer_SendChunks(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	mailer := NewMailer(MailCfg{MaxRecipients: 2, mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, joinAddresses(msg.To))
+		if strings.Contains(joinAddresses(msg.To), "bounce@test.com") {
+			return errors.New("mailbox unavailable")
+		}
+		return nil
+	}}})
+	defer mailer.Close()
+
+	err := mailer.Send(Mail{To: Addresses("a@test.com", "b@test.com", "c@test.com", "bounce@test.com", "e@test.com")})
+	if err == nil || !strings.Contains(err.Error(), "chunk 2 of 3: mailbox unavailable") {
+		t.Errorf("Expected the failure of the second chunk, got %v", err)
+	}
+	if len(calls) != 3 {
+		t.Errorf("Expected every chunk to be sent, got %q", calls)
+	}
+	if stats := mailer.Stats(); stats.SendsPerMinute != 1 {
+		t.Errorf("Expected a single logical send, got %+v", stats)
+	}
+}
@@ -0,0 +1,53 @@
+package mailer
+
+// MessageClass classifies an email for the headers preventing autoresponders,
+// e.g. vacation replies, from answering it and mail loops.
+type MessageClass string
+
+const (
+	// MessageTransactional is an email triggered by an action of the recipient,
+	// e.g. a password reset. Delivery reports are still sent for it.
+	MessageTransactional MessageClass = "transactional"
+	// MessageAutomated is an email sent by a system without any action of the
+	// recipient, e.g. an alert or a reply of a ticketing system.
+	MessageAutomated MessageClass = "automated"
+	// MessageMarketing is an email sent in bulk, e.g. a newsletter.
+	MessageMarketing MessageClass = "marketing"
+)
+
+// headers returns the Auto-Submitted (RFC 3834), Precedence and Exchange
+// X-Auto-Response-Suppress headers of the class.
+func (c MessageClass) headers() map[string]string {
+	switch c {
+	case MessageTransactional:
+		return map[string]string{
+			"Auto-Submitted":           "auto-generated",
+			"X-Auto-Response-Suppress": "OOF, AutoReply",
+		}
+	case MessageAutomated:
+		return map[string]string{
+			"Auto-Submitted":           "auto-generated",
+			"Precedence":               "auto_reply",
+			"X-Auto-Response-Suppress": "All",
+		}
+	case MessageMarketing:
+		return map[string]string{
+			"Auto-Submitted":           "auto-generated",
+			"Precedence":               "bulk",
+			"X-Auto-Response-Suppress": "All",
+		}
+	}
+	return nil
+}
+
+// applyMessageClass sets the headers of the class of the email, leaving the
+// headers already set by the caller.
+func applyMessageClass(msg Mail) Mail {
+	headers := msg.Class.headers()
+	for _, key := range headerKeys(headers) {
+		if _, ok := msg.Headers[key]; !ok {
+			msg = withHeader(msg, key, headers[key])
+		}
+	}
+	return msg
+}
@@ -0,0 +1,32 @@
+package mailer
+
+import "testing"
+
+func TestMailer_SendMessageClass(t *testing.T) {
+	sent := make(chan Mail, 1)
+	mailer := NewMailer(MailCfg{mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+		sent <- msg
+		return nil
+	}}})
+	defer mailer.Close()
+
+	mailer.Send(Mail{To: Addresses("a@test.com"), Class: MessageMarketing, Headers: map[string]string{"Precedence": "list"}})
+	msg := <-sent
+	if msg.Headers["Auto-Submitted"] != "auto-generated" || msg.Headers["X-Auto-Response-Suppress"] != "All" {
+		t.Errorf("Expected the marketing headers, got %v", msg.Headers)
+	}
+	if msg.Headers["Precedence"] != "list" {
+		t.Errorf("Expected the Precedence of the caller to be kept, got %v", msg.Headers)
+	}
+
+	mailer.Send(Mail{To: Addresses("a@test.com"), Class: MessageTransactional})
+	msg = <-sent
+	if _, ok := msg.Headers["Precedence"]; ok || msg.Headers["X-Auto-Response-Suppress"] != "OOF, AutoReply" {
+		t.Errorf("Expected the transactional headers, got %v", msg.Headers)
+	}
+
+	mailer.Send(Mail{To: Addresses("a@test.com")})
+	if msg = <-sent; msg.Headers["Auto-Submitted"] != "" || msg.Headers["Precedence"] != "" {
+		t.Errorf("Expected no class headers without a class, got %v", msg.Headers)
+	}
+}
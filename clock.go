@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"time"
+)
+
+// Clock tells the time and schedules the timers of the mailer, e.g. the requeue
+// of deferred emails, the MX retries and the periodic jobs such as the spool
+// replay, so that tests can control them.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	AfterFunc(d time.Duration, f func()) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a timer scheduled by a Clock.
+type Timer interface {
+	Stop() bool
+}
+
+// Ticker is a ticker started by a Clock, delivering the ticks on C.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct{ *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// newMessageIDFrom returns a newMessageID read from the random source.
+func newMessageIDFrom(random io.Reader) string {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(random, b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// newMessageID returns a random id identifying an email within the mailer.
+func newMessageID() string {
+	return newMessageIDFrom(rand.Reader)
+}
@@ -0,0 +1,209 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock only moving when advanced, firing the timers and the
+// tickers due.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	sleeps  []time.Duration
+	timers  []*fakeTimer
+	tickers []*fakeTicker
+}
+
+type fakeTimer struct {
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	stopped := t.stopped
+	t.stopped = true
+	return !stopped
+}
+
+type fakeTicker struct {
+	clock   *fakeClock
+	c       chan time.Time
+	period  time.Duration
+	next    time.Time
+	stopped bool
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.stopped = true
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ticker := &fakeTicker{clock: c, c: make(chan time.Time, 1), period: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, ticker)
+	return ticker
+}
+
+func (c *fakeClock) pendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []*fakeTimer
+	for _, timer := range c.timers {
+		if !timer.stopped && !timer.at.After(c.now) {
+			timer.stopped = true
+			due = append(due, timer)
+		}
+	}
+	for _, ticker := range c.tickers {
+		if ticker.stopped || ticker.next.After(c.now) {
+			continue
+		}
+		// Like a time.Ticker, the ticks missed by a slow receiver are dropped.
+		select {
+		case ticker.c <- c.now:
+		default:
+		}
+		for !ticker.next.After(c.now) {
+			ticker.next = ticker.next.Add(ticker.period)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, timer := range due {
+		timer.f()
+	}
+}
+
+func TestMailer_Clock(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)}
+	var events []Event
+	mailer := NewMailer(MailCfg{Clock: clock, EventHandler: func(e Event) { events = append(events, e) }, mailerClient: &mockMailerClient{}})
+	defer mailer.Close()
+
+	err := mailer.Send(Mail{To: Addresses("a@test.com"), ExpiresAt: clock.now.Add(-time.Second)})
+	if !errors.Is(err, ErrMessageExpired) {
+		t.Fatalf("Expected ErrMessageExpired, got %v", err)
+	}
+	if len(events) != 1 || !events[0].Timestamp.Equal(clock.now) {
+		t.Errorf("Expected the event at the time of the clock, got %+v", events)
+	}
+
+	if err := mailer.Send(Mail{To: Addresses("a@test.com"), ExpiresAt: clock.now.Add(time.Second)}); err != nil {
+		t.Errorf("Expected the email to be sent before it expires, got %v", err)
+	}
+}
+
+func TestMailer_ClockRequeue(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 23, 59, 0, 0, time.UTC)}
+	sent := make(chan Mail, 2)
+	mailer := NewMailer(MailCfg{
+		Clock:  clock,
+		Quotas: []Quota{{Limit: 1, Period: QuotaDaily, Action: QuotaDefer}},
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			sent <- msg
+			return nil
+		}},
+	})
+	defer mailer.Close()
+
+	mailer.Send(Mail{To: Addresses("a@test.com")})
+	<-sent
+	var deferred *DeferredError
+	if err := mailer.Send(Mail{To: Addresses("c@test.com")}); !errors.As(err, &deferred) || !deferred.Until.Equal(clock.now.Add(time.Minute)) {
+		t.Fatalf("Expected Send to return the deferral until midnight, got %v", err)
+	}
+	mailer.Enqueue(Mail{To: Addresses("b@test.com")})
+
+	for clock.pendingTimers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case msg := <-sent:
+		t.Fatalf("Expected the email to be deferred, got %+v", msg)
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	if msg := <-sent; joinAddresses(msg.To) != "b@test.com" {
+		t.Errorf("Expected the deferred email at midnight, got %+v", msg)
+	}
+}
+
+func (c *fakeClock) pendingTickers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.tickers)
+}
+
+func TestMailer_Rand(t *testing.T) {
+	ids := func() []string {
+		mailer := NewMailer(MailCfg{Rand: rand.New(rand.NewSource(42)), mailerClient: &mockMailerClient{}})
+		defer mailer.Close()
+		a, _ := mailer.Enqueue(Mail{To: Addresses("a@test.com")})
+		b, _ := mailer.Enqueue(Mail{To: Addresses("b@test.com")})
+		return []string{a, b}
+	}
+
+	first, second := ids(), ids()
+	if first[0] != second[0] || first[1] != second[1] || first[0] == first[1] {
+		t.Errorf("Expected deterministic ids, got %v and %v", first, second)
+	}
+}
+
+func TestMX_ClockRetries(t *testing.T) {
+	clock := &fakeClock{}
+	mx := newMX(mxParams{retries: 2, retryDelay: time.Second, clock: clock, resolver: failingResolver{}}).(*mxMailer)
+	defer mx.Close()
+
+	if err := mx.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Text: "test"}); err == nil {
+		t.Fatalf("Expected the lookup to fail")
+	}
+	if len(clock.sleeps) != 2 || clock.sleeps[0] != time.Second || clock.sleeps[1] != 2*time.Second {
+		t.Errorf("Expected the retries to wait on the clock, got %v", clock.sleeps)
+	}
+}
+
+type failingResolver struct{}
+
+func (failingResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	return nil, &net.DNSError{Err: "server misbehaving", Name: name, IsTemporary: true}
+}
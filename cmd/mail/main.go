@@ -0,0 +1,134 @@
+// Command mail provides tooling around the Caesar mail package.
+//
+// Usage:
+//
+//	mail domain-check [-selectors s1,s2] <domain>
+//	mail bench [-provider null|dev] [-n 1000] [-c 1] [-host h] [-port p] [-memprofile file]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	mailer "github.com/caesar-rocks/mail"
+	"github.com/caesar-rocks/mail/mailerbench"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "domain-check":
+		err = domainCheck(os.Args[2:])
+	case "bench":
+		err = bench(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: mail <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  domain-check   check the SPF, DKIM and DMARC records of a sending domain")
+	fmt.Fprintln(os.Stderr, "  bench          send synthetic load to a null or dev provider and report the results")
+}
+
+func domainCheck(args []string) error {
+	flags := flag.NewFlagSet("domain-check", flag.ExitOnError)
+	selectors := flags.String("selectors", "", "comma separated DKIM selectors to check")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: mail domain-check [-selectors s1,s2] <domain>")
+	}
+
+	var dkimSelectors []string
+	if *selectors != "" {
+		dkimSelectors = strings.Split(*selectors, ",")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	report, err := mailer.DomainCheck(ctx, flags.Arg(0), dkimSelectors...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("SPF:   %s\n", report.SPF)
+	for _, selector := range dkimSelectors {
+		fmt.Printf("DKIM:  %s: %s\n", selector, report.DKIM[selector])
+	}
+	fmt.Printf("DMARC: %s\n", report.DMARC)
+	for _, issue := range report.Issues {
+		fmt.Println(issue)
+	}
+
+	if !report.OK() {
+		return fmt.Errorf("%s is misconfigured", report.Domain)
+	}
+	return nil
+}
+
+func bench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	provider := flags.String("provider", "null", "provider receiving the load: null or dev")
+	messages := flags.Int("n", 1000, "number of emails to send")
+	concurrency := flags.Int("c", 1, "number of concurrent senders")
+	host := flags.String("host", "", "host of the dev mail server, defaults to localhost")
+	port := flags.String("port", "", "port of the dev mail server, defaults to 1025")
+	memProfile := flags.String("memprofile", "", "write an allocation profile to the file")
+	flags.Parse(args)
+
+	cfg := mailer.MailCfg{Host: *host, Port: *port}
+	switch *provider {
+	case "null":
+		cfg.APIService = mailer.NULL
+	case "dev":
+		cfg.APIService = mailer.DEV
+	default:
+		return fmt.Errorf("unknown provider %q, expected null or dev", *provider)
+	}
+
+	m := mailer.NewMailer(cfg)
+	defer m.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := mailerbench.Run(ctx, m, mailerbench.Config{Messages: *messages, Concurrency: *concurrency})
+	fmt.Print(report)
+	if err != nil {
+		return err
+	}
+
+	if *memProfile != "" {
+		file, err := os.Create(*memProfile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err := pprof.Lookup("allocs").WriteTo(file, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
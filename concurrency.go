@@ -0,0 +1,57 @@
+package mailer
+
+import "context"
+
+// sendLimiter bounds the number of sends in progress, overall and per provider,
+// e.g. for the SMTP relays dropping the connections beyond a handful.
+type sendLimiter struct {
+	global    chan struct{}
+	providers map[APIServiceType]chan struct{}
+}
+
+// newSendLimiter returns nil when there are no limits, acquire being a no-op on a nil limiter.
+func newSendLimiter(global int, perProvider map[APIServiceType]int) *sendLimiter {
+	limiter := &sendLimiter{providers: make(map[APIServiceType]chan struct{})}
+	if global > 0 {
+		limiter.global = make(chan struct{}, global)
+	}
+	for provider, limit := range perProvider {
+		if limit > 0 {
+			limiter.providers[provider] = make(chan struct{}, limit)
+		}
+	}
+	if limiter.global == nil && len(limiter.providers) == 0 {
+		return nil
+	}
+	return limiter
+}
+
+// acquire waits for a slot of the provider then of the global limit, returning
+// the function releasing them. The provider slot is taken first so that the
+// sends waiting on a busy provider do not hold the global slots the other
+// providers could use.
+func (l *sendLimiter) acquire(ctx context.Context, provider APIServiceType) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	var taken []chan struct{}
+	release := func() {
+		for _, sem := range taken {
+			<-sem
+		}
+	}
+	for _, sem := range []chan struct{}{l.providers[provider], l.global} {
+		if sem == nil {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+			taken = append(taken, sem)
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
@@ -0,0 +1,109 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyClient records the highest number of sends in progress at once.
+type concurrencyClient struct {
+	mockMailerClient
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (c *concurrencyClient) Send(msg Mail) error {
+	c.mu.Lock()
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return nil
+}
+
+func TestMailer_MaxConcurrentSends(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      MailCfg
+		expected int
+	}{
+		{
+			name:     "Should bound the sends overall",
+			cfg:      MailCfg{SendWorkers: 8, MaxConcurrentSends: 2},
+			expected: 2,
+		},
+		{
+			name:     "Should bound the sends per provider",
+			cfg:      MailCfg{SendWorkers: 8, MaxConcurrentSendsPerProvider: map[APIServiceType]int{RESEND: 3}},
+			expected: 3,
+		},
+		{
+			name:     "Should send in order with a single worker",
+			expected: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &concurrencyClient{}
+			cfg := tc.cfg
+			cfg.APIService, cfg.APIKey, cfg.mailerClient = RESEND, MailAPIKey, client
+			mailer := NewMailer(cfg)
+			defer mailer.Close()
+
+			var wg sync.WaitGroup
+			for i := 0; i < 12; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test", Text: "test"}); err != nil {
+						t.Errorf("Expected no error, got %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if client.peak != tc.expected {
+				t.Errorf("Expected at most %d sends at once, got %d", tc.expected, client.peak)
+			}
+		})
+	}
+}
+
+func TestSendLimiter(t *testing.T) {
+	if newSendLimiter(0, map[APIServiceType]int{SMTP: 0}) != nil {
+		t.Fatalf("Expected no limiter without limits")
+	}
+
+	limiter := newSendLimiter(2, map[APIServiceType]int{SMTP: 1})
+	releaseSMTP, err := limiter.acquire(context.Background(), SMTP)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The SMTP slot is taken, the next SMTP send waits without holding a global slot.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(ctx, SMTP); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the SMTP send to wait, got %v", err)
+	}
+	releaseResend, err := limiter.acquire(context.Background(), RESEND)
+	if err != nil {
+		t.Fatalf("Expected the other provider to get the free global slot, got %v", err)
+	}
+
+	releaseSMTP()
+	releaseResend()
+	if len(limiter.global) != 0 || len(limiter.providers[SMTP]) != 0 {
+		t.Errorf("Expected every slot to be released")
+	}
+}
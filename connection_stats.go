@@ -0,0 +1,90 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"sync"
+	"time"
+)
+
+// ConnectionStats counts the connections of an SMTP client, showing how many
+// sends paid for a new connection and a full TLS handshake, the bulk of the
+// latency of a one-time password email.
+type ConnectionStats struct {
+	// Opened is the number of connections opened to the server.
+	Opened int
+	// Reused is the number of sends over a connection that already carried one.
+	Reused int
+	// Handshakes is the number of TLS handshakes, Resumed of them having resumed
+	// a previous session from its ticket instead of a full handshake.
+	Handshakes int
+	Resumed    int
+	// ConnectTime is the average time to open a connection, from the dial to the
+	// TLS handshake and the authentication.
+	ConnectTime time.Duration
+}
+
+// ConnectionReporter is implemented by the mailer clients keeping connections
+// to an SMTP server.
+type ConnectionReporter interface {
+	ConnectionStats() ConnectionStats
+}
+
+// connTracker resumes the TLS sessions of a client and counts its connections.
+type connTracker struct {
+	sessions tls.ClientSessionCache
+
+	mu           sync.Mutex
+	stats        ConnectionStats
+	totalConnect time.Duration
+}
+
+func newConnTracker() *connTracker {
+	return &connTracker{sessions: tls.NewLRUClientSessionCache(0)}
+}
+
+// tlsConfig returns a copy of the config caching the session tickets, for the
+// next connections to the same server to resume the session, and counting the
+// handshakes.
+func (t *connTracker) tlsConfig(config *tls.Config) *tls.Config {
+	config = config.Clone()
+	config.ClientSessionCache = t.sessions
+	verify := config.VerifyConnection
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if verify != nil {
+			if err := verify(state); err != nil {
+				return err
+			}
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.stats.Handshakes++
+		if state.DidResume {
+			t.stats.Resumed++
+		}
+		return nil
+	}
+	return config
+}
+
+func (t *connTracker) opened(connectTime time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Opened++
+	t.totalConnect += connectTime
+}
+
+func (t *connTracker) reused() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Reused++
+}
+
+func (t *connTracker) snapshot() ConnectionStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.stats
+	if stats.Opened > 0 {
+		stats.ConnectTime = t.totalConnect / time.Duration(stats.Opened)
+	}
+	return stats
+}
@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConnTracker_ResumesTLSSessions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	tracker := newConnTracker()
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   tracker.tlsConfig(&tls.Config{InsecureSkipVerify: true}),
+		DisableKeepAlives: true,
+	}}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	stats := tracker.snapshot()
+	if stats.Handshakes != 3 || stats.Resumed != 2 {
+		t.Errorf("Expected 3 handshakes of which 2 resumed, got %+v", stats)
+	}
+}
+
+func TestConnTracker_Snapshot(t *testing.T) {
+	tracker := newConnTracker()
+	tracker.opened(10 * time.Millisecond)
+	tracker.opened(30 * time.Millisecond)
+	tracker.reused()
+
+	stats := tracker.snapshot()
+	if stats.Opened != 2 || stats.Reused != 1 || stats.ConnectTime != 20*time.Millisecond {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	var metrics strings.Builder
+	writeMetrics(&metrics, Stats{Connections: &stats})
+	for _, metric := range []string{"mailer_connections_opened_total 2\n", "mailer_connections_reused_total 1\n", "mailer_connect_seconds 0.02\n"} {
+		if !strings.Contains(metrics.String(), metric) {
+			t.Errorf("Expected %q in the metrics, got:\n%s", metric, metrics.String())
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrContentBlocked is returned when a content filter blocks an email.
+var ErrContentBlocked = errors.New("email content blocked")
+
+// ContentFilter inspects the rendered subject and bodies of emails. It returns the
+// email to send, possibly redacted, or an error wrapping ErrContentBlocked.
+type ContentFilter interface {
+	Filter(ctx context.Context, msg Mail) (Mail, error)
+}
+
+// ContentFilterMiddleware returns a Middleware running the filters in order on every email.
+func ContentFilterMiddleware(filters ...ContentFilter) Middleware {
+	return func(next SendFunc) SendFunc {
+		return func(msg Mail) error {
+			for _, filter := range filters {
+				var err error
+				if msg, err = filter.Filter(context.Background(), msg); err != nil {
+					return err
+				}
+			}
+			return next(msg)
+		}
+	}
+}
+
+// FilterAction is what a FilterRule does with its matches.
+type FilterAction int
+
+const (
+	// FilterBlock rejects the email.
+	FilterBlock FilterAction = iota
+	// FilterRedact replaces the matches with the redaction text.
+	FilterRedact
+)
+
+// FilterRule finds sensitive content in a text.
+type FilterRule struct {
+	// Name identifies the rule in errors.
+	Name string
+	// Find returns the [start, end) indexes of the matches in the text.
+	Find func(text string) [][]int
+	// Action is taken when the rule matches.
+	Action FilterAction
+}
+
+// RuleFilter is a ContentFilter applying rules to the subject, text and html of emails.
+type RuleFilter struct {
+	// Rules are applied in order.
+	Rules []FilterRule
+	// Redaction replaces the redacted matches. Defaults to "[REDACTED]".
+	Redaction string
+}
+
+func (f RuleFilter) Filter(ctx context.Context, msg Mail) (Mail, error) {
+	redaction := f.Redaction
+	if redaction == "" {
+		redaction = "[REDACTED]"
+	}
+
+	for _, rule := range f.Rules {
+		for _, field := range []*string{&msg.Subject, &msg.Text, &msg.Html} {
+			matches := rule.Find(*field)
+			if len(matches) == 0 {
+				continue
+			}
+			if rule.Action == FilterBlock {
+				return msg, fmt.Errorf("%w: %s", ErrContentBlocked, rule.Name)
+			}
+
+			var redacted strings.Builder
+			last := 0
+			for _, match := range matches {
+				redacted.WriteString((*field)[last:match[0]])
+				redacted.WriteString(redaction)
+				last = match[1]
+			}
+			redacted.WriteString((*field)[last:])
+			*field = redacted.String()
+		}
+	}
+	return msg, nil
+}
+
+// PatternRule creates a FilterRule matching the regular expression.
+func PatternRule(name string, pattern *regexp.Regexp, action FilterAction) FilterRule {
+	return FilterRule{
+		Name:   name,
+		Find:   func(text string) [][]int { return pattern.FindAllStringIndex(text, -1) },
+		Action: action,
+	}
+}
+
+// BannedPhrasesRule creates a FilterRule matching the phrases, case insensitively.
+func BannedPhrasesRule(action FilterAction, phrases ...string) FilterRule {
+	quoted := make([]string, len(phrases))
+	for i, phrase := range phrases {
+		quoted[i] = regexp.QuoteMeta(phrase)
+	}
+	return PatternRule("banned-phrase", regexp.MustCompile(`(?i)\b(`+strings.Join(quoted, "|")+`)\b`), action)
+}
+
+var creditCardPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+
+// CreditCardRule creates a FilterRule matching card numbers passing the Luhn check.
+func CreditCardRule(action FilterAction) FilterRule {
+	return FilterRule{
+		Name: "credit-card",
+		Find: func(text string) [][]int {
+			var matches [][]int
+			for _, match := range creditCardPattern.FindAllStringIndex(text, -1) {
+				if luhnValid(text[match[0]:match[1]]) {
+					matches = append(matches, match)
+				}
+			}
+			return matches
+		},
+		Action: action,
+	}
+}
+
+var secretPattern = regexp.MustCompile(strings.Join([]string{
+	`\bAKIA[0-9A-Z]{16}\b`,                         // AWS access key id
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----`,           // PEM private key
+	`\b(?:sk|rk)_live_[0-9a-zA-Z]{16,}\b`,          // Stripe secret key
+	`\bgh[pousr]_[0-9A-Za-z]{36}\b`,                // GitHub token
+	`\bxox[abpr]-[0-9A-Za-z-]{10,}\b`,              // Slack token
+	`\beyJ[0-9A-Za-z_-]{8,}\.eyJ[0-9A-Za-z_-]{8,}`, // JWT
+}, "|"))
+
+// SecretsRule creates a FilterRule matching common credentials such as cloud
+// access keys, private keys and API tokens.
+func SecretsRule(action FilterAction) FilterRule {
+	return PatternRule("secret", secretPattern, action)
+}
+
+func luhnValid(number string) bool {
+	sum, digits := 0, 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && sum%10 == 0
+}
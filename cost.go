@@ -0,0 +1,48 @@
+package mailer
+
+// Price is the estimated cost of sending an email with a provider, in the
+// currency of your choice.
+type Price struct {
+	// PerEmail is charged once per email.
+	PerEmail float64
+	// PerRecipient is charged for every To, Cc and Bcc recipient.
+	PerRecipient float64
+}
+
+// CostStats aggregates the estimated cost of the emails sent since the mailer was created.
+type CostStats struct {
+	Total     float64
+	Providers map[APIServiceType]float64
+	Tenants   map[string]float64
+	Tags      map[string]float64
+}
+
+// estimate returns the estimated cost of sending the email for the price.
+func (p Price) estimate(msg Mail) float64 {
+	return p.PerEmail + p.PerRecipient*float64(len(allRecipients(msg)))
+}
+
+func (s *statsRecorder) cost(provider APIServiceType, msg Mail, amount float64) {
+	if amount == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.costs.Providers == nil {
+		s.costs = CostStats{
+			Providers: make(map[APIServiceType]float64),
+			Tenants:   make(map[string]float64),
+			Tags:      make(map[string]float64),
+		}
+	}
+
+	s.costs.Total += amount
+	s.costs.Providers[provider] += amount
+	if msg.TenantID != "" {
+		s.costs.Tenants[msg.TenantID] += amount
+	}
+	for _, tag := range msg.Tags {
+		s.costs.Tags[tag] += amount
+	}
+}
@@ -0,0 +1,286 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ErrDANEMismatch is returned when the certificate of a server does not match its TLSA records.
+var ErrDANEMismatch = errors.New("dane: certificate does not match any TLSA record")
+
+const typeTLSA dnsmessage.Type = 52
+
+// TLSARecord is a DANE TLSA record (RFC 6698).
+type TLSARecord struct {
+	// Usage is 2 (DANE-TA) or 3 (DANE-EE), 0 and 1 are not usable for SMTP (RFC 7672).
+	Usage uint8
+	// Selector is 0 for the full certificate and 1 for the SubjectPublicKeyInfo.
+	Selector uint8
+	// MatchingType is 0 for the exact data, 1 for SHA-256 and 2 for SHA-512.
+	MatchingType uint8
+	Data         []byte
+}
+
+// TLSAResolver resolves the TLSA records of a service.
+type TLSAResolver interface {
+	// LookupTLSA returns the TLSA records of the host and port, and whether
+	// the answer was authenticated by DNSSEC.
+	LookupTLSA(ctx context.Context, host string, port int) ([]TLSARecord, bool, error)
+}
+
+// DNSTLSAResolver resolves TLSA records with a DNSSEC-validating recursive resolver,
+// trusting its AD bit. The path to the resolver must therefore be trusted, in
+// practice it should be running on the loopback interface.
+type DNSTLSAResolver struct {
+	// Nameserver is the host:port of the resolver. Defaults to the first
+	// nameserver of /etc/resolv.conf.
+	Nameserver string
+	// Timeout of a query. Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// LookupTLSA queries the _port._tcp.host TLSA records.
+func (r *DNSTLSAResolver) LookupTLSA(ctx context.Context, host string, port int) ([]TLSARecord, bool, error) {
+	nameserver := r.Nameserver
+	if nameserver == "" {
+		var err error
+		if nameserver, err = systemNameserver(); err != nil {
+			return nil, false, err
+		}
+	}
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	name, err := dnsmessage.NewName(fmt.Sprintf("_%d._tcp.%s.", port, strings.TrimSuffix(host, ".")))
+	if err != nil {
+		return nil, false, err
+	}
+	query, id, err := buildTLSAQuery(name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	res, err := exchangeDNS(ctx, "udp", nameserver, query)
+	if err == nil && res.Truncated {
+		res, err = exchangeDNS(ctx, "tcp", nameserver, query)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if res.ID != id {
+		return nil, false, errors.New("dane: mismatched DNS response id")
+	}
+	switch res.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, res.AuthenticData, nil
+	default:
+		return nil, false, fmt.Errorf("dane: DNS query for %s failed: %s", name, res.RCode)
+	}
+
+	var records []TLSARecord
+	for _, answer := range res.Answers {
+		if answer.Header.Type != typeTLSA {
+			continue
+		}
+		unknown, ok := answer.Body.(*dnsmessage.UnknownResource)
+		if !ok || len(unknown.Data) < 3 {
+			continue
+		}
+		records = append(records, TLSARecord{
+			Usage:        unknown.Data[0],
+			Selector:     unknown.Data[1],
+			MatchingType: unknown.Data[2],
+			Data:         unknown.Data[3:],
+		})
+	}
+	return records, res.AuthenticData, nil
+}
+
+func buildTLSAQuery(name dnsmessage.Name) ([]byte, uint16, error) {
+	// An unpredictable id keeps an off-path attacker from spoofing the answer.
+	var b [2]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, 0, err
+	}
+	id := binary.BigEndian.Uint16(b[:])
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:               id,
+		RecursionDesired: true,
+		AuthenticData:    true,
+	})
+	builder.EnableCompression()
+	if err := builder.StartQuestions(); err != nil {
+		return nil, 0, err
+	}
+	if err := builder.Question(dnsmessage.Question{Name: name, Type: typeTLSA, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, 0, err
+	}
+	if err := builder.StartAdditionals(); err != nil {
+		return nil, 0, err
+	}
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(4096, dnsmessage.RCodeSuccess, true); err != nil {
+		return nil, 0, err
+	}
+	if err := builder.OPTResource(opt, dnsmessage.OPTResource{}); err != nil {
+		return nil, 0, err
+	}
+	query, err := builder.Finish()
+	return query, id, err
+}
+
+func exchangeDNS(ctx context.Context, network string, nameserver string, query []byte) (*dnsmessage.Message, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, nameserver)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	buf := make([]byte, 65535)
+	var n int
+	if network == "tcp" {
+		// Messages over TCP are prefixed with their length.
+		if _, err := conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(query))), query...)); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+			return nil, err
+		}
+		n = int(binary.BigEndian.Uint16(buf[:2]))
+		if _, err := io.ReadFull(conn, buf[:n]); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := conn.Write(query); err != nil {
+			return nil, err
+		}
+		if n, err = conn.Read(buf); err != nil {
+			return nil, err
+		}
+	}
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(buf[:n]); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func systemNameserver() (string, error) {
+	file, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53"), nil
+		}
+	}
+	return "", errors.New("dane: no nameserver found in /etc/resolv.conf")
+}
+
+// VerifyDANE checks the certificates presented by a server against its TLSA records.
+// DANE-EE records match the leaf certificate, whose name and expiry are ignored
+// (RFC 7672). DANE-TA records match a certificate of the chain, which must then
+// be a valid trust anchor for the leaf certificate and host.
+func VerifyDANE(state tls.ConnectionState, host string, records []TLSARecord) error {
+	certs := state.PeerCertificates
+	if len(certs) == 0 {
+		return errors.New("dane: no peer certificate")
+	}
+
+	for _, record := range records {
+		switch record.Usage {
+		case 3:
+			if matchTLSA(record, certs[0]) {
+				return nil
+			}
+		case 2:
+			for _, cert := range certs[1:] {
+				if !matchTLSA(record, cert) {
+					continue
+				}
+				roots := x509.NewCertPool()
+				roots.AddCert(cert)
+				intermediates := x509.NewCertPool()
+				for _, intermediate := range certs[1:] {
+					intermediates.AddCert(intermediate)
+				}
+				_, err := certs[0].Verify(x509.VerifyOptions{
+					DNSName:       strings.TrimSuffix(host, "."),
+					Roots:         roots,
+					Intermediates: intermediates,
+				})
+				if err == nil {
+					return nil
+				}
+			}
+		}
+	}
+	return ErrDANEMismatch
+}
+
+func matchTLSA(record TLSARecord, cert *x509.Certificate) bool {
+	var data []byte
+	switch record.Selector {
+	case 0:
+		data = cert.Raw
+	case 1:
+		data = cert.RawSubjectPublicKeyInfo
+	default:
+		return false
+	}
+
+	switch record.MatchingType {
+	case 0:
+	case 1:
+		sum := sha256.Sum256(data)
+		data = sum[:]
+	case 2:
+		sum := sha512.Sum512(data)
+		data = sum[:]
+	default:
+		return false
+	}
+	return bytes.Equal(data, record.Data)
+}
+
+// usableTLSA returns the records usable for SMTP, DANE-TA and DANE-EE.
+func usableTLSA(records []TLSARecord) []TLSARecord {
+	var usable []TLSARecord
+	for _, record := range records {
+		if record.Usage == 2 || record.Usage == 3 {
+			usable = append(usable, record)
+		}
+	}
+	return usable
+}
@@ -0,0 +1,114 @@
+package mailer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func newTestCertificate(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mail.test.com"},
+		DNSNames:     []string{"mail.test.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestVerifyDANE(t *testing.T) {
+	cert := newTestCertificate(t)
+	state := tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	spki := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	testCases := []struct {
+		name    string
+		records []TLSARecord
+		err     error
+	}{
+		{name: "DANE-EE SPKI SHA-256", records: []TLSARecord{{Usage: 3, Selector: 1, MatchingType: 1, Data: spki[:]}}},
+		{name: "DANE-EE full certificate", records: []TLSARecord{{Usage: 3, Selector: 0, MatchingType: 0, Data: cert.Raw}}},
+		{name: "mismatched digest", records: []TLSARecord{{Usage: 3, Selector: 1, MatchingType: 1, Data: make([]byte, 32)}}, err: ErrDANEMismatch},
+		{name: "PKIX usage is ignored", records: []TLSARecord{{Usage: 1, Selector: 1, MatchingType: 1, Data: spki[:]}}, err: ErrDANEMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifyDANE(state, "mail.test.com", tc.records)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestDNSTLSAResolver_LookupTLSA(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	go func() {
+		buf := make([]byte, 512)
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var query dnsmessage.Message
+		if err := query.Unpack(buf[:n]); err != nil {
+			return
+		}
+		res := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: query.ID, Response: true, AuthenticData: true},
+			Questions: query.Questions,
+			Answers: []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: query.Questions[0].Name, Type: typeTLSA, Class: dnsmessage.ClassINET},
+				Body:   &dnsmessage.UnknownResource{Type: typeTLSA, Data: []byte{3, 1, 1, 0xab, 0xcd}},
+			}},
+		}
+		packed, err := res.Pack()
+		if err != nil {
+			return
+		}
+		conn.WriteTo(packed, addr)
+	}()
+
+	resolver := &DNSTLSAResolver{Nameserver: conn.LocalAddr().String(), Timeout: 2 * time.Second}
+	records, authenticated, err := resolver.LookupTLSA(context.Background(), "mail.test.com", 25)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !authenticated {
+		t.Errorf("Expected an authenticated answer")
+	}
+	if len(records) != 1 || records[0].Usage != 3 || records[0].Selector != 1 || records[0].MatchingType != 1 || len(records[0].Data) != 2 {
+		t.Errorf("Unexpected records %+v", records)
+	}
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDuplicateMessage is returned when an identical email was already sent within the DedupWindow.
+var ErrDuplicateMessage = errors.New("identical message already sent within the deduplication window")
+
+// dedupCache remembers the content hashes of the emails sent within a window.
+type dedupCache struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	sent map[string]dedupEntry
+}
+
+type dedupEntry struct {
+	sentAt time.Time
+	// addresses are the lowercased addresses of the email, for Forget.
+	addresses []string
+}
+
+// seen reports whether an email with the hash was sent within the window.
+func (c *dedupCache) seen(hash string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for h, entry := range c.sent {
+		if now.Sub(entry.sentAt) >= c.window {
+			delete(c.sent, h)
+		}
+	}
+	_, ok := c.sent[hash]
+	return ok
+}
+
+func (c *dedupCache) add(hash string, now time.Time, addresses ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sent == nil {
+		c.sent = make(map[string]dedupEntry)
+	}
+	c.sent[hash] = dedupEntry{sentAt: now, addresses: addresses}
+}
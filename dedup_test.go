@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDedupCache(t *testing.T) {
+	cache := &dedupCache{window: time.Minute}
+	now := time.Now()
+
+	if cache.seen("a", now) {
+		t.Fatalf("Expected an unknown hash not to be seen")
+	}
+	cache.add("a", now)
+	if !cache.seen("a", now.Add(30*time.Second)) {
+		t.Errorf("Expected the hash to be seen within the window")
+	}
+	if cache.seen("a", now.Add(time.Minute)) {
+		t.Errorf("Expected the hash to be forgotten after the window")
+	}
+}
+
+func TestMailer_SendDedup(t *testing.T) {
+	sent := 0
+	failing := true
+	mailer := NewMailer(MailCfg{
+		APIService:  RESEND,
+		APIKey:      MailAPIKey,
+		DedupWindow: time.Minute,
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			if failing {
+				return errors.New("provider unavailable")
+			}
+			sent++
+			return nil
+		}},
+	})
+	defer mailer.Close()
+
+	msg := Mail{To: Addresses("test@example.com"), Subject: "Your order shipped", Text: "Order #1 shipped"}
+	if err := mailer.Send(msg); err == nil {
+		t.Fatalf("Expected the provider error")
+	}
+
+	failing = false
+	if err := mailer.Send(msg); err != nil {
+		t.Fatalf("Expected a failed email to be retried, got %v", err)
+	}
+	if err := mailer.Send(msg); !errors.Is(err, ErrDuplicateMessage) {
+		t.Errorf("Expected ErrDuplicateMessage, got %v", err)
+	}
+
+	msg.Text = "Order #2 shipped"
+	if err := mailer.Send(msg); err != nil {
+		t.Errorf("Expected a different email to be sent, got %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("Expected 2 emails sent, got %d", sent)
+	}
+}
@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrTLSRequired is returned when the recipient domain requires TLS and the
+	// server does not offer STARTTLS.
+	ErrTLSRequired = errors.New("recipient domain requires TLS but the server does not offer STARTTLS")
+	// ErrMXNotAllowed is returned when an MX host is not listed in the enforced
+	// MTA-STS policy of the recipient domain.
+	ErrMXNotAllowed = errors.New("mx host is not allowed by the MTA-STS policy")
+)
+
+// TLSRequirement describes how TLS must be used to deliver to an MX host.
+type TLSRequirement struct {
+	// Required refuses delivery over a plaintext connection.
+	Required bool
+	// VerifyHostname requires a certificate valid for the MX host (MTA-STS).
+	VerifyHostname bool
+	// TLSA are the DANE records the certificate must match.
+	TLSA []TLSARecord
+}
+
+// Check returns ErrTLSRequired when TLS is required and the server does not offer STARTTLS.
+func (r TLSRequirement) Check(startTLS bool) error {
+	if r.Required && !startTLS {
+		return ErrTLSRequired
+	}
+	return nil
+}
+
+// TLSConfig returns the TLS configuration used with the MX host. Without any
+// requirement, TLS is opportunistic and the certificate is not verified.
+func (r TLSRequirement) TLSConfig(host string) *tls.Config {
+	switch {
+	case len(r.TLSA) > 0:
+		return &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: true,
+			VerifyConnection: func(state tls.ConnectionState) error {
+				return VerifyDANE(state, host, r.TLSA)
+			},
+		}
+	case r.VerifyHostname:
+		return &tls.Config{ServerName: host}
+	default:
+		return &tls.Config{ServerName: host, InsecureSkipVerify: true}
+	}
+}
+
+// DeliveryTLSPolicy decides whether TLS is mandatory when delivering directly
+// to the MX hosts of a recipient domain, from its DANE and MTA-STS policies.
+type DeliveryTLSPolicy struct {
+	// DANE resolves TLSA records, DANE is not used when nil.
+	DANE TLSAResolver
+	// MTASTS resolves MTA-STS policies, MTA-STS is not used when nil.
+	MTASTS *MTASTSResolver
+}
+
+// Requirement returns the TLS requirement to deliver mail for domain to the MX host.
+// DANE takes precedence over MTA-STS when the TLSA records are authenticated.
+func (p *DeliveryTLSPolicy) Requirement(ctx context.Context, domain string, mxHost string) (TLSRequirement, error) {
+	if p.DANE != nil {
+		records, authenticated, err := p.DANE.LookupTLSA(ctx, mxHost, 25)
+		if err != nil {
+			// A failed lookup may be an attack on DNS, delivery must not fall back to plaintext.
+			return TLSRequirement{}, fmt.Errorf("dane: lookup TLSA for %s: %w", mxHost, err)
+		}
+		if usable := usableTLSA(records); authenticated && len(usable) > 0 {
+			return TLSRequirement{Required: true, TLSA: usable}, nil
+		}
+	}
+
+	if p.MTASTS != nil {
+		policy, err := p.MTASTS.Policy(ctx, domain)
+		if err != nil && policy == nil {
+			// Failing to fetch a policy that was never cached falls back to opportunistic TLS (RFC 8461).
+			return TLSRequirement{}, nil
+		}
+		if policy != nil && policy.Mode == MTASTSEnforce {
+			if !policy.MatchesMX(mxHost) {
+				return TLSRequirement{}, fmt.Errorf("%w: %s for %s", ErrMXNotAllowed, mxHost, domain)
+			}
+			return TLSRequirement{Required: true, VerifyHostname: true}, nil
+		}
+	}
+
+	return TLSRequirement{}, nil
+}
@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type mockTLSAResolver struct {
+	records       []TLSARecord
+	authenticated bool
+	err           error
+}
+
+func (m *mockTLSAResolver) LookupTLSA(ctx context.Context, host string, port int) ([]TLSARecord, bool, error) {
+	return m.records, m.authenticated, m.err
+}
+
+func TestDeliveryTLSPolicy_Requirement(t *testing.T) {
+	mtaSTS := func(status int) *MTASTSResolver {
+		return &MTASTSResolver{
+			Resolver: mockTXTResolver{"_mta-sts.test.com": {"v=STSv1; id=1"}},
+			HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: status,
+					Body:       io.NopCloser(strings.NewReader("version: STSv1\nmode: enforce\nmx: mail.test.com\nmax_age: 86400\n")),
+				}, nil
+			})},
+		}
+	}
+	tlsa := []TLSARecord{{Usage: 3, Selector: 1, MatchingType: 1, Data: []byte{1}}}
+
+	testCases := []struct {
+		name     string
+		policy   DeliveryTLSPolicy
+		mx       string
+		expected TLSRequirement
+		err      error
+		wantErr  bool
+	}{
+		{
+			name:   "no policy is opportunistic",
+			policy: DeliveryTLSPolicy{},
+			mx:     "mail.test.com",
+		},
+		{
+			name:     "authenticated TLSA records require DANE",
+			policy:   DeliveryTLSPolicy{DANE: &mockTLSAResolver{records: tlsa, authenticated: true}, MTASTS: mtaSTS(http.StatusOK)},
+			mx:       "mail.test.com",
+			expected: TLSRequirement{Required: true, TLSA: tlsa},
+		},
+		{
+			name:     "unauthenticated TLSA records fall back to MTA-STS",
+			policy:   DeliveryTLSPolicy{DANE: &mockTLSAResolver{records: tlsa}, MTASTS: mtaSTS(http.StatusOK)},
+			mx:       "mail.test.com",
+			expected: TLSRequirement{Required: true, VerifyHostname: true},
+		},
+		{
+			name:    "failed TLSA lookup refuses delivery",
+			policy:  DeliveryTLSPolicy{DANE: &mockTLSAResolver{err: errors.New("SERVFAIL")}},
+			mx:      "mail.test.com",
+			wantErr: true,
+		},
+		{
+			name:    "MX not listed in the enforced policy",
+			policy:  DeliveryTLSPolicy{MTASTS: mtaSTS(http.StatusOK)},
+			mx:      "evil.test.org",
+			err:     ErrMXNotAllowed,
+			wantErr: true,
+		},
+		{
+			name:   "unreachable policy is opportunistic",
+			policy: DeliveryTLSPolicy{MTASTS: mtaSTS(http.StatusNotFound)},
+			mx:     "evil.test.org",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requirement, err := tc.policy.Requirement(context.Background(), "test.com", tc.mx)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got nil")
+				}
+				if tc.err != nil && !errors.Is(err, tc.err) {
+					t.Errorf("Expected error %v, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if requirement.Required != tc.expected.Required || requirement.VerifyHostname != tc.expected.VerifyHostname || len(requirement.TLSA) != len(tc.expected.TLSA) {
+				t.Errorf("Expected %+v, got %+v", tc.expected, requirement)
+			}
+		})
+	}
+}
+
+func TestTLSRequirement_Check(t *testing.T) {
+	if err := (TLSRequirement{Required: true}).Check(false); !errors.Is(err, ErrTLSRequired) {
+		t.Errorf("Expected ErrTLSRequired, got %v", err)
+	}
+	if err := (TLSRequirement{Required: true}).Check(true); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := (TLSRequirement{}).Check(false); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDevHost = "localhost"
+	defaultDevPort = "1025"
+)
+
+// DevInboxKind is the kind of local development mail server.
+type DevInboxKind string
+
+const (
+	Mailpit DevInboxKind = "mailpit"
+	MailHog DevInboxKind = "mailhog"
+)
+
+// DevMessage is a message received by a local development mail server.
+type DevMessage struct {
+	ID      string
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	Html    string
+}
+
+// DevInbox queries the HTTP API of a local Mailpit or MailHog instance, so that
+// tests can assert what was received when the mailer uses the DEV API service.
+type DevInbox struct {
+	kind       DevInboxKind
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewDevInbox creates a DevInbox for the HTTP API at baseURL e.g. http://localhost:8025.
+func NewDevInbox(kind DevInboxKind, baseURL string) *DevInbox {
+	return &DevInbox{
+		kind:       kind,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Messages returns the messages received by the mail server, newest first.
+func (i *DevInbox) Messages(ctx context.Context) ([]DevMessage, error) {
+	if i.kind == MailHog {
+		return i.mailhogMessages(ctx)
+	}
+	return i.mailpitMessages(ctx)
+}
+
+// Clear deletes every message received by the mail server.
+func (i *DevInbox) Clear(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, i.baseURL+"/api/v1/messages", nil)
+	if err != nil {
+		return err
+	}
+	return doJSONRequest(i.httpClient, DEV, req, nil)
+}
+
+func (i *DevInbox) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, i.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	return doJSONRequest(i.httpClient, DEV, req, out)
+}
+
+type mailpitAddress struct {
+	Address string `json:"Address"`
+}
+
+func (i *DevInbox) mailpitMessages(ctx context.Context) ([]DevMessage, error) {
+	var list struct {
+		Messages []struct {
+			ID string `json:"ID"`
+		} `json:"messages"`
+	}
+	if err := i.get(ctx, "/api/v1/messages", &list); err != nil {
+		return nil, err
+	}
+
+	var messages []DevMessage
+	for _, summary := range list.Messages {
+		var message struct {
+			ID      string           `json:"ID"`
+			From    mailpitAddress   `json:"From"`
+			To      []mailpitAddress `json:"To"`
+			Subject string           `json:"Subject"`
+			Text    string           `json:"Text"`
+			HTML    string           `json:"HTML"`
+		}
+		if err := i.get(ctx, "/api/v1/message/"+summary.ID, &message); err != nil {
+			return nil, err
+		}
+
+		devMessage := DevMessage{
+			ID:      message.ID,
+			From:    message.From.Address,
+			Subject: message.Subject,
+			Text:    message.Text,
+			Html:    message.HTML,
+		}
+		for _, to := range message.To {
+			devMessage.To = append(devMessage.To, to.Address)
+		}
+		messages = append(messages, devMessage)
+	}
+	return messages, nil
+}
+
+func (i *DevInbox) mailhogMessages(ctx context.Context) ([]DevMessage, error) {
+	var list struct {
+		Items []struct {
+			ID  string `json:"ID"`
+			Raw struct {
+				From string   `json:"From"`
+				To   []string `json:"To"`
+				Data string   `json:"Data"`
+			} `json:"Raw"`
+		} `json:"items"`
+	}
+	if err := i.get(ctx, "/api/v2/messages", &list); err != nil {
+		return nil, err
+	}
+
+	var messages []DevMessage
+	for _, item := range list.Items {
+		parsed, err := mail.ReadMessage(strings.NewReader(item.Raw.Data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid message %s: %w", item.ID, err)
+		}
+
+		subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+		if err != nil {
+			subject = parsed.Header.Get("Subject")
+		}
+
+		devMessage := DevMessage{
+			ID:      item.ID,
+			From:    item.Raw.From,
+			To:      item.Raw.To,
+			Subject: subject,
+		}
+		err = readMIMEBodies(parsed.Header.Get("Content-Type"), parsed.Header.Get("Content-Transfer-Encoding"), parsed.Body, &devMessage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message %s: %w", item.ID, err)
+		}
+		messages = append(messages, devMessage)
+	}
+	return messages, nil
+}
+
+// readMIMEBodies walks the MIME tree of a message and stores the first text and
+// html parts in msg.
+func readMIMEBodies(contentType string, encoding string, body io.Reader, msg *DevMessage) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			err = readMIMEBodies(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, msg)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if strings.EqualFold(encoding, "quoted-printable") {
+		body = quotedprintable.NewReader(body)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+
+	switch {
+	case mediaType == "text/plain" && msg.Text == "":
+		msg.Text = string(content)
+	case mediaType == "text/html" && msg.Html == "":
+		msg.Html = string(content)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetDevSMTPParams(t *testing.T) {
+	params := getDevSMTPParams(MailCfg{APIService: DEV})
+	if params.Host != defaultDevHost || params.Port != defaultDevPort {
+		t.Errorf("Expected %s:%s, got %s:%s", defaultDevHost, defaultDevPort, params.Host, params.Port)
+	}
+	if params.Username != "" || params.Password != "" {
+		t.Errorf("Expected no authentication")
+	}
+
+	params = getDevSMTPParams(MailCfg{APIService: DEV, Host: "mailpit", Port: "2525"})
+	if params.Host != "mailpit" || params.Port != "2525" {
+		t.Errorf("Expected mailpit:2525, got %s:%s", params.Host, params.Port)
+	}
+}
+
+func TestDevInbox_Messages(t *testing.T) {
+	message, _ := buildMessage(Mail{
+		From:    Address{Email: "info@test.com"},
+		To:      Addresses("a@test.com"),
+		Subject: "Welcome",
+		Text:    "Hello",
+		Html:    "<p>Hello</p>",
+	})
+
+	testCases := []struct {
+		name    string
+		kind    DevInboxKind
+		handler http.HandlerFunc
+	}{
+		{
+			name: "mailpit",
+			kind: Mailpit,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v1/messages":
+					w.Write([]byte(`{"messages":[{"ID":"1"}]}`))
+				case "/api/v1/message/1":
+					w.Write([]byte(`{"ID":"1","From":{"Address":"info@test.com"},"To":[{"Address":"a@test.com"}],"Subject":"Welcome","Text":"Hello","HTML":"<p>Hello</p>"}`))
+				default:
+					http.NotFound(w, r)
+				}
+			},
+		},
+		{
+			name: "mailhog",
+			kind: MailHog,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v2/messages" {
+					http.NotFound(w, r)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]any{
+					"items": []map[string]any{{
+						"ID": "1",
+						"Raw": map[string]any{
+							"From": "info@test.com",
+							"To":   []string{"a@test.com"},
+							"Data": message,
+						},
+					}},
+				})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			messages, err := NewDevInbox(tc.kind, server.URL).Messages(context.Background())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(messages) != 1 {
+				t.Fatalf("Expected one message, got %d", len(messages))
+			}
+
+			msg := messages[0]
+			if msg.ID != "1" || msg.From != "info@test.com" || len(msg.To) != 1 || msg.To[0] != "a@test.com" {
+				t.Errorf("Unexpected envelope %+v", msg)
+			}
+			if msg.Subject != "Welcome" || msg.Text != "Hello" || msg.Html != "<p>Hello</p>" {
+				t.Errorf("Unexpected content %+v", msg)
+			}
+		})
+	}
+}
+
+func TestDevInbox_Clear(t *testing.T) {
+	var method, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+	}))
+	defer server.Close()
+
+	if err := NewDevInbox(Mailpit, server.URL).Clear(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if method != http.MethodDelete || path != "/api/v1/messages" {
+		t.Errorf("Expected DELETE /api/v1/messages, got %s %s", method, path)
+	}
+}
@@ -0,0 +1,225 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// defaultAttemptDelay is the connection attempt delay recommended by RFC 8305.
+const defaultAttemptDelay = 250 * time.Millisecond
+
+// IPFamily is an IP address family, see DialOptions.
+type IPFamily string
+
+const (
+	IPv4 IPFamily = "ip4"
+	IPv6 IPFamily = "ip6"
+)
+
+// DialOptions configures how the connections to the SMTP, LMTP and MX servers
+// are dialed. The addresses of a host are tried in turn, alternating between
+// the families, a new attempt starting in parallel when the previous one did
+// not connect after AttemptDelay (RFC 8305 Happy Eyeballs).
+type DialOptions struct {
+	// Prefer is the family of the address tried first. Defaults to the family
+	// of the first address resolved.
+	Prefer IPFamily
+	// Only restricts the connections to a family, e.g. IPv4 while the IPv6
+	// addresses are not warmed up.
+	Only IPFamily
+	// LocalAddr is the local IP the connections are made from, e.g. an egress
+	// address pinned for its reputation. Only the addresses of its family are
+	// dialed.
+	LocalAddr string
+	// Interface is the name of the network interface the connections are made
+	// from, with its first address of the family dialed. Ignored when LocalAddr
+	// is set.
+	Interface string
+	// AttemptDelay is how long an attempt runs alone before the next address
+	// is tried. Defaults to 250ms.
+	AttemptDelay time.Duration
+}
+
+// dialer dials the connections following the DialOptions.
+type dialer struct {
+	opts     DialOptions
+	timeout  time.Duration
+	lookupIP func(ctx context.Context, network string, host string) ([]net.IP, error)
+}
+
+func newDialer(opts DialOptions, timeout time.Duration) *dialer {
+	if opts.AttemptDelay <= 0 {
+		opts.AttemptDelay = defaultAttemptDelay
+	}
+	return &dialer{opts: opts, timeout: timeout, lookupIP: net.DefaultResolver.LookupIP}
+}
+
+// DialContext connects to the address, racing its IPs over TCP. The other
+// networks, e.g. the unix socket of an LMTP server, are dialed as-is.
+func (d *dialer) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	if network != "tcp" {
+		plain := net.Dialer{Timeout: d.timeout}
+		return plain.DialContext(ctx, network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		if ips, err = d.lookupIP(ctx, "ip", host); err != nil {
+			return nil, err
+		}
+	}
+	local, err := d.localIPs()
+	if err != nil {
+		return nil, err
+	}
+
+	ips = d.order(ips, local)
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no address of %s can be dialed with the dial options", host)
+	}
+	return d.race(ctx, ips, port, local)
+}
+
+// localIPs returns the local IP of each family the connections are bound to,
+// nil when they are not bound.
+func (d *dialer) localIPs() (map[IPFamily]net.IP, error) {
+	if d.opts.LocalAddr != "" {
+		ip := net.ParseIP(d.opts.LocalAddr)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid local address %q", d.opts.LocalAddr)
+		}
+		return map[IPFamily]net.IP{familyOf(ip): ip}, nil
+	}
+	if d.opts.Interface == "" {
+		return nil, nil
+	}
+
+	iface, err := net.InterfaceByName(d.opts.Interface)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	local := make(map[IPFamily]net.IP)
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if family := familyOf(ipNet.IP); local[family] == nil {
+			local[family] = ipNet.IP
+		}
+	}
+	if len(local) == 0 {
+		return nil, fmt.Errorf("interface %s has no address", d.opts.Interface)
+	}
+	return local, nil
+}
+
+// order drops the IPs that cannot be dialed and interleaves the families, the
+// preferred one first (RFC 8305 section 4).
+func (d *dialer) order(ips []net.IP, local map[IPFamily]net.IP) []net.IP {
+	byFamily := make(map[IPFamily][]net.IP)
+	var families []IPFamily
+	for _, ip := range ips {
+		family := familyOf(ip)
+		if d.opts.Only != "" && family != d.opts.Only {
+			continue
+		}
+		if local != nil && local[family] == nil {
+			continue
+		}
+		if byFamily[family] == nil {
+			families = append(families, family)
+		}
+		byFamily[family] = append(byFamily[family], ip)
+	}
+	if len(families) == 2 && families[1] == d.opts.Prefer {
+		families[0], families[1] = families[1], families[0]
+	}
+
+	var ordered []net.IP
+	for i := 0; len(ordered) < len(byFamily[IPv4])+len(byFamily[IPv6]); i++ {
+		for _, family := range families {
+			if i < len(byFamily[family]) {
+				ordered = append(ordered, byFamily[family][i])
+			}
+		}
+	}
+	return ordered
+}
+
+// race starts an attempt per IP in turn, the next one when the previous failed
+// or after the attempt delay, and returns the first connection established.
+func (d *dialer) race(ctx context.Context, ips []net.IP, port string, local map[IPFamily]net.IP) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	results := make(chan result)
+	var delay <-chan time.Time
+	next, pending := 0, 0
+	start := func() {
+		ip := ips[next]
+		next++
+		pending++
+		go func() {
+			attempt := net.Dialer{Timeout: d.timeout}
+			if src := local[familyOf(ip)]; src != nil {
+				attempt.LocalAddr = &net.TCPAddr{IP: src}
+			}
+			conn, err := attempt.DialContext(ctx, "tcp", net.JoinHostPort(ip.String(), port))
+			select {
+			case results <- result{conn, err}:
+			case <-ctx.Done():
+				if conn != nil {
+					conn.Close()
+				}
+			}
+		}()
+		delay = nil
+		if next < len(ips) {
+			delay = time.After(d.opts.AttemptDelay)
+		}
+	}
+
+	start()
+	var errs []error
+	for pending > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-delay:
+			start()
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				return r.conn, nil
+			}
+			errs = append(errs, r.err)
+			if next < len(ips) {
+				start()
+			}
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+func familyOf(ip net.IP) IPFamily {
+	if ip.To4() != nil {
+		return IPv4
+	}
+	return IPv6
+}
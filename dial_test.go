@@ -0,0 +1,90 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestDialer_Order(t *testing.T) {
+	ips := []net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::2"), net.ParseIP("192.0.2.1"), net.ParseIP("192.0.2.2")}
+
+	testCases := []struct {
+		name     string
+		opts     DialOptions
+		local    map[IPFamily]net.IP
+		expected string
+	}{
+		{
+			name:     "Should alternate the families, first resolved first",
+			expected: "2001:db8::1 192.0.2.1 2001:db8::2 192.0.2.2",
+		},
+		{
+			name:     "Should start with the preferred family",
+			opts:     DialOptions{Prefer: IPv4},
+			expected: "192.0.2.1 2001:db8::1 192.0.2.2 2001:db8::2",
+		},
+		{
+			name:     "Should dial the only family allowed",
+			opts:     DialOptions{Only: IPv4},
+			expected: "192.0.2.1 192.0.2.2",
+		},
+		{
+			name:     "Should dial the family of the local address",
+			local:    map[IPFamily]net.IP{IPv6: net.ParseIP("2001:db8::ff")},
+			expected: "2001:db8::1 2001:db8::2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ordered []string
+			for _, ip := range newDialer(tc.opts, 0).order(ips, tc.local) {
+				ordered = append(ordered, ip.String())
+			}
+			if strings.Join(ordered, " ") != tc.expected {
+				t.Errorf("Expected %s, got %v", tc.expected, ordered)
+			}
+		})
+	}
+}
+
+func TestDialer_DialContext(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	remotes := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+		remotes <- host
+		conn.Close()
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	d := newDialer(DialOptions{LocalAddr: "127.0.0.3"}, 0)
+	d.lookupIP = func(ctx context.Context, network string, host string) ([]net.IP, error) {
+		// Nothing listens on the first address, the dial falls back to the next one.
+		return []net.IP{net.ParseIP("::1"), net.ParseIP("127.0.0.2"), net.ParseIP("127.0.0.1")}, nil
+	}
+	conn, err := d.DialContext(context.Background(), "tcp", net.JoinHostPort("mail.test.com", port))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer conn.Close()
+
+	if remote := <-remotes; remote != "127.0.0.3" {
+		t.Errorf("Expected the connection from 127.0.0.3, got %s", remote)
+	}
+
+	d.opts.Only = IPv6
+	if _, err := d.DialContext(context.Background(), "tcp", net.JoinHostPort("mail.test.com", port)); err == nil {
+		t.Errorf("Expected no address to be dialed from an IPv4 local address over IPv6")
+	}
+}
@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrNoDocumentRenderer is returned when an email has documents but the mailer
+// has no DocumentRenderer.
+var ErrNoDocumentRenderer = errors.New("no document renderer")
+
+// DocumentRenderer renders html documents, e.g. into PDF.
+type DocumentRenderer interface {
+	Render(ctx context.Context, html string) ([]byte, error)
+}
+
+// Document is an html document rendered by the DocumentRenderer of the mailer and
+// attached to the email, e.g. an invoice or a receipt.
+type Document struct {
+	// Name is the name of the attachment e.g. "invoice.pdf".
+	Name string
+	// Html is the html of the document.
+	Html string
+}
+
+const defaultWkhtmltopdfPath = "wkhtmltopdf"
+
+// WkhtmltopdfRenderer is a DocumentRenderer converting html into PDF with the
+// wkhtmltopdf binary.
+type WkhtmltopdfRenderer struct {
+	// Path is the path to the binary. Defaults to wkhtmltopdf in the PATH.
+	Path string
+	// Args are extra arguments e.g. "--page-size", "A4".
+	Args []string
+}
+
+func (r WkhtmltopdfRenderer) Render(ctx context.Context, html string) ([]byte, error) {
+	path := r.Path
+	if path == "" {
+		path = defaultWkhtmltopdfPath
+	}
+
+	args := append([]string{"--quiet"}, r.Args...)
+	args = append(args, "-", "-")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = strings.NewReader(html)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("wkhtmltopdf: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// renderDocuments renders the documents of the email into attachments.
+func (m *Mailer) renderDocuments(msg Mail) (Mail, error) {
+	if len(msg.Documents) == 0 {
+		return msg, nil
+	}
+	if m.documentRenderer == nil {
+		return msg, ErrNoDocumentRenderer
+	}
+
+	attachments := append([]Attachment{}, msg.Attachments...)
+	for _, doc := range msg.Documents {
+		content, err := m.documentRenderer.Render(context.Background(), doc.Html)
+		if err != nil {
+			return msg, fmt.Errorf("failed to render document %s: %w", doc.Name, err)
+		}
+		attachments = append(attachments, Attachment{Name: doc.Name, Content: content})
+	}
+	msg.Attachments = attachments
+	msg.Documents = nil
+	return msg, nil
+}
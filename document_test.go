@@ -0,0 +1,68 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+type mockDocumentRenderer struct{}
+
+func (mockDocumentRenderer) Render(ctx context.Context, html string) ([]byte, error) {
+	return []byte("%PDF " + html), nil
+}
+
+func TestWkhtmltopdfRenderer_Render(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not available on windows")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "wkhtmltopdf")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\"\ncat\n"), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pdf, err := WkhtmltopdfRenderer{Path: script, Args: []string{"--page-size", "A4"}}.Render(context.Background(), "<p>Invoice</p>")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(pdf) != "--quiet --page-size A4 - -\n<p>Invoice</p>" {
+		t.Errorf("Expected the html to be piped to wkhtmltopdf, got %q", pdf)
+	}
+
+	if _, err := (WkhtmltopdfRenderer{Path: filepath.Join(dir, "missing")}).Render(context.Background(), ""); err == nil {
+		t.Errorf("Expected an error when the binary does not exist")
+	}
+}
+
+func TestMailer_SendDocuments(t *testing.T) {
+	sent := make(chan Mail, 1)
+	client := &mockSendFuncClient{sendFunc: func(msg Mail) error {
+		sent <- msg
+		return nil
+	}}
+
+	mailer := NewMailer(MailCfg{mailerClient: client, DocumentRenderer: mockDocumentRenderer{}})
+	defer mailer.Close()
+
+	err := mailer.Send(Mail{To: Addresses("a@test.com"), Documents: []Document{{Name: "invoice.pdf", Html: "<p>42</p>"}}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	msg := <-sent
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Name != "invoice.pdf" || string(msg.Attachments[0].Content) != "%PDF <p>42</p>" {
+		t.Errorf("Expected the rendered document to be attached, got %+v", msg.Attachments)
+	}
+
+	noRenderer := NewMailer(MailCfg{mailerClient: client})
+	defer noRenderer.Close()
+	err = noRenderer.Send(Mail{To: Addresses("a@test.com"), Documents: []Document{{Name: "invoice.pdf"}}})
+	if !errors.Is(err, ErrNoDocumentRenderer) {
+		t.Errorf("Expected ErrNoDocumentRenderer, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DomainIssueSeverity is the severity of a DomainIssue.
+type DomainIssueSeverity string
+
+const (
+	// DomainWarning issues hurt deliverability.
+	DomainWarning DomainIssueSeverity = "warning"
+	// DomainError issues are likely to land mail in spam or get it rejected.
+	DomainError DomainIssueSeverity = "error"
+)
+
+// DomainIssue is a misconfiguration found by DomainCheck.
+type DomainIssue struct {
+	Severity DomainIssueSeverity
+	// Record is the checked record i.e. "spf", "dkim" or "dmarc".
+	Record  string
+	Message string
+}
+
+func (i DomainIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Record, i.Message)
+}
+
+// DomainReport is the result of DomainCheck.
+type DomainReport struct {
+	Domain string
+	// SPF is the SPF record of the domain.
+	SPF string
+	// DKIM are the DKIM records of the checked selectors, keyed by selector.
+	DKIM map[string]string
+	// DMARC is the DMARC record of the domain.
+	DMARC  string
+	Issues []DomainIssue
+}
+
+// OK reports whether no error was found.
+func (r DomainReport) OK() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == DomainError {
+			return false
+		}
+	}
+	return true
+}
+
+// TXTResolver resolves TXT records, it is implemented by *net.Resolver.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DomainCheck queries the SPF, DKIM and DMARC records of a sending domain with the
+// default resolver and reports the misconfigurations. The DKIM records are
+// checked for each selector, e.g. "google" or "s1".
+func DomainCheck(ctx context.Context, domain string, selectors ...string) (DomainReport, error) {
+	return CheckDomain(ctx, net.DefaultResolver, domain, selectors...)
+}
+
+// CheckDomain is DomainCheck with a custom resolver.
+func CheckDomain(ctx context.Context, resolver TXTResolver, domain string, selectors ...string) (DomainReport, error) {
+	report := DomainReport{Domain: domain, DKIM: make(map[string]string)}
+	issue := func(severity DomainIssueSeverity, record string, format string, args ...any) {
+		report.Issues = append(report.Issues, DomainIssue{Severity: severity, Record: record, Message: fmt.Sprintf(format, args...)})
+	}
+
+	spf, err := lookupRecords(ctx, resolver, domain, "v=spf1")
+	if err != nil {
+		return report, err
+	}
+	switch len(spf) {
+	case 0:
+		issue(DomainError, "spf", "no SPF record found for %s", domain)
+	case 1:
+		report.SPF = spf[0]
+		checkSPF(report.SPF, issue)
+	default:
+		report.SPF = spf[0]
+		issue(DomainError, "spf", "%d SPF records found, receivers treat multiple records as a permanent error", len(spf))
+	}
+
+	if len(selectors) == 0 {
+		issue(DomainWarning, "dkim", "no DKIM selector checked")
+	}
+	for _, selector := range selectors {
+		name := selector + "._domainkey." + domain
+		records, err := lookupRecords(ctx, resolver, name, "")
+		if err != nil {
+			return report, err
+		}
+		record := strings.Join(records, "")
+		if record == "" {
+			issue(DomainError, "dkim", "no DKIM record found for selector %s at %s", selector, name)
+			continue
+		}
+		report.DKIM[selector] = record
+
+		tags := parseTags(record)
+		if v, ok := tags["v"]; ok && v != "DKIM1" {
+			issue(DomainError, "dkim", "selector %s has an invalid version %q", selector, v)
+		}
+		if p, ok := tags["p"]; !ok {
+			issue(DomainError, "dkim", "selector %s has no public key", selector)
+		} else if p == "" {
+			issue(DomainError, "dkim", "selector %s is revoked, its public key is empty", selector)
+		}
+	}
+
+	dmarc, err := lookupRecords(ctx, resolver, "_dmarc."+domain, "v=DMARC1")
+	if err != nil {
+		return report, err
+	}
+	if len(dmarc) == 0 {
+		issue(DomainError, "dmarc", "no DMARC record found at _dmarc.%s", domain)
+	} else {
+		report.DMARC = dmarc[0]
+		tags := parseTags(report.DMARC)
+		switch tags["p"] {
+		case "reject", "quarantine":
+		case "none":
+			issue(DomainWarning, "dmarc", "policy is none, spoofed emails are not rejected")
+		default:
+			issue(DomainError, "dmarc", "invalid policy %q", tags["p"])
+		}
+		if tags["rua"] == "" {
+			issue(DomainWarning, "dmarc", "no aggregate report address (rua) configured")
+		}
+	}
+
+	return report, nil
+}
+
+func checkSPF(record string, issue func(DomainIssueSeverity, string, string, ...any)) {
+	mechanisms := strings.Fields(record)[1:]
+	lookups := 0
+	hasAll := false
+	for _, mechanism := range mechanisms {
+		name := strings.ToLower(strings.TrimLeft(mechanism, "+-~?"))
+		if i := strings.IndexAny(name, ":/="); i >= 0 {
+			name = name[:i]
+		}
+		switch name {
+		case "include", "a", "mx", "ptr", "exists", "redirect":
+			lookups++
+		case "all":
+			hasAll = true
+			switch mechanism[0] {
+			case '+', 'a':
+				issue(DomainError, "spf", "%s allows any server to send for the domain", mechanism)
+			case '?':
+				issue(DomainWarning, "spf", "?all is neutral, use ~all or -all")
+			}
+		}
+	}
+	if !hasAll && !strings.Contains(record, "redirect=") {
+		issue(DomainWarning, "spf", "no all mechanism, unlisted servers are not rejected")
+	}
+	if lookups > 10 {
+		issue(DomainError, "spf", "%d DNS lookups, more than the limit of 10", lookups)
+	}
+}
+
+// lookupRecords returns the TXT records of the name starting with prefix,
+// treating missing names as no records.
+func lookupRecords(ctx context.Context, resolver TXTResolver, name string, prefix string) ([]string, error) {
+	records, err := resolver.LookupTXT(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var matching []string
+	for _, record := range records {
+		if prefix == "" || strings.HasPrefix(strings.ToLower(record), strings.ToLower(prefix)) {
+			matching = append(matching, record)
+		}
+	}
+	return matching, nil
+}
+
+// parseTags parses tag=value; lists used by DKIM and DMARC records.
+func parseTags(record string) map[string]string {
+	tags := make(map[string]string)
+	for _, tag := range strings.Split(record, ";") {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok {
+			continue
+		}
+		tags[strings.ToLower(strings.TrimSpace(key))] = strings.Join(strings.Fields(value), "")
+	}
+	return tags
+}
@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+type mockTXTResolver map[string][]string
+
+func (m mockTXTResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, ok := m[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestCheckDomain(t *testing.T) {
+	testCases := []struct {
+		name      string
+		records   mockTXTResolver
+		selectors []string
+		ok        bool
+		issues    []string
+	}{
+		{
+			name: "well configured domain",
+			records: mockTXTResolver{
+				"test.com":                   {"google-site-verification=abc", "v=spf1 include:_spf.google.com ~all"},
+				"google._domainkey.test.com": {"v=DKIM1; k=rsa; p=MIIBIjANBgkq"},
+				"_dmarc.test.com":            {"v=DMARC1; p=reject; rua=mailto:dmarc@test.com"},
+			},
+			selectors: []string{"google"},
+			ok:        true,
+		},
+		{
+			name:      "missing records",
+			records:   mockTXTResolver{},
+			selectors: []string{"s1"},
+			issues: []string{
+				"error: spf: no SPF record found for test.com",
+				"error: dkim: no DKIM record found for selector s1 at s1._domainkey.test.com",
+				"error: dmarc: no DMARC record found at _dmarc.test.com",
+			},
+		},
+		{
+			name: "permissive records",
+			records: mockTXTResolver{
+				"test.com":               {"v=spf1 a mx +all"},
+				"s1._domainkey.test.com": {"v=DKIM1; p="},
+				"_dmarc.test.com":        {"v=DMARC1; p=none"},
+			},
+			selectors: []string{"s1"},
+			issues: []string{
+				"error: spf: +all allows any server to send for the domain",
+				"error: dkim: selector s1 is revoked, its public key is empty",
+				"warning: dmarc: policy is none, spoofed emails are not rejected",
+				"warning: dmarc: no aggregate report address (rua) configured",
+			},
+		},
+		{
+			name: "multiple spf records",
+			records: mockTXTResolver{
+				"test.com":        {"v=spf1 include:a.com -all", "v=spf1 include:b.com -all"},
+				"_dmarc.test.com": {"v=DMARC1; p=quarantine; rua=mailto:d@test.com"},
+			},
+			issues: []string{
+				"error: spf: 2 SPF records found, receivers treat multiple records as a permanent error",
+				"warning: dkim: no DKIM selector checked",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := CheckDomain(context.Background(), tc.records, "test.com", tc.selectors...)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if report.OK() != tc.ok {
+				t.Errorf("Expected OK to be %v, got issues %v", tc.ok, report.Issues)
+			}
+			if len(report.Issues) != len(tc.issues) {
+				t.Fatalf("Expected issues %v, got %v", tc.issues, report.Issues)
+			}
+			for i, issue := range report.Issues {
+				if issue.String() != tc.issues[i] {
+					t.Errorf("Expected issue %q, got %q", tc.issues[i], issue.String())
+				}
+			}
+		})
+	}
+}
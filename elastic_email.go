@@ -0,0 +1,157 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+)
+
+const elasticEmailBaseURL = "https://api.elasticemail.com"
+
+// ElasticEmailOptions holds the Elastic Email specific options of an email.
+type ElasticEmailOptions struct {
+	// TemplateName is the name of an Elastic Email template. When set, the template
+	// stored in Elastic Email is rendered instead of the Subject, Html and Text of
+	// the email.
+	TemplateName string
+	// Merge are the merge fields of the content or of the template, e.g. {name}.
+	Merge map[string]string
+	// ChannelName groups the email with the others of the channel in the reports.
+	ChannelName string
+}
+
+type elasticEmailParams struct {
+	apiKey     string
+	userAgent  string
+	httpClient *http.Client
+}
+
+type elasticEmailMailer struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	userAgent  string
+}
+
+type elasticEmailRecipients struct {
+	To  []string `json:"To"`
+	CC  []string `json:"CC,omitempty"`
+	BCC []string `json:"BCC,omitempty"`
+}
+
+type elasticEmailBodyPart struct {
+	ContentType string `json:"ContentType"`
+	Content     string `json:"Content"`
+	Charset     string `json:"Charset"`
+}
+
+type elasticEmailAttachment struct {
+	BinaryContent string `json:"BinaryContent"`
+	Name          string `json:"Name"`
+	ContentType   string `json:"ContentType"`
+}
+
+type elasticEmailContent struct {
+	Body         []elasticEmailBodyPart   `json:"Body,omitempty"`
+	Merge        map[string]string        `json:"Merge,omitempty"`
+	Attachments  []elasticEmailAttachment `json:"Attachments,omitempty"`
+	Headers      map[string]string        `json:"Headers,omitempty"`
+	From         string                   `json:"From"`
+	ReplyTo      string                   `json:"ReplyTo,omitempty"`
+	Subject      string                   `json:"Subject,omitempty"`
+	TemplateName string                   `json:"TemplateName,omitempty"`
+}
+
+type elasticEmailOptions struct {
+	ChannelName string `json:"ChannelName,omitempty"`
+}
+
+type elasticEmailMessage struct {
+	Recipients elasticEmailRecipients `json:"Recipients"`
+	Content    elasticEmailContent    `json:"Content"`
+	Options    *elasticEmailOptions   `json:"Options,omitempty"`
+}
+
+func newElasticEmail(params elasticEmailParams) MailerClient {
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+
+	return &elasticEmailMailer{
+		httpClient: params.httpClient,
+		baseURL:    elasticEmailBaseURL,
+		apiKey:     params.apiKey,
+		userAgent:  params.userAgent,
+	}
+}
+
+func (m *elasticEmailMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *elasticEmailMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	payload := elasticEmailMessage{
+		Recipients: elasticEmailRecipients{
+			To:  formatAddresses(msg.To),
+			CC:  formatAddresses(msg.Cc),
+			BCC: formatAddresses(msg.Bcc),
+		},
+		Content: elasticEmailContent{
+			From:    msg.From.String(),
+			ReplyTo: msg.ReplyTo.String(),
+			Subject: msg.Subject,
+			Headers: msg.Headers,
+		},
+	}
+	if msg.Html != "" {
+		payload.Content.Body = append(payload.Content.Body, elasticEmailBodyPart{ContentType: "HTML", Content: msg.Html, Charset: "utf-8"})
+	}
+	if msg.Text != "" {
+		payload.Content.Body = append(payload.Content.Body, elasticEmailBodyPart{ContentType: "PlainText", Content: msg.Text, Charset: "utf-8"})
+	}
+	if options := msg.ElasticEmail; options != nil {
+		payload.Content.Merge = options.Merge
+		if options.TemplateName != "" {
+			payload.Content.Subject, payload.Content.Body = "", nil
+			payload.Content.TemplateName = options.TemplateName
+		}
+		if options.ChannelName != "" {
+			payload.Options = &elasticEmailOptions{ChannelName: options.ChannelName}
+		}
+	}
+
+	for _, attachment := range msg.Attachments {
+		content, err := encodeAttachmentBase64(attachment)
+		if err != nil {
+			return nil, err
+		}
+		payload.Content.Attachments = append(payload.Content.Attachments, elasticEmailAttachment{
+			BinaryContent: content,
+			Name:          attachment.Name,
+			ContentType:   getAttachmentContentType(attachment),
+		})
+	}
+
+	return m.doWithResponse(context.Background(), http.MethodPost, "/v4/emails/transactional", payload)
+}
+
+func (m *elasticEmailMailer) doWithResponse(ctx context.Context, method string, path string, payload any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(method, m.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-ElasticEmail-ApiKey", m.apiKey)
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, ELASTIC_EMAIL, req.WithContext(ctx), nil)
+}
+
+// Health verifies the API key by fetching the sending domains.
+func (m *elasticEmailMailer) Health(ctx context.Context) error {
+	_, err := m.doWithResponse(ctx, http.MethodGet, "/v4/domains", nil)
+	return err
+}
+
+func (m *elasticEmailMailer) Close() {
+	// Not implemented because the Elastic Email API is stateless.
+}
@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"testing"
+)
+
+func TestElasticEmail_Send(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	elastic := newElasticEmail(elasticEmailParams{apiKey: MailAPIKey}).(*elasticEmailMailer)
+	elastic.baseURL = server.URL
+
+	err := elastic.Send(Mail{
+		From:         Address{Name: "Acme", Email: "info@test.com"},
+		To:           Addresses("a@test.com"),
+		Bcc:          Addresses("b@test.com"),
+		Subject:      "Hello {name}",
+		Html:         "<p>Hello {name}</p>",
+		Text:         "Hello {name}",
+		Attachments:  []Attachment{{Name: "hello.txt", Content: []byte("hello")}},
+		ElasticEmail: &ElasticEmailOptions{Merge: map[string]string{"name": "Ada"}, ChannelName: "welcome"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := requests[0]
+	if req.Path != "/v4/emails/transactional" || req.Header.Get("X-ElasticEmail-ApiKey") != MailAPIKey {
+		t.Fatalf("Expected an authenticated request to /v4/emails/transactional, got %s %v", req.Path, req.Header)
+	}
+	recipients := req.Body["Recipients"].(map[string]any)
+	if len(recipients["To"].([]any)) != 1 || len(recipients["BCC"].([]any)) != 1 {
+		t.Errorf("Expected the To and Bcc recipients, got %v", recipients)
+	}
+	content := req.Body["Content"].(map[string]any)
+	if body := content["Body"].([]any); len(body) != 2 || body[0].(map[string]any)["ContentType"] != "HTML" {
+		t.Errorf("Expected the HTML and plain text bodies, got %v", content["Body"])
+	}
+	if content["Merge"].(map[string]any)["name"] != "Ada" {
+		t.Errorf("Expected the merge fields, got %v", content["Merge"])
+	}
+	if attachment := content["Attachments"].([]any)[0].(map[string]any); attachment["BinaryContent"] != "aGVsbG8=" {
+		t.Errorf("Expected the attachment, got %v", attachment)
+	}
+	if options := req.Body["Options"].(map[string]any); options["ChannelName"] != "welcome" {
+		t.Errorf("Expected the channel, got %v", options)
+	}
+}
+
+func TestElasticEmail_Template(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	elastic := newElasticEmail(elasticEmailParams{apiKey: MailAPIKey}).(*elasticEmailMailer)
+	elastic.baseURL = server.URL
+
+	err := elastic.Send(Mail{
+		From:         Address{Email: "info@test.com"},
+		To:           Addresses("a@test.com"),
+		Html:         "<p>ignored</p>",
+		ElasticEmail: &ElasticEmailOptions{TemplateName: "welcome"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	content := requests[0].Body["Content"].(map[string]any)
+	if content["TemplateName"] != "welcome" || content["Body"] != nil {
+		t.Errorf("Expected the template instead of the body, got %v", content)
+	}
+}
@@ -0,0 +1,213 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// envelopeMagic prefixes the envelopes so that plaintext data is never mistaken
+// for an encrypted payload.
+var envelopeMagic = []byte("MLE1")
+
+// ErrInvalidEnvelope is returned when decrypting data that is not a valid envelope.
+var ErrInvalidEnvelope = errors.New("invalid encrypted envelope")
+
+// KeyProvider generates and unwraps the data keys used for envelope encryption,
+// typically backed by a KMS (AWS KMS GenerateDataKey/Decrypt, GCP KMS, Vault transit).
+type KeyProvider interface {
+	// GenerateDataKey returns a new 256-bit data key, in plaintext and wrapped by the master key.
+	GenerateDataKey(ctx context.Context) (plaintext []byte, wrapped []byte, err error)
+	// DecryptDataKey unwraps a data key returned by GenerateDataKey.
+	DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// StaticKeyProvider is a KeyProvider wrapping the data keys with a local master
+// key using AES-GCM, for deployments without a KMS.
+type StaticKeyProvider struct {
+	aead cipher.AEAD
+}
+
+// NewStaticKeyProvider creates a StaticKeyProvider from a 16, 24 or 32 bytes master key.
+func NewStaticKeyProvider(masterKey []byte) (*StaticKeyProvider, error) {
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &StaticKeyProvider{aead: aead}, nil
+}
+
+func (p *StaticKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := seal(p.aead, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, wrapped, nil
+}
+
+func (p *StaticKeyProvider) DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	return open(p.aead, wrapped)
+}
+
+// SealEnvelope encrypts the data with a new data key from the key provider. The
+// wrapped data key is stored alongside the ciphertext.
+func SealEnvelope(ctx context.Context, keys KeyProvider, data []byte) ([]byte, error) {
+	key, wrapped, err := keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	if len(wrapped) > 0xFFFF {
+		return nil, fmt.Errorf("wrapped data key is too long")
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(aead, data)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := bytes.NewBuffer(make([]byte, 0, len(envelopeMagic)+2+len(wrapped)+len(ciphertext)))
+	envelope.Write(envelopeMagic)
+	binary.Write(envelope, binary.BigEndian, uint16(len(wrapped)))
+	envelope.Write(wrapped)
+	envelope.Write(ciphertext)
+	return envelope.Bytes(), nil
+}
+
+// OpenEnvelope decrypts data encrypted by SealEnvelope.
+func OpenEnvelope(ctx context.Context, keys KeyProvider, envelope []byte) ([]byte, error) {
+	if !bytes.HasPrefix(envelope, envelopeMagic) || len(envelope) < len(envelopeMagic)+2 {
+		return nil, ErrInvalidEnvelope
+	}
+	envelope = envelope[len(envelopeMagic):]
+
+	size := int(binary.BigEndian.Uint16(envelope))
+	envelope = envelope[2:]
+	if len(envelope) < size {
+		return nil, ErrInvalidEnvelope
+	}
+
+	key, err := keys.DecryptDataKey(ctx, envelope[:size])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, envelope[size:])
+}
+
+// errNotForwarded is returned by the EncryptedArchiveStore methods which its
+// underlying store does not implement, for the mailer to skip the store.
+var errNotForwarded = errors.New("not implemented by the underlying archive store")
+
+// EncryptedArchiveStore encrypts the archived messages, which hold the bodies and
+// the recipients, before they reach the underlying store. It forwards List,
+// Prune and Erase to the underlying store when it implements them, which prunes
+// and erases the messages from their RetainUntil and Recipients kept in clear.
+type EncryptedArchiveStore struct {
+	store ArchiveStore
+	keys  KeyProvider
+}
+
+// NewEncryptedArchiveStore wraps the store so that messages are encrypted at rest.
+func NewEncryptedArchiveStore(store ArchiveStore, keys KeyProvider) *EncryptedArchiveStore {
+	return &EncryptedArchiveStore{store: store, keys: keys}
+}
+
+func (s *EncryptedArchiveStore) Put(ctx context.Context, msg ArchivedMessage) error {
+	raw, err := SealEnvelope(ctx, s.keys, msg.Raw)
+	if err != nil {
+		return err
+	}
+	msg.Raw = raw
+	return s.store.Put(ctx, msg)
+}
+
+func (s *EncryptedArchiveStore) Get(ctx context.Context, id string) (ArchivedMessage, error) {
+	msg, err := s.store.Get(ctx, id)
+	if err != nil {
+		return msg, err
+	}
+	if msg.Raw, err = OpenEnvelope(ctx, s.keys, msg.Raw); err != nil {
+		return ArchivedMessage{}, err
+	}
+	return msg, nil
+}
+
+// List returns the messages of the underlying ListableArchiveStore, decrypted.
+func (s *EncryptedArchiveStore) List(ctx context.Context) ([]ArchivedMessage, error) {
+	listable, ok := s.store.(ListableArchiveStore)
+	if !ok {
+		return nil, errNotForwarded
+	}
+	messages, err := listable.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range messages {
+		if messages[i].Raw, err = OpenEnvelope(ctx, s.keys, messages[i].Raw); err != nil {
+			return nil, fmt.Errorf("failed to decrypt the archive of %s: %w", messages[i].ID, err)
+		}
+	}
+	return messages, nil
+}
+
+// Prune prunes the underlying store when it is a Pruner.
+func (s *EncryptedArchiveStore) Prune(ctx context.Context, before time.Time) (int, error) {
+	pruner, ok := s.store.(Pruner)
+	if !ok {
+		return 0, errNotForwarded
+	}
+	return pruner.Prune(ctx, before)
+}
+
+// Erase erases the address from the underlying store when it is an Eraser.
+func (s *EncryptedArchiveStore) Erase(ctx context.Context, email string) (int, error) {
+	eraser, ok := s.store.(Eraser)
+	if !ok {
+		return 0, errNotForwarded
+	}
+	return eraser.Erase(ctx, email)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the data with a random nonce prepended to the ciphertext.
+func seal(aead cipher.AEAD, data []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+func open(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, ErrInvalidEnvelope
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
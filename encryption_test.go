@@ -0,0 +1,139 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func newTestKeyProvider(t *testing.T) *StaticKeyProvider {
+	t.Helper()
+	keys, err := NewStaticKeyProvider(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestEnvelopeEncryption(t *testing.T) {
+	ctx := context.Background()
+	keys := newTestKeyProvider(t)
+	data := []byte("To: a@test.com\r\nSubject: secret\r\n\r\nyour code is 123456")
+
+	envelope, err := SealEnvelope(ctx, keys, data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if bytes.Contains(envelope, []byte("a@test.com")) {
+		t.Errorf("Expected the recipients to be encrypted")
+	}
+
+	other, _ := SealEnvelope(ctx, keys, data)
+	if bytes.Equal(envelope, other) {
+		t.Errorf("Expected every envelope to use a new data key and nonce")
+	}
+
+	decrypted, err := OpenEnvelope(ctx, keys, envelope)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !bytes.Equal(decrypted, data) {
+		t.Errorf("Expected %q, got %q", data, decrypted)
+	}
+
+	testCases := []struct {
+		name     string
+		envelope []byte
+		keys     KeyProvider
+	}{
+		{name: "plaintext", envelope: data, keys: keys},
+		{name: "truncated", envelope: envelope[:len(envelopeMagic)+1], keys: keys},
+		{name: "tampered", envelope: append(append([]byte{}, envelope[:len(envelope)-1]...), envelope[len(envelope)-1]^1), keys: keys},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := OpenEnvelope(ctx, tc.keys, tc.envelope); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+
+	wrongKeys, _ := NewStaticKeyProvider(bytes.Repeat([]byte{2}, 32))
+	if _, err := OpenEnvelope(ctx, wrongKeys, envelope); err == nil {
+		t.Errorf("Expected error with the wrong master key")
+	}
+}
+
+func TestEncryptedArchiveStore(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryArchiveStore()
+	store := NewEncryptedArchiveStore(backend, newTestKeyProvider(t))
+
+	raw := []byte("To: a@test.com\r\n\r\ninvoice")
+	if err := store.Put(ctx, ArchivedMessage{ID: "abc", Raw: raw}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stored, _ := backend.Get(ctx, "abc")
+	if bytes.Contains(stored.Raw, []byte("a@test.com")) {
+		t.Errorf("Expected the message to be encrypted at rest")
+	}
+
+	archived, err := store.Get(ctx, "abc")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !bytes.Equal(archived.Raw, raw) {
+		t.Errorf("Expected %q, got %q", raw, archived.Raw)
+	}
+
+	if _, err := store.Get(ctx, "unknown"); !errors.Is(err, ErrArchiveNotFound) {
+		t.Errorf("Expected ErrArchiveNotFound, got %v", err)
+	}
+}
+
+func TestEncryptedArchiveStore_Forwards(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewEncryptedArchiveStore(NewMemoryArchiveStore(), newTestKeyProvider(t))
+	raw := []byte("To: a@test.com\r\n\r\ninvoice")
+	for _, msg := range []ArchivedMessage{
+		{ID: "expired", Raw: raw, RetainUntil: now.Add(-time.Hour)},
+		{ID: "bcc", Raw: raw, Recipients: []string{"b@test.com"}},
+		{ID: "kept", Raw: raw},
+	} {
+		if err := store.Put(ctx, msg); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if n, err := store.Prune(ctx, now); err != nil || n != 1 {
+		t.Errorf("Expected the expired message to be pruned, got %d, %v", n, err)
+	}
+	if n, err := store.Erase(ctx, "b@test.com"); err != nil || n != 1 {
+		t.Errorf("Expected the message to the bcc recipient to be erased, got %d, %v", n, err)
+	}
+	messages, err := store.List(ctx)
+	if err != nil || len(messages) != 1 || !bytes.Equal(messages[0].Raw, raw) {
+		t.Errorf("Expected the kept message decrypted, got %v, %v", messages, err)
+	}
+
+	mailer := NewMailer(MailCfg{
+		APIService:   RESEND,
+		APIKey:       MailAPIKey,
+		ArchiveStore: NewEncryptedArchiveStore(NewS3ArchiveStore(&mockS3Client{objects: map[string]*s3.PutObjectInput{}}, "archive", ""), newTestKeyProvider(t)),
+		Retention:    Retention{Archives: time.Hour},
+		mailerClient: &mockMailerClient{},
+	})
+	defer mailer.Close()
+	if report, err := mailer.Prune(ctx); err != nil || len(report.Skipped) != 1 {
+		t.Errorf("Expected the S3 store to be skipped, got %+v, %v", report, err)
+	}
+	if report, err := mailer.Forget(ctx, "a@test.com"); err != nil || len(report.Skipped) != 1 {
+		t.Errorf("Expected the S3 store to be skipped, got %+v, %v", report, err)
+	}
+}
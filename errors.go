@@ -0,0 +1,42 @@
+package mailer
+
+import "errors"
+
+// permanentErrors are the errors of emails the mailer refuses to send, which
+// would fail the same way if sent again, and of the spooled emails the mailer
+// sends itself once the provider recovers.
+var permanentErrors = []error{
+	ErrAllRecipientsOptedOut,
+	ErrAllRecipientsSuppressed,
+	ErrDuplicateMessage,
+	ErrMessageExpired,
+	ErrMessageCanceled,
+	ErrUnknownTenant,
+	ErrTenantFromDomain,
+	ErrInvalidMail,
+	ErrNoDocumentRenderer,
+	ErrAMPWithoutHtml,
+	ErrInvalidHeader,
+	ErrMessageQuarantined,
+	ErrMessageSpooled,
+	ErrContentBlocked,
+	ErrSpamScoreExceeded,
+	ErrAttachmentInfected,
+	ErrInvalidAddress,
+	ErrUnsupportedCapability,
+	ErrRenderTooLarge,
+	ErrDataTooDeep,
+}
+
+// IsPermanentError reports whether err is a refusal of the mailer to send an
+// email, e.g. expired or opted out, rather than a failure that may succeed when
+// retried. Job queues and message brokers use it to drop rather than retry jobs,
+// a spooled email being sent again by the mailer itself.
+func IsPermanentError(err error) bool {
+	for _, permanent := range permanentErrors {
+		if errors.Is(err, permanent) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsPermanentError(t *testing.T) {
+	for _, err := range []error{
+		fmt.Errorf("%w: %v", ErrMessageSpooled, errors.New("502")),
+		fmt.Errorf("%w: eicar", ErrAttachmentInfected),
+		fmt.Errorf("%w: amp", ErrUnsupportedCapability),
+		ErrDataTooDeep,
+	} {
+		if !IsPermanentError(err) {
+			t.Errorf("Expected %v not to be retried", err)
+		}
+	}
+	if IsPermanentError(&APIError{StatusCode: 503}) {
+		t.Errorf("Expected a provider failure to be retried")
+	}
+}
@@ -0,0 +1,75 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+)
+
+// ErrEventPollingUnsupported is returned by PollEvents when the provider has no
+// API to fetch its events.
+var ErrEventPollingUnsupported = errors.New("the provider does not support event polling")
+
+// EventPoller is implemented by the mailer clients whose provider has an API to
+// fetch the delivery events, for deployments that cannot expose a webhook.
+type EventPoller interface {
+	// PollEvents returns the events that happened since the given time, oldest first.
+	PollEvents(ctx context.Context, since time.Time) ([]Event, error)
+}
+
+// PollEvents fetches the events of the provider that happened since the given time.
+func (m *Mailer) PollEvents(ctx context.Context, since time.Time) ([]Event, error) {
+	poller, ok := m.mailerClient.(EventPoller)
+	if !ok {
+		return nil, ErrEventPollingUnsupported
+	}
+	return poller.PollEvents(ctx, since)
+}
+
+// pollEvents passes the events of the provider to the EventHandler every interval
+// until the mailer is closed, starting with the events since the mailer was created.
+func (m *Mailer) pollEvents(interval time.Duration) {
+	ticker := m.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	since := m.clock.Now()
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C():
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			events, err := m.PollEvents(ctx, since)
+			cancel()
+			if err != nil {
+				log.Printf("mailer: failed to poll %s events: %v", m.apiService, err)
+				continue
+			}
+			since = m.handlePolledEvents(events, since)
+		}
+	}
+}
+
+// sortEvents sorts the events oldest first.
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+}
+
+// handlePolledEvents emits the events, returning the time to poll the next ones from.
+// SendGrid only filters by the second, so the events before since were already
+// emitted by the previous poll.
+func (m *Mailer) handlePolledEvents(events []Event, since time.Time) time.Time {
+	next := since
+	for _, event := range events {
+		if event.Timestamp.Before(since) {
+			continue
+		}
+		m.emit(event)
+		if after := event.Timestamp.Add(time.Microsecond); after.After(next) {
+			next = after
+		}
+	}
+	return next
+}
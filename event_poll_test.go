@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMailgun_PollEvents(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/page2" {
+			w.Write([]byte(`{"items":[],"paging":{}}`))
+			return
+		}
+		if r.URL.Path != "/v3/test.com/events" || r.URL.Query().Get("begin") != "1893488400.000000" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		w.Write([]byte(`{"items":[
+			{"event":"delivered","timestamp":1893488401.5,"recipient":"a@test.com","message":{"headers":{"message-id":"m1"}}},
+			{"event":"failed","severity":"temporary","timestamp":1893488402,"recipient":"b@test.com","delivery-status":{"message":"mailbox full"}},
+			{"event":"accepted","timestamp":1893488403,"recipient":"c@test.com"},
+			{"event":"opened","timestamp":1893488404,"recipient":"a@test.com"}
+		],"paging":{"next":"` + server.URL + `/page2"}}`))
+	}))
+	defer server.Close()
+
+	mailgun := newMailgun(mailgunParams{apiKey: "key", domain: "test.com"}).(*mailgunMailer)
+	mailgun.baseURL = server.URL
+
+	events, err := mailgun.PollEvents(context.Background(), time.Unix(1893488400, 0))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []EventType{EventDelivered, EventDelayed, EventOpened}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), events)
+	}
+	for i, event := range events {
+		if event.Type != expected[i] {
+			t.Errorf("Expected event %d to be %s, got %s", i, expected[i], event.Type)
+		}
+	}
+	if !events[0].Timestamp.Equal(time.Unix(1893488401, 5e8)) || events[0].MessageID != "m1" {
+		t.Errorf("Unexpected delivered event %+v", events[0])
+	}
+	if events[1].Reason != "mailbox full" || events[1].Permanent {
+		t.Errorf("Unexpected delayed event %+v", events[1])
+	}
+}
+
+func TestSendGrid_PollEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Query().Get("query"), `last_event_time BETWEEN TIMESTAMP "2030-01-01T09:00:00Z"`) {
+			t.Errorf("Unexpected query %q", r.URL.Query().Get("query"))
+		}
+		w.Write([]byte(`{"messages":[
+			{"msg_id":"m2","to_email":"b@test.com","status":"not_delivered","last_event_time":"2030-01-01T09:00:05Z"},
+			{"msg_id":"m1","to_email":"a@test.com","status":"delivered","opens_count":2,"last_event_time":"2030-01-01T09:00:03Z"},
+			{"msg_id":"m3","to_email":"c@test.com","status":"processing","last_event_time":"2030-01-01T09:00:04Z"}
+		]}`))
+	}))
+	defer server.Close()
+
+	sendgrid := newSendGrid(sendgridParams{apiKey: "key"}).(*sendgridMailer)
+	sendgrid.baseURL = server.URL
+
+	events, err := sendgrid.PollEvents(context.Background(), time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 3 || events[0].Type != EventDelivered || events[1].Type != EventOpened || events[2].Type != EventBounced || !events[2].Permanent {
+		t.Errorf("Unexpected events %+v", events)
+	}
+}
+
+func TestMailer_HandlePolledEvents(t *testing.T) {
+	var emitted []Event
+	mailer := &Mailer{eventHandler: func(e Event) { emitted = append(emitted, e) }}
+	since := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	events := []Event{{Type: EventDelivered, Timestamp: since.Add(time.Second)}}
+
+	since = mailer.handlePolledEvents(events, since)
+	since = mailer.handlePolledEvents(events, since)
+	if len(emitted) != 1 {
+		t.Errorf("Expected the event polled twice to be emitted once, got %d", len(emitted))
+	}
+	if !since.After(events[0].Timestamp) {
+		t.Errorf("Expected the cursor after the last event, got %s", since)
+	}
+}
@@ -0,0 +1,84 @@
+package mailer
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// EventType is the type of a delivery event reported by a provider.
+type EventType string
+
+const (
+	EventDelivered  EventType = "delivered"
+	EventBounced    EventType = "bounced"
+	EventComplained EventType = "complained"
+	// EventDelayed is reported by a delivery status notification of a delayed email.
+	EventDelayed EventType = "delayed"
+	// EventRead is reported by a read receipt.
+	EventRead EventType = "read"
+	// EventOpened is reported by the open tracking of a provider.
+	EventOpened EventType = "opened"
+	// EventDeadLettered is raised by the mailer when it gives up on an email
+	// without sending it, the Reason tells why e.g. "expired".
+	EventDeadLettered EventType = "dead_lettered"
+	// EventFailed is raised by the mailer when an email queued with Enqueue
+	// could not be sent, the Reason holds the error.
+	EventFailed EventType = "failed"
+	// EventQuotaWarning is raised by the mailer when the sending quota of the
+	// provider is almost used, see MailCfg.SendQuotaWarning.
+	EventQuotaWarning EventType = "quota_warning"
+)
+
+// Event is a delivery event reported by a provider, normalized so that the same
+// handling code works regardless of the API service that sent the email.
+type Event struct {
+	// Type is the type of the event.
+	Type EventType
+	// Provider is the API service that reported the event.
+	Provider APIServiceType
+	// MessageID is the provider message id of the email.
+	MessageID string
+	// Recipient is the email address the event refers to.
+	Recipient string
+	// Timestamp is the time at which the event happened.
+	Timestamp time.Time
+	// Reason is the provider description of the event e.g. a bounce diagnostic code.
+	Reason string
+	// Permanent reports whether a bounce is permanent i.e. a hard bounce.
+	Permanent bool
+}
+
+// emit passes the event to the EventHandler of the mailer, if any, and queues it
+// for the Webhooks.
+func (m *Mailer) emit(event Event) {
+	if event.Type == EventBounced {
+		m.stats.bounce(m.clock.Now())
+	}
+	if m.eventHandler != nil {
+		m.eventHandler(event)
+	}
+	for _, webhook := range m.webhooks {
+		webhook.forward(event)
+	}
+}
+
+// deadLetter raises an EventDeadLettered for an email the mailer gave up on, and
+// adds it to the DeadLetterStore.
+func (m *Mailer) deadLetter(id string, msg Mail, reason string) {
+	if m.deadLetterStore != nil {
+		letter := DeadLetter{MessageID: id, Mail: msg, Reason: reason, Time: m.clock.Now()}
+		if err := m.deadLetterStore.Add(context.Background(), letter); err != nil {
+			log.Printf("mailer: failed to store dead letter %s: %v", id, err)
+		}
+	}
+	m.emit(Event{
+		Type:      EventDeadLettered,
+		Provider:  m.apiService,
+		MessageID: id,
+		Recipient: joinAddresses(msg.To),
+		Timestamp: m.clock.Now(),
+		Reason:    reason,
+		Permanent: true,
+	})
+}
@@ -0,0 +1,93 @@
+package mailer
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// Variant is one version of an email in an Experiment.
+type Variant struct {
+	// Name identifies the variant in the tags of the emails.
+	Name string
+	// Weight is the share of the recipients receiving the variant, e.g. 50 for a 50/50 split.
+	Weight int
+	// Subject replaces the subject of the email when set.
+	Subject string
+	// Apply changes the email for the variant, e.g. to render another template version.
+	Apply func(Mail) Mail
+}
+
+// Experiment splits the recipients of emails between variants, to compare their
+// open and click rates.
+type Experiment struct {
+	// Name identifies the experiment in the tags of the emails.
+	Name string
+	// Category restricts the experiment to the emails of the category.
+	Category string
+	Variants []Variant
+}
+
+// Assign returns the variant of the recipient. The same recipient always gets
+// the same variant of an experiment, whether it is given with a display name or
+// as the bare address.
+func (e Experiment) Assign(recipient string) Variant {
+	total := 0
+	for _, variant := range e.Variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return Variant{}
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(e.Name))
+	hash.Write([]byte{0})
+	hash.Write([]byte(strings.ToLower(toAddress(recipient).Email)))
+	bucket := int(hash.Sum32() % uint32(total))
+
+	for _, variant := range e.Variants {
+		if bucket < variant.Weight {
+			return variant
+		}
+		bucket -= variant.Weight
+	}
+	return e.Variants[len(e.Variants)-1]
+}
+
+// Tag returns the tag of the emails sent with the variant, "<experiment>:<variant>",
+// used to attribute the provider events to the variant.
+func (e Experiment) Tag(variant Variant) string {
+	return e.Name + ":" + variant.Name
+}
+
+// ExperimentMiddleware returns a Middleware applying the variant assigned to the
+// first To recipient of the emails, and tagging them with it.
+func ExperimentMiddleware(experiments ...Experiment) Middleware {
+	return func(next SendFunc) SendFunc {
+		return func(msg Mail) error {
+			if len(msg.To) == 0 {
+				return next(msg)
+			}
+			recipient := msg.To[0].Email
+
+			for _, experiment := range experiments {
+				if experiment.Category != "" && experiment.Category != msg.Category {
+					continue
+				}
+				variant := experiment.Assign(recipient)
+				if variant.Name == "" {
+					continue
+				}
+
+				if variant.Subject != "" {
+					msg.Subject = variant.Subject
+				}
+				if variant.Apply != nil {
+					msg = variant.Apply(msg)
+				}
+				msg.Tags = append(append([]string(nil), msg.Tags...), experiment.Tag(variant))
+			}
+			return next(msg)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExperiment_Assign(t *testing.T) {
+	experiment := Experiment{
+		Name: "subject-line",
+		Variants: []Variant{
+			{Name: "a", Weight: 80},
+			{Name: "b", Weight: 20},
+		},
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		recipient := fmt.Sprintf("user%d@example.com", i)
+		variant := experiment.Assign(recipient)
+		if again := experiment.Assign(recipient); again.Name != variant.Name {
+			t.Fatalf("Expected a deterministic assignment for %s", recipient)
+		}
+		counts[variant.Name]++
+	}
+
+	if counts["a"] < 700 || counts["a"] > 900 || counts["a"]+counts["b"] != 1000 {
+		t.Errorf("Expected a split close to 80/20, got %v", counts)
+	}
+	for i := 0; i < 100; i++ {
+		recipient := fmt.Sprintf("user%d@example.com", i)
+		named := fmt.Sprintf(`"User %d" <USER%d@Example.com>`, i, i)
+		if experiment.Assign(named).Name != experiment.Assign(recipient).Name {
+			t.Fatalf("Expected %s to get the variant of %s", named, recipient)
+		}
+	}
+	if variant := (Experiment{Name: "empty"}).Assign("user@example.com"); variant.Name != "" {
+		t.Errorf("Expected no variant, got %s", variant.Name)
+	}
+}
+
+func TestExperimentMiddleware(t *testing.T) {
+	experiment := Experiment{
+		Name:     "welcome",
+		Category: "onboarding",
+		Variants: []Variant{{
+			Name:    "short",
+			Weight:  1,
+			Subject: "Hi!",
+			Apply: func(msg Mail) Mail {
+				msg.Text = "Short welcome"
+				return msg
+			},
+		}},
+	}
+
+	var sent []Mail
+	send := ExperimentMiddleware(experiment)(func(msg Mail) error {
+		sent = append(sent, msg)
+		return nil
+	})
+
+	send(Mail{To: Addresses("user@example.com"), Category: "onboarding", Subject: "Welcome aboard", Tags: []string{"welcome"}})
+	send(Mail{To: Addresses("user@example.com"), Category: "billing", Subject: "Your invoice"})
+
+	if sent[0].Subject != "Hi!" || sent[0].Text != "Short welcome" || len(sent[0].Tags) != 2 || sent[0].Tags[1] != "welcome:short" {
+		t.Errorf("Expected the variant to be applied, got %+v", sent[0])
+	}
+	if sent[1].Subject != "Your invoice" || len(sent[1].Tags) != 0 {
+		t.Errorf("Expected other categories to be untouched, got %+v", sent[1])
+	}
+}
@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// defaultFileDir is the directory of the FILE service when FileDir is not set.
+var defaultFileDir = filepath.Join(os.TempDir(), "mailer-outbox")
+
+type fileParams struct {
+	dir   string
+	clock Clock
+}
+
+type fileMailer struct {
+	dir   string
+	clock Clock
+	seq   atomic.Uint64
+}
+
+func newFile(params fileParams) MailerClient {
+	if params.dir == "" {
+		params.dir = defaultFileDir
+	}
+	if params.clock == nil {
+		params.clock = systemClock{}
+	}
+	return &fileMailer{dir: params.dir, clock: params.clock}
+}
+
+// Send writes the message to a new .eml file of the directory, which mail
+// clients open as is.
+func (m *fileMailer) Send(msg Mail) error {
+	message, email := buildMessage(msg)
+	if email.Error != nil {
+		return email.Error
+	}
+	return m.write([]byte(message))
+}
+
+// SendRaw writes the message as is, the envelope is not kept.
+func (m *fileMailer) SendRaw(ctx context.Context, from string, rcpts []string, message []byte) error {
+	return m.write(message)
+}
+
+// write writes the message to a file named after the time, the files sorting in
+// the order the emails were sent.
+func (m *fileMailer) write(message []byte) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%06d.eml", m.clock.Now().UTC().Format("20060102T150405.000000000"), m.seq.Add(1))
+	return os.WriteFile(filepath.Join(m.dir, name), message, 0o644)
+}
+
+// Health verifies that the directory can be written to.
+func (m *fileMailer) Health(ctx context.Context) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(m.dir, ".health-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+func (m *fileMailer) Close() {}
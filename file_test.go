@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFile_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	clock := &fakeClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	file := newFile(fileParams{dir: dir, clock: clock})
+
+	for _, subject := range []string{"first", "second"} {
+		if err := file.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: subject, Text: "test"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %v, %v", files, err)
+	}
+	if !strings.HasPrefix(files[0].Name(), "20240102T030405") || filepath.Ext(files[0].Name()) != ".eml" {
+		t.Errorf("Expected a file named after the time, got %s", files[0].Name())
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, files[1].Name()))
+	if !strings.Contains(string(content), "Subject: second") {
+		t.Errorf("Expected the files in the order of the emails, got %s", content)
+	}
+
+	if err := file.(HealthChecker).Health(context.Background()); err != nil {
+		t.Errorf("Expected the directory to be writable, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// ComplianceFooter is the footer block appended to marketing emails to help with
+// CAN-SPAM and GDPR compliance.
+type ComplianceFooter struct {
+	// PhysicalAddress is the postal address of the sender.
+	PhysicalAddress string
+	// UnsubscribeURL is the unsubscribe link. The {recipient} placeholder is
+	// replaced with the url escaped To address.
+	UnsubscribeURL string
+	// LegalText is any additional legal text.
+	LegalText string
+	// Categories are the email categories receiving the footer. Defaults to "marketing".
+	Categories []string
+}
+
+// ComplianceFooterMiddleware returns a Middleware appending the footer to the html and
+// text bodies of the emails whose Category is one of the footer categories.
+func ComplianceFooterMiddleware(footer ComplianceFooter) Middleware {
+	categories := footer.Categories
+	if len(categories) == 0 {
+		categories = []string{"marketing"}
+	}
+
+	return func(next SendFunc) SendFunc {
+		return func(msg Mail) error {
+			for _, category := range categories {
+				if msg.Category == category {
+					msg = footer.apply(msg)
+					break
+				}
+			}
+			return next(msg)
+		}
+	}
+}
+
+func (f ComplianceFooter) apply(msg Mail) Mail {
+	unsubscribeURL := strings.ReplaceAll(f.UnsubscribeURL, "{recipient}", url.QueryEscape(strings.Join(addressEmails(msg.To), ",")))
+
+	if msg.Text != "" {
+		var lines []string
+		for _, line := range []string{f.LegalText, f.PhysicalAddress} {
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if unsubscribeURL != "" {
+			lines = append(lines, "Unsubscribe: "+unsubscribeURL)
+		}
+		msg.Text += "\n\n--\n" + strings.Join(lines, "\n")
+	}
+
+	if msg.Html != "" {
+		var block strings.Builder
+		block.WriteString(`<div class="compliance-footer" style="font-size:12px;color:#666666;">`)
+		for _, line := range []string{f.LegalText, f.PhysicalAddress} {
+			if line != "" {
+				block.WriteString("<p>" + html.EscapeString(line) + "</p>")
+			}
+		}
+		if unsubscribeURL != "" {
+			block.WriteString(`<p><a href="` + html.EscapeString(unsubscribeURL) + `">Unsubscribe</a></p>`)
+		}
+		block.WriteString("</div>")
+
+		if i := strings.LastIndex(strings.ToLower(msg.Html), "</body>"); i >= 0 {
+			msg.Html = msg.Html[:i] + block.String() + msg.Html[i:]
+		} else {
+			msg.Html += block.String()
+		}
+	}
+
+	return msg
+}
@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComplianceFooterMiddleware(t *testing.T) {
+	footer := ComplianceFooter{
+		PhysicalAddress: "1 Caesar Street, Rome",
+		UnsubscribeURL:  "https://test.com/unsubscribe?email={recipient}",
+		LegalText:       "You receive this email because you subscribed.",
+	}
+
+	testCases := []struct {
+		name       string
+		msg        Mail
+		expectHtml string
+		expectText string
+	}{
+		{
+			name: "Should append the footer before the end of the body",
+			msg: Mail{
+				To:       Addresses("a+b@test.com"),
+				Category: "marketing",
+				Html:     "<html><body><p>Sale</p></body></html>",
+				Text:     "Sale",
+			},
+			expectHtml: `<p><a href="https://test.com/unsubscribe?email=a%2Bb%40test.com">Unsubscribe</a></p></div></body></html>`,
+			expectText: "Sale\n\n--\nYou receive this email because you subscribed.\n1 Caesar Street, Rome\nUnsubscribe: https://test.com/unsubscribe?email=a%2Bb%40test.com",
+		},
+		{
+			name: "Should append the footer to html fragments",
+			msg: Mail{
+				To:       Addresses("a@test.com"),
+				Category: "marketing",
+				Html:     "<p>Sale</p>",
+			},
+			expectHtml: `<p>Sale</p><div class="compliance-footer"`,
+		},
+		{
+			name: "Should leave other categories untouched",
+			msg: Mail{
+				To:       Addresses("a@test.com"),
+				Category: "transactional",
+				Html:     "<p>Receipt</p>",
+				Text:     "Receipt",
+			},
+			expectHtml: "<p>Receipt</p>",
+			expectText: "Receipt",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var sent Mail
+			send := ComplianceFooterMiddleware(footer)(func(msg Mail) error {
+				sent = msg
+				return nil
+			})
+
+			if err := send(tc.msg); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !strings.Contains(sent.Html, tc.expectHtml) {
+				t.Errorf("Expected html to contain %q, got %q", tc.expectHtml, sent.Html)
+			}
+			if sent.Text != tc.expectText && tc.expectText != "" {
+				t.Errorf("Expected text %q, got %q", tc.expectText, sent.Text)
+			}
+		})
+	}
+}
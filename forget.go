@@ -0,0 +1,319 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Eraser is implemented by the stores that can erase the data of an email
+// address, to satisfy right-to-erasure requests.
+type Eraser interface {
+	// Erase removes the data of the address and returns the number of items erased.
+	Erase(ctx context.Context, email string) (int, error)
+}
+
+// ForgetReport counts the data of an address erased by Forget.
+type ForgetReport struct {
+	Email string
+	// AuditRecords are the records of the emails sent to or from the address.
+	AuditRecords int
+	// Archives are the archived messages mentioning the address.
+	Archives int
+	// DeadLetters are the dead letters sent to or from the address.
+	DeadLetters int
+	// Suppressions are the suppressions of the address whose metadata was erased,
+	// the address itself staying suppressed so that it is not emailed again.
+	Suppressions int
+	// DedupEntries are the emails to the address remembered for deduplication.
+	DedupEntries int
+	// Spooled are the emails to the address dropped from the spool.
+	Spooled int
+	// Queued are the emails to or from the address canceled before being sent.
+	Queued int
+	// Statuses are the statuses of the emails whose error or provider responses
+	// mentioned the address, cleared of them.
+	Statuses int
+	// Skipped are the stores holding data which cannot be erased because they do
+	// not implement Eraser, the data must be purged from them by other means.
+	Skipped []string
+}
+
+// Forget erases the data of the address from the audit store, the archive store,
+// the dead letter store, the suppression lists of the tenants, the deduplication
+// cache, the spool, the queue and the statuses of the emails. The stores not
+// implementing Eraser are listed in the report rather than failing the erasure,
+// and the archive sent to the ArchiveAddress or written to the ArchiveWriter is
+// out of reach of the mailer.
+func (m *Mailer) Forget(ctx context.Context, email string) (ForgetReport, error) {
+	email = strings.ToLower(toAddress(email).Email)
+	report := ForgetReport{Email: email}
+	if email == "" {
+		return report, errors.New("email address is missing")
+	}
+
+	type erasable struct {
+		name  string
+		store any
+		count *int
+	}
+	stores := []erasable{
+		{"audit store", m.auditStore, &report.AuditRecords},
+		{"archive store", m.archiveStore, &report.Archives},
+		{"dead letter store", m.deadLetterStore, &report.DeadLetters},
+	}
+	m.tenantsMu.RLock()
+	var tenants []erasable
+	for id, t := range m.tenants {
+		if t.Suppressions != nil {
+			tenants = append(tenants, erasable{"suppression list of tenant " + id, t.Suppressions, &report.Suppressions})
+		}
+	}
+	m.tenantsMu.RUnlock()
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].name < tenants[j].name })
+	stores = append(stores, tenants...)
+
+	for _, s := range stores {
+		if s.store == nil {
+			continue
+		}
+		eraser, ok := s.store.(Eraser)
+		if !ok {
+			report.Skipped = append(report.Skipped, s.name)
+			continue
+		}
+		n, err := eraser.Erase(ctx, email)
+		*s.count += n
+		if errors.Is(err, errNotForwarded) {
+			report.Skipped = append(report.Skipped, s.name)
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to erase %s from the %s: %w", email, s.name, err)
+		}
+	}
+
+	if m.dedup != nil {
+		report.DedupEntries = m.dedup.erase(email)
+	}
+	report.Queued = m.cancelQueued(email)
+	report.Statuses = m.statuses.erase(email)
+	if m.spool != nil {
+		n, err := m.spool.erase(email)
+		report.Spooled = n
+		if err != nil {
+			return report, fmt.Errorf("failed to erase %s from the spool: %w", email, err)
+		}
+	}
+	return report, nil
+}
+
+// mailAddresses returns the lowercased addresses of the sender and recipients of
+// the email, including the original recipients of a redirected email.
+func mailAddresses(msg Mail) []string {
+	addrs := append([]Address{msg.From, msg.ReplyTo}, allRecipients(msg)...)
+	for _, header := range []string{msg.Headers["X-Original-To"], msg.Headers["X-Original-Cc"]} {
+		for _, email := range getSplitEmails(header) {
+			addrs = append(addrs, toAddress(email))
+		}
+	}
+
+	var addresses []string
+	for _, addr := range addrs {
+		if addr.Email != "" {
+			addresses = append(addresses, strings.ToLower(addr.Email))
+		}
+	}
+	return addresses
+}
+
+func mailMentions(msg Mail, email string) bool {
+	for _, address := range mailAddresses(msg) {
+		if address == email {
+			return true
+		}
+	}
+	return false
+}
+
+// rawMentions reports whether the address is in the address headers of the
+// rendered message.
+func rawMentions(raw []byte, email string) bool {
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		// An unparsable message is searched as text rather than kept.
+		return bytes.Contains(bytes.ToLower(raw), []byte(email))
+	}
+	for _, header := range []string{"From", "Reply-To", "To", "Cc", "Bcc", "X-Original-To", "X-Original-Cc"} {
+		addresses, _ := parsed.Header.AddressList(header)
+		for _, address := range addresses {
+			if strings.ToLower(address.Address) == email {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Erase removes the records of the emails sent to or from the address. It is
+// the only exception to the records being append-only.
+func (s *MemoryAuditStore) Erase(ctx context.Context, email string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.records[:0]
+	for _, record := range s.records {
+		if !auditMentions(record, email) {
+			kept = append(kept, record)
+		}
+	}
+	erased := len(s.records) - len(kept)
+	s.records = kept
+	return erased, nil
+}
+
+func auditMentions(record AuditRecord, email string) bool {
+	if strings.ToLower(toAddress(record.From).Email) == email {
+		return true
+	}
+	for _, recipient := range record.Recipients {
+		if strings.ToLower(toAddress(recipient).Email) == email {
+			return true
+		}
+	}
+	return false
+}
+
+// Erase removes the archived messages whose envelope recipients or address
+// headers mention the address.
+func (s *MemoryArchiveStore) Erase(ctx context.Context, email string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	erased := 0
+	for id, msg := range s.messages {
+		if slices.Contains(msg.Recipients, email) || rawMentions(msg.Raw, email) {
+			delete(s.messages, id)
+			erased++
+		}
+	}
+	return erased, nil
+}
+
+// Erase removes the dead letters sent to or from the address.
+func (s *MemoryDeadLetterStore) Erase(ctx context.Context, email string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.letters[:0]
+	for _, letter := range s.letters {
+		if !mailMentions(letter.Mail, email) {
+			kept = append(kept, letter)
+		}
+	}
+	erased := len(s.letters) - len(kept)
+	s.letters = kept
+	return erased, nil
+}
+
+// Erase drops the reasons, groups and dates of the suppressions of the address,
+// keeping it suppressed from every email without recording why nor since when.
+func (l *MemorySuppressionList) Erase(ctx context.Context, email string) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	erased := 0
+	for key, suppression := range l.suppressions {
+		if strings.ToLower(suppression.Email) == email {
+			delete(l.suppressions, key)
+			erased++
+		}
+	}
+	if erased > 0 {
+		suppression := Suppression{Email: email, Reason: SuppressionManual}
+		l.suppressions[suppression.key()] = suppression
+	}
+	return erased, nil
+}
+
+// erase forgets the emails sent to or from the address, returning their number.
+func (c *dedupCache) erase(email string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	erased := 0
+	for hash, entry := range c.sent {
+		for _, address := range entry.addresses {
+			if address == email {
+				delete(c.sent, hash)
+				erased++
+				break
+			}
+		}
+	}
+	return erased
+}
+
+// erase removes the spooled emails sent to or from the address, which are then
+// never sent, returning their number.
+func (s *spool) erase(email string) (int, error) {
+	s.replaying.Lock()
+	defer s.replaying.Unlock()
+
+	entries, err := s.list()
+	if err != nil {
+		return 0, err
+	}
+	erased := 0
+	for _, entry := range entries {
+		if !mailMentions(entry.Mail, email) {
+			continue
+		}
+		if err := s.remove(entry.ID); err != nil {
+			return erased, err
+		}
+		erased++
+	}
+	return erased, nil
+}
+
+// cancelQueued cancels the queued emails sent to or from the address, returning
+// their number.
+func (m *Mailer) cancelQueued(email string) int {
+	m.pendingMu.Lock()
+	var ids []string
+	for id, addresses := range m.pending {
+		if slices.Contains(addresses, email) {
+			ids = append(ids, id)
+		}
+	}
+	m.pendingMu.Unlock()
+
+	canceled := 0
+	for _, id := range ids {
+		if m.Cancel(id) {
+			canceled++
+		}
+	}
+	return canceled
+}
+
+// erase clears the error and the responses of the statuses mentioning the
+// address, returning their number.
+func (t *statusTracker) erase(email string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	erased := 0
+	for id, status := range t.statuses {
+		mentioned := strings.Contains(strings.ToLower(status.Error), email)
+		for _, res := range status.Responses {
+			mentioned = mentioned || strings.Contains(strings.ToLower(res.Body), email)
+		}
+		if mentioned {
+			status.Error, status.Responses = "", nil
+			t.statuses[id] = status
+			erased++
+		}
+	}
+	return erased
+}
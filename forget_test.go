@@ -0,0 +1,126 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type appendOnlyAuditStore struct{}
+
+func (appendOnlyAuditStore) Append(ctx context.Context, record AuditRecord) error { return nil }
+
+func TestMailer_Forget(t *testing.T) {
+	audits := NewMemoryAuditStore()
+	archives := NewMemoryArchiveStore()
+	deadLetters := NewMemoryDeadLetterStore()
+	mailer := NewMailer(MailCfg{
+		APIService:      RESEND,
+		APIKey:          MailAPIKey,
+		AuditStore:      audits,
+		ArchiveStore:    archives,
+		DeadLetterStore: deadLetters,
+		DedupWindow:     time.Hour,
+		SpoolDir:        t.TempDir(),
+		mailerClient:    &mockMailerClient{},
+	})
+	defer mailer.Close()
+
+	suppressions := NewMemorySuppressionList()
+	_ = suppressions.Suppress(context.Background(), Suppression{Email: "a@test.com", Reason: SuppressionBounce, CreatedAt: time.Now()})
+	_ = suppressions.Suppress(context.Background(), Suppression{Email: "a@test.com", Reason: SuppressionUnsubscribe, Group: "news"})
+	_ = suppressions.Suppress(context.Background(), Suppression{Email: "b@test.com", Reason: SuppressionBounce})
+	if err := mailer.RegisterTenant(Tenant{ID: "acme", Config: MailCfg{APIService: RESEND, APIKey: MailAPIKey}, Suppressions: suppressions}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	toA := Mail{From: Address{Email: "info@test.com"}, To: []Address{{Name: "John", Email: "A@test.com"}}, Subject: "Invoice", Text: "test"}
+	toB := Mail{From: Address{Email: "info@test.com"}, To: Addresses("b@test.com"), Cc: Addresses("c@test.com"), Subject: "Invoice", Text: "test"}
+	bccA := Mail{From: Address{Email: "info@test.com"}, To: Addresses("d@test.com"), Bcc: Addresses("a@test.com"), Subject: "Copy", Text: "test"}
+	for _, email := range []Mail{toA, toB, bccA} {
+		if err := mailer.Send(email); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := mailer.Send(toA); !errors.Is(err, ErrDuplicateMessage) {
+		t.Fatalf("Expected the duplicate to be dead lettered, got %v", err)
+	}
+	for id, email := range map[string]Mail{"spooled-a": {From: Address{Email: "info@test.com"}, To: Addresses("c@test.com"), Bcc: Addresses("a@test.com")}, "spooled-b": toB} {
+		if err := mailer.spool.write(spooledMail{ID: id, Mail: email, SpooledAt: time.Now()}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	mailer.statuses.set("bounced", MessageFailed, errors.New("550 a@test.com unknown"), time.Now())
+	mailer.Pause()
+	queued, _ := mailer.Enqueue(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "Later", Text: "test"})
+
+	report, err := mailer.Forget(context.Background(), "a@TEST.com")
+	mailer.Resume()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := ForgetReport{Email: "a@test.com", AuditRecords: 2, Archives: 2, DeadLetters: 1, Suppressions: 2, DedupEntries: 2, Spooled: 1, Queued: 1, Statuses: 1}
+	if report.Email != expected.Email || report.AuditRecords != expected.AuditRecords || report.Archives != expected.Archives ||
+		report.DeadLetters != expected.DeadLetters || report.Suppressions != expected.Suppressions ||
+		report.DedupEntries != expected.DedupEntries || report.Spooled != expected.Spooled ||
+		report.Queued != expected.Queued || report.Statuses != expected.Statuses || len(report.Skipped) != 0 {
+		t.Errorf("Expected the report %+v, got %+v", expected, report)
+	}
+
+	if records := audits.Records(); len(records) != 1 || records[0].Recipients[0] != "b@test.com" {
+		t.Errorf("Expected only the record of b@test.com to be kept, got %v", records)
+	}
+	if messages, _ := archives.List(context.Background()); len(messages) != 1 {
+		t.Errorf("Expected only the archive of b@test.com to be kept, got %d", len(messages))
+	}
+	if letters := deadLetters.List(); len(letters) != 0 {
+		t.Errorf("Expected the dead letter to be erased, got %v", letters)
+	}
+	if entries, _ := mailer.spool.list(); len(entries) != 1 || entries[0].ID != "spooled-b" {
+		t.Errorf("Expected only the spooled email of b@test.com to be kept, got %v", entries)
+	}
+	if status, _ := mailer.Status(queued); status.State != MessageCanceled {
+		t.Errorf("Expected the queued email to be canceled, got %+v", status)
+	}
+	if status, _ := mailer.Status("bounced"); status.Error != "" {
+		t.Errorf("Expected the error of the status to be erased, got %q", status.Error)
+	}
+
+	// The address stays suppressed, without the reason nor the date.
+	list, _ := suppressions.List(context.Background())
+	if len(list) != 2 || list[0] != (Suppression{Email: "a@test.com", Reason: SuppressionManual}) {
+		t.Errorf("Expected a@test.com to stay suppressed without metadata, got %v", list)
+	}
+	if suppressed, _ := suppressions.Suppressed("a@test.com"); !suppressed {
+		t.Errorf("Expected a@test.com to stay suppressed")
+	}
+
+	// The deduplication no longer remembers the email.
+	if err := mailer.Send(toA); err != nil {
+		t.Errorf("Expected the email not to be a duplicate anymore, got %v", err)
+	}
+}
+
+func TestMailer_ForgetSkipsStoresWithoutEraser(t *testing.T) {
+	mailer := NewMailer(MailCfg{
+		APIService:   RESEND,
+		APIKey:       MailAPIKey,
+		AuditStore:   appendOnlyAuditStore{},
+		mailerClient: &mockMailerClient{},
+	})
+	defer mailer.Close()
+
+	report, err := mailer.Forget(context.Background(), "a@test.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0] != "audit store" {
+		t.Errorf("Expected the audit store to be skipped, got %v", report.Skipped)
+	}
+
+	if _, err := mailer.Forget(context.Background(), ""); err == nil {
+		t.Errorf("Expected an error for a missing address")
+	}
+}
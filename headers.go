@@ -0,0 +1,26 @@
+package mailer
+
+import "sort"
+
+// withHeader returns the email with the header set, without modifying the headers
+// of the caller.
+func withHeader(msg Mail, key string, value string) Mail {
+	headers := make(map[string]string, len(msg.Headers)+1)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[key] = value
+	msg.Headers = headers
+	return msg
+}
+
+// headerKeys returns the keys of the headers sorted, for the headers to be sent
+// in a stable order.
+func headerKeys(headers map[string]string) []string {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
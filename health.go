@@ -0,0 +1,112 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HealthChecker is implemented by the mailer clients that can verify that their
+// provider is reachable and that the credentials are valid.
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// healthStatus holds the result of the last health probe.
+type healthStatus struct {
+	mu        sync.RWMutex
+	err       error
+	checkedAt time.Time
+}
+
+func (s *healthStatus) set(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	s.checkedAt = time.Now()
+}
+
+func (s *healthStatus) get() (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.checkedAt, s.err
+}
+
+// Health verifies that the provider of the chosen API service is reachable e.g. an
+// SMTP connect and EHLO, or an authenticated API call. Clients that cannot be
+// checked are reported as healthy.
+func (m *Mailer) Health(ctx context.Context) error {
+	checker, ok := m.mailerClient.(HealthChecker)
+	if !ok {
+		return nil
+	}
+	err := checker.Health(ctx)
+	m.health.set(err)
+	return err
+}
+
+// LastHealth returns the result of the last health check, either from a call to
+// Health or from the background probes enabled with HealthCheckInterval.
+// The returned time is zero when no check ran yet.
+func (m *Mailer) LastHealth() (time.Time, error) {
+	return m.health.get()
+}
+
+// Healthy reports whether the last health check succeeded. It is true until the
+// first check ran.
+func (m *Mailer) Healthy() bool {
+	_, err := m.health.get()
+	return err == nil
+}
+
+// HealthHandler returns an http.Handler to mount as a /healthz endpoint. It responds
+// with 200 when the provider is healthy and 503 otherwise.
+func (m *Mailer) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := m.Health(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+}
+
+// verifyOnStart runs the health check once, bounded by the configured timeout
+// or 10 seconds.
+func (m *Mailer) verifyOnStart(cfg MailCfg) {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := m.Health(ctx); err != nil {
+		if cfg.WarnOnVerifyFailure {
+			log.Printf("mailer: %s verification failed: %v", cfg.APIService, err)
+			return
+		}
+		panic(fmt.Errorf("mailer: %s verification failed: %w", cfg.APIService, err))
+	}
+}
+
+// probeHealth runs the health check every interval until the mailer is closed.
+func (m *Mailer) probeHealth(interval time.Duration) {
+	ticker := m.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C():
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			m.Health(ctx)
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMailer_Health(t *testing.T) {
+	testCases := []struct {
+		name       string
+		client     MailerClient
+		healthy    bool
+		statusCode int
+	}{
+		{
+			name:       "Should report clients without health checks as healthy",
+			client:     &mockMailerClient{},
+			healthy:    true,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Should report a healthy provider",
+			client:     &mockHealthClient{},
+			healthy:    true,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Should report an unreachable provider",
+			client:     &mockHealthClient{err: errors.New("connection refused")},
+			healthy:    false,
+			statusCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mailer := NewMailer(MailCfg{
+				APIService:   RESEND,
+				APIKey:       MailAPIKey,
+				mailerClient: tc.client,
+			})
+			defer mailer.Close()
+
+			err := mailer.Health(context.Background())
+			if (err == nil) != tc.healthy {
+				t.Errorf("Expected healthy to be %v, got error %v", tc.healthy, err)
+			}
+			if mailer.Healthy() != tc.healthy {
+				t.Errorf("Expected Healthy() to be %v", tc.healthy)
+			}
+
+			rec := httptest.NewRecorder()
+			mailer.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if rec.Code != tc.statusCode {
+				t.Errorf("Expected status %d, got %d", tc.statusCode, rec.Code)
+			}
+		})
+	}
+}
+
+func TestMailer_HealthProbes(t *testing.T) {
+	client := &mockHealthClient{err: errors.New("connection refused")}
+	mailer := NewMailer(MailCfg{
+		APIService:          RESEND,
+		APIKey:              MailAPIKey,
+		HealthCheckInterval: 10 * time.Millisecond,
+		mailerClient:        client,
+	})
+	defer mailer.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&client.calls) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	checkedAt, err := mailer.LastHealth()
+	if checkedAt.IsZero() || err == nil {
+		t.Errorf("Expected a failed background probe, got %v at %v", err, checkedAt)
+	}
+	if mailer.Healthy() {
+		t.Errorf("Expected mailer to be unhealthy")
+	}
+}
+
+func TestMailer_VerifyOnStart(t *testing.T) {
+	testCases := []struct {
+		name        string
+		client      *mockHealthClient
+		warnOnly    bool
+		expectPanic bool
+	}{
+		{
+			name:   "Should start when the provider is reachable",
+			client: &mockHealthClient{},
+		},
+		{
+			name:        "Should panic when the provider is unreachable",
+			client:      &mockHealthClient{err: errors.New("invalid api key")},
+			expectPanic: true,
+		},
+		{
+			name:     "Should only warn when configured to",
+			client:   &mockHealthClient{err: errors.New("invalid api key")},
+			warnOnly: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.expectPanic {
+					t.Errorf("Expected panic to be %v, got %v", tc.expectPanic, r)
+				}
+			}()
+
+			mailer := NewMailer(MailCfg{
+				APIService:          RESEND,
+				APIKey:              MailAPIKey,
+				VerifyOnStart:       true,
+				WarnOnVerifyFailure: tc.warnOnly,
+				mailerClient:        tc.client,
+			})
+			defer mailer.Close()
+
+			if tc.client.calls != 1 {
+				t.Errorf("Expected one verification, got %d", tc.client.calls)
+			}
+		})
+	}
+}
+
+func TestMailer_VerifyOnStartPanicsBeforeStarting(t *testing.T) {
+	before := runtime.NumGoroutine()
+	func() {
+		defer func() { recover() }()
+		NewMailer(MailCfg{
+			APIService:    RESEND,
+			APIKey:        MailAPIKey,
+			VerifyOnStart: true,
+			Webhooks:      []WebhookEndpoint{{URL: "http://127.0.0.1:1"}},
+			mailerClient:  &mockHealthClient{err: errors.New("invalid api key")},
+		})
+	}()
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expected no goroutine to be started, got %d more", after-before)
+	}
+}
+
+type mockHealthClient struct {
+	mockMailerClient
+	err   error
+	calls int32
+}
+
+func (m *mockHealthClient) Health(ctx context.Context) error {
+	atomic.AddInt32(&m.calls, 1)
+	return m.err
+}
@@ -0,0 +1,97 @@
+package mailer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// APIError is returned when an API service responds with an unexpected status code.
+type APIError struct {
+	// Provider is the API service that returned the error.
+	Provider APIServiceType
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Body is the body of the response.
+	Body string
+	// RequestID identifies the request for the support of the provider.
+	RequestID string
+	// Response is the response of the provider.
+	Response *ProviderResponse
+	// RetryAfter is how long the provider asked to wait before retrying, from the
+	// Retry-After or rate limit headers of the response.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s: unexpected status code %d", e.Provider, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: unexpected status code %d: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// doJSONRequest sends the request and decodes the JSON response body into out
+// when out is not nil. Responses outside of the 2xx range are returned as an *APIError.
+func doJSONRequest(client *http.Client, provider APIServiceType, req *http.Request, out any) error {
+	_, err := doJSONRequestWithResponse(client, provider, req, out)
+	return err
+}
+
+// doJSONRequestWithResponse is doJSONRequest also returning the response.
+func doJSONRequestWithResponse(client *http.Client, provider APIServiceType, req *http.Request, out any) (*ProviderResponse, error) {
+	res, err := client.Do(req)
+	releaseRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newProviderResponse(provider, res, body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return response, &APIError{
+			Provider:   provider,
+			StatusCode: res.StatusCode,
+			Body:       string(body),
+			RequestID:  response.RequestID,
+			Response:   response,
+			RetryAfter: retryAfter(res.Header, time.Now()),
+		}
+	}
+
+	if out == nil || len(body) == 0 {
+		return response, nil
+	}
+	return response, json.Unmarshal(body, out)
+}
+
+// newJSONRequest creates a request with the payload encoded as JSON, encoded in
+// a pooled buffer.
+func newJSONRequest(method string, url string, payload any) (*http.Request, error) {
+	var req *http.Request
+	var err error
+	if payload != nil {
+		buf := getBuffer()
+		if err := json.NewEncoder(buf).Encode(payload); err != nil {
+			putBuffer(buf)
+			return nil, err
+		}
+		// Encode ends the JSON with a newline.
+		buf.Truncate(buf.Len() - 1)
+		req, err = newPooledRequest(method, url, buf)
+	} else {
+		req, err = http.NewRequest(method, url, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
@@ -0,0 +1,50 @@
+package mailer
+
+import "time"
+
+// CalendarEvent is a standalone calendar event (iCalendar, RFC 5545), e.g. for a
+// webinar or an appointment confirmation.
+type CalendarEvent struct {
+	// UID identifies the event, so that updates replace it in calendars. Required.
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	// Organizer is the email address of the organizer.
+	Organizer string
+}
+
+// Attachment returns the event as a text/calendar attachment, published rather than
+// sent as an invitation so that no reply is expected.
+func (e CalendarEvent) Attachment(name string) Attachment {
+	const format = "20060102T150405Z"
+
+	var b contentLines
+	b.line("BEGIN:VCALENDAR")
+	b.line("VERSION:2.0")
+	b.line("PRODID:-//caesar-rocks//mail//EN")
+	b.line("METHOD:PUBLISH")
+	b.line("BEGIN:VEVENT")
+	b.line("UID:" + escapeContentValue(e.UID))
+	b.line("DTSTAMP:" + time.Now().UTC().Format(format))
+	b.line("DTSTART:" + e.Start.UTC().Format(format))
+	if !e.End.IsZero() {
+		b.line("DTEND:" + e.End.UTC().Format(format))
+	}
+	b.optional("SUMMARY:", e.Summary)
+	b.optional("DESCRIPTION:", e.Description)
+	b.optional("LOCATION:", e.Location)
+	if e.URL != "" {
+		b.line("URL:" + e.URL)
+	}
+	if e.Organizer != "" {
+		b.line("ORGANIZER:mailto:" + e.Organizer)
+	}
+	b.line("END:VEVENT")
+	b.line("END:VCALENDAR")
+
+	return Attachment{Name: name, Content: []byte(b.String()), ContentType: "text/calendar; charset=utf-8; method=PUBLISH"}
+}
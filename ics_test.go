@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarEvent_Attachment(t *testing.T) {
+	start := time.Date(2030, 3, 1, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+	attachment := CalendarEvent{
+		UID:         "webinar-42@example.com",
+		Summary:     "Webinar",
+		Description: "Join us;\nbring questions",
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Organizer:   "events@example.com",
+	}.Attachment("webinar.ics")
+
+	content := string(attachment.Content)
+	for _, line := range []string{
+		"METHOD:PUBLISH\r\n",
+		"UID:webinar-42@example.com\r\n",
+		"DTSTART:20300301T090000Z\r\n",
+		"DTEND:20300301T100000Z\r\n",
+		`DESCRIPTION:Join us\;\nbring questions` + "\r\n",
+		"ORGANIZER:mailto:events@example.com\r\n",
+	} {
+		if !strings.Contains(content, line) {
+			t.Errorf("Expected %q in %q", line, content)
+		}
+	}
+	if !strings.HasPrefix(content, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(content, "END:VCALENDAR\r\n") {
+		t.Errorf("Expected a calendar, got %q", content)
+	}
+	if attachment.ContentType != "text/calendar; charset=utf-8; method=PUBLISH" {
+		t.Errorf("Unexpected content type %s", attachment.ContentType)
+	}
+}
@@ -0,0 +1,34 @@
+package mailer
+
+// RecipientResult is the result of sending an email to one of the recipients.
+type RecipientResult struct {
+	// Recipient is the address the email was sent to.
+	Recipient string
+	// ID is the id of the email sent to the recipient, see Status.
+	ID string
+	// Err is the error of the send, nil when it was sent.
+	Err error
+}
+
+// SendIndividually sends a copy of the email to each of its To, Cc and Bcc
+// recipients, as the only recipient so that recipients cannot see each other,
+// and waits for all of them. The results are in the order of the recipients.
+func (m *Mailer) SendIndividually(msg Mail) []RecipientResult {
+	recipients := allRecipients(msg)
+
+	emails := make([]*queuedEmail, len(recipients))
+	results := make([]RecipientResult, len(recipients))
+	for i, recipient := range recipients {
+		single := msg
+		single.To, single.Cc, single.Bcc = []Address{recipient}, nil, nil
+		results[i].Recipient = recipient.String()
+		emails[i], results[i].Err = m.enqueue(single, make(chan error, 1))
+	}
+
+	for i, email := range emails {
+		if email != nil {
+			results[i] = RecipientResult{Recipient: email.msg.To[0].String(), ID: email.id, Err: <-email.result}
+		}
+	}
+	return results
+}
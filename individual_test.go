@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMailer_SendIndividually(t *testing.T) {
+	mailer := NewMailer(MailCfg{mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+		if joinAddresses(msg.Cc) != "" || joinAddresses(msg.Bcc) != "" {
+			return errors.New("expected a single recipient")
+		}
+		if joinAddresses(msg.To) == "bounce@test.com" {
+			return errors.New("mailbox unavailable")
+		}
+		return nil
+	}}})
+	defer mailer.Close()
+
+	results := mailer.SendIndividually(Mail{To: Addresses("a@test.com", "bounce@test.com"), Bcc: Addresses("c@test.com"), Subject: "test"})
+	if len(results) != 3 {
+		t.Fatalf("Expected three results, got %+v", results)
+	}
+	for i, recipient := range []string{"a@test.com", "bounce@test.com", "c@test.com"} {
+		if results[i].Recipient != recipient || results[i].ID == "" {
+			t.Errorf("Unexpected result %+v for %s", results[i], recipient)
+		}
+	}
+	if results[0].Err != nil || results[2].Err != nil {
+		t.Errorf("Expected the emails to be sent, got %v and %v", results[0].Err, results[2].Err)
+	}
+	if results[1].Err == nil || results[1].Err.Error() != "mailbox unavailable" {
+		t.Errorf("Expected the bounce, got %v", results[1].Err)
+	}
+	if status, _ := mailer.Status(results[1].ID); status.State != MessageFailed {
+		t.Errorf("Expected the status of the failed email, got %+v", status)
+	}
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// RequestInterceptor adds to an outbound API request of the provider before it
+// is sent, e.g. an HMAC signature or the token of an egress gateway. The body of
+// the request can be read and is restored for the provider. Returning an error
+// cancels the request.
+//
+// Amazon SES requests are already signed by the AWS SDK, so the interceptor must
+// not modify the signed headers of these.
+type RequestInterceptor func(provider APIServiceType, req *http.Request) error
+
+// interceptingTransport runs the interceptor on every request before sending it
+// with the base transport.
+type interceptingTransport struct {
+	provider  APIServiceType
+	intercept RequestInterceptor
+	base      http.RoundTripper
+}
+
+func (t *interceptingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it was given.
+	req = req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	if err := t.intercept(t.provider, req); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// providerHTTPClient returns the client of the API requests of the provider, with
+// the transport and the request interceptor of the configuration.
+func providerHTTPClient(cfg MailCfg, provider APIServiceType) *http.Client {
+	if cfg.HTTPTransport == nil && cfg.RequestInterceptor == nil {
+		return http.DefaultClient
+	}
+
+	transport := cfg.HTTPTransport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if cfg.RequestInterceptor != nil {
+		transport = &interceptingTransport{provider: provider, intercept: cfg.RequestInterceptor, base: transport}
+	}
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestInterceptor(t *testing.T) {
+	sign := func(body []byte) string {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("X-Signature") != sign(body) || r.Header.Get("X-Provider") != string(SENDGRID) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	cfg := MailCfg{
+		APIService: SENDGRID,
+		APIKey:     "key",
+		RequestInterceptor: func(provider APIServiceType, req *http.Request) error {
+			body, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			payload, _ := io.ReadAll(body)
+			if strings.Contains(string(payload), "blocked@test.com") {
+				return errors.New("blocked by the egress policy")
+			}
+			req.Header.Set("X-Signature", sign(payload))
+			req.Header.Set("X-Provider", string(provider))
+			return nil
+		},
+	}
+	sendgrid := getMailerClient(cfg).(*sendgridMailer)
+	sendgrid.baseURL = server.URL
+
+	if err := sendgrid.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Text: "test"}); err != nil {
+		t.Errorf("Expected the signed request to be accepted, got %v", err)
+	}
+	err := sendgrid.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("blocked@test.com"), Text: "test"})
+	if err == nil || !strings.Contains(err.Error(), "blocked by the egress policy") {
+		t.Errorf("Expected the interceptor to cancel the request, got %v", err)
+	}
+}
+
+func TestProviderHTTPClient(t *testing.T) {
+	if client := providerHTTPClient(MailCfg{}, SENDGRID); client != http.DefaultClient {
+		t.Errorf("Expected the default client without a transport or interceptor")
+	}
+
+	transport := &http.Transport{}
+	if client := providerHTTPClient(MailCfg{HTTPTransport: transport}, POSTMARK); client.Transport != transport {
+		t.Errorf("Expected the configured transport, got %T", client.Transport)
+	}
+}
@@ -0,0 +1,274 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// JSONAddressFormat is how a JSONAPIConfig encodes the addresses.
+type JSONAddressFormat string
+
+const (
+	// JSONAddressString encodes an address as "Name <email>".
+	JSONAddressString JSONAddressFormat = "string"
+	// JSONAddressObject encodes an address as {"email": ..., "name": ...}.
+	JSONAddressObject JSONAddressFormat = "object"
+)
+
+// JSONAPIConfig describes the HTTP JSON API of a provider that has no client of
+// its own, so that a niche provider only takes a configuration, e.g.
+//
+//	mailer.MailCfg{
+//		APIService: mailer.JSON_API,
+//		APIKey:     os.Getenv("ACME_API_KEY"),
+//		JSONAPI: &mailer.JSONAPIConfig{
+//			Name:       "acme",
+//			Endpoint:   "https://api.acme.example/v1/send",
+//			AuthHeader: "Authorization",
+//			AuthPrefix: "Bearer ",
+//			Fields: mailer.JSONAPIFields{
+//				From:    "from",
+//				To:      "to",
+//				Subject: "subject",
+//				Html:    "content.html",
+//				Text:    "content.text",
+//			},
+//		},
+//	}
+//
+// sends the emails as {"from": "...", "to": ["..."], "subject": "...",
+// "content": {"html": "...", "text": "..."}}. Responses outside of the 2xx range
+// are returned as an *APIError.
+type JSONAPIConfig struct {
+	// Name is the provider in the errors and the responses. Defaults to JSON_API.
+	Name APIServiceType
+	// Endpoint is the URL the emails are sent to.
+	Endpoint string
+	// Method defaults to POST.
+	Method string
+	// AuthHeader is the header holding the APIKey, preceded by AuthPrefix e.g.
+	// "Bearer ". The key is not sent in a header when empty.
+	AuthHeader string
+	AuthPrefix string
+	// Headers are sent with every request, e.g. an API version.
+	Headers map[string]string
+	// Static are the fields sent with every email, by path.
+	Static map[string]any
+	// Fields maps the email to the fields of the body.
+	Fields JSONAPIFields
+	// HealthURL is requested with GET by Health, which always succeeds when empty.
+	HealthURL string
+}
+
+// JSONAPIFields are the paths of the fields of an email in the body of a JSON
+// API request, the dots separating nested objects e.g. "content.subject". The
+// fields whose path is empty are not sent.
+type JSONAPIFields struct {
+	// APIKey is the path of the APIKey, for the APIs authenticating in the body.
+	APIKey  string
+	From    string
+	ReplyTo string
+	// To, Cc and Bcc are sent as arrays of addresses.
+	To      string
+	Cc      string
+	Bcc     string
+	Subject string
+	Html    string
+	Text    string
+	// Headers are sent as an object of the header values by name.
+	Headers string
+	// Tags are sent as an array of strings.
+	Tags string
+	// Attachments are sent as an array of objects, see AttachmentName,
+	// AttachmentContent and AttachmentContentType.
+	Attachments string
+
+	// Addresses is the format of the addresses. Defaults to JSONAddressString.
+	Addresses JSONAddressFormat
+	// AttachmentName, AttachmentContent and AttachmentContentType are the fields
+	// of an attachment, its content being encoded in base64. They default to
+	// "filename", "content" and "content_type".
+	AttachmentName        string
+	AttachmentContent     string
+	AttachmentContentType string
+}
+
+type jsonAPIParams struct {
+	config     JSONAPIConfig
+	apiKey     string
+	userAgent  string
+	httpClient *http.Client
+}
+
+type jsonAPIMailer struct {
+	httpClient *http.Client
+	config     JSONAPIConfig
+	apiKey     string
+	userAgent  string
+}
+
+func newJSONAPI(params jsonAPIParams) MailerClient {
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+	config := params.config
+	if config.Name == "" {
+		config.Name = JSON_API
+	}
+	if config.Method == "" {
+		config.Method = http.MethodPost
+	}
+	if config.Fields.Addresses == "" {
+		config.Fields.Addresses = JSONAddressString
+	}
+	if config.Fields.AttachmentName == "" {
+		config.Fields.AttachmentName = "filename"
+	}
+	if config.Fields.AttachmentContent == "" {
+		config.Fields.AttachmentContent = "content"
+	}
+	if config.Fields.AttachmentContentType == "" {
+		config.Fields.AttachmentContentType = "content_type"
+	}
+
+	return &jsonAPIMailer{
+		httpClient: params.httpClient,
+		config:     config,
+		apiKey:     params.apiKey,
+		userAgent:  params.userAgent,
+	}
+}
+
+func (m *jsonAPIMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *jsonAPIMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	payload, err := m.buildPayload(msg)
+	if err != nil {
+		return nil, err
+	}
+	return m.doWithResponse(context.Background(), m.config.Method, m.config.Endpoint, payload)
+}
+
+func (m *jsonAPIMailer) buildPayload(msg Mail) (map[string]any, error) {
+	fields := m.config.Fields
+	payload := make(map[string]any)
+	for path, value := range m.config.Static {
+		setJSONPath(payload, path, value)
+	}
+
+	set := func(path string, value any) {
+		if path != "" {
+			setJSONPath(payload, path, value)
+		}
+	}
+	setString := func(path string, value string) {
+		if value != "" {
+			set(path, value)
+		}
+	}
+	setAddresses := func(path string, addrs []Address) {
+		if len(addrs) == 0 {
+			return
+		}
+		var addresses []any
+		for _, addr := range addrs {
+			addresses = append(addresses, m.address(addr))
+		}
+		set(path, addresses)
+	}
+
+	setString(fields.APIKey, m.apiKey)
+	if msg.From.Email != "" {
+		set(fields.From, m.address(msg.From))
+	}
+	if msg.ReplyTo.Email != "" {
+		set(fields.ReplyTo, m.address(msg.ReplyTo))
+	}
+	setAddresses(fields.To, msg.To)
+	setAddresses(fields.Cc, msg.Cc)
+	setAddresses(fields.Bcc, msg.Bcc)
+	setString(fields.Subject, msg.Subject)
+	setString(fields.Html, msg.Html)
+	setString(fields.Text, msg.Text)
+	if len(msg.Headers) > 0 {
+		set(fields.Headers, msg.Headers)
+	}
+	if len(msg.Tags) > 0 {
+		set(fields.Tags, msg.Tags)
+	}
+
+	if fields.Attachments != "" && len(msg.Attachments) > 0 {
+		var attachments []any
+		for _, attachment := range msg.Attachments {
+			content, err := encodeAttachmentBase64(attachment)
+			if err != nil {
+				return nil, err
+			}
+			attachments = append(attachments, map[string]any{
+				fields.AttachmentName:        attachment.Name,
+				fields.AttachmentContent:     content,
+				fields.AttachmentContentType: getAttachmentContentType(attachment),
+			})
+		}
+		set(fields.Attachments, attachments)
+	}
+	return payload, nil
+}
+
+func (m *jsonAPIMailer) address(addr Address) any {
+	if m.config.Fields.Addresses != JSONAddressObject {
+		return addr.String()
+	}
+	if addr.Name == "" {
+		return map[string]any{"email": addr.Email}
+	}
+	return map[string]any{"email": addr.Email, "name": addr.Name}
+}
+
+// setJSONPath sets the value at the dot separated path, creating the objects
+// along it.
+func setJSONPath(obj map[string]any, path string, value any) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := obj[key].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			obj[key] = child
+		}
+		obj = child
+	}
+	obj[keys[len(keys)-1]] = value
+}
+
+func (m *jsonAPIMailer) doWithResponse(ctx context.Context, method string, url string, payload any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(method, url, payload)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range m.config.Headers {
+		req.Header.Set(key, value)
+	}
+	if m.config.AuthHeader != "" {
+		req.Header.Set(m.config.AuthHeader, m.config.AuthPrefix+m.apiKey)
+	}
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, m.config.Name, req.WithContext(ctx), nil)
+}
+
+// Health requests the HealthURL of the configuration, when set.
+func (m *jsonAPIMailer) Health(ctx context.Context) error {
+	if m.config.HealthURL == "" {
+		return nil
+	}
+	_, err := m.doWithResponse(ctx, http.MethodGet, m.config.HealthURL, nil)
+	return err
+}
+
+func (m *jsonAPIMailer) Close() {
+	// Not implemented because a JSON API is stateless.
+}
@@ -0,0 +1,93 @@
+package mailer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJSONAPI_Send(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	client := newJSONAPI(jsonAPIParams{
+		apiKey: MailAPIKey,
+		config: JSONAPIConfig{
+			Endpoint:   server.URL + "/v1/send",
+			AuthHeader: "Authorization",
+			AuthPrefix: "Bearer ",
+			Static:     map[string]any{"options.track": true},
+			Fields: JSONAPIFields{
+				From:        "from",
+				To:          "to",
+				Subject:     "subject",
+				Html:        "content.html",
+				Text:        "content.text",
+				Attachments: "files",
+				Addresses:   JSONAddressObject,
+			},
+		},
+	})
+
+	err := client.Send(Mail{
+		From:        Address{Name: "Acme", Email: "info@test.com"},
+		To:          Addresses("a@test.com"),
+		Subject:     "test",
+		Html:        "<p>test</p>",
+		Text:        "test",
+		Attachments: []Attachment{{Name: "hello.txt", Content: []byte("hello")}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := requests[0]
+	if req.Method != http.MethodPost || req.Path != "/v1/send" || req.Header.Get("Authorization") != "Bearer "+MailAPIKey {
+		t.Fatalf("Expected an authenticated POST to /v1/send, got %s %s %v", req.Method, req.Path, req.Header)
+	}
+	if from := req.Body["from"].(map[string]any); from["email"] != "info@test.com" || from["name"] != "Acme" {
+		t.Errorf("Expected the sender as an object, got %v", from)
+	}
+	if to := req.Body["to"].([]any); len(to) != 1 || to[0].(map[string]any)["email"] != "a@test.com" {
+		t.Errorf("Expected the recipient as an object, got %v", to)
+	}
+	if content := req.Body["content"].(map[string]any); content["html"] != "<p>test</p>" || content["text"] != "test" {
+		t.Errorf("Expected the nested content, got %v", content)
+	}
+	if options := req.Body["options"].(map[string]any); options["track"] != true {
+		t.Errorf("Expected the static fields, got %v", req.Body["options"])
+	}
+	file := req.Body["files"].([]any)[0].(map[string]any)
+	if file["filename"] != "hello.txt" || file["content"] != "aGVsbG8=" || file["content_type"] != "text/plain; charset=utf-8" {
+		t.Errorf("Expected the attachment, got %v", file)
+	}
+	if _, ok := req.Body["cc"]; ok {
+		t.Errorf("Expected the unmapped fields not to be sent, got %v", req.Body)
+	}
+}
+
+func TestJSONAPI_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error":"invalid sender"}`))
+	}))
+	defer server.Close()
+
+	client := newJSONAPI(jsonAPIParams{config: JSONAPIConfig{Name: "acme", Endpoint: server.URL, Fields: JSONAPIFields{To: "to"}}})
+	err := client.Send(Mail{To: Addresses("a@test.com")})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Provider != "acme" || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected an APIError of acme, got %v", err)
+	}
+}
+
+func TestJSONAPI_RequiredFields(t *testing.T) {
+	if err := validateMailerRequiredFields(MailCfg{APIService: JSON_API}); err == nil {
+		t.Error("Expected an error without a JSON API configuration")
+	}
+	cfg := MailCfg{APIService: JSON_API, JSONAPI: &JSONAPIConfig{Endpoint: "https://api.test.com", Fields: JSONAPIFields{To: "to"}}}
+	if err := validateMailerRequiredFields(cfg); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// LintRule identifies a check of the HTML linter.
+type LintRule string
+
+const (
+	LintRelativeLink   LintRule = "relative-link"
+	LintBrokenLink     LintRule = "broken-link"
+	LintMissingAlt     LintRule = "missing-alt"
+	LintUnsupportedCSS LintRule = "unsupported-css"
+	LintOversizedImage LintRule = "oversized-image"
+	LintScript         LintRule = "script"
+)
+
+// LintWarning is a problem found in an html body.
+type LintWarning struct {
+	// Rule is the check that failed.
+	Rule LintRule
+	// Element is the tag of the element e.g. "img".
+	Element string
+	// Message describes the problem.
+	Message string
+}
+
+func (w LintWarning) String() string {
+	return fmt.Sprintf("%s <%s>: %s", w.Rule, w.Element, w.Message)
+}
+
+// LintError is returned by HTMLLintMiddleware when blocking emails with warnings.
+type LintError struct {
+	Warnings []LintWarning
+}
+
+func (e *LintError) Error() string {
+	messages := make([]string, len(e.Warnings))
+	for i, warning := range e.Warnings {
+		messages[i] = warning.String()
+	}
+	return "html lint failed: " + strings.Join(messages, "; ")
+}
+
+// LintOptions configures LintHTML.
+type LintOptions struct {
+	// CheckLinks requests every absolute link and image to detect broken ones and
+	// oversized images. It is disabled by default because it needs network access.
+	CheckLinks bool
+	// HTTPClient is used to check the links. Defaults to a client with a 10 seconds timeout.
+	HTTPClient *http.Client
+	// MaxImageWidth is the maximum width attribute of images. Defaults to 800 pixels.
+	MaxImageWidth int
+	// MaxImageBytes is the maximum size of the images when checking links. Defaults to 1MB.
+	MaxImageBytes int64
+}
+
+// unsupportedCSS lists the CSS features that are ignored or broken in major email
+// clients such as Outlook and Gmail.
+var unsupportedCSS = []struct {
+	pattern *regexp.Regexp
+	name    string
+}{
+	{regexp.MustCompile(`(?i)\bposition\s*:`), "position"},
+	{regexp.MustCompile(`(?i)\bdisplay\s*:\s*(flex|grid|inline-flex|inline-grid)`), "flexbox/grid layout"},
+	{regexp.MustCompile(`(?i)@import`), "@import"},
+	{regexp.MustCompile(`(?i)\b(transform|animation|transition)\s*:`), "transform/animation"},
+	{regexp.MustCompile(`(?i)\bvar\(\s*--`), "custom properties"},
+}
+
+// LintHTML checks an html body for relative or broken links, images without
+// alt text, CSS unsupported by email clients and oversized images.
+func LintHTML(ctx context.Context, body string, opts LintOptions) []LintWarning {
+	if opts.MaxImageWidth == 0 {
+		opts.MaxImageWidth = 800
+	}
+	if opts.MaxImageBytes == 0 {
+		opts.MaxImageBytes = 1 << 20
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	var warnings []LintWarning
+	warn := func(rule LintRule, element string, format string, args ...any) {
+		warnings = append(warnings, LintWarning{Rule: rule, Element: element, Message: fmt.Sprintf(format, args...)})
+	}
+	checkCSS := func(element string, css string) {
+		for _, feature := range unsupportedCSS {
+			if feature.pattern.MatchString(css) {
+				warn(LintUnsupportedCSS, element, "%s is not supported by most email clients", feature.name)
+			}
+		}
+	}
+
+	checked := make(map[string]bool)
+	checkURL := func(element string, link string, image bool) {
+		if strings.HasPrefix(link, "#") || strings.HasPrefix(link, "mailto:") || strings.HasPrefix(link, "tel:") || strings.HasPrefix(link, "cid:") {
+			return
+		}
+		u, err := url.Parse(link)
+		if err != nil || link == "" {
+			warn(LintBrokenLink, element, "invalid url %q", link)
+			return
+		}
+		if !u.IsAbs() {
+			warn(LintRelativeLink, element, "relative url %q cannot be resolved by email clients", link)
+			return
+		}
+		if !opts.CheckLinks || checked[link] || (u.Scheme != "http" && u.Scheme != "https") {
+			return
+		}
+		checked[link] = true
+
+		size, err := checkLink(ctx, opts.HTTPClient, link)
+		if err != nil {
+			warn(LintBrokenLink, element, "%s: %v", link, err)
+			return
+		}
+		if image && size > opts.MaxImageBytes {
+			warn(LintOversizedImage, element, "%s is %d bytes, more than %d", link, size, opts.MaxImageBytes)
+		}
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	inStyle := false
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		switch tokenType {
+		case html.TextToken:
+			if inStyle {
+				checkCSS("style", token.Data)
+			}
+		case html.EndTagToken:
+			if token.Data == "style" {
+				inStyle = false
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			attrs := make(map[string]string, len(token.Attr))
+			for _, attr := range token.Attr {
+				attrs[attr.Key] = attr.Val
+			}
+
+			if style, ok := attrs["style"]; ok {
+				checkCSS(token.Data, style)
+			}
+
+			switch token.Data {
+			case "style":
+				inStyle = tokenType == html.StartTagToken
+			case "script":
+				warn(LintScript, token.Data, "scripts are stripped by email clients")
+			case "link":
+				if strings.EqualFold(attrs["rel"], "stylesheet") {
+					warn(LintUnsupportedCSS, token.Data, "external stylesheets are not supported by most email clients")
+				}
+			case "a":
+				if href, ok := attrs["href"]; ok {
+					checkURL(token.Data, strings.TrimSpace(href), false)
+				}
+			case "img":
+				if _, ok := attrs["alt"]; !ok {
+					warn(LintMissingAlt, token.Data, "image %q has no alt text", attrs["src"])
+				}
+				if width, err := strconv.Atoi(strings.TrimSuffix(attrs["width"], "px")); err == nil && width > opts.MaxImageWidth {
+					warn(LintOversizedImage, token.Data, "image %q is %d pixels wide, more than %d", attrs["src"], width, opts.MaxImageWidth)
+				}
+				checkURL(token.Data, strings.TrimSpace(attrs["src"]), true)
+			}
+		}
+	}
+
+	return warnings
+}
+
+// checkLink requests the url and returns the size of its content.
+func checkLink(ctx context.Context, client *http.Client, link string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		return 0, fmt.Errorf("status code %d", res.StatusCode)
+	}
+	if res.ContentLength >= 0 {
+		return res.ContentLength, nil
+	}
+	return io.Copy(io.Discard, res.Body)
+}
+
+// HTMLLintMiddleware returns a Middleware linting the html body of every email.
+// The warnings are logged, or returned as a *LintError when block is true.
+func HTMLLintMiddleware(opts LintOptions, block bool) Middleware {
+	return func(next SendFunc) SendFunc {
+		return func(msg Mail) error {
+			if msg.Html == "" {
+				return next(msg)
+			}
+
+			warnings := LintHTML(context.Background(), msg.Html, opts)
+			if len(warnings) > 0 {
+				if block {
+					return &LintError{Warnings: warnings}
+				}
+				log.Printf("mailer: email %q has html warnings: %v", msg.Subject, warnings)
+			}
+			return next(msg)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLintHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte("ok"))
+		case "/big.png":
+			w.Write([]byte(strings.Repeat("x", 2048)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name     string
+		html     string
+		opts     LintOptions
+		expected []LintRule
+	}{
+		{
+			name: "clean html",
+			html: `<p>Hi</p><a href="https://test.com">site</a><img src="https://test.com/logo.png" alt="Logo" width="200"><a href="mailto:a@test.com">mail</a>`,
+		},
+		{
+			name:     "relative links",
+			html:     `<a href="/account">account</a><img src="logo.png" alt="">`,
+			expected: []LintRule{LintRelativeLink, LintRelativeLink},
+		},
+		{
+			name:     "missing alt text and oversized width",
+			html:     `<img src="https://test.com/banner.png" width="1200px">`,
+			expected: []LintRule{LintMissingAlt, LintOversizedImage},
+		},
+		{
+			name:     "unsupported css",
+			html:     `<style>@import url(x.css); .a { display: flex; }</style><div style="position: absolute">x</div><link rel="stylesheet" href="https://test.com/a.css">`,
+			expected: []LintRule{LintUnsupportedCSS, LintUnsupportedCSS, LintUnsupportedCSS, LintUnsupportedCSS},
+		},
+		{
+			name:     "scripts",
+			html:     `<script>alert(1)</script>`,
+			expected: []LintRule{LintScript},
+		},
+		{
+			name:     "broken links and oversized images",
+			html:     `<a href="` + server.URL + `/ok">ok</a><a href="` + server.URL + `/missing">missing</a><img src="` + server.URL + `/big.png" alt="big">`,
+			opts:     LintOptions{CheckLinks: true, HTTPClient: server.Client(), MaxImageBytes: 1024},
+			expected: []LintRule{LintBrokenLink, LintOversizedImage},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			warnings := LintHTML(context.Background(), tc.html, tc.opts)
+			if len(warnings) != len(tc.expected) {
+				t.Fatalf("Expected rules %v, got %v", tc.expected, warnings)
+			}
+			for i, warning := range warnings {
+				if warning.Rule != tc.expected[i] {
+					t.Errorf("Expected rules %v, got %v", tc.expected, warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestHTMLLintMiddleware(t *testing.T) {
+	testCases := []struct {
+		name      string
+		block     bool
+		html      string
+		expectErr bool
+	}{
+		{name: "Should send clean emails", block: true, html: `<p>Hi</p>`},
+		{name: "Should block emails with warnings", block: true, html: `<img src="/logo.png">`, expectErr: true},
+		{name: "Should only log warnings", block: false, html: `<img src="/logo.png">`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sent := false
+			send := HTMLLintMiddleware(LintOptions{}, tc.block)(func(msg Mail) error {
+				sent = true
+				return nil
+			})
+
+			err := send(Mail{Subject: "test", Html: tc.html})
+
+			var lintErr *LintError
+			if tc.expectErr {
+				if !errors.As(err, &lintErr) || len(lintErr.Warnings) != 2 {
+					t.Fatalf("Expected LintError with two warnings, got %v", err)
+				}
+				if sent {
+					t.Errorf("Expected the email not to be sent")
+				}
+				return
+			}
+			if err != nil || !sent {
+				t.Errorf("Expected the email to be sent, got %v", err)
+			}
+		})
+	}
+}
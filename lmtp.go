@@ -0,0 +1,155 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+type lmtpParams struct {
+	Host    string
+	Port    string
+	Timeout int
+	dial    DialOptions
+}
+
+type lmtpMailer struct {
+	network string
+	address string
+	timeout time.Duration
+	dialer  *dialer
+}
+
+// newLMTP creates an LMTP (RFC 2033) client. The host is dialed over TCP unless it
+// is an absolute path, in which case it is used as a unix socket e.g. Dovecot's
+// /var/run/dovecot/lmtp.
+func newLMTP(params lmtpParams) MailerClient {
+	m := &lmtpMailer{
+		network: "tcp",
+		address: net.JoinHostPort(params.Host, params.Port),
+		timeout: time.Duration(params.Timeout) * time.Second,
+	}
+	m.dialer = newDialer(params.dial, m.timeout)
+	if strings.HasPrefix(params.Host, "/") {
+		m.network = "unix"
+		m.address = params.Host
+	}
+	return m
+}
+
+func (m *lmtpMailer) Send(msg Mail) error {
+	message, email := buildMessage(msg)
+
+	if email.Error != nil {
+		return email.Error
+	}
+
+	return m.SendRaw(context.Background(), email.GetFrom(), email.GetRecipients(), []byte(message))
+}
+
+// SendRaw delivers the message as-is to the recipients.
+func (m *lmtpMailer) SendRaw(ctx context.Context, from string, rcpts []string, message []byte) error {
+	text, pipelining, err := m.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer text.Close()
+
+	if err := sendEnvelope(text, envelope{from: from, rcpts: rcpts}, pipelining); err != nil {
+		return err
+	}
+
+	w := text.DotWriter()
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	// Unlike SMTP, LMTP returns one reply per recipient after the data.
+	var failed []string
+	for _, rcpt := range rcpts {
+		if _, msg, err := text.ReadResponse(250); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", rcpt, msg))
+		}
+	}
+
+	m.cmd(text, 221, "QUIT")
+
+	if len(failed) > 0 {
+		return fmt.Errorf("lmtp delivery failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// connect opens a session and sends the LHLO greeting, reporting whether the
+// server advertises PIPELINING.
+func (m *lmtpMailer) connect(ctx context.Context) (*textproto.Conn, bool, error) {
+	conn, err := m.dialer.DialContext(ctx, m.network, m.address)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if m.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(m.timeout))
+	}
+
+	text := textproto.NewConn(conn)
+	if _, _, err := text.ReadResponse(220); err != nil {
+		text.Close()
+		return nil, false, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	id, err := text.Cmd("LHLO %s", hostname)
+	if err != nil {
+		text.Close()
+		return nil, false, err
+	}
+	text.StartResponse(id)
+	_, msg, err := text.ReadResponse(250)
+	text.EndResponse(id)
+	if err != nil {
+		text.Close()
+		return nil, false, err
+	}
+
+	// The first line of the reply is the greeting, the others the extensions.
+	pipelining := false
+	for _, ext := range strings.Split(msg, "\n")[1:] {
+		pipelining = pipelining || strings.EqualFold(strings.TrimSpace(ext), "PIPELINING")
+	}
+	return text, pipelining, nil
+}
+
+func (m *lmtpMailer) Health(ctx context.Context) error {
+	text, _, err := m.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer text.Close()
+	return m.cmd(text, 221, "QUIT")
+}
+
+func (m *lmtpMailer) cmd(text *textproto.Conn, expectCode int, format string, args ...any) error {
+	id, err := text.Cmd(format, args...)
+	if err != nil {
+		return err
+	}
+	text.StartResponse(id)
+	defer text.EndResponse(id)
+	_, _, err = text.ReadResponse(expectCode)
+	return err
+}
+
+func (m *lmtpMailer) Close() {
+	// Not implemented because every email opens its own LMTP session.
+}
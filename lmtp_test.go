@@ -0,0 +1,146 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+)
+
+// serveLMTP runs a minimal LMTP server accepting one session and rejecting the
+// recipients listed in reject after the data.
+func serveLMTP(t *testing.T, reject map[string]bool) (net.Listener, chan []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	commands := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var received, recipients []string
+		r := bufio.NewReader(conn)
+		write := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		write("220 localhost LMTP ready")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				break
+			}
+			line = strings.TrimRight(line, "\r\n")
+			received = append(received, line)
+
+			switch {
+			case strings.HasPrefix(line, "LHLO"):
+				write("250-localhost")
+				write("250 PIPELINING")
+			case strings.HasPrefix(line, "RCPT TO:"):
+				recipients = append(recipients, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+				write("250 ok")
+			case line == "DATA":
+				write("354 go ahead")
+				for {
+					data, err := r.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+				}
+				for _, rcpt := range recipients {
+					if reject[rcpt] {
+						write("552 mailbox full")
+					} else {
+						write("250 delivered")
+					}
+				}
+			case line == "QUIT":
+				write("221 bye")
+				commands <- received
+				return
+			default:
+				write("250 ok")
+			}
+		}
+		commands <- received
+	}()
+
+	return ln, commands
+}
+
+func TestLMTP_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		reject  map[string]bool
+		success bool
+	}{
+		{
+			name:    "Should deliver to every recipient",
+			success: true,
+		},
+		{
+			name:    "Should report recipients rejected after data",
+			reject:  map[string]bool{"b@test.com": true},
+			success: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ln, commands := serveLMTP(t, tc.reject)
+			host, port, _ := net.SplitHostPort(ln.Addr().String())
+
+			lmtp := newLMTP(lmtpParams{Host: host, Port: port, Timeout: 5})
+			err := lmtp.Send(Mail{
+				From:    Address{Email: "info@test.com"},
+				To:      Addresses("a@test.com"),
+				Cc:      Addresses("b@test.com"),
+				Subject: "test",
+				Text:    "test",
+			})
+
+			if tc.success && err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !tc.success {
+				if err == nil || !strings.Contains(err.Error(), "b@test.com") {
+					t.Errorf("Expected error for b@test.com, got %v", err)
+				}
+			}
+
+			received := <-commands
+			if !strings.HasPrefix(received[0], "LHLO ") {
+				t.Errorf("Expected session to start with LHLO, got %s", received[0])
+			}
+		})
+	}
+}
+
+func TestLMTP_NewLMTPUnixSocket(t *testing.T) {
+	lmtp := newLMTP(lmtpParams{Host: "/var/run/dovecot/lmtp"}).(*lmtpMailer)
+	if lmtp.network != "unix" || lmtp.address != "/var/run/dovecot/lmtp" {
+		t.Errorf("Expected unix socket, got %s %s", lmtp.network, lmtp.address)
+	}
+}
+
+func TestLMTP_Health(t *testing.T) {
+	ln, commands := serveLMTP(t, nil)
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	lmtp := newLMTP(lmtpParams{Host: host, Port: port, Timeout: 5}).(*lmtpMailer)
+	if err := lmtp.Health(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	received := <-commands
+	if len(received) != 2 || received[1] != "QUIT" {
+		t.Errorf("Expected LHLO and QUIT, got %v", received)
+	}
+}
@@ -0,0 +1,121 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const loopsBaseURL = "https://app.loops.so"
+
+// LoopsOptions holds the Loops specific options of an email. Loops only sends
+// transactional emails designed in Loops, the Subject, Html and Text of the
+// email are not sent.
+type LoopsOptions struct {
+	// TransactionalID is the id of the transactional email in Loops, required.
+	TransactionalID string
+	// DataVariables are the values of the data variables of the transactional email.
+	DataVariables map[string]any
+	// AddToAudience adds the recipient to the contacts of the audience.
+	AddToAudience bool
+}
+
+type loopsParams struct {
+	apiKey     string
+	userAgent  string
+	httpClient *http.Client
+}
+
+type loopsMailer struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	userAgent  string
+}
+
+type loopsAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Data        string `json:"data"`
+}
+
+type loopsEmail struct {
+	TransactionalID string            `json:"transactionalId"`
+	Email           string            `json:"email"`
+	AddToAudience   bool              `json:"addToAudience,omitempty"`
+	DataVariables   map[string]any    `json:"dataVariables,omitempty"`
+	Attachments     []loopsAttachment `json:"attachments,omitempty"`
+}
+
+func newLoops(params loopsParams) MailerClient {
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+
+	return &loopsMailer{
+		httpClient: params.httpClient,
+		baseURL:    loopsBaseURL,
+		apiKey:     params.apiKey,
+		userAgent:  params.userAgent,
+	}
+}
+
+func (m *loopsMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *loopsMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	if msg.Loops == nil || msg.Loops.TransactionalID == "" {
+		return nil, errors.New("loops requires the transactional id of the email")
+	}
+
+	// Loops sends to a single address per call, the email being split by
+	// maxRecipients so that it is the To, Cc or Bcc recipient of the chunk.
+	recipients := allRecipients(msg)
+	if len(recipients) != 1 {
+		return nil, fmt.Errorf("loops sends to a single recipient, got %d", len(recipients))
+	}
+	payload := loopsEmail{
+		TransactionalID: msg.Loops.TransactionalID,
+		Email:           recipients[0].Email,
+		AddToAudience:   msg.Loops.AddToAudience,
+		DataVariables:   msg.Loops.DataVariables,
+	}
+
+	for _, attachment := range msg.Attachments {
+		content, err := encodeAttachmentBase64(attachment)
+		if err != nil {
+			return nil, err
+		}
+		payload.Attachments = append(payload.Attachments, loopsAttachment{
+			Filename:    attachment.Name,
+			ContentType: getAttachmentContentType(attachment),
+			Data:        content,
+		})
+	}
+
+	return m.doWithResponse(context.Background(), http.MethodPost, "/api/v1/transactional", payload)
+}
+
+func (m *loopsMailer) doWithResponse(ctx context.Context, method string, path string, payload any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(method, m.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, LOOPS, req.WithContext(ctx), nil)
+}
+
+// Health verifies the API key with the endpoint Loops provides for it.
+func (m *loopsMailer) Health(ctx context.Context) error {
+	_, err := m.doWithResponse(ctx, http.MethodGet, "/api/v1/api-key", nil)
+	return err
+}
+
+func (m *loopsMailer) Close() {
+	// Not implemented because the Loops API is stateless.
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"testing"
+)
+
+func TestLoops_Send(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	loops := newLoops(loopsParams{apiKey: MailAPIKey}).(*loopsMailer)
+	loops.baseURL = server.URL
+
+	err := loops.Send(Mail{
+		To:          []Address{{Name: "Ada", Email: "a@test.com"}},
+		Attachments: []Attachment{{Name: "hello.txt", Content: []byte("hello")}},
+		Loops:       &LoopsOptions{TransactionalID: "tx1", DataVariables: map[string]any{"name": "Ada"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := requests[0]
+	if req.Path != "/api/v1/transactional" || req.Header.Get("Authorization") != "Bearer "+MailAPIKey {
+		t.Fatalf("Expected an authenticated request to /api/v1/transactional, got %s %v", req.Path, req.Header)
+	}
+	if req.Body["transactionalId"] != "tx1" || req.Body["email"] != "a@test.com" {
+		t.Errorf("Expected the transactional email to the recipient, got %v", req.Body)
+	}
+	if req.Body["dataVariables"].(map[string]any)["name"] != "Ada" {
+		t.Errorf("Expected the data variables, got %v", req.Body["dataVariables"])
+	}
+	if attachment := req.Body["attachments"].([]any)[0].(map[string]any); attachment["data"] != "aGVsbG8=" {
+		t.Errorf("Expected the attachment, got %v", attachment)
+	}
+}
+
+func TestLoops_SendErrors(t *testing.T) {
+	loops := newLoops(loopsParams{apiKey: MailAPIKey})
+	if err := loops.Send(Mail{To: Addresses("a@test.com")}); err == nil {
+		t.Error("Expected an error without a transactional id")
+	}
+	if err := loops.Send(Mail{To: Addresses("a@test.com", "b@test.com"), Loops: &LoopsOptions{TransactionalID: "tx1"}}); err == nil {
+		t.Error("Expected an error with several recipients")
+	}
+}
+
+func TestLoops_Chunks(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	loops := newLoops(loopsParams{apiKey: MailAPIKey}).(*loopsMailer)
+	loops.baseURL = server.URL
+
+	msg := Mail{To: Addresses("a@test.com"), Bcc: Addresses("b@test.com"), Loops: &LoopsOptions{TransactionalID: "tx1"}}
+	if _, err := sendChunks(loops, msg, loops.maxRecipients()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(requests) != 2 || requests[1].Body["email"] != "b@test.com" {
+		t.Errorf("Expected a call per recipient, got %v", requests)
+	}
+}
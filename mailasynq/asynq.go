@@ -0,0 +1,56 @@
+// Package mailasynq sends emails as asynq tasks, leaving retries and scheduling
+// to asynq while the mailer handles the transport.
+package mailasynq
+
+import (
+	"context"
+	"fmt"
+
+	mailer "github.com/caesar-rocks/mail"
+	"github.com/hibiken/asynq"
+)
+
+// TypeSend is the type of the tasks sending an email.
+const TypeSend = "mail:send"
+
+// NewSendTask returns a task sending the email, its payload is the JSON encoding
+// of the email, see mailer.EncodeMailJSON.
+func NewSendTask(msg mailer.Mail, opts ...asynq.Option) (*asynq.Task, error) {
+	payload, err := mailer.EncodeMailJSON(msg)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeSend, payload, opts...), nil
+}
+
+// Enqueue enqueues a task sending the email, e.g. with asynq.ProcessIn to schedule it.
+func Enqueue(ctx context.Context, client *asynq.Client, msg mailer.Mail, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	task, err := NewSendTask(msg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return client.EnqueueContext(ctx, task)
+}
+
+// Handler returns the handler of the TypeSend tasks. Tasks whose email the mailer
+// refuses to send, e.g. expired or suppressed, are not retried.
+func Handler(m *mailer.Mailer) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		msg, err := mailer.DecodeMailJSON(task.Payload())
+		if err != nil {
+			return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+		}
+		if err := m.Send(msg); err != nil {
+			if mailer.IsPermanentError(err) {
+				return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// Register registers the Handler of the mailer for the TypeSend tasks.
+func Register(mux *asynq.ServeMux, m *mailer.Mailer) {
+	mux.Handle(TypeSend, Handler(m))
+}
@@ -0,0 +1,43 @@
+package mailasynq
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+
+	mailer "github.com/caesar-rocks/mail"
+	"github.com/hibiken/asynq"
+)
+
+func TestHandler(t *testing.T) {
+	path, err := exec.LookPath("true")
+	if err != nil {
+		t.Skip("true binary not available")
+	}
+	m := mailer.NewMailer(mailer.MailCfg{APIService: mailer.SENDMAIL, SendmailPath: path})
+	defer m.Close()
+
+	task, err := NewSendTask(mailer.Mail{From: mailer.Address{Email: "info@test.com"}, To: mailer.Addresses("a@test.com"), Subject: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Type() != TypeSend {
+		t.Errorf("Expected type %s, got %s", TypeSend, task.Type())
+	}
+	if err := Handler(m).ProcessTask(context.Background(), task); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	invalid := asynq.NewTask(TypeSend, []byte(`{"subject":"no recipients"}`))
+	if err := Handler(m).ProcessTask(context.Background(), invalid); !errors.Is(err, asynq.SkipRetry) {
+		t.Errorf("Expected SkipRetry, got %v", err)
+	}
+}
+
+func TestNewSendTask_Attachments(t *testing.T) {
+	_, err := NewSendTask(mailer.Mail{To: mailer.Addresses("a@test.com"), Attachments: []mailer.Attachment{{Path: "a.pdf"}}})
+	if !errors.Is(err, mailer.ErrInvalidMail) {
+		t.Errorf("Expected ErrInvalidMail, got %v", err)
+	}
+}
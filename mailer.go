@@ -1,5 +1,24 @@
 package mailer
 
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrAMPWithoutHtml is returned for an email with an AmpHtml but no Html, which
+// clients without AMP support would show as empty.
+var ErrAMPWithoutHtml = errors.New("an AMP email requires an html fallback")
+
+// ErrMessageExpired is returned when an email could not be sent before its ExpiresAt.
+var ErrMessageExpired = errors.New("message expired before it could be sent")
+
 type APIServiceType string
 
 const (
@@ -8,6 +27,42 @@ const (
 	MAILGUN    APIServiceType = "mailgun"
 	AMAZON_SES APIServiceType = "amazon-ses"
 	RESEND     APIServiceType = "resend"
+	POSTMARK   APIServiceType = "postmark"
+	SENDMAIL   APIServiceType = "sendmail"
+	LMTP       APIServiceType = "lmtp"
+	// DEV targets a local Mailpit or MailHog instance, without authentication
+	// nor TLS. Host and Port default to localhost:1025.
+	DEV APIServiceType = "dev"
+	// MX delivers directly to the MX hosts of the recipient domains, without a smarthost.
+	// Port defaults to 25.
+	MX APIServiceType = "mx"
+	// NULL builds and discards the emails, e.g. for load tests and benchmarks.
+	NULL APIServiceType = "null"
+	// BREVO sends with the transactional API of Brevo, formerly Sendinblue, which
+	// is hosted in the EU.
+	BREVO     APIServiceType = "brevo"
+	SPARKPOST APIServiceType = "sparkpost"
+	// MAILJET authenticates with the APIKey and the APISecret.
+	MAILJET APIServiceType = "mailjet"
+	SMTP2GO APIServiceType = "smtp2go"
+	// JSON_API sends with the HTTP JSON API described by MailCfg.JSONAPI, for the
+	// providers without a client of their own.
+	JSON_API      APIServiceType = "json-api"
+	ELASTIC_EMAIL APIServiceType = "elastic-email"
+	// SCALEWAY sends with Scaleway Transactional Email, the APIKey being the secret
+	// key of an API key of the ScalewayProjectID.
+	SCALEWAY APIServiceType = "scaleway"
+	// OVH sends through the SMTP servers of OVHcloud with the HostUser and the
+	// HostPassword of the mailbox. Host and Port default to ssl0.ovh.net:465.
+	OVH APIServiceType = "ovh"
+	// FILE writes the emails to .eml files in the FileDir instead of sending them.
+	FILE APIServiceType = "file"
+	// MANDRILL sends with Mailchimp Transactional, formerly Mandrill.
+	MANDRILL APIServiceType = "mandrill"
+	// LOOPS sends the transactional emails designed in Loops, see LoopsOptions.
+	LOOPS APIServiceType = "loops"
+	// PLUNK authenticates with the secret key of the Plunk project as APIKey.
+	PLUNK APIServiceType = "plunk"
 )
 
 type Attachment struct {
@@ -15,27 +70,91 @@ type Attachment struct {
 	Name string
 	// Path is the path to the attachment.
 	Path string
+	// Content is the content of the attachment, used instead of reading Path when set.
+	Content []byte
+	// ContentType overrides the content type detected from the extension of the name.
+	ContentType string
 }
 
+// Mail is an email. Its address fields hold Address values, the SetFrom, SetTo,
+// SetCc, SetBcc and SetReplyTo methods parsing them from strings, e.g.
+// `"Doe, Jane" <jane@example.com>, bob@example.com`.
 type Mail struct {
-	// To is the email address of the recipient.
-	To string
-	// From is the email address of the sender. defaults to the host user.
-	From string
+	// To are the recipients of the email.
+	To []Address
+	// From is the sender of the email. defaults to the host user.
+	From Address
 	// Html is the html content of the email.
 	Html string
 	// Text is the text content of the email.
 	Text string
+	// AmpHtml is the AMP for Email content, sent as the text/x-amp-html part
+	// before the Html fallback, which is then required. Postmark and Resend do
+	// not support AMP and ignore it.
+	AmpHtml string
 	// Subject is the subject of the email.
 	Subject string
-	// Cc is the email address of the cc recipient.
-	Cc string
-	// Bcc is the email address of the bcc recipient.
-	Bcc string
-	// ReplyTo is the email address to reply to.
-	ReplyTo string
+	// Preheader is the preview text shown by inbox clients after the subject. It
+	// is injected as a hidden block at the start of the html body.
+	Preheader string
+	// Cc are the cc recipients of the email.
+	Cc []Address
+	// Bcc are the bcc recipients of the email.
+	Bcc []Address
+	// ReplyTo is the address to reply to.
+	ReplyTo Address
+	// EnvelopeTo restricts the delivery to these recipients, e.g. the ones a
+	// *PartialDeliveryError failed for. The SMTP and MX transports send the
+	// message with its headers unchanged to them, the API providers drop the
+	// other recipients from the To, Cc and Bcc lists.
+	EnvelopeTo []string
+	// Headers are extra headers of the email.
+	Headers map[string]string
+	// Class sets the headers preventing autoresponders from replying to the email.
+	Class MessageClass
+	// ReadReceiptTo requests a read receipt (RFC 8098) sent to the address.
+	ReadReceiptTo string
+	// DeliveryNotification requests delivery status notifications (RFC 3461).
+	DeliveryNotification *DeliveryNotification
 	// Attachments is an array of attachments.
 	Attachments []Attachment
+	// Documents are rendered by the DocumentRenderer and attached to the email.
+	Documents []Document
+	// Category is the category of the email e.g. "marketing" or "transactional".
+	// It is passed to the PreferenceChecker before the email is sent.
+	Category string
+	// Tags label the email, e.g. to apply quotas. They are sent as Mailgun tags
+	// and SendGrid categories.
+	Tags []string
+	// TenantID selects the tenant the email is sent on behalf of, see RegisterTenant.
+	TenantID string
+	// ExpiresAt drops the email with a dead lettered event instead of sending it
+	// once past, e.g. for a one-time password valid 10 minutes.
+	ExpiresAt time.Time
+	// SES holds the Amazon SES specific options.
+	SES *SESOptions
+	// SendGrid holds the SendGrid specific options.
+	SendGrid *SendGridOptions
+	// Mailgun holds the Mailgun specific options.
+	Mailgun *MailgunOptions
+	// Postmark holds the Postmark specific options.
+	Postmark *PostmarkOptions
+	// Brevo holds the Brevo specific options.
+	Brevo *BrevoOptions
+	// SparkPost holds the SparkPost specific options.
+	SparkPost *SparkPostOptions
+	// Mailjet holds the Mailjet specific options.
+	Mailjet *MailjetOptions
+	// SMTP2GO holds the SMTP2GO specific options.
+	SMTP2GO *SMTP2GOOptions
+	// ElasticEmail holds the Elastic Email specific options.
+	ElasticEmail *ElasticEmailOptions
+	// Mandrill holds the Mandrill specific options.
+	Mandrill *MandrillOptions
+	// Loops holds the Loops specific options, required by the LOOPS service.
+	Loops *LoopsOptions
+	// Plunk holds the Plunk specific options.
+	Plunk *PlunkOptions
 }
 
 type MailerClient interface {
@@ -59,7 +178,8 @@ type MailCfg struct {
 	Port string
 	// UseTLS is a boolean that determines whether to use TLS.
 	UseTLS bool
-	// UseSSL is a boolean that determines whether to use SSL.
+	// UseSSL connects to the SMTP server over implicit TLS, e.g. on port 465,
+	// instead of upgrading the connection with STARTTLS.
 	UseSSL bool
 	// Timeout is the timeout to connect to SMTP Server and to send the email and wait respond
 	Timeout int
@@ -71,8 +191,190 @@ type MailCfg struct {
 	APISecret string
 	// Region is the region to use for sending emails.
 	Region string
+	// SESRegions are the Amazon SES regions to send from, failing over to the next
+	// one when a region throttles or errors. They replace Region when set, and the
+	// configuration set and templates must exist in each of them.
+	SESRegions []string
+	// SESRegionSelection decides in which order the SESRegions are tried, by
+	// priority by default.
+	SESRegionSelection SESRegionSelection
+	// SESConfigurationSetName is the Amazon SES configuration set used for every email.
+	SESConfigurationSetName string
+	// MailgunDomain is the Mailgun sending domain.
+	MailgunDomain string
+	// BrevoTemplateSender is the sender of the templates created in Brevo, which
+	// requires one, e.g. "Acme <info@acme.com>".
+	BrevoTemplateSender string
+	// MailgunRegion selects the US (default) or EU Mailgun endpoint.
+	MailgunRegion MailgunRegion
+	// SparkPostRegion selects the US (default) or EU SparkPost endpoint.
+	SparkPostRegion SparkPostRegion
+	// MandrillSubaccount is the Mandrill subaccount the emails are sent from, unless
+	// an email sets its own.
+	MandrillSubaccount string
+	// ScalewayProjectID is the Scaleway project of the sending domains.
+	ScalewayProjectID string
+	// ScalewayRegion selects the Paris (default) or Amsterdam Scaleway endpoint.
+	ScalewayRegion ScalewayRegion
+	// FileDir is the directory of the FILE service, in the temporary directory by default.
+	FileDir string
+	// JSONAPI describes the API of the JSON_API service.
+	JSONAPI *JSONAPIConfig
+	// SendmailPath is the path of the sendmail compatible binary e.g. /usr/bin/msmtp.
+	// Defaults to /usr/sbin/sendmail.
+	SendmailPath string
+	// SendmailArgs are extra arguments passed to the sendmail binary.
+	SendmailArgs []string
+	// MXHelloName is the EHLO name used by MX delivery. Defaults to the hostname.
+	MXHelloName string
+	// MXRetries is the number of times MX delivery retries a domain after a transient failure.
+	MXRetries int
+	// MXTLSPolicy enforces the DANE and MTA-STS policies of the recipient domains
+	// with MX delivery. TLS is opportunistic when nil.
+	MXTLSPolicy *DeliveryTLSPolicy
+	// Environment selects the profile of the mailer, read from MAILER_ENV when
+	// empty. A non-production environment without a profile does not deliver
+	// the emails, see Profile.
+	Environment Environment
+	// Profiles override the configuration by environment.
+	Profiles map[Environment]Profile
+	// RedirectTo sends every email to the address instead of its recipients, e.g.
+	// the inbox of the QA team on staging.
+	RedirectTo string
+	// DryRun builds and logs the emails without sending them.
+	DryRun bool
+	// SpoolDir is the directory the emails are written to when the provider is
+	// down, i.e. the last health check failed or the provider could not be
+	// reached, to be sent once it recovers. Disabled when empty.
+	SpoolDir string
+	// SpoolKeys seals the emails of the SpoolDir with envelope encryption.
+	// Defaults to the keys of the ArchiveStore when it is an
+	// EncryptedArchiveStore, for the spool not to hold in the clear what the
+	// archive encrypts.
+	SpoolKeys KeyProvider
+	// SpoolReplayInterval is how often the spool is replayed. Defaults to 30 seconds.
+	SpoolReplayInterval time.Duration
+	// SendWorkers is the number of emails of the queue sent in parallel. Defaults
+	// to 1, which sends them in order.
+	SendWorkers int
+	// MaxConcurrentSends bounds the number of emails being sent to the providers
+	// at once, including SendRaw and the spool replays. Unlimited when zero.
+	MaxConcurrentSends int
+	// MaxConcurrentSendsPerProvider bounds the number of emails being sent at
+	// once per provider, shared by the tenants using the same provider, e.g. for
+	// an SMTP relay dropping the connections beyond a handful.
+	MaxConcurrentSendsPerProvider map[APIServiceType]int
+	// MaxRecipients is the number of recipients sent per API call or SMTP transaction,
+	// emails with more recipients are sent in chunks. Defaults to the limit of the provider.
+	MaxRecipients int
+	// XMailer is the X-Mailer header of the emails, e.g. for fleet identification.
+	// Defaults to "caesar-rocks/mail/<Version>".
+	XMailer string
+	// NoXMailer sends the emails without the X-Mailer header.
+	NoXMailer bool
+	// UserAgent is the User-Agent of the API requests. Defaults to
+	// "caesar-rocks/mail/<Version>". Amazon SES appends it to the user agent of
+	// the AWS SDK instead.
+	UserAgent string
+	// NoUserAgent sends the API requests without a User-Agent. Amazon SES still
+	// sends the user agent of the AWS SDK.
+	NoUserAgent bool
+	// ResponseCapture decides what is kept of the provider responses exposed by
+	// SendWithResult and Status, and of the body of an *APIError returned by a
+	// send. Defaults to the status code and request ids.
+	ResponseCapture ResponseCapture
+	// RateLimitBackoff is how long a provider is paused when it rate limits a send
+	// without a Retry-After or rate limit reset header. Defaults to 1 minute.
+	RateLimitBackoff time.Duration
+	// HTTPTransport sends the requests of the API services, e.g. with a client
+	// certificate for mTLS or through an egress proxy. Defaults to http.DefaultTransport.
+	HTTPTransport http.RoundTripper
+	// RequestInterceptor is called with every request of the API services before
+	// it is sent, e.g. to sign it.
+	RequestInterceptor RequestInterceptor
 	// KeepAlive to keep alive connection
 	KeepAlive bool
+	// Dial configures the connections to the SMTP, LMTP and MX servers, e.g. to
+	// send from a given IP or prefer IPv4.
+	Dial DialOptions
+	// PreferenceChecker is consulted before sending to drop recipients that opted out.
+	PreferenceChecker PreferenceChecker
+	// HealthCheckInterval enables background health probes of the provider when positive.
+	HealthCheckInterval time.Duration
+	// EventPollInterval enables polling the events of the provider when positive,
+	// e.g. when its webhooks cannot be exposed. The events are passed to the EventHandler.
+	EventPollInterval time.Duration
+	// SendQuotaInterval enables polling the sending quota of the provider when
+	// positive, e.g. Amazon SES.
+	SendQuotaInterval time.Duration
+	// SendQuotaWarning is the share of the sending quota used, between 0 and 1, at
+	// which an EventQuotaWarning is raised. Defaults to 0.9.
+	SendQuotaWarning float64
+	// VerifyOnStart makes NewMailer check that the provider is reachable and the
+	// credentials are valid, and panic when they are not.
+	VerifyOnStart bool
+	// WarnOnVerifyFailure logs the VerifyOnStart failures instead of panicking.
+	WarnOnVerifyFailure bool
+	// AuditStore records every send attempt when set.
+	AuditStore AuditStore
+	// ArchiveAddress receives a copy of every email sent, e.g. a compliance mailbox,
+	// with its recipients in the X-Envelope-To header.
+	ArchiveAddress string
+	// ArchiveWriter receives the raw message of every email sent.
+	ArchiveWriter io.Writer
+	// ArchiveStore stores the rendered message of every email sent, keyed by message id.
+	ArchiveStore ArchiveStore
+	// ArchiveRetention decides how long each message is kept in the ArchiveStore.
+	ArchiveRetention RetentionPolicy
+	// DocumentRenderer renders the Documents of the emails.
+	DocumentRenderer DocumentRenderer
+	// ViewInBrowserURL is the URL at which ViewInBrowserHandler is mounted, e.g.
+	// https://example.com/emails. It requires an ArchiveStore.
+	ViewInBrowserURL string
+	// ViewInBrowserSecret is the key signing the view in browser URLs.
+	ViewInBrowserSecret string
+	// Middlewares wrap the sending of every email, the first one being the outermost.
+	Middlewares []Middleware
+	// Tenants are registered when the mailer is created.
+	Tenants []Tenant
+	// Quotas limit the number of emails sent per day or month.
+	Quotas []Quota
+	// QuotaStore holds the quota counters. Defaults to an in-memory store.
+	QuotaStore QuotaStore
+	// Pricing estimates the cost of the emails sent per provider, reported by Stats.
+	Pricing map[APIServiceType]Price
+	// SeedList are the seed addresses receiving the emails of SendSeedTest.
+	SeedList []string
+	// SeedSender is the From of the emails of SendSeedTest, e.g. the sender of
+	// the campaigns whose inbox placement is measured.
+	SeedSender string
+	// Retention prunes the audit records, archives and dead letters older than
+	// their retention in the background, the stores implementing Pruner.
+	Retention Retention
+	// DedupWindow drops the emails identical to one sent within the window when
+	// positive, e.g. to stop notification storms caused by upstream retry loops.
+	DedupWindow time.Duration
+	// DeadLetterStore keeps the emails the mailer gave up on, e.g. expired or quarantined.
+	DeadLetterStore DeadLetterStore
+	// MaxPanics is the number of times sending the same email may panic before it
+	// is quarantined. Defaults to 3.
+	MaxPanics int
+	// Clock tells the time of the mailer, it defaults to the system clock. Tests
+	// can inject a fake clock to control expiry, quotas and retry timing.
+	Clock Clock
+	// Rand is the source of the ids of the emails, it defaults to crypto/rand.
+	// Tests can inject a seeded source for the ids to be deterministic.
+	Rand io.Reader
+	// EventHandler receives the events raised by the mailer itself, e.g. expired emails.
+	EventHandler func(Event)
+	// Webhooks receive the events passed to the EventHandler, signed and retried.
+	Webhooks []WebhookEndpoint
+	// Alerts posts to Slack, Teams or a webhook when the error rate, the bounce
+	// rate or the queue depth crosses its threshold.
+	Alerts AlertConfig
+	// WebhookMaxAttempts is the number of attempts to deliver an event to a
+	// webhook before dropping it. Defaults to 5.
+	WebhookMaxAttempts int
 	// MailerClient is the mailer client to use for sending emails.
 	mailerClient MailerClient
 }
@@ -85,15 +387,67 @@ type Mailer struct {
 	password     string
 	apiService   APIServiceType
 	apiKey       string
-	emailToSend  chan Mail
-	mailErr      chan error
+	emailToSend  chan *queuedEmail
 	keepAlive    bool
 	timeout      int
 	mailerClient MailerClient
+
+	redirectTo          string
+	spool               *spool
+	sendLimiter         *sendLimiter
+	maxRecipients       int
+	xMailer             string
+	responseCapture     ResponseCapture
+	rateLimits          rateLimits
+	rateLimitBackoff    time.Duration
+	preferenceChecker   PreferenceChecker
+	auditStore          AuditStore
+	archiveAddress      string
+	archiveWriter       io.Writer
+	archiveStore        ArchiveStore
+	archiveRetention    RetentionPolicy
+	retention           Retention
+	documentRenderer    DocumentRenderer
+	viewInBrowserURL    string
+	viewInBrowserSecret string
+	middlewares         []Middleware
+	eventHandler        func(Event)
+	webhooks            []*webhookForwarder
+	deadLetterStore     DeadLetterStore
+	maxPanics           int
+	poison              poisonTracker
+	clock               Clock
+	rand                io.Reader
+	archiveMu           sync.Mutex
+	health              healthStatus
+	sendQuota           sendQuotaStatus
+	sendQuotaWarning    float64
+	stats               statsRecorder
+	statuses            statusTracker
+	dedup               *dedupCache
+	tenantsMu           sync.RWMutex
+	tenants             map[string]*tenant
+	quotas              []Quota
+	quotaStore          QuotaStore
+	pricing             map[APIServiceType]Price
+	seedList            []string
+	seedSender          string
+	closeMu             sync.RWMutex
+	closed              bool
+	pendingMu           sync.Mutex
+	pending             map[string][]string
+	pauseMu             sync.Mutex
+	resumed             chan struct{}
+	done                chan struct{}
 }
 
 // NewMailer creates a new mailer instance.
 func NewMailer(cfg MailCfg) *Mailer {
+	cfg = applyProfile(cfg, os.Getenv)
+	if cfg.DryRun {
+		cfg.mailerClient = dryRunMailer{}
+	}
+
 	mailer := &Mailer{
 		host:         cfg.Host,
 		port:         cfg.Port,
@@ -103,39 +457,337 @@ func NewMailer(cfg MailCfg) *Mailer {
 		apiKey:       cfg.APIKey,
 		keepAlive:    cfg.KeepAlive,
 		timeout:      cfg.Timeout,
-		emailToSend:  make(chan Mail, 200),
-		mailErr:      make(chan error),
+		emailToSend:  make(chan *queuedEmail, 200),
 		mailerClient: getMailerClient(cfg),
+
+		redirectTo:          cfg.RedirectTo,
+		maxRecipients:       cfg.MaxRecipients,
+		xMailer:             xMailer(cfg),
+		responseCapture:     cfg.ResponseCapture,
+		rateLimitBackoff:    cfg.RateLimitBackoff,
+		sendQuotaWarning:    cfg.SendQuotaWarning,
+		preferenceChecker:   cfg.PreferenceChecker,
+		auditStore:          cfg.AuditStore,
+		archiveAddress:      cfg.ArchiveAddress,
+		archiveWriter:       cfg.ArchiveWriter,
+		archiveStore:        cfg.ArchiveStore,
+		archiveRetention:    cfg.ArchiveRetention,
+		retention:           cfg.Retention,
+		documentRenderer:    cfg.DocumentRenderer,
+		viewInBrowserURL:    cfg.ViewInBrowserURL,
+		viewInBrowserSecret: cfg.ViewInBrowserSecret,
+		middlewares:         cfg.Middlewares,
+		eventHandler:        cfg.EventHandler,
+		deadLetterStore:     cfg.DeadLetterStore,
+		maxPanics:           cfg.MaxPanics,
+		clock:               cfg.Clock,
+		rand:                cfg.Rand,
+		pending:             make(map[string][]string),
+		tenants:             make(map[string]*tenant),
+		quotas:              cfg.Quotas,
+		quotaStore:          cfg.QuotaStore,
+		pricing:             cfg.Pricing,
+		seedList:            cfg.SeedList,
+		seedSender:          cfg.SeedSender,
+		done:                make(chan struct{}),
+	}
+
+	if mailer.clock == nil {
+		mailer.clock = systemClock{}
+	}
+	if mailer.rand == nil {
+		mailer.rand = rand.Reader
+	}
+
+	if mailer.maxPanics <= 0 {
+		mailer.maxPanics = defaultMaxPanics
+	}
+	if mailer.sendQuotaWarning <= 0 {
+		mailer.sendQuotaWarning = defaultSendQuotaWarning
+	}
+	if mailer.rateLimitBackoff <= 0 {
+		mailer.rateLimitBackoff = defaultRateLimitBackoff
+	}
+
+	if mailer.quotaStore == nil {
+		store := NewMemoryQuotaStore()
+		store.clock = mailer.clock
+		mailer.quotaStore = store
+	}
+
+	for _, t := range cfg.Tenants {
+		if err := mailer.RegisterTenant(t); err != nil {
+			panic(err)
+		}
+	}
+
+	if cfg.DedupWindow > 0 {
+		mailer.dedup = &dedupCache{window: cfg.DedupWindow}
+	}
+
+	// The verification panics, before any goroutine of the mailer is started.
+	if cfg.VerifyOnStart {
+		mailer.verifyOnStart(cfg)
+	}
+
+	mailer.webhooks = newWebhookForwarders(cfg, mailer.clock)
+	for _, webhook := range mailer.webhooks {
+		go webhook.run(mailer.done)
+	}
+	if len(cfg.Alerts.Sinks) > 0 {
+		go mailer.watchAlerts(newAlerter(cfg.Alerts))
 	}
 
-	go mailer.listenForEmailsToBeSent()
+	mailer.sendLimiter = newSendLimiter(cfg.MaxConcurrentSends, cfg.MaxConcurrentSendsPerProvider)
+	for i := 0; i < max(cfg.SendWorkers, 1); i++ {
+		go mailer.listenForEmailsToBeSent()
+	}
+
+	if cfg.HealthCheckInterval > 0 {
+		go mailer.probeHealth(cfg.HealthCheckInterval)
+	}
+	if cfg.EventPollInterval > 0 {
+		go mailer.pollEvents(cfg.EventPollInterval)
+	}
+	if cfg.SendQuotaInterval > 0 {
+		go mailer.pollSendQuota(cfg.SendQuotaInterval)
+	}
+	if cfg.SpoolDir != "" {
+		keys := cfg.SpoolKeys
+		if encrypted, ok := cfg.ArchiveStore.(*EncryptedArchiveStore); ok && keys == nil {
+			keys = encrypted.keys
+		}
+		mailer.spool = &spool{dir: cfg.SpoolDir, keys: keys}
+		interval := cfg.SpoolReplayInterval
+		if interval <= 0 {
+			interval = defaultSpoolReplayInterval
+		}
+		go mailer.replaySpool(interval)
+	}
+	if cfg.Retention.enabled() {
+		interval := cfg.Retention.Interval
+		if interval <= 0 {
+			interval = defaultRetentionInterval
+		}
+		go mailer.pruneStores(interval)
+	}
 
 	return mailer
 }
 
-// Send sends an email message using the chosen API service.
+// Send sends an email message using the chosen API service. It returns
+// ErrMailerClosed once the mailer is closed.
 func (m *Mailer) Send(msg Mail) error {
-	m.emailToSend <- msg
-	return <-m.mailErr
+	email, err := m.enqueue(msg, make(chan error, 1))
+	if err != nil {
+		return err
+	}
+	return <-email.result
 }
 
-// Close closes the emailToSend, result channels and the mailerClient.
+// Close closes the emailToSend channel and the mailerClient.
 func (m *Mailer) Close() {
+	close(m.done)
+	m.closeMu.Lock()
+	m.closed = true
 	close(m.emailToSend)
-	close(m.mailErr)
+	m.closeMu.Unlock()
 	m.mailerClient.Close()
+
+	m.tenantsMu.Lock()
+	defer m.tenantsMu.Unlock()
+	for _, t := range m.tenants {
+		t.client.Close()
+	}
 }
 
-// send sends the email message using the chosen API service.
-func (m *Mailer) send(msg Mail) error {
-	return m.mailerClient.Send(msg)
+// send sends the email message using the chosen API service, counting the
+// attempt in attempts.
+func (m *Mailer) send(id string, msg Mail, attempts *int) error {
+	var hash string
+	if m.dedup != nil {
+		hash = ContentHash(msg)
+		if m.dedup.seen(hash, m.clock.Now()) {
+			m.deadLetter(id, msg, "duplicate")
+			return ErrDuplicateMessage
+		}
+	}
+
+	msg, err := sanitizeMail(msg)
+	if err != nil {
+		return err
+	}
+
+	client, provider, quotas := m.mailerClient, m.apiService, m.quotas
+	if msg.TenantID != "" {
+		t, err := m.tenant(msg.TenantID)
+		if err != nil {
+			return err
+		}
+		if msg, err = t.prepare(msg); err != nil {
+			return err
+		}
+		client, provider = t.client, t.Config.APIService
+		quotas = append([]Quota{t.quota()}, quotas...)
+	}
+
+	if msg, err = applyPreferences(m.preferenceChecker, msg); err != nil {
+		return err
+	}
+	if msg.AmpHtml != "" && msg.Html == "" {
+		return ErrAMPWithoutHtml
+	}
+	msg = applyRedirect(msg, m.redirectTo)
+	msg = applyPreheader(msg)
+	msg = applyMessageClass(msg)
+	msg = applyXMailer(msg, m.xMailer)
+	if msg.ReadReceiptTo != "" {
+		msg = withHeader(msg, "Disposition-Notification-To", msg.ReadReceiptTo)
+	}
+	msg = m.applyViewInBrowser(id, msg)
+	if msg, err = m.renderDocuments(msg); err != nil {
+		return err
+	}
+
+	return m.deliver(delivery{id: id, hash: hash, client: client, provider: provider, quotas: quotas, attempts: attempts}, msg)
+}
+
+// delivery is the send of a prepared email to its provider.
+type delivery struct {
+	id       string
+	hash     string
+	client   MailerClient
+	provider APIServiceType
+	quotas   []Quota
+	// replay is set for the emails of the spool, the provider failures being
+	// returned instead of spooling the email again.
+	replay bool
+	// attempts is the number of the previous sends of the email, incremented
+	// by the send to the provider.
+	attempts *int
+}
+
+// deliver sends the email through the middlewares to the provider. The email
+// is spooled as given to the middlewares, for the replay to run them again.
+func (m *Mailer) deliver(d delivery, msg Mail) error {
+	id, client, provider := d.id, d.client, d.provider
+	input := msg
+	send := func(msg Mail) error {
+		if !msg.ExpiresAt.IsZero() && m.clock.Now().After(msg.ExpiresAt) {
+			m.deadLetter(id, msg, "expired")
+			return ErrMessageExpired
+		}
+		if err := CapabilitiesOf(client).Check(provider, msg); err != nil {
+			return err
+		}
+
+		if until, ok := m.rateLimits.pausedUntil(client, m.clock.Now()); ok {
+			return &rateLimitDeferral{provider: provider, until: until}
+		}
+		if m.spool != nil && !d.replay && client == m.mailerClient && !m.Healthy() {
+			_, cause := m.LastHealth()
+			return m.spoolMail(id, d.hash, *d.attempts, input, cause)
+		}
+
+		reservation, err := m.reserveQuotas(msg, d.quotas, m.clock.Now())
+		if err != nil {
+			return err
+		}
+
+		release, err := m.sendLimiter.acquire(context.Background(), provider)
+		if err != nil {
+			reservation.release()
+			return err
+		}
+		m.stats.start()
+		var responses []ProviderResponse
+		err = func() (err error) {
+			defer recoverSend(id, &err)
+			responses, err = sendChunks(client, msg, m.recipientLimit(client))
+			return err
+		}()
+		release()
+		m.recordResponses(id, responses, err)
+		m.stats.done(provider, err, m.clock.Now())
+		*d.attempts++
+		m.audit(id, *d.attempts, provider, msg, err)
+		if err != nil {
+			reservation.release()
+			if until, ok := m.rateLimited(client, err); ok && !delivered(responses) {
+				return &rateLimitDeferral{provider: provider, until: until}
+			}
+			if m.spool != nil && isProviderDown(err) && !delivered(responses) {
+				if d.replay {
+					return fmt.Errorf("%w: %w", errSpoolReplayLater, err)
+				}
+				var partial *PartialDeliveryError
+				if errors.As(err, &partial) {
+					input.EnvelopeTo = partial.Failed
+				}
+				return m.spoolMail(id, d.hash, *d.attempts, input, err)
+			}
+			return err
+		}
+
+		m.stats.cost(provider, msg, m.pricing[provider].estimate(msg))
+		if m.dedup != nil {
+			m.dedup.add(d.hash, m.clock.Now(), mailAddresses(msg)...)
+		}
+		m.archive(d, msg)
+		return nil
+	}
+
+	return chainMiddlewares(send, m.middlewares)(msg)
 }
 
 // ListenForEmailsToBeSent listens for email messages and sends them using the chosen API service.
 // It is a blocking function that should be run in a goroutine.
 func (m *Mailer) listenForEmailsToBeSent() {
-	for msg := range m.emailToSend {
-		err := m.send(msg)
-		m.mailErr <- err
+	for email := range m.emailToSend {
+		m.waitIfPaused(context.Background())
+
+		if !m.dequeue(email.id) {
+			if email.result != nil {
+				email.result <- ErrMessageCanceled
+			}
+			continue
+		}
+
+		err := m.sendIsolated(email.id, email.msg, &email.attempts)
+
+		if until, ok := deferredUntil(err); ok {
+			if email.result == nil {
+				m.requeue(email, until)
+				continue
+			}
+			err = &DeferredError{Until: until, Err: err}
+		}
+
+		if errors.Is(err, ErrMessageSpooled) {
+			m.statuses.set(email.id, MessageSpooled, err, m.clock.Now())
+			if email.result != nil {
+				email.result <- err
+			}
+			continue
+		}
+
+		if err != nil {
+			m.statuses.set(email.id, MessageFailed, err, m.clock.Now())
+		} else {
+			m.statuses.set(email.id, MessageSent, nil, m.clock.Now())
+		}
+
+		if email.result != nil {
+			email.result <- err
+		} else if err != nil {
+			m.emit(Event{
+				Type:      EventFailed,
+				Provider:  m.apiService,
+				MessageID: email.id,
+				Recipient: joinAddresses(email.msg.To),
+				Timestamp: m.clock.Now(),
+				Reason:    err.Error(),
+			})
+		}
 	}
 }
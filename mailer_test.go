@@ -1,7 +1,9 @@
 package mailer
 
 import (
+	"errors"
 	"testing"
+	"time"
 )
 
 const (
@@ -103,7 +105,7 @@ func TestNewMail_SendMail(t *testing.T) {
 			})
 
 			err := mailer.Send(Mail{
-				To:      "test@example.com",
+				To:      Addresses("test@example.com"),
 				Subject: "Test",
 				Html:    "<h1>Test</h1>",
 				Text:    "Test",
@@ -117,6 +119,41 @@ func TestNewMail_SendMail(t *testing.T) {
 	}
 }
 
+func TestMailer_SendExpired(t *testing.T) {
+	sent := false
+	var events []Event
+	mailer := NewMailer(MailCfg{
+		APIService: RESEND,
+		APIKey:     MailAPIKey,
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			sent = true
+			return nil
+		}},
+		EventHandler: func(event Event) {
+			events = append(events, event)
+		},
+	})
+	defer mailer.Close()
+
+	err := mailer.Send(Mail{To: Addresses("test@example.com"), Subject: "Your code", ExpiresAt: time.Now().Add(-time.Minute)})
+	if !errors.Is(err, ErrMessageExpired) {
+		t.Fatalf("Expected ErrMessageExpired, got %v", err)
+	}
+	if sent {
+		t.Errorf("Expected the expired email not to be sent")
+	}
+	if len(events) != 1 || events[0].Type != EventDeadLettered || events[0].Reason != "expired" || events[0].Recipient != "test@example.com" {
+		t.Errorf("Expected a dead lettered event, got %+v", events)
+	}
+
+	if err := mailer.Send(Mail{To: Addresses("test@example.com"), ExpiresAt: time.Now().Add(time.Minute)}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !sent {
+		t.Errorf("Expected the email to be sent before it expires")
+	}
+}
+
 type mockMailerClient struct {
 }
 
@@ -127,3 +164,12 @@ func (m *mockMailerClient) Send(msg Mail) error {
 func (m *mockMailerClient) Close() {
 
 }
+
+type mockSendFuncClient struct {
+	mockMailerClient
+	sendFunc func(msg Mail) error
+}
+
+func (m *mockSendFuncClient) Send(msg Mail) error {
+	return m.sendFunc(msg)
+}
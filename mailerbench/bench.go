@@ -0,0 +1,153 @@
+// Package mailerbench generates synthetic load against a mailer, e.g. using the
+// NULL or DEV API service, and reports its throughput, latency and allocations
+// for capacity planning and regression detection.
+package mailerbench
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mailer "github.com/caesar-rocks/mail"
+)
+
+// Config is the load generated by Run.
+type Config struct {
+	// Messages is the number of emails sent. Defaults to 1000.
+	Messages int
+	// Concurrency is the number of goroutines sending the emails. Defaults to 1.
+	Concurrency int
+	// Message returns the i-th email sent. Defaults to SyntheticMail.
+	Message func(i int) mailer.Mail
+}
+
+// Report is the result of a load run.
+type Report struct {
+	Sent   int
+	Failed int
+	// Duration is the wall time of the run.
+	Duration time.Duration
+	// Throughput is the number of emails sent per second.
+	Throughput float64
+	// P50, P99 and Max are the latencies of Send.
+	P50 time.Duration
+	P99 time.Duration
+	Max time.Duration
+	// AllocsPerSend and BytesPerSend are the heap allocations per email of the
+	// whole process during the run.
+	AllocsPerSend float64
+	BytesPerSend  float64
+	// Errors counts the failures by error message.
+	Errors map[string]int
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "sent:       %d (%d failed) in %s\n", r.Sent, r.Failed, r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "throughput: %.1f emails/s\n", r.Throughput)
+	fmt.Fprintf(&b, "latency:    p50 %s, p99 %s, max %s\n", r.P50, r.P99, r.Max)
+	fmt.Fprintf(&b, "allocs:     %.0f allocs/email, %.0f B/email\n", r.AllocsPerSend, r.BytesPerSend)
+	for reason, count := range r.Errors {
+		fmt.Fprintf(&b, "error:      %d x %s\n", count, reason)
+	}
+	return b.String()
+}
+
+// SyntheticMail returns an email with html and text bodies of a typical size.
+func SyntheticMail(i int) mailer.Mail {
+	html := "<html><body><h1>Order confirmation</h1>" + strings.Repeat("<p>Thank you for your order, it will ship soon.</p>", 40) + "</body></html>"
+	return mailer.Mail{
+		From:    mailer.Address{Email: "bench@example.com"},
+		To:      mailer.Addresses(fmt.Sprintf("user%d@example.com", i)),
+		Subject: fmt.Sprintf("Order #%d", i),
+		Html:    html,
+		Text:    mailer.HTMLToText(html),
+	}
+}
+
+// Run sends the emails of the config with the mailer and reports the results. It
+// stops early, returning the partial report with the error, when ctx is done.
+func Run(ctx context.Context, m *mailer.Mailer, cfg Config) (Report, error) {
+	if cfg.Messages <= 0 {
+		cfg.Messages = 1000
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Message == nil {
+		cfg.Message = SyntheticMail
+	}
+
+	messages := make([]mailer.Mail, cfg.Messages)
+	for i := range messages {
+		messages[i] = cfg.Message(i)
+	}
+
+	var mu sync.Mutex
+	latencies := make([]time.Duration, 0, cfg.Messages)
+	report := Report{Errors: make(map[string]int)}
+
+	next := make(chan mailer.Mail)
+	var wg sync.WaitGroup
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	for w := 0; w < cfg.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range next {
+				sent := time.Now()
+				err := m.Send(msg)
+				latency := time.Since(sent)
+
+				mu.Lock()
+				latencies = append(latencies, latency)
+				if err != nil {
+					report.Failed++
+					report.Errors[err.Error()]++
+				} else {
+					report.Sent++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for _, msg := range messages {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		select {
+		case next <- msg:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	report.Duration = time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	if count := len(latencies); count > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		report.P50 = latencies[count*50/100]
+		report.P99 = latencies[min(count*99/100, count-1)]
+		report.Max = latencies[count-1]
+		report.Throughput = float64(report.Sent) / report.Duration.Seconds()
+		report.AllocsPerSend = float64(after.Mallocs-before.Mallocs) / float64(count)
+		report.BytesPerSend = float64(after.TotalAlloc-before.TotalAlloc) / float64(count)
+	}
+	return report, err
+}
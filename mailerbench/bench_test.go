@@ -0,0 +1,53 @@
+package mailerbench
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mailer "github.com/caesar-rocks/mail"
+)
+
+func TestRun(t *testing.T) {
+	m := mailer.NewMailer(mailer.MailCfg{APIService: mailer.NULL})
+	defer m.Close()
+
+	report, err := Run(context.Background(), m, Config{
+		Messages:    50,
+		Concurrency: 4,
+		Message: func(i int) mailer.Mail {
+			msg := SyntheticMail(i)
+			if i%10 == 0 {
+				msg.AmpHtml, msg.Html = "<html amp4email></html>", ""
+			}
+			return msg
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.Sent != 45 || report.Failed != 5 {
+		t.Errorf("Expected 45 sent and 5 failed, got %+v", report)
+	}
+	if report.Errors[mailer.ErrAMPWithoutHtml.Error()] != 5 {
+		t.Errorf("Expected the failures by reason, got %v", report.Errors)
+	}
+	if report.P50 <= 0 || report.P99 < report.P50 || report.Max < report.P99 || report.Throughput <= 0 {
+		t.Errorf("Unexpected latencies %+v", report)
+	}
+	if !strings.Contains(report.String(), "throughput:") {
+		t.Errorf("Unexpected report %s", report)
+	}
+}
+
+func TestRun_Canceled(t *testing.T) {
+	m := mailer.NewMailer(mailer.MailCfg{APIService: mailer.NULL})
+	defer m.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, m, Config{Messages: 10}); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+// Package mailertest is a conformance suite for the mailer clients, so that every
+// provider, including the ones contributed by the community, sends the same
+// matrix of emails: unicode, attachments, many recipients, empty bodies...
+//
+//	func TestAcmeConformance(t *testing.T) {
+//		mailertest.RunProviderTests(t, mailertest.Harness{
+//			New: func(t *testing.T) mailer.MailerClient {
+//				return newAcme(acmeParams{baseURL: fakeAcmeServer(t).URL})
+//			},
+//		})
+//	}
+package mailertest
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	mailer "github.com/caesar-rocks/mail"
+)
+
+// Harness is the provider under test.
+type Harness struct {
+	// New returns the client under test, e.g. sending to a fake API. It is called
+	// for every case, the client being closed after it.
+	New func(t *testing.T) mailer.MailerClient
+	// Received returns the emails the fake API received from the client of the
+	// case, decoded back into emails, for the suite to compare with the ones sent.
+	// The suite only checks that the emails are sent when nil.
+	Received func(t *testing.T) []mailer.Mail
+	// Capabilities are those of the provider, defaulting to the ones reported by
+	// the client. The cases needing a missing capability are skipped.
+	Capabilities *mailer.Capabilities
+}
+
+// Case is an email of the conformance matrix.
+type Case struct {
+	Name string
+	Mail mailer.Mail
+	// Optional cases may be refused by the provider with an error, they must
+	// not panic.
+	Optional bool
+	// needs returns whether the provider can send the email.
+	needs func(caps mailer.Capabilities) bool
+}
+
+// manyRecipients is the number of recipients of the "many recipients" case, when
+// the provider does not have a lower limit.
+const manyRecipients = 25
+
+// Cases returns the conformance matrix for a provider of the capabilities.
+func Cases(caps mailer.Capabilities) []Case {
+	recipients := manyRecipients
+	if caps.MaxRecipients > 0 && caps.MaxRecipients < recipients {
+		recipients = caps.MaxRecipients
+	}
+	var to []string
+	for i := 0; i < recipients; i++ {
+		to = append(to, fmt.Sprintf("user%d@example.com", i))
+	}
+	// The Cc and Bcc recipients are part of the limit.
+	var cc, bcc []string
+	if len(to) >= 3 {
+		cc, bcc = to[len(to)-2:len(to)-1], to[len(to)-1:]
+		to = to[:len(to)-2]
+	}
+	sender := mailer.Address{Email: "sender@example.com"}
+	recipient := mailer.Addresses("recipient@example.com")
+
+	return []Case{
+		{
+			Name: "text",
+			Mail: mailer.Mail{From: sender, To: recipient, Subject: "Text", Text: "Hello, world."},
+		},
+		{
+			Name: "html and text",
+			Mail: mailer.Mail{
+				From:    mailer.Address{Name: "Sender", Email: "sender@example.com"},
+				To:      []mailer.Address{{Name: "Recipient", Email: "recipient@example.com"}},
+				Subject: "Html",
+				Html:    "<p>Hello, <strong>world</strong>.</p>",
+				Text:    "Hello, world.",
+			},
+		},
+		{
+			Name: "unicode",
+			Mail: mailer.Mail{
+				From:    mailer.Address{Name: "Zoë Ünïcode", Email: "sender@example.com"},
+				To:      []mailer.Address{{Name: "José Müller", Email: "recipient@example.com"}},
+				Subject: "Héllo wörld 👋 世界",
+				Html:    "<p>Ça marche, 日本語, emoji 🎉</p>",
+				Text:    "Ça marche, 日本語, emoji 🎉",
+			},
+		},
+		{
+			Name: "reply-to and headers",
+			Mail: mailer.Mail{
+				From:    sender,
+				To:      recipient,
+				ReplyTo: mailer.Address{Email: "support@example.com"},
+				Subject: "Headers",
+				Text:    "Hello, world.",
+				Headers: map[string]string{"X-Conformance": "mailertest"},
+			},
+			needs: func(caps mailer.Capabilities) bool { return caps.Headers },
+		},
+		{
+			Name: "attachments",
+			Mail: mailer.Mail{
+				From:    sender,
+				To:      recipient,
+				Subject: "Attachments",
+				Text:    "See the attachments.",
+				Attachments: []mailer.Attachment{
+					{Name: "hello.txt", Content: []byte("Hello, world.\n")},
+					{Name: "pixel.png", Content: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), ContentType: "image/png"},
+				},
+			},
+			needs: func(caps mailer.Capabilities) bool { return caps.Attachments },
+		},
+		{
+			Name: "many recipients",
+			Mail: mailer.Mail{
+				From:    sender,
+				To:      mailer.Addresses(to...),
+				Cc:      mailer.Addresses(cc...),
+				Bcc:     mailer.Addresses(bcc...),
+				Subject: "Many recipients",
+				Text:    "Hello, everyone.",
+			},
+		},
+		{
+			Name:     "empty bodies",
+			Mail:     mailer.Mail{From: sender, To: recipient, Subject: "Empty"},
+			Optional: true,
+		},
+	}
+}
+
+// RunProviderTests sends every email of the conformance matrix with a new client
+// of the harness, as a subtest.
+func RunProviderTests(t *testing.T, h Harness) {
+	t.Helper()
+	if h.New == nil {
+		t.Fatal("mailertest: the harness has no New function")
+	}
+
+	var caps mailer.Capabilities
+	if h.Capabilities != nil {
+		caps = *h.Capabilities
+	} else {
+		client := h.New(t)
+		caps = mailer.CapabilitiesOf(client)
+		client.Close()
+	}
+
+	for _, c := range Cases(caps) {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			if c.needs != nil && !c.needs(caps) {
+				t.Skip("the provider does not support it")
+			}
+			client := h.New(t)
+			defer client.Close()
+
+			err := send(client, c.Mail)
+			if err != nil {
+				if c.Optional {
+					t.Logf("refused: %v", err)
+					return
+				}
+				t.Fatalf("Expected the email to be sent, got %v", err)
+			}
+			if h.Received != nil {
+				CheckReceived(t, c.Mail, h.Received(t))
+			}
+		})
+	}
+}
+
+// send sends the email, reporting a panic of the client as an error.
+func send(client mailer.MailerClient, msg mailer.Mail) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return client.Send(msg)
+}
+
+// CheckReceived reports the differences between the email sent and the ones the
+// provider received. The recipients may be split between several emails, and the
+// Bcc recipients be in any of their To, Cc or Bcc.
+func CheckReceived(t *testing.T, sent mailer.Mail, received []mailer.Mail) {
+	t.Helper()
+	if len(received) == 0 {
+		t.Fatal("Expected the email to be received")
+	}
+
+	got := received[0]
+	if got.Subject != sent.Subject {
+		t.Errorf("Expected the subject %q, got %q", sent.Subject, got.Subject)
+	}
+	if want, have := sent.From, got.From; want != have {
+		t.Errorf("Expected the sender %+v, got %+v", want, have)
+	}
+	if strings.TrimSpace(got.Html) != strings.TrimSpace(sent.Html) {
+		t.Errorf("Expected the html %q, got %q", sent.Html, got.Html)
+	}
+	if strings.TrimSpace(got.Text) != strings.TrimSpace(sent.Text) {
+		t.Errorf("Expected the text %q, got %q", sent.Text, got.Text)
+	}
+	if sent.ReplyTo.Email != "" && got.ReplyTo.Email != sent.ReplyTo.Email {
+		t.Errorf("Expected the reply-to %q, got %q", sent.ReplyTo, got.ReplyTo)
+	}
+	for key, value := range sent.Headers {
+		if got.Headers[key] != value {
+			t.Errorf("Expected the header %s: %q, got %q", key, value, got.Headers[key])
+		}
+	}
+
+	var want, have []string
+	for _, list := range [][]mailer.Address{sent.To, sent.Cc, sent.Bcc} {
+		want = append(want, emails(list)...)
+	}
+	for _, msg := range received {
+		for _, list := range [][]mailer.Address{msg.To, msg.Cc, msg.Bcc} {
+			have = append(have, emails(list)...)
+		}
+	}
+	sort.Strings(want)
+	sort.Strings(have)
+	if strings.Join(want, ",") != strings.Join(have, ",") {
+		t.Errorf("Expected the recipients %v, got %v", want, have)
+	}
+
+	if len(got.Attachments) != len(sent.Attachments) {
+		t.Fatalf("Expected %d attachments, got %d", len(sent.Attachments), len(got.Attachments))
+	}
+	for i, attachment := range sent.Attachments {
+		if got.Attachments[i].Name != attachment.Name || !bytes.Equal(got.Attachments[i].Content, attachment.Content) {
+			t.Errorf("Expected the attachment %s to be received as sent, got %s of %d bytes", attachment.Name, got.Attachments[i].Name, len(got.Attachments[i].Content))
+		}
+	}
+}
+
+func emails(list []mailer.Address) []string {
+	var emails []string
+	for _, addr := range list {
+		emails = append(emails, strings.ToLower(addr.Email))
+	}
+	return emails
+}
@@ -0,0 +1,57 @@
+package mailertest
+
+import (
+	"strings"
+	"testing"
+
+	mailer "github.com/caesar-rocks/mail"
+)
+
+// recordingClient is a provider receiving the emails as they are sent.
+type recordingClient struct {
+	received *[]mailer.Mail
+	caps     mailer.Capabilities
+}
+
+func (c *recordingClient) Send(msg mailer.Mail) error {
+	*c.received = append(*c.received, msg)
+	return nil
+}
+
+func (c *recordingClient) Capabilities() mailer.Capabilities { return c.caps }
+
+func (c *recordingClient) Close() {}
+
+func TestRunProviderTests(t *testing.T) {
+	var received []mailer.Mail
+	RunProviderTests(t, Harness{
+		New: func(t *testing.T) mailer.MailerClient {
+			received = nil
+			return &recordingClient{received: &received, caps: mailer.Capabilities{Attachments: true, Headers: true}}
+		},
+		Received: func(t *testing.T) []mailer.Mail { return received },
+	})
+}
+
+func TestCases(t *testing.T) {
+	cases := Cases(mailer.Capabilities{MaxRecipients: 5})
+	for _, c := range cases {
+		if c.Name != "many recipients" {
+			continue
+		}
+		recipients := len(c.Mail.To) + 2
+		if recipients != 5 {
+			t.Errorf("Expected the recipients within the limit of 5, got %d", recipients)
+		}
+	}
+
+	var skipped []string
+	for _, c := range cases {
+		if c.needs != nil && !c.needs(mailer.Capabilities{}) {
+			skipped = append(skipped, c.Name)
+		}
+	}
+	if strings.Join(skipped, ",") != "reply-to and headers,attachments" {
+		t.Errorf("Expected the headers and attachments cases to need the capabilities, got %v", skipped)
+	}
+}
@@ -0,0 +1,99 @@
+// Package mailgomail converts github.com/wneessen/go-mail messages into emails
+// of the mailer, so that existing message construction code can be kept.
+package mailgomail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+
+	mailer "github.com/caesar-rocks/mail"
+	gomail "github.com/wneessen/go-mail"
+)
+
+// ErrNoAttachmentDir is returned when a message has attachments but no directory
+// to write them to was given.
+var ErrNoAttachmentDir = errors.New("no directory to write the attachments to")
+
+// FromMsg converts the message into an email. As the attachments of an email are
+// files on disk, the attachments of the message are written to attachmentDir.
+func FromMsg(msg *gomail.Msg, attachmentDir string) (mailer.Mail, error) {
+	email := mailer.Mail{
+		To:      toAddresses(msg.GetTo()),
+		Cc:      toAddresses(msg.GetCc()),
+		Bcc:     toAddresses(msg.GetBcc()),
+		Subject: first(msg.GetGenHeader(gomail.HeaderSubject)),
+	}
+	if from := msg.GetFrom(); len(from) > 0 {
+		email.From = toAddresses(from)[0]
+	}
+	if replyTo := first(msg.GetGenHeader(gomail.HeaderReplyTo)); replyTo != "" {
+		if err := email.SetReplyTo(replyTo); err != nil {
+			return mailer.Mail{}, err
+		}
+	}
+
+	for _, part := range msg.GetParts() {
+		content, err := part.GetContent()
+		if err != nil {
+			return mailer.Mail{}, err
+		}
+		switch part.GetContentType() {
+		case gomail.TypeTextPlain:
+			email.Text = string(content)
+		case gomail.TypeTextHTML:
+			email.Html = string(content)
+		}
+	}
+
+	files := append(msg.GetAttachments(), msg.GetEmbeds()...)
+	if len(files) > 0 && attachmentDir == "" {
+		return mailer.Mail{}, ErrNoAttachmentDir
+	}
+	for _, file := range files {
+		var content bytes.Buffer
+		if _, err := file.Writer(&content); err != nil {
+			return mailer.Mail{}, fmt.Errorf("failed to read attachment %s: %w", file.Name, err)
+		}
+		path, err := writeAttachment(attachmentDir, file.Name, content.Bytes())
+		if err != nil {
+			return mailer.Mail{}, err
+		}
+		email.Attachments = append(email.Attachments, mailer.Attachment{Name: file.Name, Path: path})
+	}
+
+	return email, nil
+}
+
+// toAddresses converts the addresses, keeping their display names.
+func toAddresses(addresses []*mail.Address) []mailer.Address {
+	var list []mailer.Address
+	for _, address := range addresses {
+		list = append(list, mailer.Address{Name: address.Name, Email: address.Address})
+	}
+	return list
+}
+
+// writeAttachment writes the content to a new file of dir, keeping the extension
+// of name for the content type to be detected.
+func writeAttachment(dir, name string, content []byte) (string, error) {
+	f, err := os.CreateTemp(dir, "*"+filepath.Ext(name))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(content); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,54 @@
+package mailgomail
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	mailer "github.com/caesar-rocks/mail"
+	gomail "github.com/wneessen/go-mail"
+)
+
+func TestFromMsg(t *testing.T) {
+	msg := gomail.NewMsg()
+	msg.From("Info <info@test.com>")
+	msg.To("a@test.com", `"Doe, John" <b@test.com>`)
+	msg.Cc("c@test.com")
+	msg.ReplyTo("reply@test.com")
+	msg.Subject("Hello")
+	msg.SetBodyString(gomail.TypeTextPlain, "Hello")
+	msg.AddAlternativeString(gomail.TypeTextHTML, "<p>Hello</p>")
+	msg.AttachReader("report.pdf", strings.NewReader("%PDF"))
+
+	email, err := FromMsg(msg, t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	to := []mailer.Address{{Email: "a@test.com"}, {Name: "Doe, John", Email: "b@test.com"}}
+	if !reflect.DeepEqual(email.To, to) || !reflect.DeepEqual(email.Cc, mailer.Addresses("c@test.com")) {
+		t.Errorf("Unexpected recipients %+v %+v", email.To, email.Cc)
+	}
+	if email.From != (mailer.Address{Name: "Info", Email: "info@test.com"}) || email.ReplyTo != (mailer.Address{Email: "reply@test.com"}) {
+		t.Errorf("Unexpected addresses %+v %+v", email.From, email.ReplyTo)
+	}
+	if email.Subject != "Hello" || email.Text != "Hello" || email.Html != "<p>Hello</p>" {
+		t.Errorf("Unexpected content %+v", email)
+	}
+	if len(email.Attachments) != 1 || email.Attachments[0].Name != "report.pdf" {
+		t.Fatalf("Expected the attachment, got %+v", email.Attachments)
+	}
+	if content, _ := os.ReadFile(email.Attachments[0].Path); string(content) != "%PDF" {
+		t.Errorf("Expected the attachment content, got %q", content)
+	}
+}
+
+func TestFromMsg_NoAttachmentDir(t *testing.T) {
+	msg := gomail.NewMsg()
+	msg.AttachReader("report.pdf", strings.NewReader("%PDF"))
+
+	if _, err := FromMsg(msg, ""); !errors.Is(err, ErrNoAttachmentDir) {
+		t.Errorf("Expected ErrNoAttachmentDir, got %v", err)
+	}
+}
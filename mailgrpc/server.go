@@ -0,0 +1,143 @@
+// Package mailgrpc exposes a mailer as a gRPC service, so that services written
+// in other languages can queue emails through a central mail service. The
+// service definition is in mailer.proto, regenerate the code with
+// `buf generate --template buf.gen.yaml mailer.proto`.
+package mailgrpc
+
+import (
+	"context"
+	"errors"
+
+	mailer "github.com/caesar-rocks/mail"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type server struct {
+	UnimplementedMailerServiceServer
+	mailer *mailer.Mailer
+}
+
+// NewServer returns the MailerServiceServer queuing the emails in the mailer.
+// Authentication is left to interceptors of the grpc.Server.
+func NewServer(m *mailer.Mailer) MailerServiceServer {
+	return &server{mailer: m}
+}
+
+// Register registers the MailerService of the mailer on the grpc.Server.
+func Register(s *grpc.Server, m *mailer.Mailer) {
+	RegisterMailerServiceServer(s, NewServer(m))
+}
+
+func (s *server) Send(ctx context.Context, req *SendRequest) (*SendResponse, error) {
+	if req.GetMail() == nil {
+		return nil, status.Error(codes.InvalidArgument, "mail is required")
+	}
+	msg, err := toMail(req.GetMail())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	id, err := s.mailer.Enqueue(msg)
+	if err != nil {
+		return nil, enqueueError(err)
+	}
+	return &SendResponse{Id: id}, nil
+}
+
+func (s *server) SendBatch(ctx context.Context, req *SendBatchRequest) (*SendBatchResponse, error) {
+	// The emails are all converted first, for an invalid one not to leave the
+	// batch half queued.
+	msgs := make([]mailer.Mail, 0, len(req.GetMails()))
+	for i, mail := range req.GetMails() {
+		msg, err := toMail(mail)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "mail %d: %v", i, err)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	ids := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		id, err := s.mailer.Enqueue(msg)
+		if err != nil {
+			return nil, enqueueError(err)
+		}
+		ids = append(ids, id)
+	}
+	return &SendBatchResponse{Ids: ids}, nil
+}
+
+// enqueueError maps an Enqueue error to its status, a closed mailer being
+// unavailable.
+func enqueueError(err error) error {
+	if errors.Is(err, mailer.ErrMailerClosed) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func (s *server) Status(ctx context.Context, req *StatusRequest) (*StatusResponse, error) {
+	st, ok := s.mailer.Status(req.GetId())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "message %q not found", req.GetId())
+	}
+	return &StatusResponse{
+		Id:        st.ID,
+		State:     toMessageState(st.State),
+		Error:     st.Error,
+		UpdatedAt: timestamppb.New(st.UpdatedAt),
+	}, nil
+}
+
+func (s *server) Cancel(ctx context.Context, req *CancelRequest) (*CancelResponse, error) {
+	return &CancelResponse{Canceled: s.mailer.Cancel(req.GetId())}, nil
+}
+
+// toMail converts the message, its address fields being parsed as address lists.
+func toMail(msg *Mail) (mailer.Mail, error) {
+	m := mailer.Mail{
+		Html:      msg.GetHtml(),
+		Text:      msg.GetText(),
+		Subject:   msg.GetSubject(),
+		Category:  msg.GetCategory(),
+		Tags:      msg.GetTags(),
+		TenantID:  msg.GetTenantId(),
+		AmpHtml:   msg.GetAmpHtml(),
+		Preheader: msg.GetPreheader(),
+		Class:     mailer.MessageClass(msg.GetClass()),
+	}
+	if msg.GetExpiresAt() != nil {
+		m.ExpiresAt = msg.GetExpiresAt().AsTime()
+	}
+	for _, field := range []struct {
+		value string
+		set   func(string) error
+	}{{msg.GetTo(), m.SetTo}, {msg.GetFrom(), m.SetFrom}, {msg.GetCc(), m.SetCc}, {msg.GetBcc(), m.SetBcc}, {msg.GetReplyTo(), m.SetReplyTo}} {
+		if field.value == "" {
+			continue
+		}
+		if err := field.set(field.value); err != nil {
+			return mailer.Mail{}, err
+		}
+	}
+	return m, nil
+}
+
+func toMessageState(state mailer.MessageState) MessageState {
+	switch state {
+	case mailer.MessageQueued:
+		return MessageState_MESSAGE_STATE_QUEUED
+	case mailer.MessageSent:
+		return MessageState_MESSAGE_STATE_SENT
+	case mailer.MessageFailed:
+		return MessageState_MESSAGE_STATE_FAILED
+	case mailer.MessageCanceled:
+		return MessageState_MESSAGE_STATE_CANCELED
+	case mailer.MessageSpooled:
+		return MessageState_MESSAGE_STATE_SPOOLED
+	default:
+		return MessageState_MESSAGE_STATE_UNSPECIFIED
+	}
+}
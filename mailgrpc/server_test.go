@@ -0,0 +1,116 @@
+package mailgrpc
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"testing"
+	"time"
+
+	mailer "github.com/caesar-rocks/mail"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestMailer(t *testing.T) *mailer.Mailer {
+	t.Helper()
+	path, err := exec.LookPath("true")
+	if err != nil {
+		t.Skip("true binary not available")
+	}
+	return mailer.NewMailer(mailer.MailCfg{APIService: mailer.SENDMAIL, SendmailPath: path})
+}
+
+func newTestClient(t *testing.T, m *mailer.Mailer) MailerServiceClient {
+	t.Helper()
+	ln := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	Register(s, m)
+	go s.Serve(ln)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewMailerServiceClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	m := newTestMailer(t)
+	t.Cleanup(m.Close)
+	client := newTestClient(t, m)
+	ctx := context.Background()
+
+	res, err := client.SendBatch(ctx, &SendBatchRequest{Mails: []*Mail{
+		{From: "info@test.com", To: "a@test.com", Subject: "test", Text: "test"},
+		{From: "info@test.com", To: "b@test.com", Subject: "test", Text: "test"},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(res.GetIds()) != 2 {
+		t.Fatalf("Expected 2 ids, got %v", res.GetIds())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		st, err := client.Status(ctx, &StatusRequest{Id: res.GetIds()[1]})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if st.GetState() == MessageState_MESSAGE_STATE_SENT {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the email to be sent, got %v", st.GetState())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	canceled, err := client.Cancel(ctx, &CancelRequest{Id: res.GetIds()[0]})
+	if err != nil || canceled.GetCanceled() {
+		t.Errorf("Expected a sent email not to be canceled, got %v %v", canceled, err)
+	}
+
+	if _, err := client.Status(ctx, &StatusRequest{Id: "unknown"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+	if _, err := client.Send(ctx, &SendRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestServer_Closed(t *testing.T) {
+	m := newTestMailer(t)
+	m.Close()
+	client := newTestClient(t, m)
+
+	_, err := client.Send(context.Background(), &SendRequest{Mail: &Mail{From: "info@test.com", To: "a@test.com", Text: "test"}})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable, got %v", err)
+	}
+}
+
+func TestToMail(t *testing.T) {
+	msg, err := toMail(&Mail{To: `"Doe, John" <a@test.com>`, Html: "<p>test</p>", AmpHtml: "<html amp4email></html>", Preheader: "Your invoice", Class: "automated"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(msg.To) != 1 || msg.To[0] != (mailer.Address{Name: "Doe, John", Email: "a@test.com"}) {
+		t.Errorf("Expected the parsed recipient, got %+v", msg.To)
+	}
+	if msg.AmpHtml != "<html amp4email></html>" || msg.Preheader != "Your invoice" || msg.Class != mailer.MessageAutomated {
+		t.Errorf("Expected the AMP html, preheader and class, got %+v", msg)
+	}
+	if state := toMessageState(mailer.MessageSpooled); state != MessageState_MESSAGE_STATE_SPOOLED {
+		t.Errorf("Expected the spooled state, got %v", state)
+	}
+}
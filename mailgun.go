@@ -0,0 +1,271 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	mailgunBaseURL   = "https://api.mailgun.net"
+	mailgunEUBaseURL = "https://api.eu.mailgun.net"
+)
+
+// MailgunRegion is the region of a Mailgun account.
+type MailgunRegion string
+
+const (
+	MailgunRegionUS MailgunRegion = "us"
+	MailgunRegionEU MailgunRegion = "eu"
+)
+
+// MailgunOptions holds the Mailgun specific options of an email.
+type MailgunOptions struct {
+	// TestMode makes Mailgun accept the email without delivering it (o:testmode).
+	TestMode bool
+	// DeliveryTime schedules the delivery of the email (o:deliverytime).
+	DeliveryTime time.Time
+	// Tags are the tags attached to the email (o:tag).
+	Tags []string
+	// Tracking toggles click and open tracking (o:tracking). Nil keeps the domain setting.
+	Tracking *bool
+	// TrackingClicks toggles click tracking (o:tracking-clicks). Nil keeps the domain setting.
+	TrackingClicks *bool
+	// TrackingOpens toggles open tracking (o:tracking-opens). Nil keeps the domain setting.
+	TrackingOpens *bool
+}
+
+type mailgunParams struct {
+	apiKey     string
+	domain     string
+	region     MailgunRegion
+	userAgent  string
+	httpClient *http.Client
+	clock      Clock
+}
+
+type mailgunMailer struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	domain     string
+	userAgent  string
+	clock      Clock
+	// seq numbers the template versions tagged at the same time.
+	seq atomic.Int64
+}
+
+func newMailgun(params mailgunParams) MailerClient {
+	baseURL := mailgunBaseURL
+	if params.region == MailgunRegionEU {
+		baseURL = mailgunEUBaseURL
+	}
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+	if params.clock == nil {
+		params.clock = systemClock{}
+	}
+
+	return &mailgunMailer{
+		httpClient: params.httpClient,
+		baseURL:    baseURL,
+		apiKey:     params.apiKey,
+		domain:     params.domain,
+		userAgent:  params.userAgent,
+		clock:      params.clock,
+	}
+}
+
+func (m *mailgunMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *mailgunMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	body, contentType, err := m.buildForm(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := newPooledRequest(http.MethodPost, m.url("/messages"), body)
+	if err != nil {
+		return nil, err
+	}
+	return m.doRequest(req, contentType)
+}
+
+// do sends a request to an endpoint of the sending domain.
+func (m *mailgunMailer) do(ctx context.Context, method string, path string, body io.Reader, contentType string) error {
+	_, err := m.doWithResponse(ctx, method, path, body, contentType)
+	return err
+}
+
+func (m *mailgunMailer) doWithResponse(ctx context.Context, method string, path string, body io.Reader, contentType string) (*ProviderResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, method, m.url(path), body)
+	if err != nil {
+		return nil, err
+	}
+	return m.doRequest(req, contentType)
+}
+
+// url returns the URL of an endpoint of the sending domain.
+func (m *mailgunMailer) url(path string) string {
+	return fmt.Sprintf("%s/v3/%s%s", m.baseURL, m.domain, path)
+}
+
+func (m *mailgunMailer) doRequest(req *http.Request, contentType string) (*ProviderResponse, error) {
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.SetBasicAuth("api", m.apiKey)
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, MAILGUN, req, nil)
+}
+
+func (m *mailgunMailer) buildForm(msg Mail) (*bytes.Buffer, string, error) {
+	body := getBuffer()
+	form := multipart.NewWriter(body)
+
+	fields := [][2]string{
+		{"from", msg.From.String()},
+		{"to", joinAddresses(msg.To)},
+		{"cc", joinAddresses(msg.Cc)},
+		{"bcc", joinAddresses(msg.Bcc)},
+		{"subject", msg.Subject},
+		{"text", msg.Text},
+		{"html", msg.Html},
+		{"amp-html", msg.AmpHtml},
+		{"h:Reply-To", msg.ReplyTo.String()},
+	}
+
+	for _, tag := range msg.Tags {
+		fields = append(fields, [2]string{"o:tag", tag})
+	}
+	for _, key := range headerKeys(msg.Headers) {
+		fields = append(fields, [2]string{"h:" + key, msg.Headers[key]})
+	}
+
+	if options := msg.Mailgun; options != nil {
+		if options.TestMode {
+			fields = append(fields, [2]string{"o:testmode", "yes"})
+		}
+		if !options.DeliveryTime.IsZero() {
+			fields = append(fields, [2]string{"o:deliverytime", options.DeliveryTime.UTC().Format(time.RFC1123Z)})
+		}
+		for _, tag := range options.Tags {
+			fields = append(fields, [2]string{"o:tag", tag})
+		}
+		fields = append(fields,
+			[2]string{"o:tracking", getMailgunBool(options.Tracking)},
+			[2]string{"o:tracking-clicks", getMailgunBool(options.TrackingClicks)},
+			[2]string{"o:tracking-opens", getMailgunBool(options.TrackingOpens)},
+		)
+	}
+
+	for _, field := range fields {
+		if strings.TrimSpace(field[1]) == "" {
+			continue
+		}
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			putBuffer(body)
+			return nil, "", err
+		}
+	}
+
+	for _, attachment := range msg.Attachments {
+		part, err := form.CreateFormFile("attachment", attachment.Name)
+		if err != nil {
+			putBuffer(body)
+			return nil, "", err
+		}
+		if err := copyAttachment(part, attachment); err != nil {
+			putBuffer(body)
+			return nil, "", err
+		}
+	}
+
+	if err := form.Close(); err != nil {
+		putBuffer(body)
+		return nil, "", err
+	}
+
+	return body, form.FormDataContentType(), nil
+}
+
+func (m *mailgunMailer) CreateTemplate(ctx context.Context, tpl Template) error {
+	form, err := m.getTemplateForm(tpl)
+	if err != nil {
+		return err
+	}
+	form.Set("name", tpl.Name)
+	return m.do(ctx, http.MethodPost, "/templates", strings.NewReader(form.Encode()), "application/x-www-form-urlencoded")
+}
+
+func (m *mailgunMailer) UpdateTemplate(ctx context.Context, tpl Template) error {
+	form, err := m.getTemplateForm(tpl)
+	if err != nil {
+		return err
+	}
+	form.Set("active", "yes")
+	path := "/templates/" + url.PathEscape(tpl.Name) + "/versions"
+	return m.do(ctx, http.MethodPost, path, strings.NewReader(form.Encode()), "application/x-www-form-urlencoded")
+}
+
+func (m *mailgunMailer) DeleteTemplate(ctx context.Context, name string) error {
+	return m.do(ctx, http.MethodDelete, "/templates/"+url.PathEscape(name), nil, "")
+}
+
+// getTemplateForm returns the fields of a template version. Versions are tagged
+// with their creation time and a sequence number because Mailgun requires unique
+// version tags.
+func (m *mailgunMailer) getTemplateForm(tpl Template) (url.Values, error) {
+	headers, err := json.Marshal(map[string]string{"Subject": tpl.Subject})
+	if err != nil {
+		return nil, err
+	}
+	return url.Values{
+		"template": {tpl.Html},
+		"tag":      {m.versionTag()},
+		"headers":  {string(headers)},
+	}, nil
+}
+
+func (m *mailgunMailer) versionTag() string {
+	now := m.clock.Now().UTC()
+	return fmt.Sprintf("%s%09d-%d", now.Format("20060102150405"), now.Nanosecond(), m.seq.Add(1))
+}
+
+// Health verifies the API key and the sending domain by fetching the domain.
+func (m *mailgunMailer) Health(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v3/domains/%s", m.baseURL, m.domain), nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("api", m.apiKey)
+	setUserAgent(req, m.userAgent)
+	return doJSONRequest(m.httpClient, MAILGUN, req, nil)
+}
+
+func getMailgunBool(value *bool) string {
+	if value == nil {
+		return ""
+	}
+	if *value {
+		return "yes"
+	}
+	return "no"
+}
+
+func (m *mailgunMailer) Close() {
+	// Not implemented because the Mailgun API is stateless.
+}
@@ -0,0 +1,101 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+// mailgunEventTypes maps the Mailgun events to the unified events, the other
+// Mailgun events e.g. accepted and clicked being ignored.
+var mailgunEventTypes = map[string]EventType{
+	"delivered":  EventDelivered,
+	"failed":     EventBounced,
+	"complained": EventComplained,
+	"opened":     EventOpened,
+}
+
+type mailgunEvent struct {
+	Event     string  `json:"event"`
+	Timestamp float64 `json:"timestamp"`
+	Recipient string  `json:"recipient"`
+	Severity  string  `json:"severity"`
+	Reason    string  `json:"reason"`
+	Message   struct {
+		Headers struct {
+			MessageID string `json:"message-id"`
+		} `json:"headers"`
+	} `json:"message"`
+	DeliveryStatus struct {
+		Description string `json:"description"`
+		Message     string `json:"message"`
+	} `json:"delivery-status"`
+}
+
+type mailgunEventsPage struct {
+	Items  []mailgunEvent `json:"items"`
+	Paging struct {
+		Next string `json:"next"`
+	} `json:"paging"`
+}
+
+// PollEvents fetches the events of the sending domain from the Mailgun Events API,
+// following the pages until the last one.
+func (m *mailgunMailer) PollEvents(ctx context.Context, since time.Time) ([]Event, error) {
+	url := fmt.Sprintf("%s/v3/%s/events?ascending=yes&limit=300&begin=%.6f", m.baseURL, m.domain, float64(since.UnixMicro())/1e6)
+
+	var events []Event
+	for url != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth("api", m.apiKey)
+		setUserAgent(req, m.userAgent)
+
+		var page mailgunEventsPage
+		if err := doJSONRequest(m.httpClient, MAILGUN, req, &page); err != nil {
+			return nil, err
+		}
+		if len(page.Items) == 0 {
+			break
+		}
+		for _, item := range page.Items {
+			if event, ok := item.event(); ok {
+				events = append(events, event)
+			}
+		}
+		url = page.Paging.Next
+	}
+	return events, nil
+}
+
+func (e mailgunEvent) event() (Event, bool) {
+	eventType, ok := mailgunEventTypes[e.Event]
+	if !ok {
+		return Event{}, false
+	}
+	if eventType == EventBounced && e.Severity == "temporary" {
+		eventType = EventDelayed
+	}
+
+	seconds, fraction := math.Modf(e.Timestamp)
+	reason := e.DeliveryStatus.Message
+	if reason == "" {
+		reason = e.DeliveryStatus.Description
+	}
+	if reason == "" {
+		reason = e.Reason
+	}
+	return Event{
+		Type:      eventType,
+		Provider:  MAILGUN,
+		MessageID: e.Message.Headers.MessageID,
+		Recipient: e.Recipient,
+		Timestamp: time.Unix(int64(seconds), int64(math.Round(fraction*1e6))*1e3).UTC(),
+		Reason:    reason,
+		Permanent: eventType == EventBounced,
+	}, true
+}
@@ -0,0 +1,170 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMailgun_NewMailgun(t *testing.T) {
+	testCases := []struct {
+		name     string
+		region   MailgunRegion
+		expected string
+	}{
+		{
+			name:     "Should use the US endpoint by default",
+			expected: mailgunBaseURL,
+		},
+		{
+			name:     "Should use the EU endpoint",
+			region:   MailgunRegionEU,
+			expected: mailgunEUBaseURL,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mailgun := newMailgun(mailgunParams{apiKey: MailAPIKey, domain: "mg.test.com", region: tc.region})
+			if baseURL := mailgun.(*mailgunMailer).baseURL; baseURL != tc.expected {
+				t.Errorf("Expected base url %s, got %s", tc.expected, baseURL)
+			}
+		})
+	}
+}
+
+func TestMailgun_Send(t *testing.T) {
+	attachmentPath := filepath.Join(t.TempDir(), "invoice.txt")
+	if err := os.WriteFile(attachmentPath, []byte("invoice"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tracking := false
+	deliveryTime := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		received = r
+		w.Write([]byte(`{"id":"<id@mg.test.com>","message":"Queued. Thank you."}`))
+	}))
+	defer server.Close()
+
+	mailgun := &mailgunMailer{
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+		apiKey:     MailAPIKey,
+		domain:     "mg.test.com",
+	}
+
+	err := mailgun.Send(Mail{
+		From:        Address{Email: "info@test.com"},
+		To:          Addresses("a@test.com"),
+		Subject:     "test",
+		Text:        "test",
+		Attachments: []Attachment{{Name: "invoice.txt", Path: attachmentPath}},
+		Tags:        []string{"welcome:short"},
+		Mailgun: &MailgunOptions{
+			TestMode:      true,
+			DeliveryTime:  deliveryTime,
+			Tags:          []string{"welcome", "onboarding"},
+			TrackingOpens: &tracking,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if received.URL.Path != "/v3/mg.test.com/messages" {
+		t.Errorf("Expected domain messages path, got %s", received.URL.Path)
+	}
+	if user, pass, _ := received.BasicAuth(); user != "api" || pass != MailAPIKey {
+		t.Errorf("Expected basic auth with api key, got %s:%s", user, pass)
+	}
+
+	form := received.MultipartForm
+	expected := map[string][]string{
+		"o:testmode":       {"yes"},
+		"o:deliverytime":   {deliveryTime.Format(time.RFC1123Z)},
+		"o:tag":            {"welcome:short", "welcome", "onboarding"},
+		"o:tracking-opens": {"no"},
+	}
+	for key, values := range expected {
+		if len(form.Value[key]) != len(values) {
+			t.Errorf("Expected %s to be %v, got %v", key, values, form.Value[key])
+			continue
+		}
+		for i := range values {
+			if form.Value[key][i] != values[i] {
+				t.Errorf("Expected %s to be %v, got %v", key, values, form.Value[key])
+			}
+		}
+	}
+	if _, ok := form.Value["o:tracking"]; ok {
+		t.Errorf("Expected o:tracking to be omitted when unset")
+	}
+	if len(form.File["attachment"]) != 1 {
+		t.Errorf("Expected one attachment, got %d", len(form.File["attachment"]))
+	}
+}
+
+func TestMailgun_TemplateManagement(t *testing.T) {
+	var calls, tags []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+r.PostForm.Get("name"))
+		if tag := r.PostForm.Get("tag"); tag != "" {
+			tags = append(tags, tag)
+		}
+		w.Write([]byte(`{"message":"ok"}`))
+	}))
+	defer server.Close()
+
+	mailgun := &mailgunMailer{
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+		apiKey:     MailAPIKey,
+		domain:     "mg.test.com",
+		clock:      &fakeClock{now: time.Now()},
+	}
+
+	ctx := context.Background()
+	tpl := Template{Name: "welcome", Subject: "Welcome", Html: "<p>Hi</p>"}
+	if err := mailgun.CreateTemplate(ctx, tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := mailgun.UpdateTemplate(ctx, tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := mailgun.UpdateTemplate(ctx, tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := mailgun.DeleteTemplate(ctx, tpl.Name); err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 3 || tags[1] == tags[2] {
+		t.Errorf("Expected unique version tags, got %v", tags)
+	}
+
+	expected := []string{
+		"POST /v3/mg.test.com/templates welcome",
+		"POST /v3/mg.test.com/templates/welcome/versions ",
+		"POST /v3/mg.test.com/templates/welcome/versions ",
+		"DELETE /v3/mg.test.com/templates/welcome ",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("Expected calls %q, got %q", expected, calls)
+			break
+		}
+	}
+}
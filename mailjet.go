@@ -0,0 +1,160 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+)
+
+const mailjetBaseURL = "https://api.mailjet.com"
+
+// MailjetOptions holds the Mailjet specific options of an email.
+type MailjetOptions struct {
+	// TemplateID is the id of a Mailjet template. When set, the template stored in
+	// Mailjet is rendered instead of the Html and Text of the email.
+	TemplateID int64
+	// TemplateLanguage enables the Mailjet template language in the content of the
+	// email or of the template, e.g. {{var:name}}.
+	TemplateLanguage bool
+	// Variables are the values of the template language.
+	Variables map[string]any
+	// SandboxMode validates the request without delivering the email.
+	SandboxMode bool
+}
+
+type mailjetParams struct {
+	apiKey     string
+	apiSecret  string
+	userAgent  string
+	httpClient *http.Client
+}
+
+type mailjetMailer struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	apiSecret  string
+	userAgent  string
+}
+
+type mailjetAddress struct {
+	Email string `json:"Email"`
+	Name  string `json:"Name,omitempty"`
+}
+
+type mailjetAttachment struct {
+	ContentType   string `json:"ContentType"`
+	Filename      string `json:"Filename"`
+	Base64Content string `json:"Base64Content"`
+}
+
+type mailjetMessage struct {
+	From             mailjetAddress      `json:"From"`
+	To               []mailjetAddress    `json:"To"`
+	Cc               []mailjetAddress    `json:"Cc,omitempty"`
+	Bcc              []mailjetAddress    `json:"Bcc,omitempty"`
+	ReplyTo          *mailjetAddress     `json:"ReplyTo,omitempty"`
+	Subject          string              `json:"Subject,omitempty"`
+	TextPart         string              `json:"TextPart,omitempty"`
+	HTMLPart         string              `json:"HTMLPart,omitempty"`
+	Attachments      []mailjetAttachment `json:"Attachments,omitempty"`
+	Headers          map[string]string   `json:"Headers,omitempty"`
+	TemplateID       int64               `json:"TemplateID,omitempty"`
+	TemplateLanguage bool                `json:"TemplateLanguage,omitempty"`
+	Variables        map[string]any      `json:"Variables,omitempty"`
+}
+
+type mailjetRequest struct {
+	Messages    []mailjetMessage `json:"Messages"`
+	SandboxMode bool             `json:"SandboxMode,omitempty"`
+}
+
+func newMailjet(params mailjetParams) MailerClient {
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+
+	return &mailjetMailer{
+		httpClient: params.httpClient,
+		baseURL:    mailjetBaseURL,
+		apiKey:     params.apiKey,
+		apiSecret:  params.apiSecret,
+		userAgent:  params.userAgent,
+	}
+}
+
+func (m *mailjetMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *mailjetMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	options := msg.Mailjet
+	if options == nil {
+		options = &MailjetOptions{}
+	}
+
+	message := mailjetMessage{
+		From:             mailjetAddress{Email: msg.From.Email, Name: msg.From.Name},
+		To:               getMailjetAddresses(msg.To),
+		Cc:               getMailjetAddresses(msg.Cc),
+		Bcc:              getMailjetAddresses(msg.Bcc),
+		Subject:          msg.Subject,
+		TextPart:         msg.Text,
+		HTMLPart:         msg.Html,
+		Headers:          msg.Headers,
+		TemplateID:       options.TemplateID,
+		TemplateLanguage: options.TemplateLanguage,
+		Variables:        options.Variables,
+	}
+	if msg.ReplyTo.Email != "" {
+		replyTo := msg.ReplyTo
+		message.ReplyTo = &mailjetAddress{Email: replyTo.Email, Name: replyTo.Name}
+	}
+	if options.TemplateID != 0 {
+		message.TextPart, message.HTMLPart = "", ""
+	}
+
+	for _, attachment := range msg.Attachments {
+		content, err := encodeAttachmentBase64(attachment)
+		if err != nil {
+			return nil, err
+		}
+		message.Attachments = append(message.Attachments, mailjetAttachment{
+			ContentType:   getAttachmentContentType(attachment),
+			Filename:      attachment.Name,
+			Base64Content: content,
+		})
+	}
+
+	payload := mailjetRequest{Messages: []mailjetMessage{message}, SandboxMode: options.SandboxMode}
+	return m.doWithResponse(context.Background(), http.MethodPost, "/v3.1/send", payload)
+}
+
+func getMailjetAddresses(addrs []Address) []mailjetAddress {
+	var addresses []mailjetAddress
+	for _, addr := range addrs {
+		addresses = append(addresses, mailjetAddress{Email: addr.Email, Name: addr.Name})
+	}
+	return addresses
+}
+
+func (m *mailjetMailer) doWithResponse(ctx context.Context, method string, path string, payload any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(method, m.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(m.apiKey, m.apiSecret)
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, MAILJET, req.WithContext(ctx), nil)
+}
+
+// Health verifies the API key and secret by fetching the user.
+func (m *mailjetMailer) Health(ctx context.Context) error {
+	_, err := m.doWithResponse(ctx, http.MethodGet, "/v3/REST/user", nil)
+	return err
+}
+
+func (m *mailjetMailer) Close() {
+	// Not implemented because the Mailjet API is stateless.
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMailjet_Send(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	mailjet := newMailjet(mailjetParams{apiKey: "key", apiSecret: "secret"}).(*mailjetMailer)
+	mailjet.baseURL = server.URL
+
+	err := mailjet.Send(Mail{
+		From:        Address{Name: "Acme", Email: "info@test.com"},
+		To:          Addresses("a@test.com"),
+		Subject:     "test",
+		Html:        "<p>Hi {{var:name}}</p>",
+		Attachments: []Attachment{{Name: "a.txt", Content: []byte("hi")}},
+		Mailjet:     &MailjetOptions{TemplateLanguage: true, Variables: map[string]any{"name": "Ada"}, SandboxMode: true},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := requests[0]
+	if req.Path != "/v3.1/send" {
+		t.Fatalf("Expected a request to /v3.1/send, got %s", req.Path)
+	}
+	if user, pass, ok := (&http.Request{Header: req.Header}).BasicAuth(); !ok || user != "key" || pass != "secret" {
+		t.Errorf("Expected basic auth with the key and secret, got %q %q", user, pass)
+	}
+	if req.Body["SandboxMode"] != true {
+		t.Errorf("Expected the sandbox mode, got %v", req.Body["SandboxMode"])
+	}
+	message := req.Body["Messages"].([]any)[0].(map[string]any)
+	if message["TemplateLanguage"] != true || message["Variables"].(map[string]any)["name"] != "Ada" {
+		t.Errorf("Expected the template language and its variables, got %v", message)
+	}
+	if from := message["From"].(map[string]any); from["Email"] != "info@test.com" || from["Name"] != "Acme" {
+		t.Errorf("Expected the sender with its name, got %v", from)
+	}
+	if attachments := message["Attachments"].([]any); attachments[0].(map[string]any)["Base64Content"] != "aGk=" {
+		t.Errorf("Expected the base64 attachment, got %v", attachments)
+	}
+}
+
+func TestMailjet_SendWithTemplate(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	mailjet := newMailjet(mailjetParams{apiKey: "key", apiSecret: "secret"}).(*mailjetMailer)
+	mailjet.baseURL = server.URL
+
+	err := mailjet.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Html: "ignored", Mailjet: &MailjetOptions{TemplateID: 42}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	message := requests[0].Body["Messages"].([]any)[0].(map[string]any)
+	if message["TemplateID"] != float64(42) || message["HTMLPart"] != nil {
+		t.Errorf("Expected the template instead of the html, got %v", message)
+	}
+}
@@ -0,0 +1,86 @@
+// Package mailjwemail converts github.com/jordan-wright/email emails into emails
+// of the mailer, so that existing message construction code can be kept.
+package mailjwemail
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	mailer "github.com/caesar-rocks/mail"
+	"github.com/jordan-wright/email"
+)
+
+// ErrNoAttachmentDir is returned when an email has attachments but no directory
+// to write them to was given.
+var ErrNoAttachmentDir = errors.New("no directory to write the attachments to")
+
+// FromEmail converts the email into an email of the mailer. As the attachments of
+// the mailer are files on disk, the attachments of e are written to attachmentDir.
+func FromEmail(e *email.Email, attachmentDir string) (mailer.Mail, error) {
+	msg := mailer.Mail{
+		Subject: e.Subject,
+		Text:    string(e.Text),
+		Html:    string(e.HTML),
+	}
+	var err error
+	for _, field := range []struct {
+		list  []string
+		addrs *[]mailer.Address
+	}{{e.To, &msg.To}, {e.Cc, &msg.Cc}, {e.Bcc, &msg.Bcc}} {
+		if *field.addrs, err = parseAddresses(field.list); err != nil {
+			return mailer.Mail{}, err
+		}
+	}
+	if e.From != "" {
+		if err := msg.SetFrom(e.From); err != nil {
+			return mailer.Mail{}, err
+		}
+	}
+	if len(e.ReplyTo) > 0 {
+		if err := msg.SetReplyTo(e.ReplyTo[0]); err != nil {
+			return mailer.Mail{}, err
+		}
+	}
+
+	if len(e.Attachments) > 0 && attachmentDir == "" {
+		return mailer.Mail{}, ErrNoAttachmentDir
+	}
+	for _, attachment := range e.Attachments {
+		path, err := writeAttachment(attachmentDir, attachment.Filename, attachment.Content)
+		if err != nil {
+			return mailer.Mail{}, err
+		}
+		msg.Attachments = append(msg.Attachments, mailer.Attachment{Name: attachment.Filename, Path: path})
+	}
+
+	return msg, nil
+}
+
+// parseAddresses parses each of the addresses, which may have a display name
+// with a comma.
+func parseAddresses(list []string) ([]mailer.Address, error) {
+	var addrs []mailer.Address
+	for _, s := range list {
+		addr, err := mailer.ParseAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// writeAttachment writes the content to a new file of dir, keeping the extension
+// of name for the content type to be detected.
+func writeAttachment(dir, name string, content []byte) (string, error) {
+	f, err := os.CreateTemp(dir, "*"+filepath.Ext(name))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(content); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
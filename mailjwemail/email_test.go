@@ -0,0 +1,46 @@
+package mailjwemail
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	mailer "github.com/caesar-rocks/mail"
+	"github.com/jordan-wright/email"
+)
+
+func TestFromEmail(t *testing.T) {
+	e := email.NewEmail()
+	e.From = "Info <info@test.com>"
+	e.To = []string{"a@test.com", "b@test.com"}
+	e.ReplyTo = []string{"reply@test.com"}
+	e.Subject = "Hello"
+	e.Text = []byte("Hello")
+	e.HTML = []byte("<p>Hello</p>")
+	if _, err := e.Attach(strings.NewReader("%PDF"), "report.pdf", "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := FromEmail(e, t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(msg.To, mailer.Addresses("a@test.com", "b@test.com")) || msg.From != (mailer.Address{Name: "Info", Email: "info@test.com"}) || msg.ReplyTo != (mailer.Address{Email: "reply@test.com"}) {
+		t.Errorf("Unexpected addresses %+v", msg)
+	}
+	if msg.Subject != "Hello" || msg.Text != "Hello" || msg.Html != "<p>Hello</p>" {
+		t.Errorf("Unexpected content %+v", msg)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Name != "report.pdf" {
+		t.Fatalf("Expected the attachment, got %+v", msg.Attachments)
+	}
+	if content, _ := os.ReadFile(msg.Attachments[0].Path); string(content) != "%PDF" {
+		t.Errorf("Expected the attachment content, got %q", content)
+	}
+
+	if _, err := FromEmail(e, ""); !errors.Is(err, ErrNoAttachmentDir) {
+		t.Errorf("Expected ErrNoAttachmentDir, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+// Package mailkafka sends the emails published on a Kafka topic.
+package mailkafka
+
+import (
+	"context"
+	"log"
+	"time"
+
+	mailer "github.com/caesar-rocks/mail"
+	"github.com/segmentio/kafka-go"
+)
+
+// Reader is the part of *kafka.Reader used by Consume. The reader must have a
+// GroupID for its offsets to be committed.
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Consume sends the JSON encoded emails, see mailer.DecodeMailJSON, read from the
+// topic until ctx is done. The offset of a message is committed once its email is
+// sent, failed sends are retried with a backoff of up to a minute so that the
+// partition keeps its order. Invalid messages are logged and skipped.
+func Consume(ctx context.Context, r Reader, m *mailer.Mailer) error {
+	for {
+		msg, err := r.FetchMessage(ctx)
+		if err != nil {
+			return err
+		}
+
+		email, err := mailer.DecodeMailJSON(msg.Value)
+		if err != nil {
+			log.Printf("mailkafka: skipping message at offset %d: %v", msg.Offset, err)
+		} else if err := send(ctx, m, email); err != nil {
+			return err
+		}
+
+		if err := r.CommitMessages(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// send sends the email until it succeeds, fails permanently or ctx is done.
+func send(ctx context.Context, m *mailer.Mailer, email mailer.Mail) error {
+	backoff := time.Second
+	for {
+		err := m.Send(email)
+		if err == nil {
+			return nil
+		}
+		if mailer.IsPermanentError(err) {
+			log.Printf("mailkafka: dropping email to %s: %v", email.To, err)
+			return nil
+		}
+		log.Printf("mailkafka: failed to send email to %s, retrying in %s: %v", email.To, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package mailkafka
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+
+	mailer "github.com/caesar-rocks/mail"
+	"github.com/segmentio/kafka-go"
+)
+
+type mockReader struct {
+	messages  []kafka.Message
+	committed []int64
+}
+
+func (r *mockReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if len(r.messages) == 0 {
+		return kafka.Message{}, context.Canceled
+	}
+	msg := r.messages[0]
+	r.messages = r.messages[1:]
+	return msg, nil
+}
+
+func (r *mockReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func TestConsume(t *testing.T) {
+	path, err := exec.LookPath("true")
+	if err != nil {
+		t.Skip("true binary not available")
+	}
+	m := mailer.NewMailer(mailer.MailCfg{APIService: mailer.SENDMAIL, SendmailPath: path})
+	defer m.Close()
+
+	r := &mockReader{messages: []kafka.Message{
+		{Offset: 1, Value: []byte(`{"from":"info@test.com","to":"a@test.com","subject":"test"}`)},
+		{Offset: 2, Value: []byte(`not json`)},
+		{Offset: 3, Value: []byte(`{"from":"info@test.com","to":"b@test.com","subject":"test"}`)},
+	}}
+
+	if err := Consume(context.Background(), r, m); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the reader error, got %v", err)
+	}
+	if len(r.committed) != 3 {
+		t.Errorf("Expected every message to be committed, got %v", r.committed)
+	}
+}
+
+func TestConsume_RetriesUntilCanceled(t *testing.T) {
+	path, err := exec.LookPath("false")
+	if err != nil {
+		t.Skip("false binary not available")
+	}
+	m := mailer.NewMailer(mailer.MailCfg{APIService: mailer.SENDMAIL, SendmailPath: path})
+	defer m.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &mockReader{messages: []kafka.Message{
+		{Offset: 1, Value: []byte(`{"from":"info@test.com","to":"a@test.com","subject":"test"}`)},
+	}}
+	cancel()
+
+	if err := Consume(ctx, r, m); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(r.committed) != 0 {
+		t.Errorf("Expected the failed message not to be committed, got %v", r.committed)
+	}
+}
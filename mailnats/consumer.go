@@ -0,0 +1,55 @@
+// Package mailnats sends the emails published on a NATS JetStream stream.
+package mailnats
+
+import (
+	"context"
+	"log"
+
+	mailer "github.com/caesar-rocks/mail"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ackMsg is the part of jetstream.Msg used to acknowledge messages.
+type ackMsg interface {
+	Data() []byte
+	Ack() error
+	Nak() error
+	Term() error
+}
+
+// Consume sends the JSON encoded emails, see mailer.DecodeMailJSON, consumed from
+// the JetStream consumer until ctx is done. Messages are acked once their email
+// is sent and nacked for redelivery when sending fails, invalid ones are terminated.
+func Consume(ctx context.Context, consumer jetstream.Consumer, m *mailer.Mailer) error {
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		handle(m, msg)
+	})
+	if err != nil {
+		return err
+	}
+	defer consumeCtx.Stop()
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func handle(m *mailer.Mailer, msg ackMsg) {
+	email, err := mailer.DecodeMailJSON(msg.Data())
+	if err != nil {
+		log.Printf("mailnats: dropping message: %v", err)
+		msg.Term()
+		return
+	}
+
+	if err := m.Send(email); err != nil {
+		if mailer.IsPermanentError(err) {
+			// Retrying would fail the same way.
+			msg.Term()
+			return
+		}
+		log.Printf("mailnats: failed to send email to %s: %v", email.To, err)
+		msg.Nak()
+		return
+	}
+	msg.Ack()
+}
@@ -0,0 +1,52 @@
+package mailnats
+
+import (
+	"os/exec"
+	"testing"
+
+	mailer "github.com/caesar-rocks/mail"
+)
+
+type mockMsg struct {
+	data  string
+	acked string
+}
+
+func (m *mockMsg) Data() []byte { return []byte(m.data) }
+func (m *mockMsg) Ack() error   { m.acked = "ack"; return nil }
+func (m *mockMsg) Nak() error   { m.acked = "nak"; return nil }
+func (m *mockMsg) Term() error  { m.acked = "term"; return nil }
+
+func newTestMailer(t *testing.T, binary string) *mailer.Mailer {
+	t.Helper()
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		t.Skipf("%s binary not available", binary)
+	}
+	m := mailer.NewMailer(mailer.MailCfg{APIService: mailer.SENDMAIL, SendmailPath: path})
+	t.Cleanup(m.Close)
+	return m
+}
+
+func TestHandle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		binary   string
+		data     string
+		expected string
+	}{
+		{name: "acks sent emails", binary: "true", data: `{"from":"info@test.com","to":"a@test.com","subject":"test"}`, expected: "ack"},
+		{name: "nacks failed emails", binary: "false", data: `{"from":"info@test.com","to":"a@test.com","subject":"test"}`, expected: "nak"},
+		{name: "terminates invalid messages", binary: "true", data: `{"subject":"no recipients"}`, expected: "term"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := &mockMsg{data: tc.data}
+			handle(newTestMailer(t, tc.binary), msg)
+			if msg.acked != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, msg.acked)
+			}
+		})
+	}
+}
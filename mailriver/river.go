@@ -0,0 +1,66 @@
+// Package mailriver sends emails as River jobs, leaving retries and scheduling
+// to River while the mailer handles the transport.
+package mailriver
+
+import (
+	"context"
+	"encoding/json"
+
+	mailer "github.com/caesar-rocks/mail"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// SendArgs are the arguments of the jobs sending an email.
+type SendArgs struct {
+	// Email is the JSON encoding of the email, see mailer.EncodeMailJSON.
+	Email json.RawMessage `json:"email"`
+}
+
+// Kind implements river.JobArgs.
+func (SendArgs) Kind() string { return "mail_send" }
+
+// NewSendArgs returns the arguments of a job sending the email.
+func NewSendArgs(msg mailer.Mail) (SendArgs, error) {
+	email, err := mailer.EncodeMailJSON(msg)
+	if err != nil {
+		return SendArgs{}, err
+	}
+	return SendArgs{Email: email}, nil
+}
+
+// Insert inserts a job sending the email, e.g. with InsertOpts.ScheduledAt to schedule it.
+func Insert[TTx any](ctx context.Context, client *river.Client[TTx], msg mailer.Mail, opts *river.InsertOpts) (*rivertype.JobInsertResult, error) {
+	args, err := NewSendArgs(msg)
+	if err != nil {
+		return nil, err
+	}
+	return client.Insert(ctx, args, opts)
+}
+
+// Worker works the jobs sending an email. Jobs whose email the mailer refuses to
+// send, e.g. expired or suppressed, are canceled rather than retried.
+type Worker struct {
+	river.WorkerDefaults[SendArgs]
+	Mailer *mailer.Mailer
+}
+
+// Work implements river.Worker.
+func (w *Worker) Work(ctx context.Context, job *river.Job[SendArgs]) error {
+	msg, err := mailer.DecodeMailJSON(job.Args.Email)
+	if err != nil {
+		return river.JobCancel(err)
+	}
+	if err := w.Mailer.Send(msg); err != nil {
+		if mailer.IsPermanentError(err) {
+			return river.JobCancel(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// AddWorker registers a Worker sending the emails with the mailer.
+func AddWorker(workers *river.Workers, m *mailer.Mailer) {
+	river.AddWorker(workers, &Worker{Mailer: m})
+}
@@ -0,0 +1,56 @@
+package mailriver
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+
+	mailer "github.com/caesar-rocks/mail"
+	"github.com/riverqueue/river"
+)
+
+func TestWorker_Work(t *testing.T) {
+	testCases := []struct {
+		name     string
+		binary   string
+		email    string
+		canceled bool
+		success  bool
+	}{
+		{name: "sends the email", binary: "true", email: `{"from":"info@test.com","to":"a@test.com"}`, success: true},
+		{name: "retries failed emails", binary: "false", email: `{"from":"info@test.com","to":"a@test.com"}`},
+		{name: "cancels invalid jobs", binary: "true", email: `{"subject":"no recipients"}`, canceled: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := exec.LookPath(tc.binary)
+			if err != nil {
+				t.Skipf("%s binary not available", tc.binary)
+			}
+			m := mailer.NewMailer(mailer.MailCfg{APIService: mailer.SENDMAIL, SendmailPath: path})
+			defer m.Close()
+
+			w := &Worker{Mailer: m}
+			err = w.Work(context.Background(), &river.Job[SendArgs]{Args: SendArgs{Email: []byte(tc.email)}})
+			if tc.success != (err == nil) {
+				t.Fatalf("Expected success %v, got %v", tc.success, err)
+			}
+			if canceled := errors.Is(err, river.JobCancel(nil)); canceled != tc.canceled {
+				t.Errorf("Expected canceled %v, got %v", tc.canceled, err)
+			}
+		})
+	}
+}
+
+func TestNewSendArgs(t *testing.T) {
+	args, err := NewSendArgs(mailer.Mail{To: mailer.Addresses("a@test.com"), Subject: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mailer.DecodeMailJSON(args.Email)
+	if err != nil || msg.Subject != "Hello" {
+		t.Errorf("Expected the email to round trip, got %+v %v", msg, err)
+	}
+}
@@ -0,0 +1,287 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const mandrillBaseURL = "https://mandrillapp.com/api/1.0"
+
+// MandrillOptions holds the Mandrill specific options of an email.
+type MandrillOptions struct {
+	// TemplateName is the name of a Mandrill template. When set, the template
+	// stored in Mandrill is rendered with the merge vars instead of the Html and
+	// Text of the email.
+	TemplateName string
+	// TemplateContent fills the editable regions of the template, by mc:edit name.
+	TemplateContent map[string]string
+	// GlobalMergeVars are the merge vars shared by every recipient, e.g. *|NAME|*.
+	GlobalMergeVars map[string]any
+	// MergeVars are the merge vars of each recipient, keyed by email address, which
+	// override the GlobalMergeVars.
+	MergeVars map[string]map[string]any
+	// MergeLanguage is "mailchimp" (default) or "handlebars".
+	MergeLanguage string
+	// Subaccount sends the email from a subaccount instead of the one of the
+	// configuration.
+	Subaccount string
+}
+
+type mandrillParams struct {
+	apiKey     string
+	subaccount string
+	userAgent  string
+	httpClient *http.Client
+}
+
+type mandrillMailer struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	subaccount string
+	userAgent  string
+}
+
+type mandrillRecipient struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type"`
+}
+
+type mandrillVar struct {
+	Name    string `json:"name"`
+	Content any    `json:"content"`
+}
+
+type mandrillMergeVars struct {
+	Rcpt string        `json:"rcpt"`
+	Vars []mandrillVar `json:"vars"`
+}
+
+type mandrillAttachment struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+type mandrillMessage struct {
+	Html               string               `json:"html,omitempty"`
+	Text               string               `json:"text,omitempty"`
+	Subject            string               `json:"subject,omitempty"`
+	FromEmail          string               `json:"from_email"`
+	FromName           string               `json:"from_name,omitempty"`
+	To                 []mandrillRecipient  `json:"to"`
+	Headers            map[string]string    `json:"headers,omitempty"`
+	PreserveRecipients bool                 `json:"preserve_recipients"`
+	Tags               []string             `json:"tags,omitempty"`
+	Subaccount         string               `json:"subaccount,omitempty"`
+	MergeLanguage      string               `json:"merge_language,omitempty"`
+	GlobalMergeVars    []mandrillVar        `json:"global_merge_vars,omitempty"`
+	MergeVars          []mandrillMergeVars  `json:"merge_vars,omitempty"`
+	Attachments        []mandrillAttachment `json:"attachments,omitempty"`
+}
+
+type mandrillSend struct {
+	Key             string          `json:"key"`
+	TemplateName    string          `json:"template_name,omitempty"`
+	TemplateContent any             `json:"template_content,omitempty"`
+	Message         mandrillMessage `json:"message"`
+}
+
+type mandrillResult struct {
+	Email        string `json:"email"`
+	Status       string `json:"status"`
+	RejectReason string `json:"reject_reason"`
+}
+
+type mandrillTemplate struct {
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Subject string `json:"subject,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Text    string `json:"text,omitempty"`
+	Publish bool   `json:"publish"`
+}
+
+func newMandrill(params mandrillParams) MailerClient {
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+
+	return &mandrillMailer{
+		httpClient: params.httpClient,
+		baseURL:    mandrillBaseURL,
+		apiKey:     params.apiKey,
+		subaccount: params.subaccount,
+		userAgent:  params.userAgent,
+	}
+}
+
+func (m *mandrillMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *mandrillMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	options := msg.Mandrill
+	if options == nil {
+		options = &MandrillOptions{}
+	}
+
+	payload := mandrillSend{
+		Key: m.apiKey,
+		Message: mandrillMessage{
+			Html:      msg.Html,
+			Text:      msg.Text,
+			Subject:   msg.Subject,
+			FromEmail: msg.From.Email,
+			FromName:  msg.From.Name,
+			Headers:   msg.Headers,
+			// The Cc recipients are only shown to the others when the recipients are preserved.
+			PreserveRecipients: len(msg.Cc) > 0,
+			Tags:               msg.Tags,
+			Subaccount:         m.subaccount,
+			MergeLanguage:      options.MergeLanguage,
+			GlobalMergeVars:    getMandrillVars(options.GlobalMergeVars),
+		},
+	}
+	if options.Subaccount != "" {
+		payload.Message.Subaccount = options.Subaccount
+	}
+	if msg.ReplyTo.Email != "" {
+		payload.Message.Headers = withHeader(Mail{Headers: payload.Message.Headers}, "Reply-To", msg.ReplyTo.String()).Headers
+	}
+	// Mandrill has a single list of recipients, typed to, cc or bcc.
+	for _, recipients := range []struct {
+		kind  string
+		addrs []Address
+	}{{"to", msg.To}, {"cc", msg.Cc}, {"bcc", msg.Bcc}} {
+		for _, addr := range recipients.addrs {
+			payload.Message.To = append(payload.Message.To, mandrillRecipient{Email: addr.Email, Name: addr.Name, Type: recipients.kind})
+		}
+	}
+	for _, recipient := range payload.Message.To {
+		if vars, ok := options.MergeVars[recipient.Email]; ok {
+			payload.Message.MergeVars = append(payload.Message.MergeVars, mandrillMergeVars{Rcpt: recipient.Email, Vars: getMandrillVars(vars)})
+		}
+	}
+
+	for _, attachment := range msg.Attachments {
+		content, err := encodeAttachmentBase64(attachment)
+		if err != nil {
+			return nil, err
+		}
+		payload.Message.Attachments = append(payload.Message.Attachments, mandrillAttachment{
+			Type:    getAttachmentContentType(attachment),
+			Name:    attachment.Name,
+			Content: content,
+		})
+	}
+
+	path := "/messages/send.json"
+	if options.TemplateName != "" {
+		path = "/messages/send-template.json"
+		payload.TemplateName = options.TemplateName
+		// Mandrill requires the template content, even when empty.
+		content := getMandrillStringVars(options.TemplateContent)
+		if content == nil {
+			content = []mandrillVar{}
+		}
+		payload.TemplateContent = content
+		payload.Message.Html, payload.Message.Text = "", ""
+	}
+
+	// Mandrill answers 200 with the status of each recipient, which may all have
+	// been rejected.
+	var results []mandrillResult
+	response, err := m.doWithResponse(context.Background(), path, payload, &results)
+	if err != nil {
+		return response, err
+	}
+	var rejections []string
+	for _, result := range results {
+		if result.Status != "rejected" && result.Status != "invalid" {
+			return response, nil
+		}
+		rejections = append(rejections, fmt.Sprintf("%s: %s %s", result.Email, result.Status, result.RejectReason))
+	}
+	if len(rejections) > 0 {
+		return response, fmt.Errorf("mandrill rejected the email: %s", strings.Join(rejections, "; "))
+	}
+	return response, nil
+}
+
+// getMandrillVars returns the vars sorted by name, for the payload to be stable.
+func getMandrillVars(values map[string]any) []mandrillVar {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var vars []mandrillVar
+	for _, name := range names {
+		vars = append(vars, mandrillVar{Name: name, Content: values[name]})
+	}
+	return vars
+}
+
+func getMandrillStringVars(values map[string]string) []mandrillVar {
+	var vars []mandrillVar
+	for _, name := range headerKeys(values) {
+		vars = append(vars, mandrillVar{Name: name, Content: values[name]})
+	}
+	return vars
+}
+
+func (m *mandrillMailer) do(ctx context.Context, path string, payload any, out any) error {
+	_, err := m.doWithResponse(ctx, path, payload, out)
+	return err
+}
+
+// doWithResponse posts the payload, every Mandrill call being a POST
+// authenticated by the key in its body.
+func (m *mandrillMailer) doWithResponse(ctx context.Context, path string, payload any, out any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(http.MethodPost, m.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, MANDRILL, req.WithContext(ctx), out)
+}
+
+func (m *mandrillMailer) CreateTemplate(ctx context.Context, tpl Template) error {
+	return m.do(ctx, "/templates/add.json", m.getTemplate(tpl), nil)
+}
+
+func (m *mandrillMailer) UpdateTemplate(ctx context.Context, tpl Template) error {
+	return m.do(ctx, "/templates/update.json", m.getTemplate(tpl), nil)
+}
+
+func (m *mandrillMailer) DeleteTemplate(ctx context.Context, name string) error {
+	return m.do(ctx, "/templates/delete.json", mandrillTemplate{Key: m.apiKey, Name: name}, nil)
+}
+
+func (m *mandrillMailer) getTemplate(tpl Template) mandrillTemplate {
+	return mandrillTemplate{
+		Key:     m.apiKey,
+		Name:    tpl.Name,
+		Subject: tpl.Subject,
+		Code:    tpl.Html,
+		Text:    tpl.Text,
+		Publish: true,
+	}
+}
+
+// Health verifies the API key with the ping endpoint.
+func (m *mandrillMailer) Health(ctx context.Context) error {
+	return m.do(ctx, "/users/ping.json", map[string]string{"key": m.apiKey}, nil)
+}
+
+func (m *mandrillMailer) Close() {
+	// Not implemented because the Mandrill API is stateless.
+}
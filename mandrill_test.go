@@ -0,0 +1,120 @@
+package mailer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMandrill_Send(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, map[string]string{
+		"POST /messages/send.json": `[{"email":"a@test.com","status":"sent","_id":"1"}]`,
+	})
+	mandrill := newMandrill(mandrillParams{apiKey: MailAPIKey, subaccount: "acme"}).(*mandrillMailer)
+	mandrill.baseURL = server.URL
+
+	err := mandrill.Send(Mail{
+		From:    Address{Name: "Acme", Email: "info@test.com"},
+		To:      Addresses("a@test.com"),
+		Cc:      Addresses("c@test.com"),
+		Bcc:     Addresses("b@test.com"),
+		Subject: "test",
+		Html:    "<p>*|NAME|*</p>",
+		Mandrill: &MandrillOptions{
+			GlobalMergeVars: map[string]any{"NAME": "friend"},
+			MergeVars:       map[string]map[string]any{"a@test.com": {"NAME": "Ada"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := requests[0]
+	if req.Path != "/messages/send.json" || req.Body["key"] != MailAPIKey {
+		t.Fatalf("Expected an authenticated request to /messages/send.json, got %s %v", req.Path, req.Body["key"])
+	}
+	message := req.Body["message"].(map[string]any)
+	to := message["to"].([]any)
+	for i, kind := range []string{"to", "cc", "bcc"} {
+		if recipient := to[i].(map[string]any); recipient["type"] != kind {
+			t.Errorf("Expected a %s recipient, got %v", kind, recipient)
+		}
+	}
+	if message["from_email"] != "info@test.com" || message["from_name"] != "Acme" || message["subaccount"] != "acme" {
+		t.Errorf("Expected the sender and the subaccount, got %v", message)
+	}
+	if message["preserve_recipients"] != true {
+		t.Errorf("Expected the recipients to be preserved with Cc recipients")
+	}
+	if vars := message["global_merge_vars"].([]any)[0].(map[string]any); vars["name"] != "NAME" || vars["content"] != "friend" {
+		t.Errorf("Expected the global merge vars, got %v", vars)
+	}
+	if vars := message["merge_vars"].([]any)[0].(map[string]any); vars["rcpt"] != "a@test.com" {
+		t.Errorf("Expected the merge vars of the recipient, got %v", vars)
+	}
+}
+
+func TestMandrill_SendTemplate(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, map[string]string{
+		"POST /messages/send-template.json": `[{"email":"a@test.com","status":"queued"}]`,
+	})
+	mandrill := newMandrill(mandrillParams{apiKey: MailAPIKey}).(*mandrillMailer)
+	mandrill.baseURL = server.URL
+
+	err := mandrill.Send(Mail{
+		From:     Address{Email: "info@test.com"},
+		To:       Addresses("a@test.com"),
+		Html:     "<p>ignored</p>",
+		Mandrill: &MandrillOptions{TemplateName: "welcome", Subaccount: "other"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := requests[0]
+	if req.Path != "/messages/send-template.json" || req.Body["template_name"] != "welcome" {
+		t.Fatalf("Expected the template to be sent, got %s %v", req.Path, req.Body)
+	}
+	if content, ok := req.Body["template_content"].([]any); !ok || len(content) != 0 {
+		t.Errorf("Expected an empty template content, got %v", req.Body["template_content"])
+	}
+	message := req.Body["message"].(map[string]any)
+	if _, ok := message["html"]; ok || message["subaccount"] != "other" {
+		t.Errorf("Expected the template without html from the subaccount of the email, got %v", message)
+	}
+}
+
+func TestMandrill_Rejected(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, map[string]string{
+		"POST /messages/send.json": `[{"email":"a@test.com","status":"rejected","reject_reason":"hard-bounce"}]`,
+	})
+	mandrill := newMandrill(mandrillParams{apiKey: MailAPIKey}).(*mandrillMailer)
+	mandrill.baseURL = server.URL
+
+	if err := mandrill.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com")}); err == nil {
+		t.Error("Expected an error when every recipient is rejected")
+	}
+}
+
+func TestMandrill_Templates(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	mandrill := newMandrill(mandrillParams{apiKey: MailAPIKey}).(*mandrillMailer)
+	mandrill.baseURL = server.URL
+
+	if err := mandrill.CreateTemplate(context.Background(), Template{Name: "welcome", Subject: "Hi", Html: "<p>Hi</p>"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := mandrill.DeleteTemplate(context.Background(), "welcome"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if req := requests[0]; req.Path != "/templates/add.json" || req.Body["code"] != "<p>Hi</p>" || req.Body["publish"] != true {
+		t.Errorf("Expected the template to be added and published, got %s %v", req.Path, req.Body)
+	}
+	if req := requests[1]; req.Path != "/templates/delete.json" || req.Body["name"] != "welcome" {
+		t.Errorf("Expected the template to be deleted, got %s %v", req.Path, req.Body)
+	}
+}
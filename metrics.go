@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// MetricsHandler returns an http.Handler exposing the Stats of the mailer in the
+// Prometheus text format, to mount as a /metrics endpoint.
+func (m *Mailer) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w, m.Stats())
+	})
+}
+
+func writeMetrics(w io.Writer, stats Stats) {
+	gauge := func(name string, help string, value float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+	}
+
+	gauge("mailer_queued", "Emails waiting to be sent.", float64(stats.Queued))
+	gauge("mailer_in_flight", "Emails being sent to the provider.", float64(stats.InFlight))
+	gauge("mailer_sends_per_minute", "Emails sent to the provider during the last minute.", float64(stats.SendsPerMinute))
+	gauge("mailer_error_rate", "Ratio of failed sends during the last minute.", stats.ErrorRate)
+
+	providers := make([]string, 0, len(stats.Providers))
+	for provider := range stats.Providers {
+		providers = append(providers, string(provider))
+	}
+	sort.Strings(providers)
+
+	fmt.Fprintf(w, "# HELP mailer_sends_total Sends to the provider by result.\n# TYPE mailer_sends_total counter\n")
+	for _, provider := range providers {
+		p := stats.Providers[APIServiceType(provider)]
+		fmt.Fprintf(w, "mailer_sends_total{provider=%q,result=\"sent\"} %d\n", provider, p.Sent)
+		fmt.Fprintf(w, "mailer_sends_total{provider=%q,result=\"failed\"} %d\n", provider, p.Failed)
+	}
+
+	gauge("mailer_cost_total", "Estimated cost of the emails sent.", stats.Cost.Total)
+
+	if connections := stats.Connections; connections != nil {
+		counter := func(name string, help string, value int) {
+			fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+		}
+		counter("mailer_connections_opened_total", "Connections opened to the SMTP server.", connections.Opened)
+		counter("mailer_connections_reused_total", "Sends over an already used connection.", connections.Reused)
+		counter("mailer_tls_handshakes_total", "TLS handshakes with the SMTP server.", connections.Handshakes)
+		counter("mailer_tls_resumed_handshakes_total", "TLS handshakes resuming a previous session.", connections.Resumed)
+		gauge("mailer_connect_seconds", "Average time to open a connection to the SMTP server.", connections.ConnectTime.Seconds())
+	}
+
+	if quota := stats.SendQuota; quota != nil {
+		gauge("mailer_send_quota_max_24h", "Emails the account can send per 24 hours.", quota.Max24HourSend)
+		gauge("mailer_send_quota_sent_24h", "Emails sent during the last 24 hours.", quota.SentLast24Hours)
+		gauge("mailer_send_quota_remaining", "Emails the account can still send during the current 24 hours.", quota.Remaining())
+		gauge("mailer_send_quota_max_rate", "Emails the account can send per second.", quota.MaxSendRate)
+		enabled := 0.0
+		if quota.SendingEnabled {
+			enabled = 1
+		}
+		gauge("mailer_sending_enabled", "Whether the provider lets the account send emails.", enabled)
+		if quota.Enforcement != "" {
+			fmt.Fprintf(w, "# HELP mailer_enforcement_status Standing of the account at the provider.\n# TYPE mailer_enforcement_status gauge\n")
+			fmt.Fprintf(w, "mailer_enforcement_status{status=%q} 1\n", quota.Enforcement)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package mailer
+
+// SendFunc sends an email.
+type SendFunc func(msg Mail) error
+
+// Middleware wraps the sending of emails, e.g. to transform, inspect or reject them.
+// Middlewares run after the PreferenceChecker and before the provider.
+type Middleware func(next SendFunc) SendFunc
+
+// chainMiddlewares wraps send with the middlewares, the first middleware being the outermost.
+func chainMiddlewares(send SendFunc, middlewares []Middleware) SendFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		send = middlewares[i](send)
+	}
+	return send
+}
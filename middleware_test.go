@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMailer_Middlewares(t *testing.T) {
+	var calls []string
+	tracing := func(name string) Middleware {
+		return func(next SendFunc) SendFunc {
+			return func(msg Mail) error {
+				calls = append(calls, name)
+				msg.Subject += " " + name
+				return next(msg)
+			}
+		}
+	}
+	blocked := errors.New("blocked")
+
+	var sent Mail
+	mailer := NewMailer(MailCfg{
+		APIService: RESEND,
+		APIKey:     MailAPIKey,
+		Middlewares: []Middleware{
+			tracing("first"),
+			tracing("second"),
+			func(next SendFunc) SendFunc {
+				return func(msg Mail) error {
+					if joinAddresses(msg.To) == "blocked@test.com" {
+						return blocked
+					}
+					return next(msg)
+				}
+			},
+		},
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			sent = msg
+			return nil
+		}},
+	})
+	defer mailer.Close()
+
+	if err := mailer.Send(Mail{To: Addresses("a@test.com"), Subject: "test"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if sent.Subject != "test first second" {
+		t.Errorf("Expected middlewares to run in order, got %q", sent.Subject)
+	}
+	if len(calls) != 2 || calls[0] != "first" {
+		t.Errorf("Expected first to be the outermost middleware, got %v", calls)
+	}
+
+	if err := mailer.Send(Mail{To: Addresses("blocked@test.com")}); !errors.Is(err, blocked) {
+		t.Errorf("Expected middleware error, got %v", err)
+	}
+}
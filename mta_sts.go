@@ -0,0 +1,188 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MTASTSMode is the mode of an MTA-STS policy (RFC 8461).
+type MTASTSMode string
+
+const (
+	MTASTSEnforce MTASTSMode = "enforce"
+	MTASTSTesting MTASTSMode = "testing"
+	MTASTSNone    MTASTSMode = "none"
+)
+
+// MTASTSPolicy is the MTA-STS policy of a recipient domain.
+type MTASTSPolicy struct {
+	// ID is the id of the policy, from the _mta-sts TXT record.
+	ID   string
+	Mode MTASTSMode
+	// MX are the patterns of the mail servers allowed to receive mail, e.g. *.example.com.
+	MX     []string
+	MaxAge time.Duration
+	// FetchedAt is the time at which the policy was fetched.
+	FetchedAt time.Time
+}
+
+// MatchesMX reports whether the MX host is allowed by the policy.
+func (p MTASTSPolicy) MatchesMX(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range p.MX {
+		pattern = strings.ToLower(pattern)
+		if strings.HasPrefix(pattern, "*.") {
+			// The wildcard only matches a single label.
+			if i := strings.IndexByte(host, '.'); i > 0 && host[i+1:] == pattern[2:] {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// MTASTSResolver fetches and caches the MTA-STS policies of recipient domains.
+type MTASTSResolver struct {
+	// Resolver resolves the _mta-sts TXT records. Defaults to net.DefaultResolver.
+	Resolver TXTResolver
+	// HTTPClient fetches the policies, it must verify the certificates.
+	// Defaults to a client with a 10 seconds timeout.
+	HTTPClient *http.Client
+
+	mu       sync.Mutex
+	policies map[string]MTASTSPolicy
+}
+
+// Policy returns the MTA-STS policy of the domain, or nil when the domain does not
+// publish one. Policies are cached for their max_age and refreshed when the id
+// of the TXT record changes.
+func (r *MTASTSResolver) Policy(ctx context.Context, domain string) (*MTASTSPolicy, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	resolver := r.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	records, err := lookupRecords(ctx, resolver, "_mta-sts."+domain, "v=STSv1")
+	if err != nil {
+		return r.cached(domain, ""), err
+	}
+	if len(records) != 1 {
+		// Without a valid record, a cached policy is still honored until it expires.
+		return r.cached(domain, ""), nil
+	}
+	id := parseTags(records[0])["id"]
+
+	if policy := r.cached(domain, id); policy != nil {
+		return policy, nil
+	}
+
+	policy, err := r.fetch(ctx, domain)
+	if err != nil {
+		return r.cached(domain, ""), err
+	}
+	policy.ID = id
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.policies == nil {
+		r.policies = make(map[string]MTASTSPolicy)
+	}
+	r.policies[domain] = policy
+	return &policy, nil
+}
+
+// cached returns the unexpired cached policy of the domain, matching the id when not empty.
+func (r *MTASTSResolver) cached(domain string, id string) *MTASTSPolicy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	policy, ok := r.policies[domain]
+	if !ok || time.Since(policy.FetchedAt) > policy.MaxAge || (id != "" && policy.ID != id) {
+		return nil
+	}
+	return &policy
+}
+
+func (r *MTASTSResolver) fetch(ctx context.Context, domain string) (MTASTSPolicy, error) {
+	client := r.HTTPClient
+	if client == nil {
+		client = &http.Client{
+			Timeout: 10 * time.Second,
+			// Redirects are not allowed when fetching policies.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://mta-sts."+domain+"/.well-known/mta-sts.txt", nil)
+	if err != nil {
+		return MTASTSPolicy{}, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return MTASTSPolicy{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return MTASTSPolicy{}, fmt.Errorf("mta-sts: unexpected status code %d for %s", res.StatusCode, domain)
+	}
+	return parseMTASTSPolicy(io.LimitReader(res.Body, 64*1024))
+}
+
+func parseMTASTSPolicy(r io.Reader) (MTASTSPolicy, error) {
+	policy := MTASTSPolicy{FetchedAt: time.Now()}
+	version := ""
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "version":
+			version = value
+		case "mode":
+			policy.Mode = MTASTSMode(value)
+		case "mx":
+			policy.MX = append(policy.MX, value)
+		case "max_age":
+			seconds, err := strconv.Atoi(value)
+			if err != nil {
+				return policy, fmt.Errorf("mta-sts: invalid max_age %q", value)
+			}
+			policy.MaxAge = time.Duration(seconds) * time.Second
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return policy, err
+	}
+
+	if version != "STSv1" {
+		return policy, fmt.Errorf("mta-sts: unsupported version %q", version)
+	}
+	switch policy.Mode {
+	case MTASTSEnforce, MTASTSTesting, MTASTSNone:
+	default:
+		return policy, fmt.Errorf("mta-sts: invalid mode %q", policy.Mode)
+	}
+	if policy.Mode != MTASTSNone && len(policy.MX) == 0 {
+		return policy, fmt.Errorf("mta-sts: policy has no mx")
+	}
+	return policy, nil
+}
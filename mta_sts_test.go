@@ -0,0 +1,122 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestParseMTASTSPolicy(t *testing.T) {
+	testCases := []struct {
+		name    string
+		body    string
+		mode    MTASTSMode
+		mx      int
+		maxAge  time.Duration
+		wantErr bool
+	}{
+		{
+			name:   "enforce policy",
+			body:   "version: STSv1\r\nmode: enforce\r\nmx: mail.test.com\r\nmx: *.test.net\r\nmax_age: 604800\r\n",
+			mode:   MTASTSEnforce,
+			mx:     2,
+			maxAge: 7 * 24 * time.Hour,
+		},
+		{
+			name:   "none policy without mx",
+			body:   "version: STSv1\nmode: none\nmax_age: 86400\n",
+			mode:   MTASTSNone,
+			maxAge: 24 * time.Hour,
+		},
+		{name: "unsupported version", body: "version: STSv2\nmode: enforce\nmx: mail.test.com\n", wantErr: true},
+		{name: "invalid mode", body: "version: STSv1\nmode: strict\nmx: mail.test.com\n", wantErr: true},
+		{name: "enforce without mx", body: "version: STSv1\nmode: enforce\nmax_age: 86400\n", wantErr: true},
+		{name: "invalid max_age", body: "version: STSv1\nmode: testing\nmx: mail.test.com\nmax_age: soon\n", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := parseMTASTSPolicy(strings.NewReader(tc.body))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if policy.Mode != tc.mode || len(policy.MX) != tc.mx || policy.MaxAge != tc.maxAge {
+				t.Errorf("Unexpected policy %+v", policy)
+			}
+		})
+	}
+}
+
+func TestMTASTSPolicy_MatchesMX(t *testing.T) {
+	policy := MTASTSPolicy{MX: []string{"mail.test.com", "*.test.net"}}
+
+	testCases := map[string]bool{
+		"mail.test.com":   true,
+		"MAIL.test.com.":  true,
+		"mx1.test.net":    true,
+		"a.mx1.test.net":  false,
+		"test.net":        false,
+		"other.test.com":  false,
+		"mail.test.com.x": false,
+	}
+	for host, expected := range testCases {
+		if got := policy.MatchesMX(host); got != expected {
+			t.Errorf("MatchesMX(%q) = %v, expected %v", host, got, expected)
+		}
+	}
+}
+
+func TestMTASTSResolver_Policy(t *testing.T) {
+	fetches := 0
+	resolver := &MTASTSResolver{
+		Resolver: mockTXTResolver{
+			"_mta-sts.test.com": {"v=STSv1; id=20240101"},
+		},
+		HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			fetches++
+			if req.URL.String() != "https://mta-sts.test.com/.well-known/mta-sts.txt" {
+				t.Errorf("Unexpected URL %s", req.URL)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("version: STSv1\nmode: enforce\nmx: mail.test.com\nmax_age: 86400\n")),
+			}, nil
+		})},
+	}
+
+	for i := 0; i < 2; i++ {
+		policy, err := resolver.Policy(context.Background(), "test.com")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if policy == nil || policy.ID != "20240101" || policy.Mode != MTASTSEnforce {
+			t.Fatalf("Unexpected policy %+v", policy)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected the policy to be fetched once, got %d fetches", fetches)
+	}
+
+	policy, err := resolver.Policy(context.Background(), "other.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if policy != nil {
+		t.Errorf("Expected no policy, got %+v", policy)
+	}
+}
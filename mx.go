@@ -0,0 +1,346 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultMXPort = "25"
+
+// MXResolver resolves MX records, it is implemented by *net.Resolver.
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+type mxParams struct {
+	helloName  string
+	port       string
+	timeout    int
+	retries    int
+	retryDelay time.Duration
+	tlsPolicy  *DeliveryTLSPolicy
+	resolver   MXResolver
+	clock      Clock
+	dial       DialOptions
+}
+
+type mxMailer struct {
+	helloName  string
+	port       string
+	timeout    time.Duration
+	retries    int
+	retryDelay time.Duration
+	tlsPolicy  *DeliveryTLSPolicy
+	resolver   MXResolver
+	clock      Clock
+	dialer     *dialer
+	conns      *connTracker
+
+	mu sync.Mutex
+	// idle holds one reusable connection per recipient domain.
+	idle map[string]*mxSession
+}
+
+// mxSession is a connection to an MX host, kept with its net.Conn to extend the
+// deadline of every transaction.
+type mxSession struct {
+	*smtp.Client
+	conn net.Conn
+}
+
+// extend sets the deadline of the next exchanges, a reused session not being
+// cut off by the deadline of its dial in the middle of a transaction.
+func (s *mxSession) extend(timeout time.Duration) {
+	s.conn.SetDeadline(time.Now().Add(timeout))
+}
+
+// newMX creates a client delivering directly to the MX hosts of the recipient
+// domains, without a smarthost.
+func newMX(params mxParams) MailerClient {
+	m := &mxMailer{
+		helloName:  params.helloName,
+		port:       params.port,
+		timeout:    time.Duration(params.timeout) * time.Second,
+		retries:    params.retries,
+		retryDelay: params.retryDelay,
+		tlsPolicy:  params.tlsPolicy,
+		resolver:   params.resolver,
+		clock:      params.clock,
+		dialer:     newDialer(params.dial, time.Duration(params.timeout)*time.Second),
+		conns:      newConnTracker(),
+		idle:       make(map[string]*mxSession),
+	}
+	if m.helloName == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "localhost"
+		}
+		m.helloName = hostname
+	}
+	if m.port == "" {
+		m.port = defaultMXPort
+	}
+	if m.timeout == 0 {
+		m.timeout = 30 * time.Second
+	}
+	if m.retryDelay == 0 {
+		m.retryDelay = 5 * time.Second
+	}
+	if m.tlsPolicy == nil {
+		m.tlsPolicy = &DeliveryTLSPolicy{}
+	}
+	if m.resolver == nil {
+		m.resolver = net.DefaultResolver
+	}
+	if m.clock == nil {
+		m.clock = systemClock{}
+	}
+	return m
+}
+
+// PartialDeliveryError is returned when an email was delivered to some of its
+// recipients only, naming the recipients it failed for so that a retry or a
+// spool replay only targets them.
+type PartialDeliveryError struct {
+	Failed []string
+	Err    error
+}
+
+func (e *PartialDeliveryError) Error() string {
+	return fmt.Sprintf("delivery failed for %s: %v", strings.Join(e.Failed, ", "), e.Err)
+}
+
+func (e *PartialDeliveryError) Unwrap() error { return e.Err }
+
+// Send delivers one copy of the message per recipient domain.
+func (m *mxMailer) Send(msg Mail) error {
+	return m.sendEnvelopeChunks(msg, 0)
+}
+
+// sendEnvelopeChunks delivers one copy of the message per recipient domain, in
+// transactions of at most limit recipients.
+func (m *mxMailer) sendEnvelopeChunks(msg Mail, limit int) error {
+	message, email := buildMessage(msg)
+
+	if email.Error != nil {
+		return email.Error
+	}
+
+	rcpts := email.GetRecipients()
+	if len(msg.EnvelopeTo) > 0 {
+		rcpts = msg.EnvelopeTo
+	}
+	return m.sendRaw(context.Background(), email.GetFrom(), rcpts, message, msg.DeliveryNotification, limit)
+}
+
+// SendRaw delivers one copy of the message as-is per recipient domain.
+func (m *mxMailer) SendRaw(ctx context.Context, from string, rcpts []string, message []byte) error {
+	return m.sendRaw(ctx, from, rcpts, string(message), nil, 0)
+}
+
+// sendRaw delivers the message to every domain even when one fails. The
+// failure of some of the domains only is a *PartialDeliveryError.
+func (m *mxMailer) sendRaw(ctx context.Context, from string, rcpts []string, message string, dsn *DeliveryNotification, limit int) error {
+	var domains []string
+	recipients := make(map[string][]string)
+	for _, rcpt := range rcpts {
+		domain := strings.ToLower(rcpt[strings.LastIndex(rcpt, "@")+1:])
+		if _, ok := recipients[domain]; !ok {
+			domains = append(domains, domain)
+		}
+		recipients[domain] = append(recipients[domain], rcpt)
+	}
+
+	var failed []string
+	var errs []error
+	for _, domain := range domains {
+		domainRcpts := recipients[domain]
+		size := len(domainRcpts)
+		if limit > 0 {
+			size = limit
+		}
+		for start := 0; start < len(domainRcpts); start += size {
+			chunk := domainRcpts[start:min(start+size, len(domainRcpts))]
+			if err := m.sendDomain(ctx, domain, from, chunk, message, dsn); err != nil {
+				failed = append(failed, chunk...)
+				errs = append(errs, fmt.Errorf("%s: %w", domain, err))
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	err := fmt.Errorf("mx delivery failed: %w", errors.Join(errs...))
+	if len(failed) < len(rcpts) {
+		return &PartialDeliveryError{Failed: failed, Err: err}
+	}
+	return err
+}
+
+// sendDomain tries every MX host of the domain by preference, retrying transient
+// failures up to the configured number of times.
+func (m *mxMailer) sendDomain(ctx context.Context, domain string, from string, rcpts []string, message string, dsn *DeliveryNotification) error {
+	var err error
+	for attempt := 0; attempt <= m.retries; attempt++ {
+		if attempt > 0 {
+			m.clock.Sleep(time.Duration(attempt) * m.retryDelay)
+		}
+
+		var hosts []string
+		hosts, err = m.lookupMX(ctx, domain)
+		if err != nil {
+			if isPermanentSMTPError(err) {
+				return err
+			}
+			continue
+		}
+
+		for _, host := range hosts {
+			err = m.deliver(ctx, domain, host, from, rcpts, message, dsn)
+			if err == nil || isPermanentSMTPError(err) {
+				return err
+			}
+		}
+	}
+	return err
+}
+
+// lookupMX returns the MX hosts of the domain by preference, falling back to the
+// domain itself when it has no MX records (RFC 5321 section 5.1).
+func (m *mxMailer) lookupMX(ctx context.Context, domain string) ([]string, error) {
+	records, err := m.resolver.LookupMX(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return []string{domain}, nil
+		}
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []string{domain}, nil
+	}
+	if len(records) == 1 && records[0].Host == "." {
+		return nil, &textproto.Error{Code: 556, Msg: domain + " does not accept mail (null MX)"}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Pref < records[j].Pref })
+	hosts := make([]string, 0, len(records))
+	for _, record := range records {
+		hosts = append(hosts, strings.TrimSuffix(record.Host, "."))
+	}
+	return hosts, nil
+}
+
+func (m *mxMailer) deliver(ctx context.Context, domain string, host string, from string, rcpts []string, message string, dsn *DeliveryNotification) error {
+	client, err := m.conn(ctx, domain, host)
+	if err != nil {
+		return err
+	}
+
+	client.extend(m.timeout)
+	err = sendTransaction(client.Client, from, rcpts, message, dsn)
+	var protoErr *textproto.Error
+	if err != nil && !errors.As(err, &protoErr) {
+		client.Close()
+		return err
+	}
+
+	// A rejected transaction leaves the session usable for the next message,
+	// it is reset before being reused.
+	m.mu.Lock()
+	if _, ok := m.idle[domain]; !ok {
+		m.idle[domain] = client
+		client = nil
+	}
+	m.mu.Unlock()
+	if client != nil {
+		client.Quit()
+	}
+	return err
+}
+
+// conn returns the idle connection of the domain when it is still usable, or
+// opens a new one to the host.
+func (m *mxMailer) conn(ctx context.Context, domain string, host string) (*mxSession, error) {
+	m.mu.Lock()
+	client, ok := m.idle[domain]
+	delete(m.idle, domain)
+	m.mu.Unlock()
+
+	if ok {
+		client.extend(m.timeout)
+		if err := client.Reset(); err == nil {
+			m.conns.reused()
+			return client, nil
+		}
+		client.Close()
+	}
+	return m.dial(ctx, domain, host)
+}
+
+func (m *mxMailer) dial(ctx context.Context, domain string, host string) (*mxSession, error) {
+	requirement, err := m.tlsPolicy.Requirement(ctx, domain, host)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	conn, err := m.dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, m.port))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(m.timeout))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := client.Hello(m.helloName); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	startTLS, _ := client.Extension("STARTTLS")
+	if err := requirement.Check(startTLS); err != nil {
+		client.Close()
+		return nil, err
+	}
+	if startTLS {
+		if err := client.StartTLS(m.conns.tlsConfig(requirement.TLSConfig(host))); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	m.conns.opened(time.Since(start))
+	return &mxSession{Client: client, conn: conn}, nil
+}
+
+// isPermanentSMTPError reports whether the error is a 5xx reply, which must not be retried.
+func isPermanentSMTPError(err error) bool {
+	var protoErr *textproto.Error
+	return errors.As(err, &protoErr) && protoErr.Code >= 500
+}
+
+func (m *mxMailer) ConnectionStats() ConnectionStats {
+	return m.conns.snapshot()
+}
+
+func (m *mxMailer) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for domain, client := range m.idle {
+		client.Quit()
+		delete(m.idle, domain)
+	}
+}
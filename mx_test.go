@@ -0,0 +1,224 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type mockMXResolver map[string][]*net.MX
+
+func (m mockMXResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	records, ok := m[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+// serveSMTP runs a minimal SMTP server rejecting the recipients listed in reject,
+// it returns the listener and the number of sessions opened.
+func serveSMTP(t *testing.T, reject map[string]bool) (net.Listener, *int32) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	var sessions int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&sessions, 1)
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				write := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+				write("220 localhost ESMTP ready")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					line = strings.TrimRight(line, "\r\n")
+
+					switch {
+					case strings.HasPrefix(line, "EHLO"):
+						write("250-localhost")
+						write("250 8BITMIME")
+					case strings.HasPrefix(line, "RCPT TO:"):
+						if reject[strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>")] {
+							write("550 no such user")
+						} else {
+							write("250 ok")
+						}
+					case line == "DATA":
+						write("354 go ahead")
+						for {
+							data, err := r.ReadString('\n')
+							if err != nil || data == ".\r\n" {
+								break
+							}
+						}
+						write("250 queued")
+					case line == "QUIT":
+						write("221 bye")
+						return
+					default:
+						write("250 ok")
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return ln, &sessions
+}
+
+func newTestMX(ln net.Listener, resolver MXResolver, tlsPolicy *DeliveryTLSPolicy) *mxMailer {
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	return newMX(mxParams{
+		helloName:  "test.local",
+		port:       port,
+		timeout:    2,
+		retries:    1,
+		retryDelay: time.Millisecond,
+		tlsPolicy:  tlsPolicy,
+		resolver:   resolver,
+	}).(*mxMailer)
+}
+
+func TestMX_Send(t *testing.T) {
+	resolver := mockMXResolver{
+		"test.com":   {{Host: "127.0.0.1.", Pref: 10}},
+		"backup.com": {{Host: "127.0.0.1.", Pref: 20}, {Host: "127.0.0.2.", Pref: 10}},
+		"null.com":   {{Host: ".", Pref: 0}},
+	}
+
+	testCases := []struct {
+		name    string
+		msg     Mail
+		reject  map[string]bool
+		policy  *DeliveryTLSPolicy
+		errPart string
+	}{
+		{
+			name: "Should deliver to every domain, falling back to the next MX",
+			msg:  Mail{From: Address{Email: "info@sender.com"}, To: Addresses("a@test.com"), Cc: Addresses("b@backup.com"), Subject: "test", Text: "test"},
+		},
+		{
+			name:    "Should not retry permanent failures",
+			msg:     Mail{From: Address{Email: "info@sender.com"}, To: Addresses("a@test.com"), Subject: "test", Text: "test"},
+			reject:  map[string]bool{"a@test.com": true},
+			errPart: "test.com: 550",
+		},
+		{
+			name:    "Should refuse domains with a null MX",
+			msg:     Mail{From: Address{Email: "info@sender.com"}, To: Addresses("a@null.com"), Subject: "test", Text: "test"},
+			errPart: "null MX",
+		},
+		{
+			name:    "Should refuse plaintext delivery when DANE requires TLS",
+			msg:     Mail{From: Address{Email: "info@sender.com"}, To: Addresses("a@test.com"), Subject: "test", Text: "test"},
+			policy:  &DeliveryTLSPolicy{DANE: &mockTLSAResolver{records: []TLSARecord{{Usage: 3, Selector: 1, MatchingType: 1}}, authenticated: true}},
+			errPart: ErrTLSRequired.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ln, _ := serveSMTP(t, tc.reject)
+			mx := newTestMX(ln, resolver, tc.policy)
+			defer mx.Close()
+
+			err := mx.Send(tc.msg)
+			if tc.errPart == "" && err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if tc.errPart != "" && (err == nil || !strings.Contains(err.Error(), tc.errPart)) {
+				t.Errorf("Expected error containing %q, got %v", tc.errPart, err)
+			}
+		})
+	}
+}
+
+func TestMX_PartialDelivery(t *testing.T) {
+	ln, _ := serveSMTP(t, nil)
+	// Nothing listens on 127.0.0.2, the down.com deliveries are refused.
+	mx := newTestMX(ln, mockMXResolver{
+		"test.com": {{Host: "127.0.0.1.", Pref: 10}},
+		"down.com": {{Host: "127.0.0.2.", Pref: 10}},
+	}, nil)
+	defer mx.Close()
+
+	msg := Mail{From: Address{Email: "info@sender.com"}, To: Addresses("a@test.com", "b@down.com"), Cc: Addresses("c@down.com"), Subject: "test", Text: "test"}
+	err := mx.Send(msg)
+	var partial *PartialDeliveryError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Expected a partial delivery, got %v", err)
+	}
+	if !slices.Equal(partial.Failed, []string{"b@down.com", "c@down.com"}) || !isProviderDown(err) {
+		t.Errorf("Expected the down.com recipients to fail on a transient error, got %v", err)
+	}
+
+	msg.EnvelopeTo = []string{"a@test.com"}
+	if err := mx.Send(msg); err != nil {
+		t.Errorf("Expected the delivery to the envelope recipients only, got %v", err)
+	}
+	msg.To = Addresses("b@down.com")
+	msg.EnvelopeTo = nil
+	if err := mx.Send(msg); errors.As(err, &partial) || err == nil {
+		t.Errorf("Expected a plain failure when every recipient fails, got %v", err)
+	}
+}
+
+func TestMX_ReusesDomainConnection(t *testing.T) {
+	ln, sessions := serveSMTP(t, map[string]bool{"rejected@test.com": true})
+	mx := newTestMX(ln, mockMXResolver{"test.com": {{Host: "127.0.0.1.", Pref: 10}}}, nil)
+	defer mx.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := mx.Send(Mail{From: Address{Email: "info@sender.com"}, To: Addresses("a@test.com"), Subject: "test", Text: "test"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		// The session survives a rejected transaction.
+		if err := mx.Send(Mail{From: Address{Email: "info@sender.com"}, To: Addresses("rejected@test.com"), Subject: "test", Text: "test"}); err == nil {
+			t.Fatalf("Expected the recipient to be rejected")
+		}
+	}
+	if n := atomic.LoadInt32(sessions); n != 1 {
+		t.Errorf("Expected 1 session, got %d", n)
+	}
+	if stats := mx.ConnectionStats(); stats.Opened != 1 || stats.Reused != 5 {
+		t.Errorf("Expected 1 connection reused 5 times, got %+v", stats)
+	}
+}
+
+func TestMX_ReusedConnectionGetsANewDeadline(t *testing.T) {
+	ln, sessions := serveSMTP(t, nil)
+	mx := newTestMX(ln, mockMXResolver{"test.com": {{Host: "127.0.0.1.", Pref: 10}}}, nil)
+	mx.timeout = 100 * time.Millisecond
+	defer mx.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := mx.Send(Mail{From: Address{Email: "info@sender.com"}, To: Addresses("a@test.com"), Subject: "test", Text: "test"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		// Past the deadline of the dial, the idle connection is still usable.
+		time.Sleep(150 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(sessions); n != 1 {
+		t.Errorf("Expected 1 session, got %d", n)
+	}
+}
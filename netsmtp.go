@@ -0,0 +1,186 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"slices"
+	"strings"
+)
+
+// ErrUnsupportedMessage is returned by SendMail for messages it cannot convert
+// into an email, e.g. with attachments.
+var ErrUnsupportedMessage = errors.New("unsupported message")
+
+// SendMail has the signature of smtp.SendMail, so that code written against the
+// standard library sends through the mailer by swapping the function:
+//
+//	sendMail := m.SendMail // was smtp.SendMail
+//
+// The addr and auth are ignored in favor of the configured API service. The message
+// is sent as-is through SendRaw when the API service supports raw messages, e.g.
+// SMTP or Amazon SES. Otherwise it is converted into an email, which only
+// supports a text and/or html body and drops the headers other than the
+// addresses and the subject, the recipients missing from its To and Cc headers
+// being sent as Bcc.
+func (m *Mailer) SendMail(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	if _, ok := m.mailerClient.(RawSender); ok {
+		return m.SendRaw(context.Background(), from, to, bytes.NewReader(msg))
+	}
+
+	email, err := parseMessage(msg, false)
+	if err != nil {
+		return err
+	}
+	if email.From.Email == "" {
+		email.From = Address{Email: from}
+	}
+
+	visible := append(addressEmails(email.To), addressEmails(email.Cc)...)
+	for _, rcpt := range to {
+		if !slices.Contains(visible, rcpt) {
+			email.Bcc = append(email.Bcc, Address{Email: rcpt})
+		}
+	}
+
+	return m.Send(email)
+}
+
+// parseMessage converts an RFC 5322 message with a text and/or html body into an email.
+// Attachments and other parts fail the conversion unless skipParts is set.
+func parseMessage(msg []byte, skipParts bool) (Mail, error) {
+	parsed, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		return Mail{}, fmt.Errorf("%w: %v", ErrUnsupportedMessage, err)
+	}
+
+	var dec mime.WordDecoder
+	subject, err := dec.DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		subject = parsed.Header.Get("Subject")
+	}
+	email := Mail{Subject: subject}
+	for _, field := range []struct {
+		key   string
+		addrs *[]Address
+	}{{"To", &email.To}, {"Cc", &email.Cc}} {
+		if *field.addrs, err = headerAddresses(parsed.Header, field.key); err != nil {
+			return Mail{}, err
+		}
+	}
+	for _, field := range []struct {
+		key  string
+		addr *Address
+	}{{"From", &email.From}, {"Reply-To", &email.ReplyTo}} {
+		addrs, err := headerAddresses(parsed.Header, field.key)
+		if err != nil {
+			return Mail{}, err
+		}
+		if len(addrs) > 0 {
+			*field.addr = addrs[0]
+		}
+	}
+
+	header := mimeHeader{
+		contentType: parsed.Header.Get("Content-Type"),
+		encoding:    parsed.Header.Get("Content-Transfer-Encoding"),
+	}
+	if err := parseBody(&email, header, parsed.Body, skipParts); err != nil {
+		return Mail{}, err
+	}
+	return email, nil
+}
+
+// headerAddresses returns the addresses of the header, their display names
+// decoded.
+func headerAddresses(header mail.Header, key string) ([]Address, error) {
+	if header.Get(key) == "" {
+		return nil, nil
+	}
+	list, err := header.AddressList(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s header: %v", ErrUnsupportedMessage, key, err)
+	}
+	addresses := make([]Address, len(list))
+	for i, address := range list {
+		addresses[i] = Address{Name: address.Name, Email: address.Address}
+	}
+	return addresses, nil
+}
+
+type mimeHeader struct {
+	contentType string
+	encoding    string
+	disposition string
+}
+
+// parseBody sets the text and html of the email from a part of the message,
+// walking multipart parts.
+func parseBody(email *Mail, header mimeHeader, body io.Reader, skipParts bool) error {
+	mediaType, params, err := mime.ParseMediaType(header.contentType)
+	if header.contentType == "" {
+		mediaType, err = "text/plain", nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedMessage, err)
+	}
+	if disposition, dparams, _ := mime.ParseMediaType(header.disposition); disposition == "attachment" || dparams["filename"] != "" {
+		if skipParts {
+			return nil
+		}
+		return fmt.Errorf("%w: attachments are not supported", ErrUnsupportedMessage)
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := r.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrUnsupportedMessage, err)
+			}
+			err = parseBody(email, mimeHeader{
+				contentType: part.Header.Get("Content-Type"),
+				encoding:    part.Header.Get("Content-Transfer-Encoding"),
+				disposition: part.Header.Get("Content-Disposition"),
+			}, part, skipParts)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(header.encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedMessage, err)
+	}
+
+	switch mediaType {
+	case "text/plain":
+		email.Text = string(content)
+	case "text/html":
+		email.Html = string(content)
+	default:
+		if skipParts {
+			return nil
+		}
+		return fmt.Errorf("%w: %s parts are not supported", ErrUnsupportedMessage, mediaType)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMailer_SendMail(t *testing.T) {
+	sent := make(chan Mail, 1)
+	m := NewMailer(MailCfg{mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+		sent <- msg
+		return nil
+	}}})
+	defer m.Close()
+
+	msg := strings.Join([]string{
+		`From: "Info" <info@test.com>`,
+		`To: "Doe, John" <a@test.com>`,
+		`Cc: b@test.com`,
+		`Subject: =?utf-8?q?Caf=C3=A9?=`,
+		`Content-Type: multipart/alternative; boundary="b"`,
+		``,
+		`--b`,
+		`Content-Type: text/plain; charset=utf-8`,
+		`Content-Transfer-Encoding: quoted-printable`,
+		``,
+		`Caf=C3=A9`,
+		`--b`,
+		`Content-Type: text/html; charset=utf-8`,
+		`Content-Transfer-Encoding: base64`,
+		``,
+		`PHA+Q2Fmw6k8L3A+`,
+		`--b--`,
+	}, "\r\n")
+
+	err := m.SendMail("smtp.test.com:25", nil, "bounce@test.com", []string{"a@test.com", "b@test.com", "c@test.com"}, []byte(msg))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	email := <-sent
+	if email.From.String() != `"Info" <info@test.com>` || joinAddresses(email.To) != `"Doe, John" <a@test.com>` || joinAddresses(email.Cc) != "b@test.com" || joinAddresses(email.Bcc) != "c@test.com" {
+		t.Errorf("Unexpected addresses %+v", email)
+	}
+	if email.Subject != "Café" || email.Text != "Café" || email.Html != "<p>Café</p>" {
+		t.Errorf("Unexpected content %q %q %q", email.Subject, email.Text, email.Html)
+	}
+}
+
+func TestMailer_SendMailAttachments(t *testing.T) {
+	m := NewMailer(MailCfg{mailerClient: &mockMailerClient{}})
+	defer m.Close()
+
+	msg := strings.Join([]string{
+		`From: info@test.com`,
+		`To: a@test.com`,
+		`Content-Type: multipart/mixed; boundary="b"`,
+		``,
+		`--b`,
+		`Content-Type: text/plain`,
+		``,
+		`Hello`,
+		`--b`,
+		`Content-Type: application/pdf`,
+		`Content-Disposition: attachment; filename="report.pdf"`,
+		``,
+		`%PDF`,
+		`--b--`,
+	}, "\r\n")
+
+	err := m.SendMail("", nil, "info@test.com", []string{"a@test.com"}, []byte(msg))
+	if !errors.Is(err, ErrUnsupportedMessage) {
+		t.Errorf("Expected ErrUnsupportedMessage, got %v", err)
+	}
+}
+
+type recordingRawClient struct {
+	mockMailerClient
+	from    string
+	rcpts   []string
+	message []byte
+}
+
+func (c *recordingRawClient) SendRaw(ctx context.Context, from string, rcpts []string, message []byte) error {
+	c.from, c.rcpts, c.message = from, rcpts, message
+	return nil
+}
+
+func TestMailer_SendMailRaw(t *testing.T) {
+	client := &recordingRawClient{}
+	m := NewMailer(MailCfg{mailerClient: client})
+	defer m.Close()
+
+	msg := strings.Join([]string{
+		`From: info@test.com`,
+		`To: a@test.com`,
+		`X-Campaign: spring`,
+		`Content-Type: multipart/mixed; boundary="b"`,
+		``,
+		`--b`,
+		`Content-Type: text/plain; charset=iso-8859-1`,
+		``,
+		`Hello`,
+		`--b`,
+		`Content-Type: application/pdf`,
+		`Content-Disposition: attachment; filename="report.pdf"`,
+		``,
+		`%PDF`,
+		`--b--`,
+	}, "\r\n")
+
+	if err := m.SendMail("", nil, "bounce@test.com", []string{"a@test.com", "b@test.com"}, []byte(msg)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.from != "bounce@test.com" || strings.Join(client.rcpts, ",") != "a@test.com,b@test.com" || string(client.message) != msg {
+		t.Errorf("Expected the message to be sent as-is, got %q to %v: %q", client.from, client.rcpts, client.message)
+	}
+}
@@ -0,0 +1,22 @@
+package mailer
+
+import "context"
+
+type nullMailer struct{}
+
+// newNull creates a client discarding the emails once built, so that the rest of
+// the pipeline is exercised without a mail server.
+func newNull() MailerClient {
+	return nullMailer{}
+}
+
+func (nullMailer) Send(msg Mail) error {
+	_, email := buildMessage(msg)
+	return email.Error
+}
+
+func (nullMailer) SendRaw(ctx context.Context, from string, rcpts []string, message []byte) error {
+	return nil
+}
+
+func (nullMailer) Close() {}
@@ -0,0 +1,40 @@
+package mailer
+
+import mail "github.com/xhit/go-simple-mail/v2"
+
+// OVH has no HTTP API to send emails, the OVHcloud email offers send through
+// their SMTP servers, over implicit TLS.
+const (
+	defaultOVHHost = "ssl0.ovh.net"
+	defaultOVHPort = "465"
+)
+
+// getOVHSMTPParams returns the SMTP parameters of the OVHcloud mail servers, the
+// Host and the Port of the configuration overriding the MX Plan ones, e.g. with
+// "pro1.mail.ovh.net" and "587" for Email Pro, which uses STARTTLS.
+func getOVHSMTPParams(cfg MailCfg) smtpParams {
+	params := smtpParams{
+		Host:       cfg.Host,
+		Port:       cfg.Port,
+		Username:   cfg.HostUser,
+		Password:   cfg.HostPassword,
+		KeepAlive:  cfg.KeepAlive,
+		Timeout:    cfg.Timeout,
+		useTLS:     cfg.UseTLS,
+		encryption: mail.EncryptionSSLTLS,
+		dial:       cfg.Dial,
+	}
+	if params.Host == "" {
+		params.Host = defaultOVHHost
+	}
+	if params.Port == "" {
+		params.Port = defaultOVHPort
+	}
+	if params.Port != defaultOVHPort {
+		params.encryption = mail.EncryptionSTARTTLS
+	}
+	if params.Timeout == 0 {
+		params.Timeout = 10
+	}
+	return params
+}
@@ -0,0 +1,25 @@
+package mailer
+
+import (
+	"testing"
+
+	mail "github.com/xhit/go-simple-mail/v2"
+)
+
+func TestGetOVHSMTPParams(t *testing.T) {
+	params := getOVHSMTPParams(MailCfg{APIService: OVH, HostUser: "info@test.com", HostPassword: "secret"})
+	if params.Host != defaultOVHHost || params.Port != defaultOVHPort || params.Username != "info@test.com" {
+		t.Errorf("Expected %s:%s, got %s:%s", defaultOVHHost, defaultOVHPort, params.Host, params.Port)
+	}
+	if params.encryption != mail.EncryptionSSLTLS {
+		t.Errorf("Expected implicit TLS, got %v", params.encryption)
+	}
+
+	params = getOVHSMTPParams(MailCfg{APIService: OVH, Host: "pro1.mail.ovh.net", Port: "587"})
+	if params.Host != "pro1.mail.ovh.net" || params.Port != "587" {
+		t.Errorf("Expected pro1.mail.ovh.net:587, got %s:%s", params.Host, params.Port)
+	}
+	if params.encryption != mail.EncryptionSTARTTLS {
+		t.Errorf("Expected STARTTLS on the submission port, got %v", params.encryption)
+	}
+}
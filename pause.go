@@ -0,0 +1,49 @@
+package mailer
+
+import "context"
+
+// Pause stops sending emails, e.g. during an incident with a bad template or a
+// compromised account. Send and Enqueue still accept emails, which are sent once
+// the mailer is resumed, but Enqueue blocks once the queue of 200 emails is full.
+func (m *Mailer) Pause() {
+	m.pauseMu.Lock()
+	defer m.pauseMu.Unlock()
+	if m.resumed == nil {
+		m.resumed = make(chan struct{})
+	}
+}
+
+// Resume resumes sending the emails queued while the mailer was paused.
+func (m *Mailer) Resume() {
+	m.pauseMu.Lock()
+	defer m.pauseMu.Unlock()
+	if m.resumed != nil {
+		close(m.resumed)
+		m.resumed = nil
+	}
+}
+
+// Paused reports whether the mailer is paused.
+func (m *Mailer) Paused() bool {
+	m.pauseMu.Lock()
+	defer m.pauseMu.Unlock()
+	return m.resumed != nil
+}
+
+// waitIfPaused blocks until the mailer is resumed or closed, or ctx is done.
+func (m *Mailer) waitIfPaused(ctx context.Context) error {
+	m.pauseMu.Lock()
+	resumed := m.resumed
+	m.pauseMu.Unlock()
+	if resumed == nil {
+		return nil
+	}
+
+	select {
+	case <-resumed:
+	case <-m.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMailer_PauseResume(t *testing.T) {
+	sent := make(chan string, 2)
+	mailer := NewMailer(MailCfg{
+		APIService: RESEND,
+		APIKey:     MailAPIKey,
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			sent <- joinAddresses(msg.To)
+			return nil
+		}},
+	})
+	defer mailer.Close()
+
+	mailer.Pause()
+	if !mailer.Paused() {
+		t.Fatalf("Expected the mailer to be paused")
+	}
+
+	mailer.Enqueue(Mail{To: Addresses("first@example.com")})
+	mailer.Enqueue(Mail{To: Addresses("second@example.com")})
+
+	select {
+	case to := <-sent:
+		t.Fatalf("Expected no email to be sent while paused, got %s", to)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	mailer.Resume()
+	if mailer.Paused() {
+		t.Errorf("Expected the mailer to be resumed")
+	}
+	for _, expected := range []string{"first@example.com", "second@example.com"} {
+		select {
+		case to := <-sent:
+			if to != expected {
+				t.Errorf("Expected %s to be sent, got %s", expected, to)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %s", expected)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package mailer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HTMLToText converts an html body into a readable text body: headings are kept
+// as markdown style headings, lists as bullets or numbers and links are replaced
+// with footnote references listed at the end of the text.
+func HTMLToText(body string) string {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	c := &textConverter{linkIndex: make(map[string]int)}
+	c.walk(doc)
+
+	lines := strings.Split(c.b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	text := strings.TrimSpace(strings.Join(lines, "\n"))
+
+	if len(c.links) > 0 {
+		text += "\n\n"
+		for i, link := range c.links {
+			text += fmt.Sprintf("[%d] %s\n", i+1, link)
+		}
+		text = strings.TrimSuffix(text, "\n")
+	}
+	return text
+}
+
+// PlainTextMiddleware returns a Middleware generating the text body of the emails
+// having only an html body, see HTMLToText.
+func PlainTextMiddleware() Middleware {
+	return func(next SendFunc) SendFunc {
+		return func(msg Mail) error {
+			if msg.Text == "" && msg.Html != "" {
+				msg.Text = HTMLToText(msg.Html)
+			}
+			return next(msg)
+		}
+	}
+}
+
+type textList struct {
+	ordered bool
+	n       int
+}
+
+type textConverter struct {
+	b strings.Builder
+	// newlines is the number of newlines ending the text, -1 while it is empty.
+	newlines  int
+	links     []string
+	linkIndex map[string]int
+	lists     []textList
+	pre       int
+}
+
+func (c *textConverter) write(s string) {
+	if s == "" {
+		return
+	}
+	c.b.WriteString(s)
+	trimmed := strings.TrimRight(s, "\n")
+	if trimmed == "" && c.newlines >= 0 {
+		c.newlines += len(s)
+	} else {
+		c.newlines = len(s) - len(trimmed)
+	}
+}
+
+// block ends the current line and leaves n-1 blank lines, unless at the start.
+func (c *textConverter) block(n int) {
+	if c.b.Len() == 0 {
+		return
+	}
+	for c.newlines < n {
+		c.write("\n")
+	}
+}
+
+func (c *textConverter) text(s string) {
+	if c.pre > 0 {
+		c.write(s)
+		return
+	}
+
+	collapsed := strings.Join(strings.Fields(s), " ")
+	if collapsed == "" {
+		if s != "" && c.newlines == 0 && c.b.Len() > 0 && !strings.HasSuffix(c.b.String(), " ") {
+			c.write(" ")
+		}
+		return
+	}
+	atLineStart := c.b.Len() == 0 || c.newlines > 0 || strings.HasSuffix(c.b.String(), " ")
+	if !atLineStart && strings.TrimLeft(s[:1], " \t\r\n\f") == "" {
+		collapsed = " " + collapsed
+	}
+	if strings.TrimRight(s[len(s)-1:], " \t\r\n\f") == "" {
+		collapsed += " "
+	}
+	c.write(collapsed)
+}
+
+func (c *textConverter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.walk(child)
+	}
+}
+
+func (c *textConverter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		c.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		c.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Title, atom.Template:
+	case atom.Br:
+		c.write("\n")
+	case atom.Hr:
+		c.block(2)
+		c.write("----")
+		c.block(2)
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level, _ := strconv.Atoi(n.Data[1:])
+		c.block(2)
+		c.write(strings.Repeat("#", level) + " ")
+		c.children(n)
+		c.block(2)
+	case atom.P, atom.Blockquote, atom.Table:
+		c.block(2)
+		c.children(n)
+		c.block(2)
+	case atom.Pre:
+		c.block(2)
+		c.pre++
+		c.children(n)
+		c.pre--
+		c.block(2)
+	case atom.Ul, atom.Ol:
+		c.block(1)
+		c.lists = append(c.lists, textList{ordered: n.DataAtom == atom.Ol})
+		c.children(n)
+		c.lists = c.lists[:len(c.lists)-1]
+		c.block(1)
+	case atom.Li:
+		c.block(1)
+		marker := "* "
+		if len(c.lists) > 0 {
+			list := &c.lists[len(c.lists)-1]
+			list.n++
+			if list.ordered {
+				marker = strconv.Itoa(list.n) + ". "
+			}
+			marker = strings.Repeat("  ", len(c.lists)-1) + marker
+		}
+		c.write(marker)
+		c.children(n)
+		c.block(1)
+	case atom.Div, atom.Tr, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Address:
+		c.block(1)
+		c.children(n)
+		c.block(1)
+	case atom.Td, atom.Th:
+		c.children(n)
+		c.text(" ")
+	case atom.Img:
+		c.text(attr(n, "alt"))
+	case atom.A:
+		start := c.b.Len()
+		c.children(n)
+		c.footnote(attr(n, "href"), strings.TrimSpace(c.b.String()[start:]))
+	default:
+		c.children(n)
+	}
+}
+
+// footnote references the link after its text, unless it is the text itself.
+func (c *textConverter) footnote(href string, text string) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+		return
+	}
+	if text == href || text == strings.TrimPrefix(href, "mailto:") {
+		return
+	}
+	if text == "" {
+		c.text(href)
+		return
+	}
+
+	i, ok := c.linkIndex[href]
+	if !ok {
+		c.links = append(c.links, href)
+		i = len(c.links)
+		c.linkIndex[href] = i
+	}
+	if strings.HasSuffix(c.b.String(), " ") {
+		c.write(fmt.Sprintf("[%d] ", i))
+	} else {
+		c.write(fmt.Sprintf(" [%d]", i))
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package mailer
+
+import "testing"
+
+func TestHTMLToText(t *testing.T) {
+	body := `<html><head><title>Ignored</title><style>p { color: red; }</style></head><body>
+<h1>Your order</h1>
+<p>Hello <b>Ada</b>,
+   thanks for your order.</p>
+<ul>
+  <li>Book</li>
+  <li>Pen
+    <ol><li>Blue</li><li>Red</li></ol>
+  </li>
+</ul>
+<p>Track it <a href="https://example.com/track">here</a> or see
+<a href="https://example.com">https://example.com</a>.
+Questions? <a href="mailto:help@example.com">help@example.com</a> or <a href="https://example.com/track">track</a>.</p>
+<img src="logo.png" alt="Example">
+</body></html>`
+
+	expected := `# Your order
+
+Hello Ada, thanks for your order.
+
+* Book
+* Pen
+  1. Blue
+  2. Red
+
+Track it here [1] or see https://example.com. Questions? help@example.com or track [1].
+
+Example
+
+[1] https://example.com/track`
+
+	if text := HTMLToText(body); text != expected {
+		t.Errorf("Unexpected text\n%s\nexpected\n%s", text, expected)
+	}
+}
+
+func TestPlainTextMiddleware(t *testing.T) {
+	var sent Mail
+	send := PlainTextMiddleware()(func(msg Mail) error {
+		sent = msg
+		return nil
+	})
+
+	send(Mail{Html: "<p>Hello</p>"})
+	if sent.Text != "Hello" {
+		t.Errorf("Expected the text to be generated, got %q", sent.Text)
+	}
+
+	send(Mail{Html: "<p>Hello</p>", Text: "Custom"})
+	if sent.Text != "Custom" {
+		t.Errorf("Expected the text to be kept, got %q", sent.Text)
+	}
+}
@@ -0,0 +1,100 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+)
+
+const plunkBaseURL = "https://api.useplunk.com"
+
+// PlunkOptions holds the Plunk specific options of an email.
+type PlunkOptions struct {
+	// Subscribed adds the recipients to the contacts subscribed to the campaigns.
+	Subscribed bool
+}
+
+type plunkParams struct {
+	apiKey     string
+	userAgent  string
+	httpClient *http.Client
+}
+
+type plunkMailer struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	userAgent  string
+}
+
+type plunkEmail struct {
+	To         []string          `json:"to"`
+	Subject    string            `json:"subject"`
+	Body       string            `json:"body"`
+	Name       string            `json:"name,omitempty"`
+	From       string            `json:"from,omitempty"`
+	Reply      string            `json:"reply,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Subscribed bool              `json:"subscribed,omitempty"`
+}
+
+func newPlunk(params plunkParams) MailerClient {
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+
+	return &plunkMailer{
+		httpClient: params.httpClient,
+		baseURL:    plunkBaseURL,
+		apiKey:     params.apiKey,
+		userAgent:  params.userAgent,
+	}
+}
+
+func (m *plunkMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+// sendWithResponse sends the email to every recipient. Plunk sends a copy to each
+// address of "to" and has no Cc nor Bcc, so the Cc and Bcc recipients get their
+// own copy, and the email has no text part.
+func (m *plunkMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	payload := plunkEmail{
+		Subject: msg.Subject,
+		Body:    msg.Html,
+		Name:    msg.From.Name,
+		From:    msg.From.Email,
+		Reply:   msg.ReplyTo.String(),
+		Headers: msg.Headers,
+	}
+	if payload.Body == "" {
+		payload.Body = msg.Text
+	}
+	payload.To = addressEmails(allRecipients(msg))
+	if msg.Plunk != nil {
+		payload.Subscribed = msg.Plunk.Subscribed
+	}
+
+	return m.doWithResponse(context.Background(), http.MethodPost, "/v1/send", payload)
+}
+
+func (m *plunkMailer) doWithResponse(ctx context.Context, method string, path string, payload any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(method, m.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, PLUNK, req.WithContext(ctx), nil)
+}
+
+// Health verifies the secret key by counting the contacts.
+func (m *plunkMailer) Health(ctx context.Context) error {
+	_, err := m.doWithResponse(ctx, http.MethodGet, "/v1/contacts/count", nil)
+	return err
+}
+
+func (m *plunkMailer) Close() {
+	// Not implemented because the Plunk API is stateless.
+}
@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"testing"
+)
+
+func TestPlunk_Send(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	plunk := newPlunk(plunkParams{apiKey: MailAPIKey}).(*plunkMailer)
+	plunk.baseURL = server.URL
+
+	err := plunk.Send(Mail{
+		From:    Address{Name: "Acme", Email: "info@test.com"},
+		To:      Addresses("a@test.com"),
+		Bcc:     Addresses("b@test.com"),
+		ReplyTo: Address{Email: "support@test.com"},
+		Subject: "test",
+		Text:    "test",
+		Plunk:   &PlunkOptions{Subscribed: true},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := requests[0]
+	if req.Path != "/v1/send" || req.Header.Get("Authorization") != "Bearer "+MailAPIKey {
+		t.Fatalf("Expected an authenticated request to /v1/send, got %s %v", req.Path, req.Header)
+	}
+	if to := req.Body["to"].([]any); len(to) != 2 {
+		t.Errorf("Expected every recipient in to, got %v", to)
+	}
+	if req.Body["from"] != "info@test.com" || req.Body["name"] != "Acme" || req.Body["reply"] != "support@test.com" {
+		t.Errorf("Expected the sender and the reply address, got %v", req.Body)
+	}
+	if req.Body["body"] != "test" || req.Body["subscribed"] != true {
+		t.Errorf("Expected the text as body of a subscribed contact, got %v", req.Body)
+	}
+}
@@ -0,0 +1,114 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const defaultMaxPanics = 3
+
+var (
+	// ErrSendPanicked is returned when sending an email panicked, e.g. in a provider
+	// client or a middleware. The mailer keeps sending the other emails.
+	ErrSendPanicked = errors.New("send panicked")
+	// ErrMessageQuarantined is returned for an email whose sending panicked too many
+	// times, it is not sent again.
+	ErrMessageQuarantined = errors.New("message quarantined")
+)
+
+// DeadLetter is an email the mailer gave up on.
+type DeadLetter struct {
+	MessageID string
+	Mail      Mail
+	Reason    string
+	Time      time.Time
+}
+
+// DeadLetterStore keeps the emails the mailer gave up on, e.g. to inspect and
+// resend them once the cause is fixed.
+type DeadLetterStore interface {
+	Add(ctx context.Context, letter DeadLetter) error
+}
+
+// MemoryDeadLetterStore is a DeadLetterStore kept in memory.
+type MemoryDeadLetterStore struct {
+	mu      sync.Mutex
+	letters []DeadLetter
+}
+
+// NewMemoryDeadLetterStore creates an empty in-memory dead letter store.
+func NewMemoryDeadLetterStore() *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{}
+}
+
+// Add adds the dead letter to the store.
+func (s *MemoryDeadLetterStore) Add(ctx context.Context, letter DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+// List returns the dead letters in the order they were added.
+func (s *MemoryDeadLetterStore) List() []DeadLetter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]DeadLetter(nil), s.letters...)
+}
+
+// poisonTracker counts the panics per email content, so that an email crashing
+// every time it is retried, e.g. by a job queue, is isolated.
+type poisonTracker struct {
+	mu     sync.Mutex
+	panics map[string]int
+}
+
+func (p *poisonTracker) count(hash string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.panics[hash]
+}
+
+func (p *poisonTracker) panicked(hash string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.panics == nil {
+		p.panics = make(map[string]int)
+	}
+	p.panics[hash]++
+	return p.panics[hash]
+}
+
+// recoverSend converts a panic of the sending of an email into ErrSendPanicked.
+func recoverSend(id string, err *error) {
+	if r := recover(); r != nil {
+		log.Printf("mailer: sending %s panicked: %v\n%s", id, r, debug.Stack())
+		*err = fmt.Errorf("%w: %v", ErrSendPanicked, r)
+	}
+}
+
+// sendIsolated sends the email, recovering its panics and quarantining it in the
+// dead letters once it panicked MaxPanics times.
+func (m *Mailer) sendIsolated(id string, msg Mail, attempts *int) (err error) {
+	hash := ContentHash(msg)
+	if m.poison.count(hash) >= m.maxPanics {
+		return ErrMessageQuarantined
+	}
+
+	defer func() {
+		if !errors.Is(err, ErrSendPanicked) {
+			return
+		}
+		if panics := m.poison.panicked(hash); panics >= m.maxPanics {
+			m.deadLetter(id, msg, fmt.Sprintf("quarantined after %d panics: %v", panics, err))
+		}
+	}()
+	defer recoverSend(id, &err)
+
+	return m.send(id, msg, attempts)
+}
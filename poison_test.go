@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMailer_SendRecoversPanics(t *testing.T) {
+	calls := 0
+	store := NewMemoryDeadLetterStore()
+	mailer := NewMailer(MailCfg{MaxPanics: 2, DeadLetterStore: store, mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+		if joinAddresses(msg.To) == "poison@test.com" {
+			calls++
+			var m map[string]string
+			m["crash"] = "now"
+		}
+		return nil
+	}}})
+	defer mailer.Close()
+
+	poison := Mail{To: Addresses("poison@test.com"), Subject: "test"}
+	for i := 0; i < 2; i++ {
+		if err := mailer.Send(poison); !errors.Is(err, ErrSendPanicked) {
+			t.Fatalf("Expected ErrSendPanicked, got %v", err)
+		}
+	}
+
+	if err := mailer.Send(Mail{To: Addresses("ok@test.com")}); err != nil {
+		t.Errorf("Expected the mailer to keep sending after a panic, got %v", err)
+	}
+	if stats := mailer.Stats(); stats.InFlight != 0 {
+		t.Errorf("Expected no email in flight, got %d", stats.InFlight)
+	}
+
+	if err := mailer.Send(poison); !errors.Is(err, ErrMessageQuarantined) || !IsPermanentError(err) {
+		t.Errorf("Expected ErrMessageQuarantined, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the quarantined email not to be sent again, got %d calls", calls)
+	}
+
+	letters := store.List()
+	if len(letters) != 1 || joinAddresses(letters[0].Mail.To) != "poison@test.com" {
+		t.Fatalf("Expected the quarantined email in the dead letters, got %+v", letters)
+	}
+}
+
+func TestMailer_SendRecoversMiddlewarePanics(t *testing.T) {
+	mailer := NewMailer(MailCfg{
+		mailerClient: &mockMailerClient{},
+		Middlewares: []Middleware{func(next SendFunc) SendFunc {
+			return func(msg Mail) error { panic("middleware bug") }
+		}},
+	})
+	defer mailer.Close()
+
+	if err := mailer.Send(Mail{To: Addresses("a@test.com")}); !errors.Is(err, ErrSendPanicked) {
+		t.Errorf("Expected ErrSendPanicked, got %v", err)
+	}
+	if err := mailer.Send(Mail{To: Addresses("a@test.com")}); !errors.Is(err, ErrSendPanicked) {
+		t.Errorf("Expected the listener to survive the panic, got %v", err)
+	}
+}
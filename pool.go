@@ -0,0 +1,136 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledBuffer is the capacity above which buffers are not returned to the
+// pool, so that one huge attachment does not keep its memory around.
+const maxPooledBuffer = 16 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// pooledBody is a request body read from a pooled buffer. The transport may
+// read the body from another goroutine after the response was returned, and the
+// redirects and HTTP/2 retries read it again through GetBody, so the buffer only
+// goes back to the pool once the request was done and every reader was closed.
+type pooledBody struct {
+	buf  *bytes.Buffer
+	refs atomic.Int32
+}
+
+func (b *pooledBody) reader() io.ReadCloser {
+	b.refs.Add(1)
+	return &pooledReader{Reader: bytes.NewReader(b.buf.Bytes()), body: b}
+}
+
+func (b *pooledBody) release() {
+	if b.refs.Add(-1) == 0 {
+		putBuffer(b.buf)
+	}
+}
+
+type pooledReader struct {
+	*bytes.Reader
+	body *pooledBody
+	once sync.Once
+}
+
+func (r *pooledReader) Close() error {
+	r.once.Do(r.body.release)
+	return nil
+}
+
+// newPooledRequest creates a request whose body is the pooled buffer, which is
+// released by releaseRequestBody once the request was sent.
+func newPooledRequest(method string, url string, buf *bytes.Buffer) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		putBuffer(buf)
+		return nil, err
+	}
+	body := &pooledBody{buf: buf}
+	body.refs.Store(1)
+	req.Body = body.reader()
+	req.GetBody = func() (io.ReadCloser, error) { return body.reader(), nil }
+	req.ContentLength = int64(buf.Len())
+	return req, nil
+}
+
+// releaseRequestBody releases the pooled body of the request after it was sent,
+// the buffer going back to the pool when the transport closed the body.
+func releaseRequestBody(req *http.Request) {
+	if body, ok := req.Body.(*pooledReader); ok {
+		body.body.release()
+	}
+}
+
+// encodeAttachmentBase64 returns the content of the attachment encoded as base64,
+// streaming files through the encoder instead of reading them into memory first.
+func encodeAttachmentBase64(attachment Attachment) (string, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if attachment.Content != nil {
+		buf.Grow(base64.StdEncoding.EncodedLen(len(attachment.Content)))
+		encoder := base64.NewEncoder(base64.StdEncoding, buf)
+		encoder.Write(attachment.Content)
+		encoder.Close()
+		return buf.String(), nil
+	}
+
+	file, err := os.Open(attachment.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read attachment %s: %w", attachment.Name, err)
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil {
+		buf.Grow(base64.StdEncoding.EncodedLen(int(info.Size())))
+	}
+
+	encoder := base64.NewEncoder(base64.StdEncoding, buf)
+	if _, err := io.Copy(encoder, file); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// copyAttachment streams the content of the attachment to w.
+func copyAttachment(w io.Writer, attachment Attachment) error {
+	if attachment.Content != nil {
+		_, err := w.Write(attachment.Content)
+		return err
+	}
+
+	file, err := os.Open(attachment.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read attachment %s: %w", attachment.Name, err)
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
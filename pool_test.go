@@ -0,0 +1,165 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEncodeAttachmentBase64(t *testing.T) {
+	content := bytes.Repeat([]byte("attachment content "), 1000)
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	expected := base64.StdEncoding.EncodeToString(content)
+
+	for _, attachment := range []Attachment{{Name: "report.txt", Path: path}, {Name: "report.txt", Content: content}} {
+		encoded, err := encodeAttachmentBase64(attachment)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if encoded != expected {
+			t.Errorf("Unexpected encoding of %+v", attachment.Path)
+		}
+	}
+
+	if _, err := encodeAttachmentBase64(Attachment{Name: "missing.txt", Path: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
+
+func TestNewJSONRequest_Body(t *testing.T) {
+	req, err := newJSONRequest("POST", "https://api.test.com", map[string]string{"to": "a@test.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"to":"a@test.com"}` || req.ContentLength != int64(len(body)) {
+		t.Errorf("Unexpected body %q of length %d", body, req.ContentLength)
+	}
+	req.Body.Close()
+
+	// The redirects and the HTTP/2 retries send the body again.
+	if req.GetBody == nil {
+		t.Fatalf("Expected the body to be replayable")
+	}
+	again, _ := req.GetBody()
+	if body, _ := io.ReadAll(again); string(body) != `{"to":"a@test.com"}` {
+		t.Errorf("Unexpected replayed body %q", body)
+	}
+}
+
+func TestNewJSONRequest_ReleasesTheBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+
+	req, err := newJSONRequest("POST", server.URL, map[string]string{"to": "a@test.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body := req.Body.(*pooledReader).body
+	if err := doJSONRequest(server.Client(), SENDGRID, req, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The transport closes the body asynchronously.
+	for i := 0; body.refs.Load() != 0 && i < 100; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if refs := body.refs.Load(); refs != 0 {
+		t.Errorf("Expected the buffer to go back to the pool, got %d references", refs)
+	}
+}
+
+func benchmarkAttachment(b *testing.B) Attachment {
+	path := filepath.Join(b.TempDir(), "report.pdf")
+	if err := os.WriteFile(path, bytes.Repeat([]byte{0x25, 0x50, 0x44, 0x46}, 256<<10), 0o600); err != nil {
+		b.Fatal(err)
+	}
+	return Attachment{Name: "report.pdf", Path: path}
+}
+
+// BenchmarkEncodeAttachmentBase64_ReadFile is the encoding used before the
+// attachments were streamed through a pooled buffer, as a baseline.
+func BenchmarkEncodeAttachmentBase64_ReadFile(b *testing.B) {
+	attachment := benchmarkAttachment(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		content, err := getAttachmentContent(attachment)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = base64.StdEncoding.EncodeToString(content)
+	}
+}
+
+func BenchmarkEncodeAttachmentBase64(b *testing.B) {
+	attachment := benchmarkAttachment(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := encodeAttachmentBase64(attachment); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSendGridRequest(b *testing.B) {
+	attachment := benchmarkAttachment(b)
+	sendgrid := &sendgridMailer{}
+	msg := Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test", Html: "<p>test</p>", Attachments: []Attachment{attachment}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		payload, err := sendgrid.buildRequest(msg)
+		if err != nil {
+			b.Fatal(err)
+		}
+		req, err := newJSONRequest("POST", "https://api.sendgrid.com/v3/mail/send", payload)
+		if err != nil {
+			b.Fatal(err)
+		}
+		req.Body.Close()
+		releaseRequestBody(req)
+	}
+}
+
+func BenchmarkMailgunForm(b *testing.B) {
+	attachment := benchmarkAttachment(b)
+	mailgun := &mailgunMailer{}
+	msg := Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test", Html: "<p>test</p>", Attachments: []Attachment{attachment}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		body, _, err := mailgun.buildForm(msg)
+		if err != nil {
+			b.Fatal(err)
+		}
+		req, err := newPooledRequest("POST", "https://api.mailgun.net/v3/test.com/messages", body)
+		if err != nil {
+			b.Fatal(err)
+		}
+		req.Body.Close()
+		releaseRequestBody(req)
+	}
+}
+
+func BenchmarkBuildMessage(b *testing.B) {
+	msg := Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test", Html: "<p>test</p>", Attachments: []Attachment{benchmarkAttachment(b)}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, email := buildMessage(msg); email.Error != nil {
+			b.Fatal(email.Error)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+const postmarkBaseURL = "https://api.postmarkapp.com"
+
+// The default message streams of a Postmark server.
+const (
+	PostmarkTransactionalStream = "outbound"
+	PostmarkBroadcastStream     = "broadcast"
+)
+
+// PostmarkTrackLinks selects the links whose clicks Postmark tracks.
+type PostmarkTrackLinks string
+
+const (
+	PostmarkTrackLinksNone        PostmarkTrackLinks = "None"
+	PostmarkTrackLinksHtmlAndText PostmarkTrackLinks = "HtmlAndText"
+	PostmarkTrackLinksHtmlOnly    PostmarkTrackLinks = "HtmlOnly"
+	PostmarkTrackLinksTextOnly    PostmarkTrackLinks = "TextOnly"
+)
+
+// PostmarkOptions holds the Postmark specific options of an email.
+type PostmarkOptions struct {
+	// MessageStream is the id of the message stream of the email. Defaults to the
+	// broadcast stream for marketing emails and to the transactional one otherwise.
+	MessageStream string
+	// TemplateID is the id of a Postmark server template. When set, or when the
+	// TemplateAlias is, the template stored in Postmark is rendered instead of the
+	// Subject, Html and Text of the email.
+	TemplateID int64
+	// TemplateAlias is the alias of a Postmark server template.
+	TemplateAlias string
+	// TemplateModel holds the values of the template.
+	TemplateModel map[string]any
+	// TrackOpens toggles open tracking. Nil keeps the setting of the server.
+	TrackOpens *bool
+	// TrackLinks selects the tracked links. Empty keeps the setting of the server.
+	TrackLinks PostmarkTrackLinks
+}
+
+type postmarkParams struct {
+	serverToken string
+	userAgent   string
+	httpClient  *http.Client
+}
+
+type postmarkMailer struct {
+	httpClient  *http.Client
+	baseURL     string
+	serverToken string
+	userAgent   string
+}
+
+type postmarkAttachment struct {
+	Name        string `json:"Name"`
+	Content     string `json:"Content"`
+	ContentType string `json:"ContentType"`
+}
+
+type postmarkEmail struct {
+	From        string               `json:"From"`
+	To          string               `json:"To"`
+	Cc          string               `json:"Cc,omitempty"`
+	Bcc         string               `json:"Bcc,omitempty"`
+	Subject     string               `json:"Subject,omitempty"`
+	HtmlBody    string               `json:"HtmlBody,omitempty"`
+	TextBody    string               `json:"TextBody,omitempty"`
+	ReplyTo     string               `json:"ReplyTo,omitempty"`
+	Headers     []postmarkHeader     `json:"Headers,omitempty"`
+	Attachments []postmarkAttachment `json:"Attachments,omitempty"`
+
+	MessageStream string             `json:"MessageStream,omitempty"`
+	TrackOpens    *bool              `json:"TrackOpens,omitempty"`
+	TrackLinks    PostmarkTrackLinks `json:"TrackLinks,omitempty"`
+	TemplateId    int64              `json:"TemplateId,omitempty"`
+	TemplateAlias string             `json:"TemplateAlias,omitempty"`
+	TemplateModel map[string]any     `json:"TemplateModel,omitempty"`
+}
+
+type postmarkHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type postmarkTemplate struct {
+	Name         string `json:"Name"`
+	Alias        string `json:"Alias"`
+	Subject      string `json:"Subject"`
+	HtmlBody     string `json:"HtmlBody,omitempty"`
+	TextBody     string `json:"TextBody,omitempty"`
+	TemplateType string `json:"TemplateType,omitempty"`
+}
+
+func newPostmark(params postmarkParams) MailerClient {
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+
+	return &postmarkMailer{
+		httpClient:  params.httpClient,
+		baseURL:     postmarkBaseURL,
+		serverToken: params.serverToken,
+		userAgent:   params.userAgent,
+	}
+}
+
+func (m *postmarkMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *postmarkMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	payload := postmarkEmail{
+		From:     msg.From.String(),
+		To:       joinAddresses(msg.To),
+		Cc:       joinAddresses(msg.Cc),
+		Bcc:      joinAddresses(msg.Bcc),
+		Subject:  msg.Subject,
+		HtmlBody: msg.Html,
+		TextBody: msg.Text,
+		ReplyTo:  msg.ReplyTo.String(),
+	}
+	for _, key := range headerKeys(msg.Headers) {
+		payload.Headers = append(payload.Headers, postmarkHeader{Name: key, Value: msg.Headers[key]})
+	}
+
+	for _, attachment := range msg.Attachments {
+		content, err := encodeAttachmentBase64(attachment)
+		if err != nil {
+			return nil, err
+		}
+		payload.Attachments = append(payload.Attachments, postmarkAttachment{
+			Name:        attachment.Name,
+			Content:     content,
+			ContentType: getAttachmentContentType(attachment),
+		})
+	}
+
+	options := msg.Postmark
+	if options == nil {
+		options = &PostmarkOptions{}
+	}
+	payload.MessageStream = options.MessageStream
+	if payload.MessageStream == "" {
+		payload.MessageStream = PostmarkTransactionalStream
+		if msg.Class == MessageMarketing {
+			payload.MessageStream = PostmarkBroadcastStream
+		}
+	}
+	payload.TrackOpens = options.TrackOpens
+	payload.TrackLinks = options.TrackLinks
+
+	path := "/email"
+	if options.TemplateID != 0 || options.TemplateAlias != "" {
+		path = "/email/withTemplate"
+		payload.Subject, payload.HtmlBody, payload.TextBody = "", "", ""
+		payload.TemplateId = options.TemplateID
+		payload.TemplateAlias = options.TemplateAlias
+		payload.TemplateModel = options.TemplateModel
+		if payload.TemplateModel == nil {
+			// Postmark requires a model, even for a template without values.
+			payload.TemplateModel = map[string]any{}
+		}
+	}
+
+	return m.doWithResponse(context.Background(), http.MethodPost, path, payload)
+}
+
+func (m *postmarkMailer) CreateTemplate(ctx context.Context, tpl Template) error {
+	return m.do(ctx, http.MethodPost, "/templates", getPostmarkTemplate(tpl))
+}
+
+func (m *postmarkMailer) UpdateTemplate(ctx context.Context, tpl Template) error {
+	return m.do(ctx, http.MethodPut, "/templates/"+url.PathEscape(tpl.Name), getPostmarkTemplate(tpl))
+}
+
+func (m *postmarkMailer) DeleteTemplate(ctx context.Context, name string) error {
+	return m.do(ctx, http.MethodDelete, "/templates/"+url.PathEscape(name), nil)
+}
+
+// Health verifies the server token by fetching the server.
+func (m *postmarkMailer) Health(ctx context.Context) error {
+	return m.do(ctx, http.MethodGet, "/server", nil)
+}
+
+func (m *postmarkMailer) do(ctx context.Context, method string, path string, payload any) error {
+	_, err := m.doWithResponse(ctx, method, path, payload)
+	return err
+}
+
+func (m *postmarkMailer) doWithResponse(ctx context.Context, method string, path string, payload any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(method, m.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Postmark-Server-Token", m.serverToken)
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, POSTMARK, req.WithContext(ctx), nil)
+}
+
+// getPostmarkTemplate uses the template name as the Postmark alias so that
+// templates can be referenced by name in updates and deletions.
+func getPostmarkTemplate(tpl Template) postmarkTemplate {
+	return postmarkTemplate{
+		Name:         tpl.Name,
+		Alias:        tpl.Name,
+		Subject:      tpl.Subject,
+		HtmlBody:     tpl.Html,
+		TextBody:     tpl.Text,
+		TemplateType: "Standard",
+	}
+}
+
+func (m *postmarkMailer) Close() {
+	// Not implemented because the Postmark API is stateless.
+}
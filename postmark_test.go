@@ -0,0 +1,159 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type postmarkRequest struct {
+	Method string
+	Path   string
+	Body   map[string]any
+}
+
+func newTestPostmark(t *testing.T, requests *[]postmarkRequest) *postmarkMailer {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Postmark-Server-Token") != MailAPIKey {
+			t.Errorf("Expected server token header, got %q", r.Header.Get("X-Postmark-Server-Token"))
+		}
+		req := postmarkRequest{Method: r.Method, Path: r.URL.Path}
+		if r.ContentLength > 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req.Body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		*requests = append(*requests, req)
+		w.Write([]byte(`{"ErrorCode":0,"Message":"OK"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return &postmarkMailer{
+		httpClient:  server.Client(),
+		baseURL:     server.URL,
+		serverToken: MailAPIKey,
+	}
+}
+
+func TestPostmark_Send(t *testing.T) {
+	var requests []postmarkRequest
+	postmark := newTestPostmark(t, &requests)
+
+	err := postmark.Send(Mail{
+		From:    Address{Email: "info@test.com"},
+		To:      Addresses("a@test.com"),
+		Subject: "test",
+		Html:    "<p>test</p>",
+		Text:    "test",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(requests) != 1 || requests[0].Path != "/email" {
+		t.Fatalf("Expected a request to /email, got %+v", requests)
+	}
+	if requests[0].Body["HtmlBody"] != "<p>test</p>" || requests[0].Body["TextBody"] != "test" {
+		t.Errorf("Expected html and text bodies, got %v", requests[0].Body)
+	}
+}
+
+func TestPostmark_TemplateManagement(t *testing.T) {
+	var requests []postmarkRequest
+	postmark := newTestPostmark(t, &requests)
+
+	ctx := context.Background()
+	tpl := Template{Name: "welcome", Subject: "Welcome", Html: "<p>Hi</p>"}
+	if err := postmark.CreateTemplate(ctx, tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := postmark.UpdateTemplate(ctx, tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := postmark.DeleteTemplate(ctx, tpl.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []postmarkRequest{
+		{Method: http.MethodPost, Path: "/templates"},
+		{Method: http.MethodPut, Path: "/templates/welcome"},
+		{Method: http.MethodDelete, Path: "/templates/welcome"},
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("Expected %d requests, got %d", len(expected), len(requests))
+	}
+	for i, req := range expected {
+		if requests[i].Method != req.Method || requests[i].Path != req.Path {
+			t.Errorf("Expected %s %s, got %s %s", req.Method, req.Path, requests[i].Method, requests[i].Path)
+		}
+	}
+	if requests[0].Body["Alias"] != "welcome" {
+		t.Errorf("Expected the template name to be used as alias, got %v", requests[0].Body["Alias"])
+	}
+}
+
+func TestPostmark_SendMessageStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      Mail
+		expected string
+	}{
+		{name: "transactional", msg: Mail{}, expected: PostmarkTransactionalStream},
+		{name: "marketing", msg: Mail{Class: MessageMarketing}, expected: PostmarkBroadcastStream},
+		{name: "option", msg: Mail{Class: MessageMarketing, Postmark: &PostmarkOptions{MessageStream: "digest"}}, expected: "digest"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests []postmarkRequest
+			postmark := newTestPostmark(t, &requests)
+
+			test.msg.From, test.msg.To, test.msg.Text = Address{Email: "info@test.com"}, Addresses("a@test.com"), "test"
+			if err := postmark.Send(test.msg); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if stream := requests[0].Body["MessageStream"]; stream != test.expected {
+				t.Errorf("Expected stream %q, got %v", test.expected, stream)
+			}
+		})
+	}
+}
+
+func TestPostmark_SendWithTemplate(t *testing.T) {
+	var requests []postmarkRequest
+	postmark := newTestPostmark(t, &requests)
+
+	trackOpens := true
+	err := postmark.Send(Mail{
+		From:    Address{Email: "info@test.com"},
+		To:      Addresses("a@test.com"),
+		Subject: "ignored",
+		Text:    "ignored",
+		Postmark: &PostmarkOptions{
+			TemplateAlias: "welcome",
+			TemplateModel: map[string]any{"name": "Ada"},
+			TrackOpens:    &trackOpens,
+			TrackLinks:    PostmarkTrackLinksHtmlOnly,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	body := requests[0].Body
+	if requests[0].Path != "/email/withTemplate" || body["TemplateAlias"] != "welcome" {
+		t.Fatalf("Expected a request to /email/withTemplate, got %+v", requests[0])
+	}
+	if model, _ := body["TemplateModel"].(map[string]any); model["name"] != "Ada" {
+		t.Errorf("Expected the template model, got %v", body["TemplateModel"])
+	}
+	if _, ok := body["Subject"]; ok {
+		t.Errorf("Expected no subject with a template, got %v", body["Subject"])
+	}
+	if body["TrackOpens"] != true || body["TrackLinks"] != "HtmlOnly" {
+		t.Errorf("Expected the tracking flags, got %v and %v", body["TrackOpens"], body["TrackLinks"])
+	}
+}
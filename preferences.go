@@ -0,0 +1,67 @@
+package mailer
+
+import (
+	"errors"
+)
+
+// ErrAllRecipientsOptedOut is returned when every recipient of a message has
+// opted out of the message category.
+var ErrAllRecipientsOptedOut = errors.New("all recipients have opted out of this message category")
+
+// PreferenceChecker is consulted before every send to decide whether a
+// recipient wants to receive messages of a given category.
+type PreferenceChecker interface {
+	// Allowed reports whether the recipient accepts messages of the category.
+	Allowed(recipient string, category string) (bool, error)
+}
+
+// PreferenceCheckerFunc is an adapter to allow the use of ordinary functions
+// as a PreferenceChecker.
+type PreferenceCheckerFunc func(recipient string, category string) (bool, error)
+
+// Allowed calls f(recipient, category).
+func (f PreferenceCheckerFunc) Allowed(recipient string, category string) (bool, error) {
+	return f(recipient, category)
+}
+
+// applyPreferences removes the recipients that opted out of the message
+// category from the To, Cc and Bcc fields.
+func applyPreferences(checker PreferenceChecker, msg Mail) (Mail, error) {
+	if checker == nil {
+		return msg, nil
+	}
+
+	var err error
+	if msg.To, err = filterRecipients(checker, msg.To, msg.Category); err != nil {
+		return msg, err
+	}
+	if msg.Cc, err = filterRecipients(checker, msg.Cc, msg.Category); err != nil {
+		return msg, err
+	}
+	if msg.Bcc, err = filterRecipients(checker, msg.Bcc, msg.Category); err != nil {
+		return msg, err
+	}
+
+	if len(msg.To) == 0 && len(msg.Cc) == 0 && len(msg.Bcc) == 0 {
+		return msg, ErrAllRecipientsOptedOut
+	}
+
+	return msg, nil
+}
+
+func filterRecipients(checker PreferenceChecker, addrs []Address, category string) ([]Address, error) {
+	var allowed []Address
+	for _, addr := range addrs {
+		if addr.Email == "" {
+			continue
+		}
+		ok, err := checker.Allowed(addr.Email, category)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			allowed = append(allowed, addr)
+		}
+	}
+	return allowed, nil
+}
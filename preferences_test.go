@@ -0,0 +1,98 @@
+package mailer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyPreferences(t *testing.T) {
+	optedOut := map[string]bool{
+		"out@example.com": true,
+	}
+	checker := PreferenceCheckerFunc(func(recipient string, category string) (bool, error) {
+		if recipient == "broken@example.com" {
+			return false, errors.New("store unavailable")
+		}
+		return category != "marketing" || !optedOut[recipient], nil
+	})
+
+	testCases := []struct {
+		name     string
+		checker  PreferenceChecker
+		msg      Mail
+		expected Mail
+		err      error
+		wantErr  bool
+	}{
+		{
+			name:     "no checker keeps the message untouched",
+			checker:  nil,
+			msg:      Mail{To: Addresses("out@example.com"), Category: "marketing"},
+			expected: Mail{To: Addresses("out@example.com"), Category: "marketing"},
+		},
+		{
+			name:     "drops opted out recipients",
+			checker:  checker,
+			msg:      Mail{To: Addresses("in@example.com", "out@example.com"), Cc: Addresses("out@example.com"), Category: "marketing"},
+			expected: Mail{To: Addresses("in@example.com"), Category: "marketing"},
+		},
+		{
+			name:     "keeps recipients for other categories",
+			checker:  checker,
+			msg:      Mail{To: Addresses("out@example.com"), Category: "transactional"},
+			expected: Mail{To: Addresses("out@example.com"), Category: "transactional"},
+		},
+		{
+			name:    "fails when every recipient opted out",
+			checker: checker,
+			msg:     Mail{To: Addresses("out@example.com"), Bcc: Addresses("out@example.com"), Category: "marketing"},
+			err:     ErrAllRecipientsOptedOut,
+			wantErr: true,
+		},
+		{
+			name:    "returns checker errors",
+			checker: checker,
+			msg:     Mail{To: Addresses("broken@example.com"), Category: "marketing"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, err := applyPreferences(tc.checker, tc.msg)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got nil")
+				}
+				if tc.err != nil && !errors.Is(err, tc.err) {
+					t.Errorf("Expected error %v, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(msg.To, tc.expected.To) || !reflect.DeepEqual(msg.Cc, tc.expected.Cc) || !reflect.DeepEqual(msg.Bcc, tc.expected.Bcc) {
+				t.Errorf("Expected %+v, got %+v", tc.expected, msg)
+			}
+		})
+	}
+}
+
+func TestMailer_SendWithPreferenceChecker(t *testing.T) {
+	mailer := NewMailer(MailCfg{
+		APIService:   RESEND,
+		APIKey:       MailAPIKey,
+		mailerClient: &mockMailerClient{},
+		PreferenceChecker: PreferenceCheckerFunc(func(recipient string, category string) (bool, error) {
+			return false, nil
+		}),
+	})
+	defer mailer.Close()
+
+	err := mailer.Send(Mail{To: Addresses("test@example.com"), Category: "marketing"})
+	if !errors.Is(err, ErrAllRecipientsOptedOut) {
+		t.Errorf("Expected ErrAllRecipientsOptedOut, got %v", err)
+	}
+}
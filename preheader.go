@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var bodyTag = regexp.MustCompile(`(?i)<body[^>]*>`)
+
+// preheaderPadding follows the preheader so that inbox snippets do not continue
+// with the beginning of the body when the preheader is short.
+var preheaderPadding = strings.Repeat("&#847;&zwnj;&nbsp;", 60)
+
+// applyPreheader injects the Preheader of the email as a hidden block at the start
+// of the html body, which inbox clients show as the preview text.
+func applyPreheader(msg Mail) Mail {
+	if msg.Preheader == "" || msg.Html == "" {
+		return msg
+	}
+
+	block := `<div style="display:none;font-size:1px;line-height:1px;max-height:0;max-width:0;opacity:0;overflow:hidden;mso-hide:all;">` +
+		html.EscapeString(msg.Preheader) + preheaderPadding + `</div>`
+
+	if loc := bodyTag.FindStringIndex(msg.Html); loc != nil {
+		msg.Html = msg.Html[:loc[1]] + block + msg.Html[loc[1]:]
+	} else {
+		msg.Html = block + msg.Html
+	}
+	return msg
+}
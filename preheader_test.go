@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyPreheader(t *testing.T) {
+	testCases := []struct {
+		name   string
+		html   string
+		prefix string
+	}{
+		{name: "Should inject after the body tag", html: `<html><body class="x"><p>Hi</p></body></html>`, prefix: `<html><body class="x"><div style="display:none;`},
+		{name: "Should prepend to fragments", html: `<p>Hi</p>`, prefix: `<div style="display:none;`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := applyPreheader(Mail{Html: tc.html, Preheader: "Your <order> shipped"})
+			if !strings.HasPrefix(msg.Html, tc.prefix) {
+				t.Errorf("Expected prefix %q, got %q", tc.prefix, msg.Html)
+			}
+			if !strings.Contains(msg.Html, "Your &lt;order&gt; shipped") {
+				t.Errorf("Expected the escaped preheader, got %q", msg.Html)
+			}
+			if !strings.Contains(msg.Html, "<p>Hi</p>") {
+				t.Errorf("Expected the body to be kept, got %q", msg.Html)
+			}
+		})
+	}
+
+	if msg := applyPreheader(Mail{Text: "Hi", Preheader: "Hello"}); msg.Html != "" {
+		t.Errorf("Expected text only emails to be left alone, got %q", msg.Html)
+	}
+}
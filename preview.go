@@ -0,0 +1,133 @@
+package mailer
+
+import (
+	"encoding/json"
+	"errors"
+	htmltemplate "html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+var previewPage = htmltemplate.Must(htmltemplate.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{if .Name}}{{.Name}} - {{end}}Email previews</title></head>
+<body style="font-family:sans-serif;margin:2em;">
+{{if .Name}}
+<p><a href="../">All templates</a></p>
+<h1>{{.Name}}</h1>
+{{if .Error}}<pre style="color:#b00020;">{{.Error}}</pre>{{else}}
+<p><strong>Subject:</strong> {{.Mail.Subject}}</p>
+<p><a href="html">HTML</a> | <a href="text">Text</a> | <a href="raw">Raw MIME</a></p>
+<iframe src="html" style="width:100%;height:600px;border:1px solid #ccc;"></iframe>
+<h2>Text</h2>
+<pre style="white-space:pre-wrap;">{{.Mail.Text}}</pre>
+{{end}}
+{{else}}
+<h1>Email previews</h1>
+<ul>{{range .Templates}}<li><a href="{{.Name}}/">{{.Name}}</a></li>{{end}}</ul>
+{{end}}
+</body>
+</html>`))
+
+// PreviewHandler returns an http.Handler for development that lists the templates
+// and shows each one rendered with the sample data of <sampleDir>/<name>.json:
+//
+//	GET /             lists the templates
+//	GET /{name}/      shows the subject, html and text of a template
+//	GET /{name}/html  serves the rendered html
+//	GET /{name}/text  serves the rendered text
+//	GET /{name}/raw   serves the rendered MIME message
+//
+// The sample data is read on every request, so that editing it only needs a
+// reload. The handler must not be exposed in production.
+func PreviewHandler(sampleDir string, templates ...Template) http.Handler {
+	byName := make(map[string]Template)
+	for _, tpl := range templates {
+		byName[tpl.Name] = tpl
+	}
+
+	render := func(w http.ResponseWriter, r *http.Request) (Mail, bool) {
+		tpl, ok := byName[r.PathValue("name")]
+		if !ok {
+			http.NotFound(w, r)
+			return Mail{}, false
+		}
+		msg, err := renderPreview(tpl, sampleDir)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return Mail{}, false
+		}
+		return msg, true
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		previewPage.Execute(w, map[string]any{"Templates": templates})
+	})
+
+	mux.HandleFunc("GET /{name}/{$}", func(w http.ResponseWriter, r *http.Request) {
+		tpl, ok := byName[r.PathValue("name")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data := map[string]any{"Name": tpl.Name}
+		if msg, err := renderPreview(tpl, sampleDir); err != nil {
+			data["Error"] = err.Error()
+		} else {
+			data["Mail"] = msg
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		previewPage.Execute(w, data)
+	})
+
+	mux.HandleFunc("GET /{name}/html", func(w http.ResponseWriter, r *http.Request) {
+		if msg, ok := render(w, r); ok {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(msg.Html))
+		}
+	})
+
+	mux.HandleFunc("GET /{name}/text", func(w http.ResponseWriter, r *http.Request) {
+		if msg, ok := render(w, r); ok {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte(msg.Text))
+		}
+	})
+
+	mux.HandleFunc("GET /{name}/raw", func(w http.ResponseWriter, r *http.Request) {
+		msg, ok := render(w, r)
+		if !ok {
+			return
+		}
+		msg.From, msg.To = Address{Email: "preview@example.com"}, Addresses("preview@example.com")
+		message, email := buildMessage(msg)
+		if email.Error != nil {
+			http.Error(w, email.Error.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(message))
+	})
+
+	return mux
+}
+
+// renderPreview renders the template with its sample data, if any.
+func renderPreview(tpl Template, sampleDir string) (Mail, error) {
+	var data map[string]any
+	content, err := os.ReadFile(filepath.Join(sampleDir, filepath.Base(tpl.Name)+".json"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Mail{}, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, &data); err != nil {
+			return Mail{}, err
+		}
+	}
+	return tpl.Render(data)
+}
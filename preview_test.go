@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreviewHandler(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "welcome.json"), []byte(`{"Name": "Ada"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(PreviewHandler(dir, Template{
+		Name:    "welcome",
+		Subject: "Welcome {{.Name}}",
+		Html:    "<p>Hello {{.Name}}</p>",
+		Text:    "Hello {{.Name}}",
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{path: "/", status: http.StatusOK, contains: `<a href="welcome/">welcome</a>`},
+		{path: "/welcome/", status: http.StatusOK, contains: "Welcome Ada"},
+		{path: "/welcome/html", status: http.StatusOK, contains: "<p>Hello Ada</p>"},
+		{path: "/welcome/text", status: http.StatusOK, contains: "Hello Ada"},
+		{path: "/welcome/raw", status: http.StatusOK, contains: "Subject: Welcome Ada"},
+		{path: "/missing/html", status: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tc.status {
+				t.Fatalf("Expected status %d, got %d", tc.status, resp.StatusCode)
+			}
+			if !strings.Contains(string(body), tc.contains) {
+				t.Errorf("Expected %q in %s", tc.contains, body)
+			}
+		})
+	}
+}
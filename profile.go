@@ -0,0 +1,104 @@
+package mailer
+
+import (
+	"log"
+	"strings"
+)
+
+// EnvironmentEnv is the environment variable selecting the profile when
+// MailCfg.Environment is not set.
+const EnvironmentEnv = "MAILER_ENV"
+
+// Environment is the environment the mailer runs in, selecting its Profile.
+type Environment string
+
+const (
+	Development Environment = "development"
+	Staging     Environment = "staging"
+	Production  Environment = "production"
+)
+
+// Profile overrides the configuration of the mailer in an environment.
+type Profile struct {
+	// Override changes the configuration, e.g. to use another provider.
+	Override func(cfg *MailCfg)
+	// RedirectTo sends every email to the address instead of its recipients.
+	RedirectTo string
+	// DryRun runs the emails through the whole pipeline without sending them.
+	DryRun bool
+	// FileDir writes the emails to .eml files in the directory instead of sending them.
+	FileDir string
+}
+
+// defaultProfiles are the profiles of the environments missing from the
+// Profiles of the configuration: the emails of a development machine are
+// written to files, those of the other non-production environments are not sent.
+var defaultProfiles = map[Environment]Profile{
+	Development: {FileDir: defaultFileDir},
+	Staging:     {DryRun: true},
+}
+
+// applyProfile returns the configuration overridden by the profile of its
+// environment. Production, and a configuration without environment, is not
+// overridden unless it has a profile.
+func applyProfile(cfg MailCfg, getenv func(string) string) MailCfg {
+	env := cfg.Environment
+	if env == "" {
+		env = Environment(strings.ToLower(getenv(EnvironmentEnv)))
+	}
+	if env == "" {
+		return cfg
+	}
+	cfg.Environment = env
+
+	profile, ok := cfg.Profiles[env]
+	if !ok {
+		if profile, ok = defaultProfiles[env]; !ok && env != Production {
+			// An unknown environment e.g. "test" or "preview" is not production.
+			profile = defaultProfiles[Staging]
+		}
+	}
+
+	if profile.Override != nil {
+		profile.Override(&cfg)
+	}
+	if profile.RedirectTo != "" {
+		cfg.RedirectTo = profile.RedirectTo
+	}
+	if profile.DryRun {
+		cfg.DryRun = true
+	}
+	if profile.FileDir != "" {
+		cfg.APIService, cfg.FileDir = FILE, profile.FileDir
+		cfg.mailerClient = nil
+	}
+	return cfg
+}
+
+// applyRedirect sends the email to the address instead of its recipients, which
+// are kept in the X-Original-To and X-Original-Cc headers.
+func applyRedirect(msg Mail, address string) Mail {
+	if address == "" {
+		return msg
+	}
+	msg = withHeader(msg, "X-Original-To", joinAddresses(msg.To))
+	if len(msg.Cc) > 0 {
+		msg = withHeader(msg, "X-Original-Cc", joinAddresses(msg.Cc))
+	}
+	msg.To, msg.Cc, msg.Bcc = []Address{toAddress(address)}, nil, nil
+	return msg
+}
+
+// dryRunMailer logs the emails instead of sending them.
+type dryRunMailer struct{}
+
+func (dryRunMailer) Send(msg Mail) error {
+	_, email := buildMessage(msg)
+	if email.Error != nil {
+		return email.Error
+	}
+	log.Printf("mailer: dry run, not sending %q to %s", msg.Subject, joinAddresses(msg.To))
+	return nil
+}
+
+func (dryRunMailer) Close() {}
@@ -0,0 +1,101 @@
+package mailer
+
+import (
+	"os"
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	env := func(value string) func(string) string {
+		return func(name string) string {
+			if name == EnvironmentEnv {
+				return value
+			}
+			return ""
+		}
+	}
+	base := MailCfg{APIService: SENDGRID, APIKey: MailAPIKey}
+
+	if cfg := applyProfile(base, env("")); cfg.Environment != "" || cfg.APIService != SENDGRID || cfg.DryRun {
+		t.Errorf("Expected no profile without environment, got %+v", cfg)
+	}
+	if cfg := applyProfile(base, env("production")); cfg.APIService != SENDGRID || cfg.DryRun || cfg.RedirectTo != "" {
+		t.Errorf("Expected production not to be overridden, got %+v", cfg)
+	}
+	if cfg := applyProfile(base, env("Development")); cfg.Environment != Development || cfg.APIService != FILE || cfg.FileDir != defaultFileDir {
+		t.Errorf("Expected development to write files, got %+v", cfg)
+	}
+	if cfg := applyProfile(base, env("preview")); !cfg.DryRun {
+		t.Errorf("Expected an unknown environment to be a dry run, got %+v", cfg)
+	}
+
+	base.Environment = Staging
+	base.Profiles = map[Environment]Profile{
+		Staging: {
+			RedirectTo: "qa@test.com",
+			Override:   func(cfg *MailCfg) { cfg.FromName = "Acme (staging)" },
+		},
+	}
+	cfg := applyProfile(base, env("production"))
+	if cfg.RedirectTo != "qa@test.com" || cfg.FromName != "Acme (staging)" || cfg.DryRun || cfg.APIService != SENDGRID {
+		t.Errorf("Expected the staging profile of the configuration, got %+v", cfg)
+	}
+}
+
+func TestMailer_RedirectTo(t *testing.T) {
+	sent := make(chan Mail, 1)
+	mailer := NewMailer(MailCfg{
+		RedirectTo: "qa@test.com",
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			sent <- msg
+			return nil
+		}},
+	})
+	defer mailer.Close()
+
+	if err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Cc: Addresses("c@test.com"), Bcc: Addresses("b@test.com"), Subject: "test"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	msg := <-sent
+	if joinAddresses(msg.To) != "qa@test.com" || joinAddresses(msg.Cc) != "" || joinAddresses(msg.Bcc) != "" {
+		t.Errorf("Expected the email to be redirected, got %q %q %q", msg.To, msg.Cc, msg.Bcc)
+	}
+	if msg.Headers["X-Original-To"] != "a@test.com" || msg.Headers["X-Original-Cc"] != "c@test.com" {
+		t.Errorf("Expected the original recipients in the headers, got %v", msg.Headers)
+	}
+}
+
+func TestMailer_DryRun(t *testing.T) {
+	sent := false
+	mailer := NewMailer(MailCfg{
+		DryRun:       true,
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error { sent = true; return nil }},
+	})
+	defer mailer.Close()
+
+	if err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if sent {
+		t.Error("Expected the email not to be sent")
+	}
+}
+
+func TestMailer_DevelopmentProfile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(EnvironmentEnv, "development")
+
+	mailer := NewMailer(MailCfg{
+		APIService: SENDGRID,
+		APIKey:     MailAPIKey,
+		Profiles:   map[Environment]Profile{Development: {FileDir: dir}},
+	})
+	defer mailer.Close()
+
+	if err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test", Text: "test"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("Expected the email to be written to a file, got %d files", len(files))
+	}
+}
@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// recordedRequest is a request received by a recording server.
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   map[string]any
+}
+
+// newRecordingServer starts a server recording the requests with a JSON body,
+// and responding with the response for every path.
+func newRecordingServer(t *testing.T, requests *[]recordedRequest, responses map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := recordedRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Header: r.Header}
+		if body, _ := io.ReadAll(r.Body); len(body) > 0 {
+			if err := json.Unmarshal(body, &req.Body); err != nil {
+				t.Errorf("Expected a JSON body, got %s", body)
+			}
+		}
+		*requests = append(*requests, req)
+
+		response, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			response = "{}"
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
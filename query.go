@@ -0,0 +1,160 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrQueryUnsupported is returned by Query when the mailer has no audit store or
+// its audit store cannot be queried.
+var ErrQueryUnsupported = errors.New("the audit store does not support queries")
+
+// MessageQuery selects the emails returned by Query. The zero value of a field
+// matches every email.
+type MessageQuery struct {
+	// Recipient is a To, Cc or Bcc address of the email, compared case-insensitively.
+	Recipient string
+	// Since and Until bound the time of the send attempt, Until being excluded.
+	Since time.Time
+	Until time.Time
+	// Tag is one of the tags of the email.
+	Tag string
+	// Subject is a substring of the subject, compared case-insensitively.
+	Subject string
+	// State is the state of the email, e.g. MessageSent, MessageFailed or MessageSpooled.
+	State MessageState
+	// Limit is the maximum number of emails returned, the most recent first.
+	Limit int
+}
+
+// Matches reports whether the audit record matches the query, the State being
+// ignored as it depends on the other attempts of the email. Audit stores
+// implementing QueryableAuditStore may use it to filter their records.
+func (q MessageQuery) Matches(record AuditRecord) bool {
+	if !q.Since.IsZero() && record.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !record.Time.Before(q.Until) {
+		return false
+	}
+	if q.Subject != "" && !strings.Contains(strings.ToLower(record.Subject), strings.ToLower(q.Subject)) {
+		return false
+	}
+	if q.Tag != "" && !slices.Contains(record.Tags, q.Tag) {
+		return false
+	}
+	if q.Recipient != "" {
+		recipient := strings.ToLower(toAddress(q.Recipient).Email)
+		found := false
+		for _, r := range record.Recipients {
+			if strings.ToLower(toAddress(r).Email) == recipient {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// QueryableAuditStore is an AuditStore whose records can be searched.
+type QueryableAuditStore interface {
+	AuditStore
+	// Query returns the records matching the query, ignoring its State and Limit.
+	Query(ctx context.Context, q MessageQuery) ([]AuditRecord, error)
+}
+
+func (s *MemoryAuditStore) Query(ctx context.Context, q MessageQuery) ([]AuditRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var records []AuditRecord
+	for _, record := range s.records {
+		if q.Matches(record) {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// MessageSummary describes an email found by Query.
+type MessageSummary struct {
+	ID         string
+	From       string
+	Recipients []string
+	Subject    string
+	Tags       []string
+	Provider   APIServiceType
+	// State is the state of the email after its last attempt.
+	State MessageState
+	// Error is the error of the last attempt of a failed email.
+	Error string
+	// Attempts is the number of attempts of the email.
+	Attempts int
+	// Time is the time of the last attempt.
+	Time time.Time
+}
+
+// Query searches the audit trail of the mailer, e.g. to answer whether a user got
+// an invoice email, and returns the matching emails, the most recent first.
+func (m *Mailer) Query(ctx context.Context, q MessageQuery) ([]MessageSummary, error) {
+	store, ok := m.auditStore.(QueryableAuditStore)
+	if !ok {
+		return nil, ErrQueryUnsupported
+	}
+	records, err := store.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string]*MessageSummary)
+	for _, record := range records {
+		summary, ok := summaries[record.MessageID]
+		if !ok {
+			summary = &MessageSummary{ID: record.MessageID}
+			summaries[record.MessageID] = summary
+		}
+		summary.Attempts++
+		if record.Time.Before(summary.Time) {
+			continue
+		}
+		summary.From = record.From
+		summary.Recipients = record.Recipients
+		summary.Subject = record.Subject
+		summary.Tags = record.Tags
+		summary.Provider = record.Provider
+		summary.Time = record.Time
+		summary.State, summary.Error = MessageSent, record.Error
+		if record.Error != "" {
+			summary.State = MessageFailed
+		}
+	}
+
+	var results []MessageSummary
+	for _, summary := range summaries {
+		// The status of an email still tracked by the mailer is more recent than
+		// its audit trail, e.g. a failed attempt spooled for a retry.
+		if status, ok := m.statuses.get(summary.ID); ok && !status.UpdatedAt.Before(summary.Time) {
+			summary.State = status.State
+		}
+		if q.State != "" && summary.State != q.State {
+			continue
+		}
+		results = append(results, *summary)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].Time.Equal(results[j].Time) {
+			return results[i].Time.After(results[j].Time)
+		}
+		return results[i].ID < results[j].ID
+	})
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, nil
+}
@@ -0,0 +1,121 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMailer_Query(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)}
+	mailer := NewMailer(MailCfg{
+		APIService: RESEND,
+		APIKey:     MailAPIKey,
+		Clock:      clock,
+		AuditStore: NewMemoryAuditStore(),
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			if joinAddresses(msg.To) == "bounce@test.com" {
+				return errors.New("mailbox unavailable")
+			}
+			return nil
+		}},
+	})
+	defer mailer.Close()
+
+	emails := []Mail{
+		{To: Addresses("a@test.com"), Subject: "Welcome", Tags: []string{"onboarding"}},
+		{To: []Address{{Name: "John", Email: "A@Test.com"}}, Cc: Addresses("b@test.com"), Subject: "Your invoice #42", Tags: []string{"billing"}},
+		{To: Addresses("bounce@test.com"), Subject: "Your invoice #43", Tags: []string{"billing"}},
+	}
+	for _, email := range emails {
+		email.From = Address{Email: "info@test.com"}
+		email.Text = "test"
+		_ = mailer.Send(email)
+		clock.Advance(time.Hour)
+	}
+
+	testCases := []struct {
+		name     string
+		query    MessageQuery
+		subjects []string
+	}{
+		{
+			name:     "Should return every email, the most recent first",
+			subjects: []string{"Your invoice #43", "Your invoice #42", "Welcome"},
+		},
+		{
+			name:     "Should match the recipient case-insensitively",
+			query:    MessageQuery{Recipient: "a@TEST.com"},
+			subjects: []string{"Your invoice #42", "Welcome"},
+		},
+		{
+			name:     "Should match the Cc recipients",
+			query:    MessageQuery{Recipient: "b@test.com"},
+			subjects: []string{"Your invoice #42"},
+		},
+		{
+			name:     "Should match a substring of the subject",
+			query:    MessageQuery{Recipient: "a@test.com", Subject: "INVOICE"},
+			subjects: []string{"Your invoice #42"},
+		},
+		{
+			name:     "Should match the tag",
+			query:    MessageQuery{Tag: "billing"},
+			subjects: []string{"Your invoice #43", "Your invoice #42"},
+		},
+		{
+			name:     "Should match the state",
+			query:    MessageQuery{Tag: "billing", State: MessageFailed},
+			subjects: []string{"Your invoice #43"},
+		},
+		{
+			name: "Should match the date range",
+			query: MessageQuery{
+				Since: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				Until: time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC),
+			},
+			subjects: []string{"Your invoice #42"},
+		},
+		{
+			name:     "Should limit the results",
+			query:    MessageQuery{Limit: 1},
+			subjects: []string{"Your invoice #43"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := mailer.Query(context.Background(), tc.query)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			var subjects []string
+			for _, result := range results {
+				subjects = append(subjects, result.Subject)
+			}
+			if len(subjects) != len(tc.subjects) {
+				t.Fatalf("Expected %v, got %v", tc.subjects, subjects)
+			}
+			for i := range subjects {
+				if subjects[i] != tc.subjects[i] {
+					t.Errorf("Expected %v, got %v", tc.subjects, subjects)
+				}
+			}
+		})
+	}
+
+	results, _ := mailer.Query(context.Background(), MessageQuery{State: MessageFailed})
+	if len(results) != 1 || results[0].Error != "mailbox unavailable" || results[0].Attempts != 1 {
+		t.Errorf("Expected the failed email with its error, got %+v", results)
+	}
+}
+
+func TestMailer_QueryUnsupported(t *testing.T) {
+	mailer := NewMailer(MailCfg{APIService: RESEND, APIKey: MailAPIKey, mailerClient: &mockMailerClient{}})
+	defer mailer.Close()
+
+	if _, err := mailer.Query(context.Background(), MessageQuery{}); !errors.Is(err, ErrQueryUnsupported) {
+		t.Errorf("Expected ErrQueryUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package mailer
+
+import "errors"
+
+var (
+	// ErrMessageCanceled is returned when an email is canceled before being sent.
+	ErrMessageCanceled = errors.New("message canceled before it was sent")
+	// ErrMailerClosed is returned by Send and Enqueue once the mailer is closed.
+	ErrMailerClosed = errors.New("mailer closed")
+)
+
+// queuedEmail is an email waiting to be sent by the mailer.
+type queuedEmail struct {
+	id  string
+	msg Mail
+	// result receives the outcome of Send, it is nil for Enqueue.
+	result chan error
+	// attempts counts the sends of the email to its provider, kept when it is
+	// requeued for the audit trail to number its attempts.
+	attempts int
+}
+
+// Enqueue queues the email without waiting for it to be sent and returns its id,
+// which can be passed to Cancel. Failures are reported to the EventHandler. It
+// returns ErrMailerClosed once the mailer is closed.
+func (m *Mailer) Enqueue(msg Mail) (string, error) {
+	email, err := m.enqueue(msg, nil)
+	if err != nil {
+		return "", err
+	}
+	return email.id, nil
+}
+
+// Cancel removes a queued email that was not sent yet, e.g. when the user deleted
+// their account, and reports whether it was canceled.
+func (m *Mailer) Cancel(id string) bool {
+	if !m.dequeue(id) {
+		return false
+	}
+	m.statuses.set(id, MessageCanceled, nil, m.clock.Now())
+	return true
+}
+
+// enqueue queues the email unless the mailer is closed. The close lock is held
+// until the email is in the queue, a Close in the meantime failing the email
+// instead of waiting for room in the queue.
+func (m *Mailer) enqueue(msg Mail, result chan error) (*queuedEmail, error) {
+	m.closeMu.RLock()
+	defer m.closeMu.RUnlock()
+	if m.closed {
+		return nil, ErrMailerClosed
+	}
+
+	email := &queuedEmail{id: newMessageIDFrom(m.rand), msg: dedupeRecipients(msg), result: result}
+
+	m.pendingMu.Lock()
+	m.pending[email.id] = mailAddresses(email.msg)
+	m.pendingMu.Unlock()
+	m.statuses.set(email.id, MessageQueued, nil, m.clock.Now())
+
+	select {
+	case m.emailToSend <- email:
+		return email, nil
+	case <-m.done:
+		m.dequeue(email.id)
+		m.statuses.set(email.id, MessageFailed, ErrMailerClosed, m.clock.Now())
+		return nil, ErrMailerClosed
+	}
+}
+
+// dequeue removes the email from the pending ones, it returns false when it was
+// already canceled or sent.
+func (m *Mailer) dequeue(id string) bool {
+	m.pendingMu.Lock()
+	defer m.pendingMu.Unlock()
+	if _, ok := m.pending[id]; !ok {
+		return false
+	}
+	delete(m.pending, id)
+	return true
+}
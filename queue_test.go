@@ -0,0 +1,76 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMailer_Cancel(t *testing.T) {
+	release := make(chan struct{})
+	sent := make(chan string, 2)
+	var events []Event
+	mailer := NewMailer(MailCfg{
+		APIService: RESEND,
+		APIKey:     MailAPIKey,
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			<-release
+			sent <- joinAddresses(msg.To)
+			return nil
+		}},
+		EventHandler: func(event Event) {
+			events = append(events, event)
+		},
+	})
+	defer mailer.Close()
+
+	first, _ := mailer.Enqueue(Mail{To: Addresses("first@example.com")})
+	second, _ := mailer.Enqueue(Mail{To: Addresses("second@example.com")})
+	third, _ := mailer.Enqueue(Mail{To: Addresses("third@example.com")})
+
+	if first == "" || first == second {
+		t.Fatalf("Expected distinct message ids, got %q and %q", first, second)
+	}
+	if !mailer.Cancel(second) {
+		t.Errorf("Expected the queued email to be canceled")
+	}
+	if mailer.Cancel(second) {
+		t.Errorf("Expected a canceled email not to be canceled twice")
+	}
+	if mailer.Cancel("unknown") {
+		t.Errorf("Expected an unknown id not to be canceled")
+	}
+	close(release)
+
+	for _, expected := range []string{"first@example.com", "third@example.com"} {
+		select {
+		case to := <-sent:
+			if to != expected {
+				t.Errorf("Expected %s to be sent, got %s", expected, to)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %s", expected)
+		}
+	}
+	if mailer.Cancel(third) {
+		t.Errorf("Expected a sent email not to be canceled")
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected no events, got %+v", events)
+	}
+}
+
+func TestMailer_EnqueueAfterClose(t *testing.T) {
+	mailer := NewMailer(MailCfg{APIService: RESEND, APIKey: MailAPIKey, mailerClient: &mockMailerClient{}})
+	mailer.Close()
+
+	if id, err := mailer.Enqueue(Mail{To: Addresses("a@test.com")}); !errors.Is(err, ErrMailerClosed) || id != "" {
+		t.Errorf("Expected ErrMailerClosed, got %q %v", id, err)
+	}
+	if err := mailer.Send(Mail{To: Addresses("a@test.com")}); !errors.Is(err, ErrMailerClosed) {
+		t.Errorf("Expected ErrMailerClosed, got %v", err)
+	}
+	if len(mailer.pending) != 0 {
+		t.Errorf("Expected nothing pending, got %v", mailer.pending)
+	}
+}
@@ -0,0 +1,237 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requeueRetryDelay is how long a deferred email waits for room in a full queue.
+const requeueRetryDelay = 100 * time.Millisecond
+
+// QuotaPeriod is the window over which a quota is counted, in UTC.
+type QuotaPeriod string
+
+const (
+	QuotaDaily   QuotaPeriod = "daily"
+	QuotaMonthly QuotaPeriod = "monthly"
+)
+
+// QuotaAction is what happens to the emails exceeding a quota.
+type QuotaAction int
+
+const (
+	// QuotaReject fails the emails with ErrQuotaExceeded.
+	QuotaReject QuotaAction = iota
+	// QuotaDefer queues the emails again at the start of the next window. Send
+	// returns a *DeferredError instead of waiting for it.
+	QuotaDefer
+)
+
+// Quota limits the number of emails sent per period. It applies to every email
+// unless it is restricted to a tenant or a tag.
+type Quota struct {
+	// Tenant restricts the quota to the emails of the tenant.
+	Tenant string
+	// Tag restricts the quota to the emails with the tag.
+	Tag    string
+	Period QuotaPeriod
+	Limit  int64
+	Action QuotaAction
+}
+
+// key returns the counter key of the quota for the window starting at start.
+func (q Quota) key(start time.Time) string {
+	scope := "global"
+	switch {
+	case q.Tenant != "" && q.Tag != "":
+		scope = "tenant=" + q.Tenant + ",tag=" + q.Tag
+	case q.Tenant != "":
+		scope = "tenant=" + q.Tenant
+	case q.Tag != "":
+		scope = "tag=" + q.Tag
+	}
+	return fmt.Sprintf("%s:%s:%s", scope, q.Period, start.Format(time.DateOnly))
+}
+
+// window returns the start and the end of the window containing now.
+func (q Quota) window(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	if q.Period == QuotaMonthly {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+func (q Quota) matches(msg Mail) bool {
+	if q.Tenant != "" && q.Tenant != msg.TenantID {
+		return false
+	}
+	if q.Tag != "" {
+		for _, tag := range msg.Tags {
+			if tag == q.Tag {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// QuotaStore holds the quota counters, e.g. in Redis to share them between instances.
+type QuotaStore interface {
+	// Increment adds n to the counter and returns its new value. The counter
+	// can be forgotten after expiresAt.
+	Increment(ctx context.Context, key string, n int64, expiresAt time.Time) (int64, error)
+}
+
+// MemoryQuotaStore is a QuotaStore kept in memory.
+type MemoryQuotaStore struct {
+	mu       sync.Mutex
+	counters map[string]quotaCounter
+	// clock tells the time the expired counters are pruned at, the clock of the
+	// mailer for its default store.
+	clock Clock
+}
+
+type quotaCounter struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// NewMemoryQuotaStore creates an empty in-memory quota store.
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{counters: make(map[string]quotaCounter), clock: systemClock{}}
+}
+
+// Increment adds n to the counter and returns its new value.
+func (s *MemoryQuotaStore) Increment(ctx context.Context, key string, n int64, expiresAt time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	for k, counter := range s.counters {
+		if now.After(counter.expiresAt) {
+			delete(s.counters, k)
+		}
+	}
+
+	counter := s.counters[key]
+	counter.value += n
+	counter.expiresAt = expiresAt
+	s.counters[key] = counter
+	return counter.value, nil
+}
+
+// DeferredError is returned by Send for an email deferred by a QuotaDefer quota or
+// a rate limited provider. The email was not sent, it can be sent again after Until.
+// The emails queued with Enqueue are queued again instead.
+type DeferredError struct {
+	Until time.Time
+	Err   error
+}
+
+func (e *DeferredError) Error() string {
+	return fmt.Sprintf("message deferred: %v", e.Err)
+}
+
+func (e *DeferredError) Unwrap() error {
+	return e.Err
+}
+
+// deferredUntil returns when the email failed with a quota or rate limit
+// deferral can be sent again.
+func deferredUntil(err error) (time.Time, bool) {
+	var deferral *quotaDeferral
+	if errors.As(err, &deferral) {
+		return deferral.until, true
+	}
+	var rateLimit *rateLimitDeferral
+	if errors.As(err, &rateLimit) {
+		return rateLimit.until, true
+	}
+	return time.Time{}, false
+}
+
+// quotaDeferral is returned by send when an email must be queued again at until.
+type quotaDeferral struct {
+	quota Quota
+	until time.Time
+}
+
+func (d *quotaDeferral) Error() string {
+	return fmt.Sprintf("%s quota of %d deferred until %s", d.quota.Period, d.quota.Limit, d.until.Format(time.RFC3339))
+}
+
+// quotaReservation holds the counters incremented for an email.
+type quotaReservation struct {
+	store QuotaStore
+	keys  []string
+	ends  []time.Time
+}
+
+// release gives back the email to the counters, when it could not be sent.
+func (r *quotaReservation) release() {
+	for i, key := range r.keys {
+		r.store.Increment(context.Background(), key, -1, r.ends[i])
+	}
+}
+
+// reserveQuotas counts the email against every matching quota, releasing them
+// all when one is exceeded.
+func (m *Mailer) reserveQuotas(msg Mail, quotas []Quota, now time.Time) (*quotaReservation, error) {
+	reservation := &quotaReservation{store: m.quotaStore}
+	for _, quota := range quotas {
+		if quota.Limit <= 0 || !quota.matches(msg) {
+			continue
+		}
+
+		start, end := quota.window(now)
+		key := quota.key(start)
+		count, err := m.quotaStore.Increment(context.Background(), key, 1, end)
+		if err != nil {
+			reservation.release()
+			return nil, err
+		}
+		reservation.keys = append(reservation.keys, key)
+		reservation.ends = append(reservation.ends, end)
+
+		if count > quota.Limit {
+			reservation.release()
+			if quota.Action == QuotaDefer {
+				return nil, &quotaDeferral{quota: quota, until: end}
+			}
+			return nil, fmt.Errorf("%w: %s", ErrQuotaExceeded, strings.ReplaceAll(key, ":", " "))
+		}
+	}
+	return reservation, nil
+}
+
+// requeue queues the email again at until, unless the mailer was closed. The
+// close lock is only held for a send that cannot block, a full queue being retried
+// after requeueRetryDelay, so that Close never waits for room in the queue.
+func (m *Mailer) requeue(email *queuedEmail, until time.Time) {
+	m.pendingMu.Lock()
+	m.pending[email.id] = mailAddresses(email.msg)
+	m.pendingMu.Unlock()
+
+	var push func()
+	push = func() {
+		m.closeMu.RLock()
+		defer m.closeMu.RUnlock()
+		if m.closed {
+			return
+		}
+		select {
+		case m.emailToSend <- email:
+		default:
+			m.clock.AfterFunc(requeueRetryDelay, push)
+		}
+	}
+	m.clock.AfterFunc(until.Sub(m.clock.Now()), push)
+}
@@ -0,0 +1,120 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMailer_ReserveQuotas(t *testing.T) {
+	mailer := &Mailer{quotaStore: NewMemoryQuotaStore()}
+	// The last hour of a month in the future, as the memory store forgets expired counters.
+	nextMonth := time.Date(time.Now().Year()+1, time.June, 1, 0, 0, 0, 0, time.UTC)
+	now := nextMonth.Add(-time.Hour)
+	quotas := []Quota{
+		{Period: QuotaMonthly, Limit: 3},
+		{Tag: "digest", Period: QuotaDaily, Limit: 1, Action: QuotaDefer},
+	}
+
+	if _, err := mailer.reserveQuotas(Mail{Tags: []string{"digest"}}, quotas, now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err := mailer.reserveQuotas(Mail{Tags: []string{"digest"}}, quotas, now)
+	var deferral *quotaDeferral
+	if !errors.As(err, &deferral) {
+		t.Fatalf("Expected the tag quota to defer, got %v", err)
+	}
+	if !deferral.until.Equal(nextMonth) {
+		t.Errorf("Expected deferral until %s, got %s", nextMonth, deferral.until)
+	}
+
+	reservation, err := mailer.reserveQuotas(Mail{}, quotas, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	reservation.release()
+
+	for i := 0; i < 2; i++ {
+		if _, err := mailer.reserveQuotas(Mail{}, quotas, now); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if _, err := mailer.reserveQuotas(Mail{}, quotas, now); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if _, err := mailer.reserveQuotas(Mail{}, quotas, now.Add(time.Hour)); err != nil {
+		t.Errorf("Expected the next month to start a new window, got %v", err)
+	}
+}
+
+func TestMemoryQuotaStore_Increment(t *testing.T) {
+	store := NewMemoryQuotaStore()
+	ctx := context.Background()
+
+	store.Increment(ctx, "expired", 5, time.Now().Add(-time.Second))
+	if n, _ := store.Increment(ctx, "expired", 1, time.Now().Add(time.Hour)); n != 1 {
+		t.Errorf("Expected an expired counter to restart, got %d", n)
+	}
+	if n, _ := store.Increment(ctx, "expired", 1, time.Now().Add(time.Hour)); n != 2 {
+		t.Errorf("Expected 2, got %d", n)
+	}
+
+	clock := &fakeClock{now: time.Now()}
+	store.clock = clock
+	clock.Advance(2 * time.Hour)
+	if n, _ := store.Increment(ctx, "expired", 1, clock.Now().Add(time.Hour)); n != 1 {
+		t.Errorf("Expected the counter to expire at the time of the clock, got %d", n)
+	}
+}
+
+func TestMailer_Requeue(t *testing.T) {
+	sent := make(chan string, 1)
+	mailer := NewMailer(MailCfg{
+		APIService: RESEND,
+		APIKey:     MailAPIKey,
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			sent <- joinAddresses(msg.To)
+			return nil
+		}},
+	})
+	defer mailer.Close()
+
+	mailer.requeue(&queuedEmail{id: "deferred", msg: Mail{To: Addresses("test@example.com")}}, time.Now().Add(10*time.Millisecond))
+	if stats := mailer.Stats(); stats.Queued != 1 {
+		t.Errorf("Expected the deferred email to be queued, got %d", stats.Queued)
+	}
+
+	select {
+	case to := <-sent:
+		if to != "test@example.com" {
+			t.Errorf("Unexpected recipient %s", to)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the deferred email")
+	}
+}
+
+func TestMailer_RequeueFullQueue(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	mailer := &Mailer{clock: clock, emailToSend: make(chan *queuedEmail, 1), pending: make(map[string][]string)}
+	mailer.emailToSend <- &queuedEmail{id: "waiting"}
+
+	// The queue is full, the deferred email waits without blocking the timer.
+	mailer.requeue(&queuedEmail{id: "deferred"}, clock.Now())
+	clock.Advance(0)
+	if email := <-mailer.emailToSend; email.id != "waiting" {
+		t.Fatalf("Expected the waiting email first, got %s", email.id)
+	}
+
+	clock.Advance(requeueRetryDelay)
+	select {
+	case email := <-mailer.emailToSend:
+		if email.id != "deferred" {
+			t.Errorf("Expected the deferred email, got %s", email.id)
+		}
+	default:
+		t.Fatalf("Expected the deferred email to be queued once there is room")
+	}
+}
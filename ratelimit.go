@@ -0,0 +1,127 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultRateLimitBackoff is how long a provider is paused when it rate limits
+// a request without telling when to retry.
+const defaultRateLimitBackoff = time.Minute
+
+// rateLimitResetHeaders are the headers telling when the rate limit of a provider
+// resets, either in seconds from now or as a Unix timestamp.
+var rateLimitResetHeaders = []string{"X-RateLimit-Reset", "RateLimit-Reset"}
+
+// retryAfter returns how long to wait before sending another request, according
+// to the Retry-After or rate limit headers of a rate limited response.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return at.Sub(now)
+		}
+	}
+
+	for _, name := range rateLimitResetHeaders {
+		value, err := strconv.ParseInt(header.Get(name), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch {
+		case value > 1e12:
+			return time.UnixMilli(value).Sub(now)
+		case value > 1e9:
+			return time.Unix(value, 0).Sub(now)
+		default:
+			return time.Duration(value) * time.Second
+		}
+	}
+	return 0
+}
+
+// isRateLimited reports whether the provider rejected the request because of its
+// rate limit, or because it is overloaded and asked to retry later.
+func isRateLimited(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return nil, false
+	}
+	return apiErr, apiErr.StatusCode == http.StatusTooManyRequests ||
+		apiErr.StatusCode == http.StatusServiceUnavailable && apiErr.RetryAfter > 0
+}
+
+// rateLimitDeferral is returned by send when the provider is rate limited, the
+// email being queued again at until.
+type rateLimitDeferral struct {
+	provider APIServiceType
+	until    time.Time
+}
+
+func (d *rateLimitDeferral) Error() string {
+	return fmt.Sprintf("%s rate limited until %s", d.provider, d.until.Format(time.RFC3339))
+}
+
+// rateLimits holds the clients paused after being rate limited, so that the
+// emails queued for them wait instead of burning the quota further.
+type rateLimits struct {
+	mu     sync.Mutex
+	paused map[MailerClient]time.Time
+}
+
+// pausedUntil returns when the client can be sent to again, if it is paused.
+func (r *rateLimits) pausedUntil(client MailerClient, now time.Time) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	until, ok := r.paused[client]
+	if ok && !now.Before(until) {
+		delete(r.paused, client)
+		return time.Time{}, false
+	}
+	return until, ok
+}
+
+// pause pauses the client until the given time, unless it is already paused for longer.
+func (r *rateLimits) pause(client MailerClient, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.paused == nil {
+		r.paused = make(map[MailerClient]time.Time)
+	}
+	if until.After(r.paused[client]) {
+		r.paused[client] = until
+	}
+}
+
+// rateLimited pauses the client when err is a rate limited response, returning
+// when it can be sent to again.
+func (m *Mailer) rateLimited(client MailerClient, err error) (time.Time, bool) {
+	apiErr, ok := isRateLimited(err)
+	if !ok {
+		return time.Time{}, false
+	}
+	wait := apiErr.RetryAfter
+	if wait <= 0 {
+		wait = m.rateLimitBackoff
+	}
+	until := m.clock.Now().Add(wait)
+	m.rateLimits.pause(client, until)
+	return until, true
+}
+
+// delivered reports whether one of the responses is a success, e.g. a chunk of
+// the recipients was sent before the provider rate limited the next one.
+func delivered(responses []ProviderResponse) bool {
+	for _, res := range responses {
+		if res.StatusCode >= 200 && res.StatusCode <= 299 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected time.Duration
+	}{
+		{name: "seconds", header: http.Header{"Retry-After": {"120"}}, expected: 2 * time.Minute},
+		{name: "date", header: http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, expected: time.Minute},
+		{name: "reset timestamp", header: http.Header{"X-Ratelimit-Reset": {"1893488430"}}, expected: 30 * time.Second},
+		{name: "reset milliseconds", header: http.Header{"X-Ratelimit-Reset": {"1893488410000"}}, expected: 10 * time.Second},
+		{name: "reset seconds", header: http.Header{"Ratelimit-Reset": {"5"}}, expected: 5 * time.Second},
+		{name: "none", header: http.Header{}, expected: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := retryAfter(test.header, now); got != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestMailer_RateLimited(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)}
+	sendgrid := newSendGrid(sendgridParams{apiKey: "key"}).(*sendgridMailer)
+	sendgrid.baseURL = server.URL
+	mailer := NewMailer(MailCfg{Clock: clock, mailerClient: sendgrid})
+	defer mailer.Close()
+
+	first, _ := mailer.Enqueue(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Text: "test"})
+	for clock.pendingTimers() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	second, _ := mailer.Enqueue(Mail{From: Address{Email: "info@test.com"}, To: Addresses("b@test.com"), Text: "test"})
+	for clock.pendingTimers() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("Expected the paused provider to get no more requests, got %d", n)
+	}
+
+	clock.Advance(30 * time.Second)
+	for _, id := range []string{first, second} {
+		for {
+			status, _ := mailer.Status(id)
+			if status.State == MessageSent {
+				break
+			}
+			if status.State == MessageFailed {
+				t.Fatalf("Expected %s to be sent once the provider resumed, got %s", id, status.Error)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("Expected 3 requests, got %d", n)
+	}
+}
@@ -0,0 +1,106 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/textproto"
+	"time"
+)
+
+// rawSendAttempts is the number of attempts of SendRaw on transient failures,
+// waiting rawSendBackoff times the attempt between them.
+const (
+	rawSendAttempts = 3
+	rawSendBackoff  = time.Second
+)
+
+// ErrRawUnsupported is returned by SendRaw when the API service cannot send
+// pre-built messages.
+var ErrRawUnsupported = errors.New("raw messages are not supported by the API service")
+
+// RawSender is implemented by the clients able to send a pre-built MIME message
+// as-is, i.e. SMTP, LMTP, sendmail, MX, Amazon SES and NULL.
+type RawSender interface {
+	SendRaw(ctx context.Context, from string, rcpts []string, message []byte) error
+}
+
+// SendRaw sends a pre-built MIME message as-is to the recipients, e.g. generated
+// with other tooling. The message bypasses the queue, the middlewares and the
+// preferences but waits while the mailer is paused, is bounded by MaxConcurrentSends
+// and is counted in its Stats. Every attempt is recorded in the AuditStore, the
+// transient failures, e.g. a 4xx reply or an unreachable server, being retried
+// up to 3 times. A rate limited provider returns a *DeferredError.
+func (m *Mailer) SendRaw(ctx context.Context, envelopeFrom string, rcpts []string, r io.Reader) error {
+	sender, ok := m.mailerClient.(RawSender)
+	if !ok {
+		return ErrRawUnsupported
+	}
+	if len(rcpts) == 0 {
+		return fmt.Errorf("%w: no recipients", ErrInvalidMail)
+	}
+
+	message, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	id := newMessageIDFrom(m.rand)
+	for attempt := 1; ; attempt++ {
+		if err := m.waitIfPaused(ctx); err != nil {
+			return err
+		}
+		if until, ok := m.rateLimits.pausedUntil(m.mailerClient, m.clock.Now()); ok {
+			return &DeferredError{Until: until, Err: &rateLimitDeferral{provider: m.apiService, until: until}}
+		}
+
+		err := m.sendRawAttempt(ctx, sender, envelopeFrom, rcpts, message)
+		m.audit(id, attempt, m.apiService, rawAuditMail(envelopeFrom, rcpts, message), err)
+		if until, ok := m.rateLimited(m.mailerClient, err); ok {
+			return &DeferredError{Until: until, Err: err}
+		}
+		if err == nil || attempt == rawSendAttempts || !isTransientRawError(err) || ctx.Err() != nil {
+			return err
+		}
+		var partial *PartialDeliveryError
+		if errors.As(err, &partial) {
+			rcpts = partial.Failed
+		}
+		m.clock.Sleep(time.Duration(attempt) * rawSendBackoff)
+	}
+}
+
+func (m *Mailer) sendRawAttempt(ctx context.Context, sender RawSender, envelopeFrom string, rcpts []string, message []byte) error {
+	release, err := m.sendLimiter.acquire(ctx, m.apiService)
+	if err != nil {
+		return err
+	}
+	m.stats.start()
+	err = sender.SendRaw(ctx, envelopeFrom, rcpts, message)
+	release()
+	m.stats.done(m.apiService, err, m.clock.Now())
+	return err
+}
+
+// isTransientRawError reports whether a raw send can be retried, the server
+// being unreachable or having answered with a 4xx reply.
+func isTransientRawError(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+	return isProviderDown(err)
+}
+
+// rawAuditMail returns the email audited for a raw message, its content hash
+// covering the whole message.
+func rawAuditMail(envelopeFrom string, rcpts []string, message []byte) Mail {
+	msg := Mail{From: Address{Email: envelopeFrom}, To: Addresses(rcpts...), Text: string(message)}
+	if parsed, err := mail.ReadMessage(bytes.NewReader(message)); err == nil {
+		msg.Subject = parsed.Header.Get("Subject")
+	}
+	return msg
+}
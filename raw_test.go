@@ -0,0 +1,130 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMailer_SendRaw(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sendmail is not available on windows")
+	}
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+	script := filepath.Join(dir, "sendmail")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+output+"\ncat >> "+output+"\n"), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMailer(MailCfg{APIService: SENDMAIL, SendmailPath: script})
+	defer m.Close()
+
+	message := "From: info@test.com\r\nTo: a@test.com\r\nSubject: raw\r\n\r\nHello\r\n"
+	err = m.SendRaw(context.Background(), "bounce@test.com", []string{"a@test.com", "b@test.com"}, strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "-i -f bounce@test.com -- a@test.com b@test.com\n"+message) {
+		t.Errorf("Expected the message to be piped as-is, got %q", content)
+	}
+	if stats := m.Stats(); stats.Providers[SENDMAIL].Sent != 1 {
+		t.Errorf("Expected the message to be counted, got %+v", stats.Providers)
+	}
+}
+
+func TestMailer_SendRawUnsupported(t *testing.T) {
+	m := NewMailer(MailCfg{mailerClient: &mockMailerClient{}})
+	defer m.Close()
+
+	err := m.SendRaw(context.Background(), "info@test.com", []string{"a@test.com"}, strings.NewReader(""))
+	if !errors.Is(err, ErrRawUnsupported) {
+		t.Errorf("Expected ErrRawUnsupported, got %v", err)
+	}
+}
+
+func TestMailer_SendRawPaused(t *testing.T) {
+	m := NewMailer(MailCfg{APIService: SENDMAIL, SendmailPath: "/bin/true"})
+	defer m.Close()
+	m.Pause()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := m.SendRaw(ctx, "info@test.com", []string{"a@test.com"}, strings.NewReader(""))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+type flakyRawClient struct {
+	mockMailerClient
+	errs  []error
+	calls int
+}
+
+func (c *flakyRawClient) SendRaw(ctx context.Context, from string, rcpts []string, message []byte) error {
+	c.calls++
+	if len(c.errs) == 0 {
+		return nil
+	}
+	err := c.errs[0]
+	c.errs = c.errs[1:]
+	return err
+}
+
+func TestMailer_SendRawRetries(t *testing.T) {
+	testCases := []struct {
+		name     string
+		errs     []error
+		expected error
+		attempts int
+	}{
+		{
+			name:     "Should retry the transient failures",
+			errs:     []error{&textproto.Error{Code: 451, Msg: "try again later"}, &textproto.Error{Code: 421, Msg: "busy"}},
+			attempts: 3,
+		},
+		{
+			name:     "Should not retry a permanent failure",
+			errs:     []error{&textproto.Error{Code: 550, Msg: "no such user"}},
+			expected: &textproto.Error{Code: 550, Msg: "no such user"},
+			attempts: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Now()}
+			audits := NewMemoryAuditStore()
+			client := &flakyRawClient{errs: tc.errs}
+			m := NewMailer(MailCfg{Clock: clock, AuditStore: audits, mailerClient: client})
+			defer m.Close()
+
+			err := m.SendRaw(context.Background(), "info@test.com", []string{"a@test.com"}, strings.NewReader("Subject: raw\r\n\r\ntest\r\n"))
+			if (err == nil) != (tc.expected == nil) || err != nil && err.Error() != tc.expected.Error() {
+				t.Fatalf("Expected %v, got %v", tc.expected, err)
+			}
+			if client.calls != tc.attempts {
+				t.Errorf("Expected %d attempts, got %d", tc.attempts, client.calls)
+			}
+			records := audits.Records()
+			if len(records) != tc.attempts || records[len(records)-1].Attempt != tc.attempts || records[0].Subject != "raw" {
+				t.Errorf("Expected an audit record per attempt, got %+v", records)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// ErrNotAReport is returned by ParseReport for messages which are neither delivery
+// status notifications nor read receipts.
+var ErrNotAReport = errors.New("not a delivery status notification or read receipt")
+
+// DeliveryNotification requests delivery status notifications (RFC 3461) from the
+// servers supporting the DSN extension. It is sent by the SMTP, sendmail and MX
+// API services and ignored by the others.
+type DeliveryNotification struct {
+	// Notify lists the conditions among "SUCCESS", "FAILURE" and "DELAY" under
+	// which a notification is sent, or "NEVER". Defaults to SUCCESS and FAILURE.
+	Notify []string
+	// FullMessage returns the full message in failure notifications rather than
+	// only its headers. It is not supported by the SMTP API service.
+	FullMessage bool
+}
+
+func (n *DeliveryNotification) notify() []string {
+	if len(n.Notify) == 0 {
+		return []string{"SUCCESS", "FAILURE"}
+	}
+	notify := make([]string, len(n.Notify))
+	for i, condition := range n.Notify {
+		notify[i] = strings.ToUpper(condition)
+	}
+	return notify
+}
+
+func (n *DeliveryNotification) ret() string {
+	if n.FullMessage {
+		return "FULL"
+	}
+	return "HDRS"
+}
+
+// HandleReport parses a delivery status notification or a read receipt received
+// e.g. in the mailbox of the envelope sender, and passes its events to the
+// EventHandler of the mailer.
+func (m *Mailer) HandleReport(r io.Reader) error {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	events, err := ParseReport(raw)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		m.emit(event)
+	}
+	return nil
+}
+
+// ParseReport parses a delivery status notification (RFC 3464) into one event per
+// recipient, or a read receipt (RFC 8098) into an EventRead. The MessageID of the
+// events is the Message-ID of the original message, when reported.
+func ParseReport(raw []byte) ([]Event, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" {
+		return nil, ErrNotAReport
+	}
+	timestamp, err := msg.Header.Date()
+	if err != nil {
+		timestamp = time.Now()
+	}
+
+	var events []Event
+	var messageID string
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status":
+			statusEvents, err := parseDeliveryStatus(part)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, statusEvents...)
+		case "message/disposition-notification":
+			event, err := parseDispositionNotification(part)
+			if err != nil {
+				return nil, err
+			}
+			if event != nil {
+				events = append(events, *event)
+			}
+		case "message/rfc822", "text/rfc822-headers":
+			original, _ := textproto.NewReader(bufio.NewReader(part)).ReadMIMEHeader()
+			messageID = original.Get("Message-Id")
+		}
+	}
+
+	if events == nil {
+		return nil, ErrNotAReport
+	}
+	for i := range events {
+		if events[i].MessageID == "" {
+			events[i].MessageID = messageID
+		}
+		events[i].Timestamp = timestamp
+	}
+	return events, nil
+}
+
+// parseDeliveryStatus returns an event per recipient field group following the
+// per message fields.
+func parseDeliveryStatus(r io.Reader) ([]Event, error) {
+	tp := textproto.NewReader(bufio.NewReader(r))
+	if _, err := tp.ReadMIMEHeader(); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid delivery status: %w", err)
+	}
+
+	var events []Event
+	for {
+		fields, err := tp.ReadMIMEHeader()
+		if len(fields) > 0 {
+			event := Event{
+				Recipient: reportAddress(fields.Get("Final-Recipient")),
+				Reason:    fields.Get("Diagnostic-Code"),
+			}
+			status := fields.Get("Status")
+			switch strings.ToLower(fields.Get("Action")) {
+			case "delivered", "relayed", "expanded":
+				event.Type = EventDelivered
+			case "failed":
+				event.Type = EventBounced
+				event.Permanent = strings.HasPrefix(status, "5")
+			case "delayed":
+				event.Type = EventDelayed
+			}
+			if event.Reason == "" {
+				event.Reason = status
+			}
+			if event.Type != "" {
+				events = append(events, event)
+			}
+		}
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid delivery status: %w", err)
+		}
+	}
+}
+
+// parseDispositionNotification returns an EventRead for a displayed disposition.
+func parseDispositionNotification(r io.Reader) (*Event, error) {
+	fields, err := textproto.NewReader(bufio.NewReader(r)).ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid disposition notification: %w", err)
+	}
+
+	disposition := fields.Get("Disposition")
+	if i := strings.LastIndex(disposition, ";"); i < 0 || !strings.HasPrefix(strings.TrimSpace(strings.ToLower(disposition[i+1:])), "displayed") {
+		return nil, nil
+	}
+	return &Event{
+		Type:      EventRead,
+		MessageID: fields.Get("Original-Message-Id"),
+		Recipient: reportAddress(fields.Get("Final-Recipient")),
+		Reason:    disposition,
+	}, nil
+}
+
+// reportAddress returns the address of a recipient field e.g. "rfc822; a@example.com".
+func reportAddress(field string) string {
+	if i := strings.Index(field, ";"); i >= 0 {
+		field = field[i+1:]
+	}
+	return strings.Trim(strings.TrimSpace(field), "<>")
+}
@@ -0,0 +1,144 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testDeliveryReport = "From: MAILER-DAEMON@mx.test.com\r\n" +
+	"Date: Mon, 02 Jan 2030 15:04:05 +0000\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"r\"\r\n" +
+	"\r\n" +
+	"--r\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Delivery report\r\n" +
+	"--r\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.test.com\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; a@test.com\r\n" +
+	"Action: delivered\r\n" +
+	"Status: 2.0.0\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; b@test.com\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"Diagnostic-Code: smtp; 550 5.1.1 user unknown\r\n" +
+	"\r\n" +
+	"--r\r\n" +
+	"Content-Type: text/rfc822-headers\r\n" +
+	"\r\n" +
+	"Message-ID: <42@sender.com>\r\n" +
+	"Subject: test\r\n" +
+	"\r\n" +
+	"--r--\r\n"
+
+const testReadReceipt = "From: a@test.com\r\n" +
+	"Content-Type: multipart/report; report-type=disposition-notification; boundary=\"r\"\r\n" +
+	"\r\n" +
+	"--r\r\n" +
+	"Content-Type: message/disposition-notification\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; a@test.com\r\n" +
+	"Original-Message-ID: <42@sender.com>\r\n" +
+	"Disposition: manual-action/MDN-sent-manually; displayed\r\n" +
+	"\r\n" +
+	"--r--\r\n"
+
+func TestParseReport(t *testing.T) {
+	events, err := ParseReport([]byte(testDeliveryReport))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected two events, got %+v", events)
+	}
+	if events[0].Type != EventDelivered || events[0].Recipient != "a@test.com" || events[0].MessageID != "<42@sender.com>" {
+		t.Errorf("Unexpected delivered event %+v", events[0])
+	}
+	if events[1].Type != EventBounced || !events[1].Permanent || events[1].Reason != "smtp; 550 5.1.1 user unknown" {
+		t.Errorf("Unexpected bounced event %+v", events[1])
+	}
+	if events[1].Timestamp.Year() != 2030 {
+		t.Errorf("Expected the date of the report, got %v", events[1].Timestamp)
+	}
+
+	events, err = ParseReport([]byte(testReadReceipt))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventRead || events[0].MessageID != "<42@sender.com>" {
+		t.Errorf("Unexpected read receipt events %+v", events)
+	}
+
+	if _, err := ParseReport([]byte("Subject: hello\r\n\r\nhello")); err != ErrNotAReport {
+		t.Errorf("Expected ErrNotAReport, got %v", err)
+	}
+}
+
+func TestMailer_HandleReport(t *testing.T) {
+	var events []Event
+	mailer := NewMailer(MailCfg{mailerClient: &mockMailerClient{}, EventHandler: func(e Event) { events = append(events, e) }})
+	defer mailer.Close()
+
+	if err := mailer.HandleReport(strings.NewReader(testReadReceipt)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventRead {
+		t.Errorf("Expected a read event, got %+v", events)
+	}
+}
+
+func TestMailer_SendReadReceipt(t *testing.T) {
+	sent := make(chan Mail, 1)
+	mailer := NewMailer(MailCfg{mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+		sent <- msg
+		return nil
+	}}})
+	defer mailer.Close()
+
+	headers := map[string]string{"X-Campaign": "42"}
+	mailer.Send(Mail{To: Addresses("a@test.com"), ReadReceiptTo: "receipts@test.com", Headers: headers})
+
+	msg := <-sent
+	if msg.Headers["Disposition-Notification-To"] != "receipts@test.com" || msg.Headers["X-Campaign"] != "42" {
+		t.Errorf("Expected the read receipt header, got %v", msg.Headers)
+	}
+	if len(headers) != 1 {
+		t.Errorf("Expected the headers of the caller to be left alone, got %v", headers)
+	}
+
+	message, _ := buildMessage(msg)
+	if !strings.Contains(message, "Disposition-Notification-To: receipts@test.com\r\n") {
+		t.Errorf("Expected the header in the message, got %s", message)
+	}
+}
+
+func TestSendmail_SendDeliveryNotification(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sendmail is not available on windows")
+	}
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+	script := filepath.Join(dir, "sendmail")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+output+"\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	sendmail := newSendmail(sendmailParams{path: script})
+	err := sendmail.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Text: "test", DeliveryNotification: &DeliveryNotification{}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	args, _ := os.ReadFile(output)
+	if !strings.HasPrefix(string(args), "-N success,failure -R hdrs -i -f info@test.com") {
+		t.Errorf("Expected the DSN arguments, got %q", args)
+	}
+}
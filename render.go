@@ -0,0 +1,197 @@
+package mailer
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+)
+
+// TemplateVariablesError is returned by RenderStrict when the data does not match
+// the variables used by the template.
+type TemplateVariablesError struct {
+	// Template is the name of the template.
+	Template string
+	// Missing are the variables used by the template but missing from the data.
+	Missing []string
+	// Unused are the variables of the data not used by the template.
+	Unused []string
+}
+
+func (e *TemplateVariablesError) Error() string {
+	var problems []string
+	if len(e.Missing) > 0 {
+		problems = append(problems, "missing variables "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Unused) > 0 {
+		problems = append(problems, "unused variables "+strings.Join(e.Unused, ", "))
+	}
+	return fmt.Sprintf("template %s: %s", e.Template, strings.Join(problems, "; "))
+}
+
+// RenderedMessage is the content of a rendered template.
+type RenderedMessage struct {
+	Subject string
+	Html    string
+	Text    string
+}
+
+// Render renders the template with the data without sending it, e.g. to preview
+// an email in an application or store a rendered copy. The text is generated
+// from the html when the template has none, see HTMLToText.
+func Render(tpl Template, data any) (RenderedMessage, error) {
+	msg, err := tpl.Render(data)
+	if err != nil {
+		return RenderedMessage{}, err
+	}
+	if msg.Text == "" && msg.Html != "" {
+		msg.Text = HTMLToText(msg.Html)
+	}
+	return RenderedMessage{Subject: msg.Subject, Html: msg.Html, Text: msg.Text}, nil
+}
+
+// Render executes the subject, html and text of the template with the data, the
+// html being escaped with html/template. The returned email has no recipients.
+func (t Template) Render(data any) (Mail, error) {
+	return t.render(data, "missingkey=default")
+}
+
+// RenderStrict is Render failing with a *TemplateVariablesError when a variable
+// used by the template is missing from the data or a key of the data is unused,
+// instead of rendering "<no value>" in front of customers.
+func (t Template) RenderStrict(data map[string]any) (Mail, error) {
+	used := make(map[string]bool)
+	for _, text := range []string{t.Subject, t.Text, t.Html} {
+		if err := templateVariables(text, used); err != nil {
+			return Mail{}, err
+		}
+	}
+
+	verr := &TemplateVariablesError{Template: t.Name}
+	for name := range used {
+		if _, ok := data[name]; !ok {
+			verr.Missing = append(verr.Missing, name)
+		}
+	}
+	for name := range data {
+		if !used[name] {
+			verr.Unused = append(verr.Unused, name)
+		}
+	}
+	if len(verr.Missing) > 0 || len(verr.Unused) > 0 {
+		sort.Strings(verr.Missing)
+		sort.Strings(verr.Unused)
+		return Mail{}, verr
+	}
+
+	// Nested maps with missing keys still fail at execution.
+	return t.render(data, "missingkey=error")
+}
+
+func (t Template) render(data any, option string) (Mail, error) {
+	var msg Mail
+	var err error
+
+	if err := checkDataDepth(t.Name, data, t.Limits.maxDepth()); err != nil {
+		return msg, err
+	}
+	budget := newRenderBudget(t.Name, t.Limits)
+	defer budget.stop()
+
+	if msg.Subject, err = renderText(budget, t.Name+":subject", t.Subject, data, option); err != nil {
+		return msg, err
+	}
+	if msg.Text, err = renderText(budget, t.Name+":text", t.Text, data, option); err != nil {
+		return msg, err
+	}
+	if t.Html != "" {
+		tpl, err := htmltemplate.New(t.Name + ":html").Option(option).Parse(t.Html)
+		if err != nil {
+			return msg, err
+		}
+		if msg.Html, err = budget.execute(tpl, data); err != nil {
+			return msg, err
+		}
+	}
+	return msg, nil
+}
+
+func renderText(budget *renderBudget, name string, text string, data any, option string) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tpl, err := texttemplate.New(name).Option(option).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	return budget.execute(tpl, data)
+}
+
+// templateVariables adds the top level variables used by the template text to used,
+// i.e. the fields of the data accessed as {{.Name}} or {{$.Name}}.
+func templateVariables(text string, used map[string]bool) error {
+	if text == "" {
+		return nil
+	}
+	tpl, err := texttemplate.New("").Parse(text)
+	if err != nil {
+		return err
+	}
+	for _, t := range tpl.Templates() {
+		if t.Tree != nil {
+			walkTemplateNode(t.Tree.Root, true, used)
+		}
+	}
+	return nil
+}
+
+// walkTemplateNode collects the variables of the node, dotIsRoot reporting whether
+// the dot is still the data i.e. outside of range and with blocks.
+func walkTemplateNode(node parse.Node, dotIsRoot bool, used map[string]bool) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, n := range node.Nodes {
+			walkTemplateNode(n, dotIsRoot, used)
+		}
+	case *parse.ActionNode:
+		walkTemplateNode(node.Pipe, dotIsRoot, used)
+	case *parse.TemplateNode:
+		walkTemplateNode(node.Pipe, dotIsRoot, used)
+	case *parse.IfNode:
+		walkTemplateNode(node.Pipe, dotIsRoot, used)
+		walkTemplateNode(node.List, dotIsRoot, used)
+		walkTemplateNode(node.ElseList, dotIsRoot, used)
+	case *parse.RangeNode:
+		walkTemplateNode(node.Pipe, dotIsRoot, used)
+		walkTemplateNode(node.List, false, used)
+		walkTemplateNode(node.ElseList, dotIsRoot, used)
+	case *parse.WithNode:
+		walkTemplateNode(node.Pipe, dotIsRoot, used)
+		walkTemplateNode(node.List, false, used)
+		walkTemplateNode(node.ElseList, dotIsRoot, used)
+	case *parse.PipeNode:
+		if node == nil {
+			return
+		}
+		for _, cmd := range node.Cmds {
+			for _, arg := range cmd.Args {
+				walkTemplateNode(arg, dotIsRoot, used)
+			}
+		}
+	case *parse.ChainNode:
+		walkTemplateNode(node.Node, dotIsRoot, used)
+	case *parse.FieldNode:
+		if dotIsRoot {
+			used[node.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		if node.Ident[0] == "$" && len(node.Ident) > 1 {
+			used[node.Ident[1]] = true
+		}
+	}
+}
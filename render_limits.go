@@ -0,0 +1,186 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRenderTooLarge is returned when a rendered template exceeds RenderLimits.MaxSize.
+	ErrRenderTooLarge = errors.New("rendered template too large")
+	// ErrRenderTimeout is returned when a template takes longer than RenderLimits.Timeout to render.
+	ErrRenderTimeout = errors.New("template rendering timed out")
+	// ErrDataTooDeep is returned when the template data is nested deeper than RenderLimits.MaxDepth.
+	ErrDataTooDeep = errors.New("template data too deep")
+)
+
+const (
+	defaultRenderMaxSize  = 10 << 20
+	defaultRenderTimeout  = 5 * time.Second
+	defaultRenderMaxDepth = 32
+)
+
+// RenderLimits protect the rendering of a template against pathological data, e.g.
+// a huge list ranged over or a cyclic structure. The zero value uses the defaults.
+type RenderLimits struct {
+	// MaxSize is the maximum size in bytes of the subject, html and text rendered
+	// together. Defaults to 10 MiB.
+	MaxSize int
+	// Timeout is the maximum duration of the rendering. Defaults to 5 seconds.
+	Timeout time.Duration
+	// MaxDepth is the maximum nesting of maps, slices, structs and pointers in the
+	// data. Defaults to 32.
+	MaxDepth int
+}
+
+func (l RenderLimits) maxSize() int {
+	if l.MaxSize > 0 {
+		return l.MaxSize
+	}
+	return defaultRenderMaxSize
+}
+
+func (l RenderLimits) timeout() time.Duration {
+	if l.Timeout > 0 {
+		return l.Timeout
+	}
+	return defaultRenderTimeout
+}
+
+func (l RenderLimits) maxDepth() int {
+	if l.MaxDepth > 0 {
+		return l.MaxDepth
+	}
+	return defaultRenderMaxDepth
+}
+
+type templateExecutor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// renderBudget is shared by the parts of a template, so that the size and the
+// timeout apply to the rendering as a whole. Its timer runs from the start of the
+// rendering, instead of a deadline read from the wall clock.
+type renderBudget struct {
+	template  string
+	remaining int
+	timer     *time.Timer
+}
+
+func newRenderBudget(name string, limits RenderLimits) *renderBudget {
+	return &renderBudget{template: name, remaining: limits.maxSize(), timer: time.NewTimer(limits.timeout())}
+}
+
+// stop stops the timer once the rendering is done.
+func (b *renderBudget) stop() {
+	b.timer.Stop()
+}
+
+// execute executes the template within the budget. Templates cannot be
+// interrupted, one past the timeout is abandoned and stops at its next write.
+func (b *renderBudget) execute(tpl templateExecutor, data any) (string, error) {
+	w := &limitedWriter{remaining: b.remaining}
+	done := make(chan error, 1)
+	go func() { done <- tpl.Execute(w, data) }()
+
+	select {
+	case err := <-done:
+		if errors.Is(err, ErrRenderTooLarge) {
+			return "", fmt.Errorf("template %s: %w", b.template, ErrRenderTooLarge)
+		}
+		if err != nil {
+			return "", err
+		}
+		b.remaining = w.remaining
+		return w.buf.String(), nil
+	case <-b.timer.C:
+		w.abandon()
+		return "", fmt.Errorf("template %s: %w", b.template, ErrRenderTimeout)
+	}
+}
+
+// limitedWriter fails the writes beyond the remaining size or once abandoned.
+type limitedWriter struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	remaining int
+	abandoned bool
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.abandoned {
+		return 0, ErrRenderTimeout
+	}
+	if len(p) > w.remaining {
+		return 0, ErrRenderTooLarge
+	}
+	w.remaining -= len(p)
+	return w.buf.Write(p)
+}
+
+func (w *limitedWriter) abandon() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.abandoned = true
+}
+
+// checkDataDepth returns ErrDataTooDeep when the data nests more than maxDepth
+// maps, slices, arrays, structs, pointers or interfaces, cyclic data included.
+func checkDataDepth(name string, data any, maxDepth int) error {
+	if !withinDepth(reflect.ValueOf(data), maxDepth) {
+		return fmt.Errorf("template %s: %w: more than %d levels", name, ErrDataTooDeep, maxDepth)
+	}
+	return nil
+}
+
+func withinDepth(v reflect.Value, depth int) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+		return depth > 0 && withinDepth(v.Elem(), depth-1)
+	case reflect.Map:
+		if v.Len() == 0 {
+			return true
+		}
+		if depth == 0 {
+			return false
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if !withinDepth(iter.Value(), depth-1) {
+				return false
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return true
+		}
+		if depth == 0 {
+			return false
+		}
+		for i := 0; i < v.Len(); i++ {
+			if !withinDepth(v.Index(i), depth-1) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		if depth == 0 {
+			return v.NumField() == 0
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if !withinDepth(v.Field(i), depth-1) {
+				return false
+			}
+		}
+	}
+	return true
+}
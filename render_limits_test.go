@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplate_RenderLimits(t *testing.T) {
+	tpl := Template{Name: "list", Html: `{{range .Items}}<p>{{.}}</p>{{end}}`, Limits: RenderLimits{MaxSize: 100}}
+
+	if _, err := tpl.Render(map[string]any{"Items": []string{"a", "b"}}); err != nil {
+		t.Errorf("Expected no error within the limits, got %v", err)
+	}
+
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = "item"
+	}
+	if _, err := tpl.Render(map[string]any{"Items": items}); !errors.Is(err, ErrRenderTooLarge) {
+		t.Errorf("Expected ErrRenderTooLarge, got %v", err)
+	}
+}
+
+func TestTemplate_RenderTimeout(t *testing.T) {
+	block := make(chan string)
+	defer close(block)
+
+	tpl := Template{Name: "slow", Text: `{{range .Items}}{{.}}{{end}}`, Limits: RenderLimits{Timeout: 10 * time.Millisecond}}
+	_, err := tpl.Render(map[string]any{"Items": block})
+	if !errors.Is(err, ErrRenderTimeout) || !strings.Contains(err.Error(), "template slow") {
+		t.Errorf("Expected ErrRenderTimeout, got %v", err)
+	}
+}
+
+func TestTemplate_RenderDataDepth(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+	cyclic := &node{}
+	cyclic.Next = cyclic
+
+	tpl := Template{Name: "tree", Text: "{{.Next}}", Limits: RenderLimits{MaxDepth: 8}}
+	if _, err := tpl.Render(cyclic); !errors.Is(err, ErrDataTooDeep) {
+		t.Errorf("Expected ErrDataTooDeep, got %v", err)
+	}
+
+	nested := map[string]any{"a": map[string]any{"b": []any{"c"}}}
+	if _, err := tpl.Render(nested); err != nil {
+		t.Errorf("Expected no error for shallow data, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTemplate_Render(t *testing.T) {
+	tpl := Template{
+		Name:    "welcome",
+		Subject: "Welcome {{.Name}}",
+		Html:    "<p>Hello {{.Name}}</p>",
+		Text:    "Hello {{.Name}}",
+	}
+
+	msg, err := tpl.Render(map[string]string{"Name": "<Ada>"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if msg.Subject != "Welcome <Ada>" || msg.Text != "Hello <Ada>" {
+		t.Errorf("Unexpected subject or text %q %q", msg.Subject, msg.Text)
+	}
+	if msg.Html != "<p>Hello &lt;Ada&gt;</p>" {
+		t.Errorf("Expected the html to be escaped, got %q", msg.Html)
+	}
+
+	if _, err := (Template{Name: "broken", Subject: "{{.Name"}).Render(nil); err == nil {
+		t.Errorf("Expected a parse error")
+	}
+}
+
+func TestTemplate_RenderStrict(t *testing.T) {
+	tpl := Template{
+		Name:    "order",
+		Subject: "Order {{.Order.ID}}",
+		Html:    "{{range .Items}}<li>{{.Name}} {{$.Currency}}</li>{{end}}",
+		Text:    "{{with .Coupon}}{{.Code}}{{else}}{{.Name}}{{end}}",
+	}
+
+	data := map[string]any{
+		"Order":    map[string]any{"ID": 42},
+		"Items":    []map[string]string{{"Name": "Book"}},
+		"Currency": "EUR",
+		"Coupon":   nil,
+		"Name":     "Ada",
+	}
+	msg, err := tpl.RenderStrict(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if msg.Subject != "Order 42" || msg.Html != "<li>Book EUR</li>" || msg.Text != "Ada" {
+		t.Errorf("Unexpected email %q %q %q", msg.Subject, msg.Html, msg.Text)
+	}
+
+	delete(data, "Currency")
+	data["Debug"] = true
+	_, err = tpl.RenderStrict(data)
+	var verr *TemplateVariablesError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected a TemplateVariablesError, got %v", err)
+	}
+	if err.Error() != "template order: missing variables Currency; unused variables Debug" {
+		t.Errorf("Unexpected error %q", err)
+	}
+
+	nested := Template{Name: "nested", Subject: "{{.Order.ID}}"}
+	if _, err := nested.RenderStrict(map[string]any{"Order": map[string]any{}}); err == nil {
+		t.Errorf("Expected an error for the missing nested key")
+	}
+}
+
+func TestRender(t *testing.T) {
+	rendered, err := Render(Template{Name: "welcome", Subject: "Hi {{.Name}}", Html: "<h1>Hello {{.Name}}</h1>"}, map[string]string{"Name": "Ada"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := RenderedMessage{Subject: "Hi Ada", Html: "<h1>Hello Ada</h1>", Text: "# Hello Ada"}
+	if rendered != expected {
+		t.Errorf("Expected %+v, got %+v", expected, rendered)
+	}
+
+	if _, err := Render(Template{Name: "broken", Html: "{{.Name"}, nil); err == nil {
+		t.Errorf("Expected a parse error")
+	}
+}
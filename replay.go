@@ -0,0 +1,126 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrReplayedWebhook is returned when a webhook callback was already handled or is too old.
+var ErrReplayedWebhook = errors.New("webhook callback replayed")
+
+// ReplayStore remembers the ids of the webhook callbacks already handled, e.g.
+// in Redis to share them between instances.
+type ReplayStore interface {
+	// Seen records the id until expiresAt and reports whether it was already recorded.
+	// It must be atomic for concurrent callbacks with the same id.
+	Seen(ctx context.Context, id string, expiresAt time.Time) (bool, error)
+	// Forget removes the id, when its callback could not be handled and must be retried.
+	Forget(ctx context.Context, id string) error
+}
+
+// MemoryReplayStore is a ReplayStore kept in memory.
+type MemoryReplayStore struct {
+	mu  sync.Mutex
+	ids map[string]time.Time
+	// clock tells the time the expired ids are pruned at, the clock of the
+	// ReplayGuard for its default store.
+	clock Clock
+}
+
+// NewMemoryReplayStore creates an empty in-memory replay store.
+func NewMemoryReplayStore() *MemoryReplayStore {
+	return &MemoryReplayStore{ids: make(map[string]time.Time), clock: systemClock{}}
+}
+
+// Seen records the id until expiresAt and reports whether it was already recorded.
+func (s *MemoryReplayStore) Seen(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	for k, expires := range s.ids {
+		if now.After(expires) {
+			delete(s.ids, k)
+		}
+	}
+
+	if _, ok := s.ids[id]; ok {
+		return true, nil
+	}
+	s.ids[id] = expiresAt
+	return false, nil
+}
+
+// Forget removes the id.
+func (s *MemoryReplayStore) Forget(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.ids, id)
+	return nil
+}
+
+// ReplayGuard rejects the webhook callbacks already handled or older than MaxAge,
+// so that retried or replayed callbacks do not suppress or count the same event twice.
+type ReplayGuard struct {
+	// Store remembers the handled callbacks. Defaults to an in-memory store.
+	Store ReplayStore
+	// MaxAge rejects the callbacks whose timestamp is older, when positive.
+	MaxAge time.Duration
+	// Retention is how long the ids are remembered. Defaults to MaxAge, or 24 hours.
+	Retention time.Duration
+	// Clock tells the time the callbacks are checked at. Defaults to the system clock.
+	Clock Clock
+
+	once sync.Once
+}
+
+// Check returns ErrReplayedWebhook when the callback with the id and timestamp
+// was already handled or is too old, and records it otherwise.
+func (g *ReplayGuard) Check(ctx context.Context, id string, timestamp time.Time) error {
+	g.init()
+
+	now := g.Clock.Now()
+	if g.MaxAge > 0 && now.Sub(timestamp) > g.MaxAge {
+		return fmt.Errorf("%w: %s is older than %s", ErrReplayedWebhook, id, g.MaxAge)
+	}
+
+	retention := g.Retention
+	if retention == 0 {
+		retention = g.MaxAge
+	}
+	if retention == 0 {
+		retention = 24 * time.Hour
+	}
+
+	seen, err := g.Store.Seen(ctx, id, now.Add(retention))
+	if err != nil {
+		return err
+	}
+	if seen {
+		return fmt.Errorf("%w: %s", ErrReplayedWebhook, id)
+	}
+	return nil
+}
+
+// Forget lets the callback with the id be handled again, e.g. when handling it failed
+// and the provider will retry it.
+func (g *ReplayGuard) Forget(ctx context.Context, id string) error {
+	g.init()
+	return g.Store.Forget(ctx, id)
+}
+
+func (g *ReplayGuard) init() {
+	g.once.Do(func() {
+		if g.Clock == nil {
+			g.Clock = systemClock{}
+		}
+		if g.Store == nil {
+			store := NewMemoryReplayStore()
+			store.clock = g.Clock
+			g.Store = store
+		}
+	})
+}
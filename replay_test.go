@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReplayGuard_Check(t *testing.T) {
+	guard := &ReplayGuard{MaxAge: 5 * time.Minute}
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := guard.Check(ctx, "evt-1", now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := guard.Check(ctx, "evt-1", now); !errors.Is(err, ErrReplayedWebhook) {
+		t.Errorf("Expected ErrReplayedWebhook for a duplicate, got %v", err)
+	}
+	if err := guard.Check(ctx, "evt-2", now.Add(-10*time.Minute)); !errors.Is(err, ErrReplayedWebhook) {
+		t.Errorf("Expected ErrReplayedWebhook for an old callback, got %v", err)
+	}
+
+	if err := guard.Forget(ctx, "evt-1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := guard.Check(ctx, "evt-1", now); err != nil {
+		t.Errorf("Expected a forgotten callback to be accepted, got %v", err)
+	}
+}
+
+func TestReplayGuard_Clock(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	guard := &ReplayGuard{MaxAge: 5 * time.Minute, Clock: clock}
+	ctx := context.Background()
+
+	if err := guard.Check(ctx, "evt-1", clock.Now()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	clock.Advance(10 * time.Minute)
+	if err := guard.Check(ctx, "evt-2", clock.Now().Add(-time.Minute)); err != nil {
+		t.Errorf("Expected a recent callback at the time of the clock, got %v", err)
+	}
+	if err := guard.Check(ctx, "evt-1", clock.Now()); err != nil {
+		t.Errorf("Expected the id to expire at the time of the clock, got %v", err)
+	}
+}
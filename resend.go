@@ -1,11 +1,16 @@
 package mailer
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/resend/resend-go/v2"
 )
 
 type resendParams struct {
-	apiKey string
+	apiKey     string
+	userAgent  string
+	httpClient *http.Client
 }
 
 type resendMailer struct {
@@ -14,21 +19,26 @@ type resendMailer struct {
 
 func newResend(params resendParams) MailerClient {
 	client := resend.NewClient(params.apiKey)
+	if params.httpClient != nil {
+		client = resend.NewCustomClient(params.httpClient, params.apiKey)
+	}
+	client.UserAgent = params.userAgent
 
 	return &resendMailer{resendClient: client}
 }
 
 func (m *resendMailer) Send(msg Mail) error {
 	params := &resend.SendEmailRequest{
-		To:          getSplitEmails(msg.To),
-		From:        msg.From,
+		To:          formatAddresses(msg.To),
+		From:        msg.From.String(),
 		Text:        msg.Text,
 		Html:        msg.Html,
 		Attachments: m.getAttachments(msg.Attachments),
 		Subject:     msg.Subject,
-		Cc:          getSplitEmails(msg.Cc),
-		Bcc:         getSplitEmails(msg.Bcc),
-		ReplyTo:     msg.ReplyTo,
+		Cc:          formatAddresses(msg.Cc),
+		Bcc:         formatAddresses(msg.Bcc),
+		ReplyTo:     msg.ReplyTo.String(),
+		Headers:     msg.Headers,
 	}
 
 	_, err := m.resendClient.Emails.Send(params)
@@ -44,11 +54,19 @@ func (m *resendMailer) getAttachments(a []Attachment) []*resend.Attachment {
 		attachments = append(attachments, &resend.Attachment{
 			Filename: attachment.Name,
 			Path:     attachment.Path,
+			Content:  attachment.Content,
 		})
 	}
 	return attachments
 }
 
+// Health verifies the API key by listing the domains, which requires a full
+// access key.
+func (m *resendMailer) Health(ctx context.Context) error {
+	_, err := m.resendClient.Domains.ListWithContext(ctx)
+	return err
+}
+
 func (m *resendMailer) Close() {
 	// Not implemented because resend-go does not have a Close method.
 }
@@ -0,0 +1,125 @@
+package mailer
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+)
+
+// requestIDHeaders are the response headers identifying a request, as asked by
+// the providers when filing a ticket.
+var requestIDHeaders = []string{"X-Request-Id", "X-Message-Id", "X-Amzn-Requestid", "Request-Id"}
+
+// ProviderResponse is the response of the provider to the sending of an email.
+type ProviderResponse struct {
+	Provider   APIServiceType
+	StatusCode int
+	// RequestID identifies the request for the support of the provider.
+	RequestID string
+	// Headers are the request id headers of the response.
+	Headers map[string]string
+	// Body is the body of the response, kept according to the ResponseCapture.
+	Body string
+}
+
+// ResponseCapture decides what is kept of the provider responses exposed by
+// SendWithResult and Status, and of the body of an *APIError returned by a send.
+type ResponseCapture int
+
+const (
+	// CaptureResponseMetadata keeps the status code and the request ids.
+	CaptureResponseMetadata ResponseCapture = iota
+	// CaptureResponseRedacted also keeps the body, its email addresses masked.
+	CaptureResponseRedacted
+	// CaptureResponseBody keeps the body as-is.
+	CaptureResponseBody
+)
+
+var emailAddress = regexp.MustCompile(`[^\s"'<>@,;:]+@[^\s"'<>@,;:]+`)
+
+// apply returns the response with its body kept according to the policy.
+func (c ResponseCapture) apply(res ProviderResponse) ProviderResponse {
+	switch c {
+	case CaptureResponseBody:
+	case CaptureResponseRedacted:
+		res.Body = emailAddress.ReplaceAllString(res.Body, "[redacted]")
+	default:
+		res.Body = ""
+	}
+	return res
+}
+
+// SendResult is the result of an email sent with SendWithResult.
+type SendResult struct {
+	ID string
+	// Responses are the responses of the provider, one per call when the email
+	// was sent in recipient chunks. Only the HTTP API providers and Amazon SES
+	// report them.
+	Responses []ProviderResponse
+}
+
+// SendWithResult is Send also returning the responses of the provider, e.g. the
+// request ids to give to its support.
+func (m *Mailer) SendWithResult(msg Mail) (SendResult, error) {
+	email, err := m.enqueue(msg, make(chan error, 1))
+	if err != nil {
+		return SendResult{}, err
+	}
+	err = <-email.result
+	status, _ := m.Status(email.id)
+	return SendResult{ID: email.id, Responses: status.Responses}, err
+}
+
+// responseSender is implemented by the clients reporting the response of the
+// provider to a send.
+type responseSender interface {
+	sendWithResponse(msg Mail) (*ProviderResponse, error)
+}
+
+// sendCapturing sends the email, returning the response of the provider when the
+// client reports it. Failed requests report it in their *APIError.
+func sendCapturing(client MailerClient, msg Mail) (*ProviderResponse, error) {
+	sender, ok := client.(responseSender)
+	if !ok {
+		return nil, client.Send(msg)
+	}
+	return sender.sendWithResponse(msg)
+}
+
+func newProviderResponse(provider APIServiceType, res *http.Response, body []byte) *ProviderResponse {
+	response := &ProviderResponse{Provider: provider, StatusCode: res.StatusCode, Body: string(body)}
+	for _, name := range requestIDHeaders {
+		if value := res.Header.Get(name); value != "" {
+			if response.Headers == nil {
+				response.Headers = make(map[string]string)
+			}
+			response.Headers[name] = value
+			if response.RequestID == "" {
+				response.RequestID = value
+			}
+		}
+	}
+	return response
+}
+
+// recordResponses keeps the responses of the provider in the status of the email,
+// and in the *APIError of a failed send, according to the ResponseCapture.
+func (m *Mailer) recordResponses(id string, responses []ProviderResponse, err error) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		// The body is also in the message of the error, kept in the status.
+		apiErr.Body = m.responseCapture.apply(ProviderResponse{Body: apiErr.Body}).Body
+		if apiErr.Response != nil {
+			captured := m.responseCapture.apply(*apiErr.Response)
+			apiErr.Response = &captured
+		}
+	}
+	if len(responses) == 0 {
+		return
+	}
+	captured := make([]ProviderResponse, len(responses))
+	for i, res := range responses {
+		captured[i] = m.responseCapture.apply(res)
+	}
+	m.statuses.respond(id, captured)
+}
@@ -0,0 +1,87 @@
+package mailer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMailer_SendWithResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		capture  ResponseCapture
+		expected string
+	}{
+		{name: "metadata", capture: CaptureResponseMetadata, expected: ""},
+		{name: "redacted", capture: CaptureResponseRedacted, expected: `{"to":"[redacted]"}`},
+		{name: "body", capture: CaptureResponseBody, expected: `{"to":"a@test.com"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Message-Id", "msg-1")
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte(`{"to":"a@test.com"}`))
+			}))
+			defer server.Close()
+
+			sendgrid := newSendGrid(sendgridParams{apiKey: "key"}).(*sendgridMailer)
+			sendgrid.baseURL = server.URL
+			mailer := NewMailer(MailCfg{mailerClient: sendgrid, ResponseCapture: test.capture})
+			defer mailer.Close()
+
+			result, err := mailer.SendWithResult(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Text: "test"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(result.Responses) != 1 {
+				t.Fatalf("Expected 1 response, got %d", len(result.Responses))
+			}
+			res := result.Responses[0]
+			if res.StatusCode != http.StatusAccepted || res.RequestID != "msg-1" || res.Headers["X-Message-Id"] != "msg-1" {
+				t.Errorf("Unexpected response %+v", res)
+			}
+			if res.Body != test.expected {
+				t.Errorf("Expected body %q, got %q", test.expected, res.Body)
+			}
+		})
+	}
+}
+
+func TestMailer_SendWithResultError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"a@test.com is invalid"}`))
+	}))
+	defer server.Close()
+
+	postmark := newPostmark(postmarkParams{serverToken: "token"}).(*postmarkMailer)
+	postmark.baseURL = server.URL
+	mailer := NewMailer(MailCfg{mailerClient: postmark, ResponseCapture: CaptureResponseRedacted})
+	defer mailer.Close()
+
+	result, err := mailer.SendWithResult(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Text: "test"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, got %v", err)
+	}
+	if apiErr.RequestID != "req-1" {
+		t.Errorf("Expected request id req-1, got %q", apiErr.RequestID)
+	}
+	if apiErr.Response == nil || apiErr.Response.Body != `{"error":"[redacted] is invalid"}` {
+		t.Errorf("Expected the redacted response, got %+v", apiErr.Response)
+	}
+	if strings.Contains(err.Error(), "a@test.com") {
+		t.Errorf("Expected the error to be redacted, got %v", err)
+	}
+	if status, _ := mailer.Status(result.ID); strings.Contains(status.Error, "a@test.com") {
+		t.Errorf("Expected the status error to be redacted, got %q", status.Error)
+	}
+	if len(result.Responses) != 1 || result.Responses[0].StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected the failed response, got %+v", result.Responses)
+	}
+}
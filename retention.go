@@ -0,0 +1,158 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const defaultRetentionInterval = time.Hour
+
+// Retention is how long the stores of the mailer keep their data, e.g. 90 days
+// of audit records, 7 years of archives and 30 days of dead letters. A zero
+// duration keeps the data forever.
+type Retention struct {
+	// AuditRecords is how long the records of the AuditStore are kept.
+	AuditRecords time.Duration
+	// Archives is how long the messages of the ArchiveStore are kept, unless the
+	// ArchiveRetention of the mailer decides otherwise. It sets the RetainUntil of
+	// the messages when they are archived, the already archived ones are kept.
+	Archives time.Duration
+	// DeadLetters is how long the emails of the DeadLetterStore are kept.
+	DeadLetters time.Duration
+	// Interval is how often the janitor prunes the stores, defaults to an hour.
+	// Setting it starts the janitor without any duration, e.g. to prune the
+	// archives according to the ArchiveRetention of the mailer.
+	Interval time.Duration
+}
+
+func (r Retention) enabled() bool {
+	return r.AuditRecords > 0 || r.Archives > 0 || r.DeadLetters > 0 || r.Interval > 0
+}
+
+// Pruner is implemented by the stores that can remove their old data, for the
+// Retention of the mailer.
+type Pruner interface {
+	// Prune removes the items recorded before the time and returns their number.
+	// The archive stores remove the messages whose RetainUntil is before the time.
+	Prune(ctx context.Context, before time.Time) (int, error)
+}
+
+// PruneReport counts the items removed by Prune.
+type PruneReport struct {
+	AuditRecords int
+	Archives     int
+	DeadLetters  int
+	// Skipped are the stores with a retention which do not implement Pruner.
+	Skipped []string
+}
+
+// Prune removes the data older than the Retention from the stores of the mailer.
+// It is run by a background janitor every Retention.Interval, and may be called
+// to prune on demand, e.g. from a cron job.
+func (m *Mailer) Prune(ctx context.Context) (PruneReport, error) {
+	var report PruneReport
+	now := m.clock.Now()
+	for _, s := range []struct {
+		name    string
+		store   any
+		enabled bool
+		before  time.Time
+		count   *int
+	}{
+		{"audit store", m.auditStore, m.retention.AuditRecords > 0, now.Add(-m.retention.AuditRecords), &report.AuditRecords},
+		// The retention of each archived message is in its RetainUntil.
+		{"archive store", m.archiveStore, m.retention.Archives > 0 || m.archiveRetention != nil, now, &report.Archives},
+		{"dead letter store", m.deadLetterStore, m.retention.DeadLetters > 0, now.Add(-m.retention.DeadLetters), &report.DeadLetters},
+	} {
+		if s.store == nil || !s.enabled {
+			continue
+		}
+		pruner, ok := s.store.(Pruner)
+		if !ok {
+			report.Skipped = append(report.Skipped, s.name)
+			continue
+		}
+		n, err := pruner.Prune(ctx, s.before)
+		*s.count = n
+		if errors.Is(err, errNotForwarded) {
+			report.Skipped = append(report.Skipped, s.name)
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to prune the %s: %w", s.name, err)
+		}
+	}
+	return report, nil
+}
+
+// pruneStores prunes the stores every interval until the mailer is closed.
+func (m *Mailer) pruneStores(interval time.Duration) {
+	ticker := m.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C():
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			report, err := m.Prune(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("mailer: failed to prune the stores: %v", err)
+			}
+			if len(report.Skipped) > 0 {
+				log.Printf("mailer: cannot prune the %v, they do not implement Pruner", report.Skipped)
+			}
+		}
+	}
+}
+
+// Prune removes the records of the attempts made before the time. It is, with
+// Erase, the only exception to the records being append-only.
+func (s *MemoryAuditStore) Prune(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.records[:0]
+	for _, record := range s.records {
+		if !record.Time.Before(before) {
+			kept = append(kept, record)
+		}
+	}
+	pruned := len(s.records) - len(kept)
+	s.records = kept
+	return pruned, nil
+}
+
+// Prune removes the messages whose RetainUntil is before the time, the messages
+// without one being kept forever.
+func (s *MemoryArchiveStore) Prune(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pruned := 0
+	for id, msg := range s.messages {
+		if !msg.RetainUntil.IsZero() && msg.RetainUntil.Before(before) {
+			delete(s.messages, id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// Prune removes the dead letters added before the time.
+func (s *MemoryDeadLetterStore) Prune(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.letters[:0]
+	for _, letter := range s.letters {
+		if !letter.Time.Before(before) {
+			kept = append(kept, letter)
+		}
+	}
+	pruned := len(s.letters) - len(kept)
+	s.letters = kept
+	return pruned, nil
+}
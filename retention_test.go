@@ -0,0 +1,120 @@
+package mailer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMailer_Prune(t *testing.T) {
+	day := 24 * time.Hour
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	audits := NewMemoryAuditStore()
+	archives := NewMemoryArchiveStore()
+	deadLetters := NewMemoryDeadLetterStore()
+	mailer := NewMailer(MailCfg{
+		APIService:      RESEND,
+		APIKey:          MailAPIKey,
+		Clock:           clock,
+		AuditStore:      audits,
+		ArchiveStore:    archives,
+		DeadLetterStore: deadLetters,
+		ArchiveRetention: func(msg Mail) time.Duration {
+			if msg.Subject == "Invoice" {
+				return 365 * day
+			}
+			return 0
+		},
+		Retention:    Retention{AuditRecords: 90 * day, Archives: 30 * day, DeadLetters: 30 * day},
+		mailerClient: &mockMailerClient{},
+	})
+	defer mailer.Close()
+
+	for _, subject := range []string{"Invoice", "Welcome"} {
+		if err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: subject, Text: "test"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	mailer.deadLetter("old", Mail{To: Addresses("a@test.com")}, "duplicate")
+
+	clock.Advance(20 * day)
+	mailer.deadLetter("recent", Mail{To: Addresses("a@test.com")}, "duplicate")
+
+	clock.Advance(20 * day)
+	report, err := mailer.Prune(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.AuditRecords != 0 || report.Archives != 1 || report.DeadLetters != 1 || len(report.Skipped) != 0 {
+		t.Errorf("Expected the welcome archive and the old dead letter to be pruned, got %+v", report)
+	}
+	messages, _ := archives.List(context.Background())
+	if len(messages) != 1 || messages[0].RetainUntil.Sub(messages[0].SentAt) != 365*day {
+		t.Errorf("Expected the invoice to be retained by the ArchiveRetention, got %v", messages)
+	}
+	if letters := deadLetters.List(); len(letters) != 1 || letters[0].MessageID != "recent" {
+		t.Errorf("Expected the recent dead letter to be kept, got %v", letters)
+	}
+
+	clock.Advance(60 * day)
+	report, err = mailer.Prune(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.AuditRecords != 2 || len(audits.Records()) != 0 {
+		t.Errorf("Expected the audit records to be pruned after 90 days, got %+v", report)
+	}
+}
+
+func TestMailer_PruneSkipsStoresWithoutPruner(t *testing.T) {
+	mailer := NewMailer(MailCfg{
+		APIService:   RESEND,
+		APIKey:       MailAPIKey,
+		AuditStore:   appendOnlyAuditStore{},
+		ArchiveStore: NewMemoryArchiveStore(),
+		Retention:    Retention{AuditRecords: time.Hour},
+		mailerClient: &mockMailerClient{},
+	})
+	defer mailer.Close()
+
+	report, err := mailer.Prune(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The archive store has no retention, it is not pruned.
+	if len(report.Skipped) != 1 || report.Skipped[0] != "audit store" {
+		t.Errorf("Expected the audit store to be skipped, got %v", report.Skipped)
+	}
+}
+
+func TestMailer_RetentionJanitor(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	deadLetters := NewMemoryDeadLetterStore()
+	mailer := NewMailer(MailCfg{
+		APIService:      RESEND,
+		APIKey:          MailAPIKey,
+		Clock:           clock,
+		DeadLetterStore: deadLetters,
+		Retention:       Retention{DeadLetters: time.Hour, Interval: time.Hour},
+		mailerClient:    &mockMailerClient{},
+	})
+	defer mailer.Close()
+
+	mailer.deadLetter("old", Mail{To: Addresses("a@test.com")}, "duplicate")
+	for clock.pendingTickers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(30 * time.Minute)
+	time.Sleep(10 * time.Millisecond)
+	if len(deadLetters.List()) != 1 {
+		t.Fatalf("Expected the janitor to wait for the interval")
+	}
+
+	clock.Advance(2 * time.Hour)
+	for i := 0; i < 200 && len(deadLetters.List()) > 0; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if letters := deadLetters.List(); len(letters) != 0 {
+		t.Errorf("Expected the janitor to prune the dead letter, got %v", letters)
+	}
+}
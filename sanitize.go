@@ -0,0 +1,168 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+)
+
+// ErrInvalidHeader is returned when a header name of an email is not a valid
+// RFC 5322 field name.
+var ErrInvalidHeader = errors.New("invalid header name")
+
+// sanitizeMail neutralizes the header injections of the user provided fields of
+// the email: CR and LF, as well as the other control characters, are replaced in
+// the subject, addresses, header values, tags and file names. RFC 2047 encoded
+// words are decoded first, so that "=?utf-8?q?=0D=0A?=" cannot smuggle a line
+// break to the clients decoding them, and re-encoded when the message is built.
+// Only the display names of the addresses are decoded, see sanitizeAddress and
+// sanitizeAddressList.
+func sanitizeMail(msg Mail) (Mail, error) {
+	msg.Subject = sanitizeHeaderValue(msg.Subject)
+	msg.From = sanitizeAddress(msg.From)
+	msg.To = sanitizeAddresses(msg.To)
+	msg.Cc = sanitizeAddresses(msg.Cc)
+	msg.Bcc = sanitizeAddresses(msg.Bcc)
+	msg.ReplyTo = sanitizeAddress(msg.ReplyTo)
+	msg.ReadReceiptTo = sanitizeAddressList(msg.ReadReceiptTo)
+	msg.Category = sanitizeHeaderValue(msg.Category)
+
+	if len(msg.Headers) > 0 {
+		headers := make(map[string]string, len(msg.Headers))
+		for key, value := range msg.Headers {
+			if !validHeaderName(key) {
+				return msg, fmt.Errorf("%w: %q", ErrInvalidHeader, key)
+			}
+			headers[key] = sanitizeHeaderValue(value)
+		}
+		msg.Headers = headers
+	}
+
+	if len(msg.Tags) > 0 {
+		tags := make([]string, len(msg.Tags))
+		for i, tag := range msg.Tags {
+			tags[i] = sanitizeHeaderValue(tag)
+		}
+		msg.Tags = tags
+	}
+
+	if len(msg.Attachments) > 0 {
+		attachments := make([]Attachment, len(msg.Attachments))
+		for i, attachment := range msg.Attachments {
+			attachment.Name = sanitizeFileName(attachment.Name)
+			attachment.ContentType = sanitizeHeaderValue(attachment.ContentType)
+			attachments[i] = attachment
+		}
+		msg.Attachments = attachments
+	}
+
+	if len(msg.Documents) > 0 {
+		documents := make([]Document, len(msg.Documents))
+		for i, document := range msg.Documents {
+			document.Name = sanitizeFileName(document.Name)
+			documents[i] = document
+		}
+		msg.Documents = documents
+	}
+	return msg, nil
+}
+
+// sanitizeAddress decodes the encoded words of the display name, String encoding
+// it again, and replaces the control characters of the name and the address. The
+// encoded words of the address are broken up, for it not to decode into others.
+func sanitizeAddress(addr Address) Address {
+	addr.Name = sanitizeHeaderValue(addr.Name)
+	addr.Email = strings.ReplaceAll(replaceControlChars(addr.Email), "=?", "= ?")
+	return addr
+}
+
+func sanitizeAddresses(addrs []Address) []Address {
+	if len(addrs) == 0 {
+		return addrs
+	}
+	sanitized := make([]Address, len(addrs))
+	for i, addr := range addrs {
+		sanitized[i] = sanitizeAddress(addr)
+	}
+	return sanitized
+}
+
+// sanitizeAddressList splits the address list before decoding anything, so that
+// an encoded word cannot add a recipient, e.g.
+// "=?utf-8?q?evil=40test.com=2C?= <a@test.com>" which decodes to
+// "evil@test.com, <a@test.com>". The display names decoded by net/mail are
+// sanitized and quoted again, the addresses it cannot parse have their encoded
+// words broken up.
+func sanitizeAddressList(list string) string {
+	list = replaceControlChars(list)
+	if !strings.Contains(list, "=?") {
+		return list
+	}
+	var addrs []string
+	for _, part := range splitAddressList(list) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if addr, err := mail.ParseAddress(part); err == nil {
+			addrs = append(addrs, Address{Name: replaceControlChars(addr.Name), Email: addr.Address}.String())
+			continue
+		}
+		addrs = append(addrs, strings.ReplaceAll(part, "=?", "= ?"))
+	}
+	return strings.Join(addrs, ", ")
+}
+
+// sanitizeHeaderValue decodes the encoded words of the value and replaces its
+// line breaks and control characters with spaces, tabs being kept. Encoded words
+// of unsupported charsets are broken up, as their content cannot be checked.
+func sanitizeHeaderValue(value string) string {
+	if strings.Contains(value, "=?") {
+		decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+		if err != nil {
+			decoded = strings.ReplaceAll(value, "=?", "= ?")
+		}
+		value = decoded
+	}
+	return replaceControlChars(value)
+}
+
+// replaceControlChars replaces the line breaks and control characters with
+// spaces, tabs being kept.
+func replaceControlChars(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, value)
+}
+
+// sanitizeFileName keeps the base name of the file name, without the directories
+// and the quotes which would end the filename parameter of Content-Disposition.
+func sanitizeFileName(name string) string {
+	name = sanitizeHeaderValue(name)
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSpace(strings.ReplaceAll(name, `"`, ""))
+}
+
+// validHeaderName reports whether the name is made of printable ASCII characters
+// other than colon (RFC 5322 section 2.2).
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] < 33 || name[i] > 126 || name[i] == ':' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeMail(t *testing.T) {
+	msg, err := sanitizeMail(Mail{
+		From:    Address{Email: "info@test.com"},
+		To:      []Address{{Name: "Jane\r\nBcc: evil@test.com", Email: "a@test.com\r\nBcc: evil@test.com"}},
+		Subject: "Hello\r\nBcc: evil@test.com",
+		Headers: map[string]string{"X-Campaign": "42\nBcc: evil@test.com"},
+		Tags:    []string{"welcome\r\n"},
+		Attachments: []Attachment{
+			{Name: "../../etc/passwd\r\nX-Evil: 1", Content: []byte("test")},
+			{Name: `invoice".pdf`, Content: []byte("test")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if msg.To[0] != (Address{Name: "Jane  Bcc: evil@test.com", Email: "a@test.com  Bcc: evil@test.com"}) {
+		t.Errorf("Unexpected recipient %+v", msg.To[0])
+	}
+	if msg.Subject != "Hello  Bcc: evil@test.com" {
+		t.Errorf("Unexpected subject %q", msg.Subject)
+	}
+	if msg.Headers["X-Campaign"] != "42 Bcc: evil@test.com" || msg.Tags[0] != "welcome  " {
+		t.Errorf("Unexpected header and tags %q %q", msg.Headers, msg.Tags)
+	}
+	if msg.Attachments[0].Name != "passwd  X-Evil: 1" || msg.Attachments[1].Name != "invoice.pdf" {
+		t.Errorf("Unexpected attachment names %q and %q", msg.Attachments[0].Name, msg.Attachments[1].Name)
+	}
+
+	message, _ := buildMessage(msg)
+	if strings.Contains(message, "\r\nBcc:") || strings.Contains(message, "\nBcc:") {
+		t.Errorf("Expected no injected header in %s", message)
+	}
+}
+
+func TestSanitizeHeaderValue(t *testing.T) {
+	tests := map[string]string{
+		"Hello":                                  "Hello",
+		"tab\tkept":                              "tab\tkept",
+		"null\x00byte":                           "null byte",
+		"=?utf-8?q?Hi=0D=0ABcc:_evil@test.com?=": "Hi  Bcc: evil@test.com",
+		"=?utf-8?b?SGkNCkJjYzogZXZpbEB0ZXN0LmNvbQ==?=": "Hi  Bcc: evil@test.com",
+		"=?x-unknown?q?Hi=0D=0ABcc:_evil?=":            "= ?x-unknown?q?Hi=0D=0ABcc:_evil?=",
+		"=?utf-8?q?Zo=C3=AB?=":                         "Zoë",
+	}
+	for value, expected := range tests {
+		if got := sanitizeHeaderValue(value); got != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, value, got)
+		}
+	}
+}
+
+func TestMailer_SendInvalidHeader(t *testing.T) {
+	mailer := NewMailer(MailCfg{mailerClient: &mockMailerClient{}})
+	defer mailer.Close()
+
+	for _, name := range []string{"X-Evil\r\nBcc", "X-Evil:", "", "X Evil"} {
+		err := mailer.Send(Mail{To: Addresses("a@test.com"), Headers: map[string]string{name: "test"}})
+		if !errors.Is(err, ErrInvalidHeader) || !IsPermanentError(err) {
+			t.Errorf("Expected ErrInvalidHeader for %q, got %v", name, err)
+		}
+	}
+}
+
+func TestSanitizeAddressList(t *testing.T) {
+	tests := map[string]string{
+		"a@test.com, Jane <b@test.com>":                       "a@test.com, Jane <b@test.com>",
+		"a@test.com\r\nBcc: evil@test.com":                    "a@test.com  Bcc: evil@test.com",
+		"=?utf-8?q?evil=40test.com=2C?= <a@test.com>":         `"evil@test.com," <a@test.com>`,
+		"=?utf-8?q?Zo=C3=AB?= <a@test.com>, b@test.com":       "=?utf-8?q?Zo=C3=AB?= <a@test.com>, b@test.com",
+		"=?utf-8?q?Hi=0D=0ABcc:_evil@test.com?= <a@test.com>": "= ?utf-8?q?Hi=0D=0ABcc:_evil@test.com?= <a@test.com>",
+		"=?utf-8?q?evil=40test.com=2C_a=40test.com?=":         "= ?utf-8?q?evil=40test.com=2C_a=40test.com?=",
+	}
+	for list, expected := range tests {
+		got := sanitizeAddressList(list)
+		if got != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, list, got)
+		}
+		if len(splitAddressList(got)) != len(splitAddressList(list)) {
+			t.Errorf("Expected %q to keep the recipients of %q", got, list)
+		}
+	}
+}
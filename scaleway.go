@@ -0,0 +1,155 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+const scalewayBaseURL = "https://api.scaleway.com"
+
+// ScalewayRegion is the region of the Scaleway Transactional Email service, which
+// is hosted in the EU.
+type ScalewayRegion string
+
+const (
+	ScalewayRegionParis     ScalewayRegion = "fr-par"
+	ScalewayRegionAmsterdam ScalewayRegion = "nl-ams"
+)
+
+type scalewayParams struct {
+	secretKey  string
+	projectID  string
+	region     ScalewayRegion
+	userAgent  string
+	httpClient *http.Client
+}
+
+type scalewayMailer struct {
+	httpClient *http.Client
+	baseURL    string
+	secretKey  string
+	projectID  string
+	region     ScalewayRegion
+	userAgent  string
+}
+
+type scalewayAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type scalewayAttachment struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+type scalewayHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type scalewayEmail struct {
+	From              scalewayAddress      `json:"from"`
+	To                []scalewayAddress    `json:"to,omitempty"`
+	Cc                []scalewayAddress    `json:"cc,omitempty"`
+	Bcc               []scalewayAddress    `json:"bcc,omitempty"`
+	Subject           string               `json:"subject"`
+	Text              string               `json:"text"`
+	Html              string               `json:"html"`
+	ProjectID         string               `json:"project_id"`
+	Attachments       []scalewayAttachment `json:"attachments,omitempty"`
+	AdditionalHeaders []scalewayHeader     `json:"additional_headers,omitempty"`
+}
+
+func newScaleway(params scalewayParams) MailerClient {
+	if params.region == "" {
+		params.region = ScalewayRegionParis
+	}
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+
+	return &scalewayMailer{
+		httpClient: params.httpClient,
+		baseURL:    scalewayBaseURL,
+		secretKey:  params.secretKey,
+		projectID:  params.projectID,
+		region:     params.region,
+		userAgent:  params.userAgent,
+	}
+}
+
+func (m *scalewayMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *scalewayMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	payload := scalewayEmail{
+		From:      scalewayAddress{Email: msg.From.Email, Name: msg.From.Name},
+		To:        getScalewayAddresses(msg.To),
+		Cc:        getScalewayAddresses(msg.Cc),
+		Bcc:       getScalewayAddresses(msg.Bcc),
+		Subject:   msg.Subject,
+		Text:      msg.Text,
+		Html:      msg.Html,
+		ProjectID: m.projectID,
+	}
+	// Scaleway has no reply-to field, it is one of the additional headers.
+	if msg.ReplyTo.Email != "" {
+		msg = withHeader(msg, "Reply-To", msg.ReplyTo.String())
+	}
+	for _, key := range headerKeys(msg.Headers) {
+		payload.AdditionalHeaders = append(payload.AdditionalHeaders, scalewayHeader{Key: key, Value: msg.Headers[key]})
+	}
+
+	for _, attachment := range msg.Attachments {
+		content, err := encodeAttachmentBase64(attachment)
+		if err != nil {
+			return nil, err
+		}
+		payload.Attachments = append(payload.Attachments, scalewayAttachment{
+			Name:    attachment.Name,
+			Type:    getAttachmentContentType(attachment),
+			Content: content,
+		})
+	}
+
+	return m.doWithResponse(context.Background(), http.MethodPost, m.path("/emails"), payload)
+}
+
+func getScalewayAddresses(addrs []Address) []scalewayAddress {
+	var addresses []scalewayAddress
+	for _, addr := range addrs {
+		addresses = append(addresses, scalewayAddress{Email: addr.Email, Name: addr.Name})
+	}
+	return addresses
+}
+
+// path returns the path of the resource in the region of the mailer.
+func (m *scalewayMailer) path(resource string) string {
+	return "/transactional-email/v1alpha1/regions/" + string(m.region) + resource
+}
+
+func (m *scalewayMailer) doWithResponse(ctx context.Context, method string, path string, payload any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(method, m.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Auth-Token", m.secretKey)
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, SCALEWAY, req.WithContext(ctx), nil)
+}
+
+// Health verifies the secret key by fetching the domains of the project.
+func (m *scalewayMailer) Health(ctx context.Context) error {
+	_, err := m.doWithResponse(ctx, http.MethodGet, m.path("/domains?project_id="+url.QueryEscape(m.projectID)), nil)
+	return err
+}
+
+func (m *scalewayMailer) Close() {
+	// Not implemented because the Scaleway API is stateless.
+}
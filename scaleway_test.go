@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestScaleway_Send(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	scaleway := newScaleway(scalewayParams{secretKey: MailAPIKey, projectID: "project"}).(*scalewayMailer)
+	scaleway.baseURL = server.URL
+
+	err := scaleway.Send(Mail{
+		From:        Address{Name: "Acme", Email: "info@test.com"},
+		To:          []Address{{Name: "Ada", Email: "a@test.com"}},
+		ReplyTo:     Address{Email: "support@test.com"},
+		Subject:     "test",
+		Html:        "<p>test</p>",
+		Text:        "test",
+		Attachments: []Attachment{{Name: "hello.txt", Content: []byte("hello")}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := requests[0]
+	if req.Path != "/transactional-email/v1alpha1/regions/fr-par/emails" || req.Header.Get("X-Auth-Token") != MailAPIKey {
+		t.Fatalf("Expected an authenticated request to the Paris region, got %s %v", req.Path, req.Header)
+	}
+	if req.Body["project_id"] != "project" {
+		t.Errorf("Expected the project, got %v", req.Body["project_id"])
+	}
+	if to := req.Body["to"].([]any)[0].(map[string]any); to["email"] != "a@test.com" || to["name"] != "Ada" {
+		t.Errorf("Expected the recipient, got %v", to)
+	}
+	if header := req.Body["additional_headers"].([]any)[0].(map[string]any); header["key"] != "Reply-To" {
+		t.Errorf("Expected the Reply-To header, got %v", header)
+	}
+	if attachment := req.Body["attachments"].([]any)[0].(map[string]any); attachment["content"] != "aGVsbG8=" {
+		t.Errorf("Expected the attachment, got %v", attachment)
+	}
+}
+
+func TestScaleway_Region(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	scaleway := newScaleway(scalewayParams{secretKey: MailAPIKey, projectID: "project", region: ScalewayRegionAmsterdam}).(*scalewayMailer)
+	scaleway.baseURL = server.URL
+
+	if err := scaleway.Health(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if req := requests[0]; req.Path != "/transactional-email/v1alpha1/regions/nl-ams/domains" || req.Query != "project_id=project" {
+		t.Errorf("Expected the domains of the project in Amsterdam, got %s?%s", req.Path, req.Query)
+	}
+}
@@ -0,0 +1,165 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrAttachmentInfected is returned when an attachment is reported as infected.
+var ErrAttachmentInfected = errors.New("attachment is infected")
+
+// InfectedAttachmentError describes the infected attachment of an email.
+type InfectedAttachmentError struct {
+	// Name is the name of the attachment.
+	Name string
+	// Signature is the name of the malware found by the scanner.
+	Signature string
+}
+
+func (e *InfectedAttachmentError) Error() string {
+	return fmt.Sprintf("attachment %s is infected: %s", e.Name, e.Signature)
+}
+
+func (e *InfectedAttachmentError) Unwrap() error {
+	return ErrAttachmentInfected
+}
+
+// ScanResult is the verdict of a Scanner.
+type ScanResult struct {
+	// Infected reports whether malware was found.
+	Infected bool
+	// Signature is the name of the malware found.
+	Signature string
+}
+
+// Scanner scans the content of attachments for malware.
+type Scanner interface {
+	Scan(ctx context.Context, name string, content io.Reader) (ScanResult, error)
+}
+
+// AttachmentScanMiddleware returns a Middleware scanning every local attachment
+// before the email is sent. Emails are rejected when an attachment is infected
+// or when it cannot be scanned. Attachments referenced by url are fetched by the
+// provider and cannot be scanned.
+func AttachmentScanMiddleware(scanner Scanner) Middleware {
+	return func(next SendFunc) SendFunc {
+		return func(msg Mail) error {
+			for _, attachment := range msg.Attachments {
+				remote := strings.HasPrefix(attachment.Path, "http://") || strings.HasPrefix(attachment.Path, "https://")
+				if remote && attachment.Content == nil {
+					continue
+				}
+				if err := scanAttachment(scanner, attachment); err != nil {
+					return err
+				}
+			}
+			return next(msg)
+		}
+	}
+}
+
+func scanAttachment(scanner Scanner, attachment Attachment) error {
+	var content io.Reader = bytes.NewReader(attachment.Content)
+	if attachment.Content == nil {
+		file, err := os.Open(attachment.Path)
+		if err != nil {
+			return fmt.Errorf("failed to scan attachment %s: %w", attachment.Name, err)
+		}
+		defer file.Close()
+		content = file
+	}
+
+	result, err := scanner.Scan(context.Background(), attachment.Name, content)
+	if err != nil {
+		return fmt.Errorf("failed to scan attachment %s: %w", attachment.Name, err)
+	}
+	if result.Infected {
+		return &InfectedAttachmentError{Name: attachment.Name, Signature: result.Signature}
+	}
+	return nil
+}
+
+// ClamAVScanner scans attachments with a clamd daemon using the INSTREAM command.
+type ClamAVScanner struct {
+	// Network is "tcp" or "unix". Defaults to "tcp".
+	Network string
+	// Address is the address of clamd. Defaults to localhost:3310.
+	Address string
+	// Timeout bounds the whole scan. Defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// clamdChunkSize is the size of the INSTREAM chunks, below the default StreamMaxLength.
+const clamdChunkSize = 64 * 1024
+
+func (s ClamAVScanner) Scan(ctx context.Context, name string, content io.Reader) (ScanResult, error) {
+	network, address, timeout := s.Network, s.Address, s.Timeout
+	if network == "" {
+		network = "tcp"
+	}
+	if address == "" {
+		address = "localhost:3310"
+	}
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	w := bufio.NewWriter(conn)
+	w.WriteString("zINSTREAM\x00")
+
+	chunk := make([]byte, clamdChunkSize)
+	for {
+		n, err := content.Read(chunk)
+		if n > 0 {
+			binary.Write(w, binary.BigEndian, uint32(n))
+			w.Write(chunk[:n])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return ScanResult{}, err
+		}
+	}
+	binary.Write(w, binary.BigEndian, uint32(0))
+	if err := w.Flush(); err != nil {
+		return ScanResult{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return ScanResult{}, err
+	}
+	return parseClamdReply(string(bytes.TrimRight([]byte(reply), "\x00")))
+}
+
+// parseClamdReply parses replies such as "stream: OK" and
+// "stream: Eicar-Signature FOUND".
+func parseClamdReply(reply string) (ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case reply == "OK":
+		return ScanResult{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return ScanResult{Infected: true, Signature: strings.TrimSuffix(reply, " FOUND")}, nil
+	default:
+		return ScanResult{}, fmt.Errorf("clamd error: %s", reply)
+	}
+}
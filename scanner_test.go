@@ -0,0 +1,150 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// serveClamd runs a fake clamd flagging streams containing the EICAR test string.
+func serveClamd(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				if command, _ := r.ReadString(0); command != "zINSTREAM\x00" {
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+				var content bytes.Buffer
+				for {
+					var size uint32
+					if err := binary.Read(r, binary.BigEndian, &size); err != nil || size == 0 {
+						break
+					}
+					io.CopyN(&content, r, int64(size))
+				}
+				if strings.Contains(content.String(), "EICAR-STANDARD-ANTIVIRUS-TEST-FILE") {
+					conn.Write([]byte("stream: Eicar-Signature FOUND\x00"))
+					return
+				}
+				conn.Write([]byte("stream: OK\x00"))
+			}(conn)
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func TestClamAVScanner(t *testing.T) {
+	scanner := ClamAVScanner{Address: serveClamd(t)}
+
+	testCases := []struct {
+		name      string
+		content   string
+		infected  bool
+		signature string
+	}{
+		{name: "clean file", content: strings.Repeat("invoice ", clamdChunkSize/4)},
+		{name: "infected file", content: eicar, infected: true, signature: "Eicar-Signature"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := scanner.Scan(context.Background(), "file", strings.NewReader(tc.content))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.Infected != tc.infected || result.Signature != tc.signature {
+				t.Errorf("Unexpected result %+v", result)
+			}
+		})
+	}
+}
+
+func TestAttachmentScanMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	clean := filepath.Join(dir, "invoice.txt")
+	infected := filepath.Join(dir, "eicar.txt")
+	os.WriteFile(clean, []byte("invoice"), 0o600)
+	os.WriteFile(infected, []byte(eicar), 0o600)
+
+	scanner := ClamAVScanner{Address: serveClamd(t)}
+
+	testCases := []struct {
+		name        string
+		attachments []Attachment
+		scanner     Scanner
+		expectErr   error
+		anyErr      bool
+	}{
+		{
+			name:        "Should send clean attachments",
+			attachments: []Attachment{{Name: "invoice.txt", Path: clean}, {Name: "remote.pdf", Path: "https://test.com/remote.pdf"}},
+			scanner:     scanner,
+		},
+		{
+			name:        "Should reject infected attachments",
+			attachments: []Attachment{{Name: "invoice.txt", Path: clean}, {Name: "eicar.txt", Path: infected}},
+			scanner:     scanner,
+			expectErr:   ErrAttachmentInfected,
+		},
+		{
+			name:        "Should reject attachments that cannot be scanned",
+			attachments: []Attachment{{Name: "invoice.txt", Path: clean}},
+			scanner:     ClamAVScanner{Address: "127.0.0.1:1"},
+			anyErr:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sent := false
+			send := AttachmentScanMiddleware(tc.scanner)(func(msg Mail) error {
+				sent = true
+				return nil
+			})
+
+			err := send(Mail{Attachments: tc.attachments})
+			if tc.anyErr {
+				if err == nil || sent {
+					t.Errorf("Expected the email to be rejected, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.expectErr) {
+				t.Fatalf("Expected %v, got %v", tc.expectErr, err)
+			}
+			if sent != (tc.expectErr == nil) {
+				t.Errorf("Expected sent to be %v", tc.expectErr == nil)
+			}
+
+			var infectedErr *InfectedAttachmentError
+			if tc.expectErr != nil && (!errors.As(err, &infectedErr) || infectedErr.Name != "eicar.txt") {
+				t.Errorf("Expected the infected attachment to be named, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoSeedList is returned by SendSeedTest when MailCfg.SeedList is empty.
+	ErrNoSeedList = errors.New("no seed list configured")
+	// ErrNoSeedSender is returned by SendSeedTest when MailCfg.SeedSender is empty.
+	ErrNoSeedSender = errors.New("no seed sender configured")
+)
+
+// SendSeedTest renders the template with the data and sends it from the seed
+// sender separately to every address of the seed list, e.g. accounts at Gmail,
+// Outlook and Yahoo, to measure inbox placement. The emails are tagged "seed-test" and "seed-test-<id>", the
+// returned id relating them in the inbox placement analysis.
+func (m *Mailer) SendSeedTest(tpl Template, data any) (string, error) {
+	if len(m.seedList) == 0 {
+		return "", ErrNoSeedList
+	}
+	if m.seedSender == "" {
+		return "", ErrNoSeedSender
+	}
+
+	msg, err := tpl.Render(data)
+	if err != nil {
+		return "", err
+	}
+
+	msg.From = toAddress(m.seedSender)
+	id := newMessageIDFrom(m.rand)
+	msg.Tags = []string{"seed-test", "seed-test-" + id}
+
+	var errs []error
+	for _, seed := range m.seedList {
+		msg.To = []Address{toAddress(seed)}
+		if err := m.Send(msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", seed, err))
+		}
+	}
+	return id, errors.Join(errs...)
+}
@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMailer_SendSeedTest(t *testing.T) {
+	var mu sync.Mutex
+	var sent []sendgridRequest
+	client := newTestSendGrid(t, func(w http.ResponseWriter, r *http.Request) {
+		var req sendgridRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Expected a valid request, got %v", err)
+		}
+		mu.Lock()
+		sent = append(sent, req)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mailer := NewMailer(MailCfg{
+		APIService:   SENDGRID,
+		APIKey:       MailAPIKey,
+		SeedList:     []string{"seed@gmail.com", "seed@outlook.com"},
+		SeedSender:   `"Acme Inc" <news@acme.com>`,
+		mailerClient: client,
+	})
+	defer mailer.Close()
+
+	id, err := mailer.SendSeedTest(Template{Name: "promo", Subject: "Sale for {{.}}", Text: "Sale"}, "you")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(sent) != 2 || sent[0].Personalizations[0].To[0].Email != "seed@gmail.com" || sent[1].Personalizations[0].To[0].Email != "seed@outlook.com" {
+		t.Fatalf("Expected one email per seed, got %+v", sent)
+	}
+	if sent[0].From != (sendgridAddress{Email: "news@acme.com", Name: "Acme Inc"}) {
+		t.Errorf("Expected the seed sender, got %+v", sent[0].From)
+	}
+	if sent[0].Subject != "Sale for you" || strings.Join(sent[0].Categories, ",") != "seed-test,seed-test-"+id {
+		t.Errorf("Unexpected seed email %+v", sent[0])
+	}
+}
+
+func TestMailer_SendSeedTestWithoutSeedList(t *testing.T) {
+	mailer := NewMailer(MailCfg{APIService: RESEND, APIKey: MailAPIKey, mailerClient: &mockMailerClient{}})
+	defer mailer.Close()
+
+	if _, err := mailer.SendSeedTest(Template{Name: "promo"}, nil); !errors.Is(err, ErrNoSeedList) {
+		t.Errorf("Expected ErrNoSeedList, got %v", err)
+	}
+}
+
+func TestMailer_SendSeedTestWithoutSeedSender(t *testing.T) {
+	mailer := NewMailer(MailCfg{APIService: RESEND, APIKey: MailAPIKey, SeedList: []string{"seed@gmail.com"}, mailerClient: &mockMailerClient{}})
+	defer mailer.Close()
+
+	if _, err := mailer.SendSeedTest(Template{Name: "promo"}, nil); !errors.Is(err, ErrNoSeedSender) {
+		t.Errorf("Expected ErrNoSeedSender, got %v", err)
+	}
+}
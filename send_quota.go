@@ -0,0 +1,149 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+)
+
+// defaultSendQuotaWarning is the share of the quota used at which
+// EventQuotaWarning is raised.
+const defaultSendQuotaWarning = 0.9
+
+// ErrSendQuotaUnsupported is returned by CheckSendQuota when the provider does
+// not report its sending quota.
+var ErrSendQuotaUnsupported = errors.New("the provider does not report its sending quota")
+
+// SendQuota is the sending quota of the account at the provider, and its standing.
+type SendQuota struct {
+	Provider APIServiceType
+	// Max24HourSend is the number of emails the account can send per 24 hours.
+	Max24HourSend float64
+	// SentLast24Hours is the number of emails sent during the last 24 hours.
+	SentLast24Hours float64
+	// MaxSendRate is the number of emails the account can send per second.
+	MaxSendRate float64
+	// Enforcement is the standing of the account, e.g. HEALTHY, PROBATION or
+	// SHUTDOWN for Amazon SES.
+	Enforcement    string
+	SendingEnabled bool
+	CheckedAt      time.Time
+}
+
+// Remaining returns the number of emails the account can still send during the
+// current 24 hours.
+func (q SendQuota) Remaining() float64 {
+	if remaining := q.Max24HourSend - q.SentLast24Hours; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Used returns the share of the quota used, between 0 and 1.
+func (q SendQuota) Used() float64 {
+	if q.Max24HourSend <= 0 {
+		return 0
+	}
+	return min(q.SentLast24Hours/q.Max24HourSend, 1)
+}
+
+// SendQuotaChecker is implemented by the mailer clients whose provider reports
+// the sending quota of the account.
+type SendQuotaChecker interface {
+	SendQuota(ctx context.Context) (SendQuota, error)
+}
+
+// sendQuotaStatus holds the last sending quota reported by the provider.
+type sendQuotaStatus struct {
+	mu     sync.Mutex
+	quota  *SendQuota
+	warned bool
+}
+
+// set records the quota, reporting whether it just reached the warning threshold.
+func (s *sendQuotaStatus) set(quota SendQuota, warning float64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quota = &quota
+
+	exceeded := quota.Used() >= warning
+	warn := exceeded && !s.warned
+	s.warned = exceeded
+	return warn
+}
+
+func (s *sendQuotaStatus) get() *SendQuota {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.quota == nil {
+		return nil
+	}
+	quota := *s.quota
+	return &quota
+}
+
+// CheckSendQuota fetches the sending quota of the provider, reported by Stats
+// until the next check. It raises an EventQuotaWarning once the share of the
+// quota used reaches MailCfg.SendQuotaWarning.
+func (m *Mailer) CheckSendQuota(ctx context.Context) (SendQuota, error) {
+	checker, ok := m.mailerClient.(SendQuotaChecker)
+	if !ok {
+		return SendQuota{}, ErrSendQuotaUnsupported
+	}
+	quota, err := checker.SendQuota(ctx)
+	if err != nil {
+		return SendQuota{}, err
+	}
+	quota.Provider = m.apiService
+	quota.CheckedAt = m.clock.Now()
+
+	if m.sendQuota.set(quota, m.sendQuotaWarning) {
+		m.emit(Event{
+			Type:      EventQuotaWarning,
+			Provider:  m.apiService,
+			Timestamp: quota.CheckedAt,
+			Reason:    fmt.Sprintf("%.0f of %.0f emails sent during the last 24 hours", quota.SentLast24Hours, quota.Max24HourSend),
+		})
+	}
+	return quota, nil
+}
+
+// pollSendQuota checks the sending quota every interval until the mailer is closed.
+func (m *Mailer) pollSendQuota(interval time.Duration) {
+	ticker := m.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C():
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			m.CheckSendQuota(ctx)
+			cancel()
+		}
+	}
+}
+
+// SendQuota fetches the sending quota and the enforcement status of the SES account.
+func (m *sesMailer) SendQuota(ctx context.Context) (SendQuota, error) {
+	account, err := m.accountClient.GetAccount(ctx, &sesv2.GetAccountInput{})
+	if err != nil {
+		return SendQuota{}, err
+	}
+
+	quota := SendQuota{SendingEnabled: account.SendingEnabled}
+	if account.SendQuota != nil {
+		quota.Max24HourSend = account.SendQuota.Max24HourSend
+		quota.SentLast24Hours = account.SendQuota.SentLast24Hours
+		quota.MaxSendRate = account.SendQuota.MaxSendRate
+	}
+	if account.EnforcementStatus != nil {
+		quota.Enforcement = *account.EnforcementStatus
+	}
+	return quota, nil
+}
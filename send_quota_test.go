@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+type mockSESAccountClient struct {
+	sent float64
+}
+
+func (c *mockSESAccountClient) GetAccount(ctx context.Context, params *sesv2.GetAccountInput, optFns ...func(*sesv2.Options)) (*sesv2.GetAccountOutput, error) {
+	return &sesv2.GetAccountOutput{
+		SendingEnabled:    true,
+		EnforcementStatus: aws.String("HEALTHY"),
+		SendQuota:         &types.SendQuota{Max24HourSend: 1000, MaxSendRate: 14, SentLast24Hours: c.sent},
+	}, nil
+}
+
+func TestMailer_CheckSendQuota(t *testing.T) {
+	account := &mockSESAccountClient{sent: 850}
+	var events []Event
+	mailer := NewMailer(MailCfg{
+		APIService:   AMAZON_SES,
+		APIKey:       "key",
+		APISecret:    "secret",
+		Region:       "us-east-1",
+		EventHandler: func(e Event) { events = append(events, e) },
+		mailerClient: &sesMailer{accountClient: account},
+	})
+	defer mailer.Close()
+
+	quota, err := mailer.CheckSendQuota(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if quota.Remaining() != 150 || quota.MaxSendRate != 14 || quota.Enforcement != "HEALTHY" || !quota.SendingEnabled {
+		t.Errorf("Unexpected quota %+v", quota)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected no warning below 90%%, got %+v", events)
+	}
+
+	account.sent = 950
+	mailer.CheckSendQuota(context.Background())
+	mailer.CheckSendQuota(context.Background())
+	if len(events) != 1 || events[0].Type != EventQuotaWarning {
+		t.Errorf("Expected one quota warning, got %+v", events)
+	}
+	if stats := mailer.Stats(); stats.SendQuota == nil || stats.SendQuota.SentLast24Hours != 950 {
+		t.Errorf("Expected the quota in the stats, got %+v", stats.SendQuota)
+	}
+
+	recorder := httptest.NewRecorder()
+	mailer.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	for _, metric := range []string{"mailer_send_quota_remaining 50\n", `mailer_enforcement_status{status="HEALTHY"} 1`} {
+		if !strings.Contains(recorder.Body.String(), metric) {
+			t.Errorf("Expected the metrics to contain %q, got:\n%s", metric, recorder.Body.String())
+		}
+	}
+}
+
+func TestMailer_CheckSendQuotaUnsupported(t *testing.T) {
+	mailer := NewMailer(MailCfg{mailerClient: &mockMailerClient{}})
+	defer mailer.Close()
+
+	if _, err := mailer.CheckSendQuota(context.Background()); !errors.Is(err, ErrSendQuotaUnsupported) {
+		t.Errorf("Expected ErrSendQuotaUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,294 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const sendgridBaseURL = "https://api.sendgrid.com"
+
+// SendGridOptions holds the SendGrid specific options of an email.
+type SendGridOptions struct {
+	// TemplateID is the id of a SendGrid dynamic template. When set, the template
+	// stored in SendGrid is rendered instead of the Html and Text of the email.
+	TemplateID string
+	// TemplateData is the dynamic_template_data shared by every recipient.
+	TemplateData map[string]any
+	// RecipientTemplateData is the dynamic_template_data of each To recipient,
+	// keyed by email address. It is merged over TemplateData and every recipient
+	// gets an individual personalization.
+	RecipientTemplateData map[string]map[string]any
+	// SandboxMode validates the request without delivering the email.
+	SandboxMode bool
+}
+
+type sendgridParams struct {
+	apiKey     string
+	userAgent  string
+	httpClient *http.Client
+}
+
+type sendgridMailer struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	userAgent  string
+}
+
+type sendgridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendgridPersonalization struct {
+	To                  []sendgridAddress `json:"to"`
+	Cc                  []sendgridAddress `json:"cc,omitempty"`
+	Bcc                 []sendgridAddress `json:"bcc,omitempty"`
+	DynamicTemplateData map[string]any    `json:"dynamic_template_data,omitempty"`
+}
+
+type sendgridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendgridAttachment struct {
+	Content  string `json:"content"`
+	Type     string `json:"type,omitempty"`
+	Filename string `json:"filename"`
+}
+
+type sendgridMailSettings struct {
+	SandboxMode struct {
+		Enable bool `json:"enable"`
+	} `json:"sandbox_mode"`
+}
+
+type sendgridRequest struct {
+	Personalizations []sendgridPersonalization `json:"personalizations"`
+	From             sendgridAddress           `json:"from"`
+	ReplyTo          *sendgridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject,omitempty"`
+	Content          []sendgridContent         `json:"content,omitempty"`
+	Attachments      []sendgridAttachment      `json:"attachments,omitempty"`
+	TemplateID       string                    `json:"template_id,omitempty"`
+	Categories       []string                  `json:"categories,omitempty"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+	MailSettings     *sendgridMailSettings     `json:"mail_settings,omitempty"`
+}
+
+func newSendGrid(params sendgridParams) MailerClient {
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+
+	return &sendgridMailer{
+		httpClient: params.httpClient,
+		baseURL:    sendgridBaseURL,
+		apiKey:     params.apiKey,
+		userAgent:  params.userAgent,
+	}
+}
+
+func (m *sendgridMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *sendgridMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	payload, err := m.buildRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.doWithResponse(context.Background(), http.MethodPost, "/v3/mail/send", payload, nil)
+}
+
+func (m *sendgridMailer) do(ctx context.Context, method string, path string, payload any, out any) error {
+	_, err := m.doWithResponse(ctx, method, path, payload, out)
+	return err
+}
+
+func (m *sendgridMailer) doWithResponse(ctx context.Context, method string, path string, payload any, out any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(method, m.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, SENDGRID, req.WithContext(ctx), out)
+}
+
+func (m *sendgridMailer) buildRequest(msg Mail) (*sendgridRequest, error) {
+	payload := &sendgridRequest{
+		From:       sendgridAddress{Email: msg.From.Email, Name: msg.From.Name},
+		Subject:    msg.Subject,
+		Categories: msg.Tags,
+		Headers:    msg.Headers,
+	}
+
+	if msg.ReplyTo.Email != "" {
+		replyTo := msg.ReplyTo
+		payload.ReplyTo = &sendgridAddress{Email: replyTo.Email, Name: replyTo.Name}
+	}
+
+	options := msg.SendGrid
+	if options == nil {
+		options = &SendGridOptions{}
+	}
+
+	if options.TemplateID != "" {
+		payload.TemplateID = options.TemplateID
+	} else {
+		if msg.Text != "" {
+			payload.Content = append(payload.Content, sendgridContent{Type: "text/plain", Value: msg.Text})
+		}
+		if msg.AmpHtml != "" {
+			payload.Content = append(payload.Content, sendgridContent{Type: "text/x-amp-html", Value: msg.AmpHtml})
+		}
+		if msg.Html != "" {
+			payload.Content = append(payload.Content, sendgridContent{Type: "text/html", Value: msg.Html})
+		}
+	}
+
+	if options.SandboxMode {
+		payload.MailSettings = &sendgridMailSettings{}
+		payload.MailSettings.SandboxMode.Enable = true
+	}
+
+	payload.Personalizations = m.getPersonalizations(msg, options)
+
+	for _, attachment := range msg.Attachments {
+		content, err := encodeAttachmentBase64(attachment)
+		if err != nil {
+			return nil, err
+		}
+		payload.Attachments = append(payload.Attachments, sendgridAttachment{
+			Content:  content,
+			Type:     getAttachmentContentType(attachment),
+			Filename: attachment.Name,
+		})
+	}
+
+	return payload, nil
+}
+
+func (m *sendgridMailer) getPersonalizations(msg Mail, options *SendGridOptions) []sendgridPersonalization {
+	var personalizations []sendgridPersonalization
+
+	// A mail sent only to Cc or Bcc recipients has no To recipient to carry
+	// its data, its single personalization gets the shared data.
+	if len(options.RecipientTemplateData) == 0 || len(msg.To) == 0 {
+		personalizations = append(personalizations, sendgridPersonalization{
+			To:                  m.getAddresses(msg.To),
+			DynamicTemplateData: options.TemplateData,
+		})
+	} else {
+		for _, addr := range msg.To {
+			data := make(map[string]any, len(options.TemplateData))
+			for key, value := range options.TemplateData {
+				data[key] = value
+			}
+			for key, value := range options.RecipientTemplateData[addr.Email] {
+				data[key] = value
+			}
+			personalizations = append(personalizations, sendgridPersonalization{
+				To:                  []sendgridAddress{{Email: addr.Email, Name: addr.Name}},
+				DynamicTemplateData: data,
+			})
+		}
+	}
+
+	personalizations[0].Cc = m.getAddresses(msg.Cc)
+	personalizations[0].Bcc = m.getAddresses(msg.Bcc)
+
+	return personalizations
+}
+
+func (m *sendgridMailer) getAddresses(addrs []Address) []sendgridAddress {
+	var addresses []sendgridAddress
+	for _, addr := range addrs {
+		addresses = append(addresses, sendgridAddress{Email: addr.Email, Name: addr.Name})
+	}
+	return addresses
+}
+
+type sendgridTemplate struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sendgridTemplateVersion struct {
+	Name         string `json:"name"`
+	Subject      string `json:"subject"`
+	HtmlContent  string `json:"html_content"`
+	PlainContent string `json:"plain_content,omitempty"`
+	Active       int    `json:"active"`
+}
+
+func (m *sendgridMailer) CreateTemplate(ctx context.Context, tpl Template) error {
+	var created sendgridTemplate
+	err := m.do(ctx, http.MethodPost, "/v3/templates", map[string]string{
+		"name":       tpl.Name,
+		"generation": "dynamic",
+	}, &created)
+	if err != nil {
+		return err
+	}
+	return m.createTemplateVersion(ctx, created.ID, tpl)
+}
+
+func (m *sendgridMailer) UpdateTemplate(ctx context.Context, tpl Template) error {
+	id, err := m.findTemplateID(ctx, tpl.Name)
+	if err != nil {
+		return err
+	}
+	return m.createTemplateVersion(ctx, id, tpl)
+}
+
+func (m *sendgridMailer) DeleteTemplate(ctx context.Context, name string) error {
+	id, err := m.findTemplateID(ctx, name)
+	if err != nil {
+		return err
+	}
+	return m.do(ctx, http.MethodDelete, "/v3/templates/"+id, nil, nil)
+}
+
+// createTemplateVersion adds an active version to the template, SendGrid keeps
+// the previous versions so that they can be restored from the dashboard.
+func (m *sendgridMailer) createTemplateVersion(ctx context.Context, id string, tpl Template) error {
+	return m.do(ctx, http.MethodPost, "/v3/templates/"+id+"/versions", sendgridTemplateVersion{
+		Name:         tpl.Name,
+		Subject:      tpl.Subject,
+		HtmlContent:  tpl.Html,
+		PlainContent: tpl.Text,
+		Active:       1,
+	}, nil)
+}
+
+func (m *sendgridMailer) findTemplateID(ctx context.Context, name string) (string, error) {
+	var templates struct {
+		Result []sendgridTemplate `json:"result"`
+	}
+	err := m.do(ctx, http.MethodGet, "/v3/templates?generations=dynamic&page_size=200", nil, &templates)
+	if err != nil {
+		return "", err
+	}
+	for _, template := range templates.Result {
+		if template.Name == name {
+			return template.ID, nil
+		}
+	}
+	return "", fmt.Errorf("sendgrid template %q not found", name)
+}
+
+// Health verifies the API key by listing its scopes.
+func (m *sendgridMailer) Health(ctx context.Context) error {
+	return m.do(ctx, http.MethodGet, "/v3/scopes", nil, nil)
+}
+
+func (m *sendgridMailer) Close() {
+	// Not implemented because the SendGrid API is stateless.
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type sendgridMessage struct {
+	MsgID         string    `json:"msg_id"`
+	ToEmail       string    `json:"to_email"`
+	Status        string    `json:"status"`
+	OpensCount    int       `json:"opens_count"`
+	LastEventTime time.Time `json:"last_event_time"`
+}
+
+// PollEvents fetches the messages whose last event happened since the given time
+// from the SendGrid Email Activity API, which requires the extended history
+// add-on. The API reports the state of each message rather than every event, so
+// a message is reported as delivered or bounced, and opened once it was.
+func (m *sendgridMailer) PollEvents(ctx context.Context, since time.Time) ([]Event, error) {
+	query := url.Values{
+		"limit": {"1000"},
+		"query": {`last_event_time BETWEEN TIMESTAMP "` + since.UTC().Format(time.RFC3339) + `" AND TIMESTAMP "` + time.Now().UTC().Format(time.RFC3339) + `"`},
+	}
+
+	var activity struct {
+		Messages []sendgridMessage `json:"messages"`
+	}
+	if err := m.do(ctx, http.MethodGet, "/v3/messages?"+query.Encode(), nil, &activity); err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, message := range activity.Messages {
+		event := Event{
+			Provider:  SENDGRID,
+			MessageID: message.MsgID,
+			Recipient: message.ToEmail,
+			Timestamp: message.LastEventTime,
+		}
+		switch message.Status {
+		case "delivered":
+			event.Type = EventDelivered
+		case "not_delivered":
+			event.Type = EventBounced
+			event.Permanent = true
+		default:
+			continue
+		}
+		events = append(events, event)
+
+		if message.OpensCount > 0 {
+			event.Type = EventOpened
+			event.Permanent = false
+			events = append(events, event)
+		}
+	}
+	sortEvents(events)
+	return events, nil
+}
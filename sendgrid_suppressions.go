@@ -0,0 +1,137 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sendgridPageSize is the largest page of the SendGrid suppression endpoints.
+const sendgridPageSize = 500
+
+// sendgridSuppressionLists are the suppression lists of SendGrid by reason.
+var sendgridSuppressionLists = []struct {
+	path   string
+	reason SuppressionReason
+}{
+	{path: "/v3/suppression/bounces", reason: SuppressionBounce},
+	{path: "/v3/suppression/blocks", reason: SuppressionBlock},
+	{path: "/v3/suppression/spam_reports", reason: SuppressionSpamReport},
+	{path: "/v3/suppression/unsubscribes", reason: SuppressionUnsubscribe},
+}
+
+type sendgridSuppression struct {
+	Email   string `json:"email"`
+	Created int64  `json:"created"`
+}
+
+type sendgridGroup struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// SyncSuppressions synchronizes the list with the bounces, blocks, spam reports,
+// global unsubscribes and group unsubscribes of SendGrid. SendGrid only lets
+// unsubscribes be added, so the other suppressions of the list are pushed as
+// global unsubscribes.
+func (m *sendgridMailer) SyncSuppressions(ctx context.Context, list SyncableSuppressionList) (SuppressionSyncResult, error) {
+	var result SuppressionSyncResult
+
+	remote, groups, err := m.listSuppressions(ctx)
+	if err != nil {
+		return result, err
+	}
+	local, err := list.List(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	// An address suppressed for any reason at SendGrid is not sent to, however
+	// it is suppressed locally.
+	remoteKeys := make(map[string]bool, len(remote))
+	for _, suppression := range remote {
+		remoteKeys[suppression.key()] = true
+	}
+	localKeys := make(map[string]bool, len(local))
+	for _, suppression := range local {
+		localKeys[suppression.key()] = true
+	}
+
+	for _, suppression := range remote {
+		if localKeys[suppression.key()] {
+			continue
+		}
+		if err := list.Suppress(ctx, suppression); err != nil {
+			return result, err
+		}
+		localKeys[suppression.key()] = true
+		result.Pulled++
+	}
+
+	pushes := make(map[string][]string)
+	for _, suppression := range local {
+		if remoteKeys[suppression.key()] {
+			continue
+		}
+		pushes[suppression.Group] = append(pushes[suppression.Group], suppression.Email)
+	}
+
+	for group, emails := range pushes {
+		path := "/v3/asm/suppressions/global"
+		if group != "" {
+			id, ok := groups[group]
+			if !ok {
+				continue
+			}
+			path = fmt.Sprintf("/v3/asm/groups/%d/suppressions", id)
+		}
+		for start := 0; start < len(emails); start += sendgridPageSize {
+			batch := emails[start:min(start+sendgridPageSize, len(emails))]
+			err := m.do(ctx, http.MethodPost, path, map[string][]string{"recipient_emails": batch}, nil)
+			if err != nil {
+				return result, err
+			}
+			result.Pushed += len(batch)
+		}
+	}
+	return result, nil
+}
+
+// listSuppressions returns every suppression of SendGrid, and the ids of the
+// unsubscribe groups by name.
+func (m *sendgridMailer) listSuppressions(ctx context.Context) ([]Suppression, map[string]int, error) {
+	var suppressions []Suppression
+	for _, list := range sendgridSuppressionLists {
+		for offset := 0; ; offset += sendgridPageSize {
+			var page []sendgridSuppression
+			path := fmt.Sprintf("%s?limit=%d&offset=%d", list.path, sendgridPageSize, offset)
+			if err := m.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+				return nil, nil, err
+			}
+			for _, s := range page {
+				suppressions = append(suppressions, Suppression{Email: s.Email, Reason: list.reason, CreatedAt: time.Unix(s.Created, 0)})
+			}
+			if len(page) < sendgridPageSize {
+				break
+			}
+		}
+	}
+
+	var groups []sendgridGroup
+	if err := m.do(ctx, http.MethodGet, "/v3/asm/groups", nil, &groups); err != nil {
+		return nil, nil, err
+	}
+	ids := make(map[string]int, len(groups))
+	for _, group := range groups {
+		ids[group.Name] = group.ID
+		var emails []string
+		if err := m.do(ctx, http.MethodGet, fmt.Sprintf("/v3/asm/groups/%d/suppressions", group.ID), nil, &emails); err != nil {
+			return nil, nil, err
+		}
+		for _, email := range emails {
+			suppressions = append(suppressions, Suppression{Email: email, Reason: SuppressionUnsubscribe, Group: group.Name})
+		}
+	}
+	return suppressions, ids, nil
+}
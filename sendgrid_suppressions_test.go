@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+)
+
+func TestSendGrid_SyncSuppressions(t *testing.T) {
+	pushed := make(map[string][]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body struct {
+				RecipientEmails []string `json:"recipient_emails"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			pushed[r.URL.Path] = body.RecipientEmails
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v3/suppression/bounces":
+			w.Write([]byte(`[{"email":"bounced@test.com","created":1700000000}]`))
+		case "/v3/suppression/unsubscribes":
+			w.Write([]byte(`[{"email":"shared@test.com","created":1700000000}]`))
+		case "/v3/asm/groups":
+			w.Write([]byte(`[{"id":7,"name":"newsletter"}]`))
+		case "/v3/asm/groups/7/suppressions":
+			w.Write([]byte(`["reader@test.com"]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	sendgrid := newSendGrid(sendgridParams{apiKey: "key"}).(*sendgridMailer)
+	sendgrid.baseURL = server.URL
+
+	list := NewMemorySuppressionList("shared@test.com", "local@test.com")
+	list.Suppress(context.Background(), Suppression{Email: "weekly@test.com", Reason: SuppressionUnsubscribe, Group: "newsletter"})
+
+	result, err := sendgrid.SyncSuppressions(context.Background(), list)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Pulled != 2 || result.Pushed != 2 {
+		t.Errorf("Expected 2 pulled and 2 pushed, got %+v", result)
+	}
+
+	if suppressed, _ := list.Suppressed("bounced@test.com"); !suppressed {
+		t.Errorf("Expected the SendGrid bounce to be suppressed locally")
+	}
+	if suppressed, _ := list.Suppressed("reader@test.com"); suppressed {
+		t.Errorf("Expected a group unsubscribe not to suppress every email")
+	}
+	suppressions, _ := list.List(context.Background())
+	if len(suppressions) != 5 {
+		t.Errorf("Expected 5 suppressions, got %+v", suppressions)
+	}
+
+	global := pushed["/v3/asm/suppressions/global"]
+	sort.Strings(global)
+	if len(global) != 1 || global[0] != "local@test.com" {
+		t.Errorf("Expected the local suppression pushed as a global unsubscribe, got %v", global)
+	}
+	if group := pushed["/v3/asm/groups/7/suppressions"]; len(group) != 1 || group[0] != "weekly@test.com" {
+		t.Errorf("Expected the group unsubscribe pushed to its group, got %v", group)
+	}
+}
@@ -0,0 +1,261 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestSendGrid(t *testing.T, handler http.HandlerFunc) *sendgridMailer {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return &sendgridMailer{
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+		apiKey:     MailAPIKey,
+	}
+}
+
+func TestSendGrid_Send(t *testing.T) {
+	testCases := []struct {
+		name     string
+		payload  Mail
+		validate func(t *testing.T, req sendgridRequest)
+	}{
+		{
+			name: "Should send html and text content",
+			payload: Mail{
+				From:    Address{Email: "info@test.com"},
+				To:      Addresses("a@test.com", "b@test.com"),
+				Cc:      Addresses("c@test.com"),
+				Subject: "test",
+				Html:    "<p>test</p>",
+				Text:    "test",
+				Tags:    []string{"welcome:short"},
+			},
+			validate: func(t *testing.T, req sendgridRequest) {
+				if len(req.Categories) != 1 || req.Categories[0] != "welcome:short" {
+					t.Errorf("Expected the tags as categories, got %v", req.Categories)
+				}
+				if len(req.Personalizations) != 1 || len(req.Personalizations[0].To) != 2 {
+					t.Errorf("Expected one personalization with two recipients, got %+v", req.Personalizations)
+				}
+				if len(req.Personalizations[0].Cc) != 1 {
+					t.Errorf("Expected one cc recipient, got %+v", req.Personalizations[0].Cc)
+				}
+				if len(req.Content) != 2 {
+					t.Errorf("Expected two content parts, got %d", len(req.Content))
+				}
+			},
+		},
+		{
+			name: "Should split the display names of the sender and the reply-to",
+			payload: Mail{
+				From:    Address{Name: "Acme Inc", Email: "info@acme.com"},
+				ReplyTo: Address{Name: "Support", Email: "support@acme.com"},
+				To:      Addresses("a@test.com"),
+				Text:    "test",
+			},
+			validate: func(t *testing.T, req sendgridRequest) {
+				if req.From != (sendgridAddress{Email: "info@acme.com", Name: "Acme Inc"}) {
+					t.Errorf("Expected the sender name and address, got %+v", req.From)
+				}
+				if req.ReplyTo == nil || *req.ReplyTo != (sendgridAddress{Email: "support@acme.com", Name: "Support"}) {
+					t.Errorf("Expected the reply-to name and address, got %+v", req.ReplyTo)
+				}
+			},
+		},
+		{
+			name: "Should send the AMP content before the html",
+			payload: Mail{
+				From:    Address{Email: "info@test.com"},
+				To:      Addresses("a@test.com"),
+				Text:    "test",
+				AmpHtml: "<html amp4email></html>",
+				Html:    "<p>test</p>",
+			},
+			validate: func(t *testing.T, req sendgridRequest) {
+				if len(req.Content) != 3 || req.Content[1].Type != "text/x-amp-html" || req.Content[2].Type != "text/html" {
+					t.Errorf("Expected text, AMP and html content, got %+v", req.Content)
+				}
+			},
+		},
+		{
+			name: "Should send a dynamic template with per recipient data",
+			payload: Mail{
+				From: Address{Email: "info@test.com"},
+				To:   Addresses("a@test.com", "b@test.com"),
+				Html: "<p>ignored</p>",
+				SendGrid: &SendGridOptions{
+					TemplateID:   "d-123",
+					TemplateData: map[string]any{"product": "Caesar"},
+					RecipientTemplateData: map[string]map[string]any{
+						"a@test.com": {"name": "Alice"},
+						"b@test.com": {"name": "Bob"},
+					},
+					SandboxMode: true,
+				},
+			},
+			validate: func(t *testing.T, req sendgridRequest) {
+				if req.TemplateID != "d-123" {
+					t.Errorf("Expected template id d-123, got %s", req.TemplateID)
+				}
+				if len(req.Content) != 0 {
+					t.Errorf("Expected no content when using a template, got %d", len(req.Content))
+				}
+				if req.MailSettings == nil || !req.MailSettings.SandboxMode.Enable {
+					t.Errorf("Expected sandbox mode to be enabled")
+				}
+				if len(req.Personalizations) != 2 {
+					t.Fatalf("Expected two personalizations, got %d", len(req.Personalizations))
+				}
+				data := req.Personalizations[1].DynamicTemplateData
+				if data["name"] != "Bob" || data["product"] != "Caesar" {
+					t.Errorf("Expected merged template data, got %v", data)
+				}
+			},
+		},
+		{
+			name: "Should send a dynamic template with per recipient data to Bcc recipients only",
+			payload: Mail{
+				From: Address{Email: "info@test.com"},
+				Bcc:  Addresses("a@test.com"),
+				SendGrid: &SendGridOptions{
+					TemplateID:   "d-123",
+					TemplateData: map[string]any{"product": "Caesar"},
+					RecipientTemplateData: map[string]map[string]any{
+						"a@test.com": {"name": "Alice"},
+					},
+				},
+			},
+			validate: func(t *testing.T, req sendgridRequest) {
+				if len(req.Personalizations) != 1 || len(req.Personalizations[0].Bcc) != 1 {
+					t.Fatalf("Expected one personalization with the bcc recipient, got %+v", req.Personalizations)
+				}
+				if req.Personalizations[0].DynamicTemplateData["product"] != "Caesar" {
+					t.Errorf("Expected the shared template data, got %v", req.Personalizations[0].DynamicTemplateData)
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received sendgridRequest
+			sendgrid := newTestSendGrid(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v3/mail/send" {
+					t.Errorf("Expected path /v3/mail/send, got %s", r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != "Bearer "+MailAPIKey {
+					t.Errorf("Expected bearer authorization, got %s", r.Header.Get("Authorization"))
+				}
+				if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+					t.Fatal(err)
+				}
+				w.WriteHeader(http.StatusAccepted)
+			})
+
+			if err := sendgrid.Send(tc.payload); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			tc.validate(t, received)
+		})
+	}
+}
+
+func TestSendGrid_SendError(t *testing.T) {
+	sendgrid := newTestSendGrid(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors":[{"message":"invalid template"}]}`, http.StatusBadRequest)
+	})
+
+	err := sendgrid.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Text: "test"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Provider != SENDGRID {
+		t.Errorf("Unexpected error %+v", apiErr)
+	}
+}
+
+func TestSendGrid_TemplateManagement(t *testing.T) {
+	var calls []string
+	sendgrid := newTestSendGrid(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v3/templates":
+			w.Write([]byte(`{"id":"d-1","name":"welcome"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v3/templates":
+			w.Write([]byte(`{"result":[{"id":"d-0","name":"other"},{"id":"d-1","name":"welcome"}]}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	})
+
+	ctx := context.Background()
+	tpl := Template{Name: "welcome", Subject: "Welcome {{name}}", Html: "<p>Hi {{name}}</p>"}
+	if err := sendgrid.CreateTemplate(ctx, tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := sendgrid.UpdateTemplate(ctx, tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := sendgrid.DeleteTemplate(ctx, tpl.Name); err != nil {
+		t.Fatal(err)
+	}
+	if err := sendgrid.DeleteTemplate(ctx, "missing"); err == nil {
+		t.Errorf("Expected an error for a missing template")
+	}
+
+	expected := []string{
+		"POST /v3/templates",
+		"POST /v3/templates/d-1/versions",
+		"GET /v3/templates",
+		"POST /v3/templates/d-1/versions",
+		"GET /v3/templates",
+		"DELETE /v3/templates/d-1",
+		"GET /v3/templates",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("Expected calls %v, got %v", expected, calls)
+			break
+		}
+	}
+}
+
+func TestSendGrid_Health(t *testing.T) {
+	testCases := []struct {
+		name       string
+		statusCode int
+		success    bool
+	}{
+		{name: "valid api key", statusCode: http.StatusOK, success: true},
+		{name: "invalid api key", statusCode: http.StatusUnauthorized, success: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sendgrid := newTestSendGrid(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v3/scopes" {
+					t.Errorf("Expected path /v3/scopes, got %s", r.URL.Path)
+				}
+				w.WriteHeader(tc.statusCode)
+			})
+
+			err := sendgrid.Health(context.Background())
+			if (err == nil) != tc.success {
+				t.Errorf("Expected success to be %v, got %v", tc.success, err)
+			}
+		})
+	}
+}
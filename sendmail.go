@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const defaultSendmailPath = "/usr/sbin/sendmail"
+
+type sendmailParams struct {
+	path string
+	args []string
+}
+
+type sendmailMailer struct {
+	path string
+	args []string
+}
+
+func newSendmail(params sendmailParams) MailerClient {
+	path := params.path
+	if path == "" {
+		path = defaultSendmailPath
+	}
+
+	return &sendmailMailer{path: path, args: params.args}
+}
+
+// Send pipes the message to the sendmail binary. The envelope sender and the
+// recipients are passed as arguments so that Bcc recipients are not leaked in
+// the headers, which works with sendmail, postfix, exim and msmtp alike.
+func (m *sendmailMailer) Send(msg Mail) error {
+	message, email := buildMessage(msg)
+
+	if email.Error != nil {
+		return email.Error
+	}
+
+	// sendmail, postfix and exim request delivery status notifications with -N and -R.
+	var dsn []string
+	if n := msg.DeliveryNotification; n != nil {
+		dsn = []string{"-N", strings.ToLower(strings.Join(n.notify(), ",")), "-R", strings.ToLower(n.ret())}
+	}
+	return m.run(context.Background(), dsn, email.GetFrom(), email.GetRecipients(), []byte(message))
+}
+
+// SendRaw pipes the message as-is to the sendmail binary.
+func (m *sendmailMailer) SendRaw(ctx context.Context, from string, rcpts []string, message []byte) error {
+	return m.run(ctx, nil, from, rcpts, message)
+}
+
+func (m *sendmailMailer) run(ctx context.Context, extraArgs []string, from string, rcpts []string, message []byte) error {
+	args := append([]string{}, m.args...)
+	args = append(args, extraArgs...)
+	args = append(args, "-i", "-f", from, "--")
+	args = append(args, rcpts...)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, m.path, args...)
+	cmd.Stdin = bytes.NewReader(message)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sendmail: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Health verifies that the sendmail binary exists and is executable.
+func (m *sendmailMailer) Health(ctx context.Context) error {
+	_, err := exec.LookPath(m.path)
+	return err
+}
+
+func (m *sendmailMailer) Close() {
+	// Not implemented because every email runs its own sendmail process.
+}
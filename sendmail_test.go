@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSendmail_Send(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sendmail is not available on windows")
+	}
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+	script := filepath.Join(dir, "sendmail")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+output+"\ncat >> "+output+"\n"), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name    string
+		path    string
+		payload Mail
+		success bool
+	}{
+		{
+			name: "Should pipe the message to sendmail",
+			path: script,
+			payload: Mail{
+				From:    Address{Email: "info@test.com"},
+				To:      Addresses("test@gmail.com"),
+				Bcc:     Addresses("hidden@gmail.com"),
+				Subject: "test",
+				Text:    "test",
+			},
+			success: true,
+		},
+		{
+			name:    "Should fail when the binary does not exist",
+			path:    filepath.Join(dir, "missing"),
+			payload: Mail{From: Address{Email: "info@test.com"}, To: Addresses("test@gmail.com"), Text: "test"},
+			success: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sendmail := newSendmail(sendmailParams{path: tc.path})
+
+			err := sendmail.Send(tc.payload)
+			if !tc.success {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.SplitN(string(data), "\n", 2)
+			if lines[0] != "-i -f info@test.com -- test@gmail.com hidden@gmail.com" {
+				t.Errorf("Unexpected sendmail arguments %q", lines[0])
+			}
+			if !strings.Contains(lines[1], "Subject: test") {
+				t.Errorf("Expected the message on stdin, got %q", lines[1])
+			}
+			if strings.Contains(lines[1], "hidden@gmail.com") {
+				t.Errorf("Expected bcc recipients to be absent from the headers")
+			}
+		})
+	}
+}
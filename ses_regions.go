@@ -0,0 +1,217 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/smithy-go"
+)
+
+// SESRegionSelection decides in which order the SES regions are tried.
+type SESRegionSelection int
+
+const (
+	// SESRegionPriority tries the regions in the order of MailCfg.SESRegions.
+	SESRegionPriority SESRegionSelection = iota
+	// SESRegionLatency tries the regions with the lowest latency of the recent
+	// sends first.
+	SESRegionLatency
+)
+
+// sesRegionCooldown is how long a region that throttled or failed is tried last.
+const sesRegionCooldown = 30 * time.Second
+
+// sesFailoverCodes are the error codes of SES worth retrying in another region.
+var sesFailoverCodes = []string{"TooManyRequestsException", "LimitExceededException", "ThrottlingException", "SendingPausedException"}
+
+type sesRegion struct {
+	name           string
+	sesClient      sesMailerClient
+	templateClient sesTemplateClient
+	accountClient  sesAccountClient
+
+	mu sync.Mutex
+	// latency is the moving average of the duration of the sends.
+	latency   time.Duration
+	downUntil time.Time
+}
+
+// observe records the outcome of a send from the region.
+func (r *sesRegion) observe(d time.Duration, err error, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		if isSESFailover(err) {
+			r.downUntil = now.Add(sesRegionCooldown)
+		}
+		return
+	}
+	if r.latency == 0 {
+		r.latency = d
+	} else {
+		r.latency = (r.latency*4 + d) / 5
+	}
+}
+
+func (r *sesRegion) state(now time.Time) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.latency, now.Before(r.downUntil)
+}
+
+// sesRegions sends from one region, failing over to the next when it throttles
+// or errors.
+type sesRegions struct {
+	regions   []*sesRegion
+	selection SESRegionSelection
+	clock     Clock
+}
+
+func newSESRegions(params sesParams) *sesRegions {
+	regions := &sesRegions{selection: params.RegionSelection, clock: params.Clock}
+	if regions.clock == nil {
+		regions.clock = systemClock{}
+	}
+	for _, name := range params.Regions {
+		regionParams := params
+		regionParams.Region = name
+		client := sesv2.NewFromConfig(loadSESConfig(regionParams))
+		regions.regions = append(regions.regions, &sesRegion{
+			name:           name,
+			sesClient:      client,
+			templateClient: client,
+			accountClient:  client,
+		})
+	}
+	return regions
+}
+
+// ordered returns the regions in the order they are tried, the regions down
+// since a recent failure coming last.
+func (r *sesRegions) ordered(now time.Time) []*sesRegion {
+	type candidate struct {
+		region  *sesRegion
+		latency time.Duration
+		down    bool
+	}
+	candidates := make([]candidate, len(r.regions))
+	for i, region := range r.regions {
+		latency, down := region.state(now)
+		candidates[i] = candidate{region: region, latency: latency, down: down}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].down != candidates[j].down {
+			return !candidates[i].down
+		}
+		return r.selection == SESRegionLatency && candidates[i].latency < candidates[j].latency
+	})
+
+	regions := make([]*sesRegion, len(candidates))
+	for i, candidate := range candidates {
+		regions[i] = candidate.region
+	}
+	return regions
+}
+
+// SendEmail sends the email from the first region that accepts it. The regions
+// use the same configuration set, which must exist in each of them.
+func (r *sesRegions) SendEmail(ctx context.Context, params *sesv2.SendEmailInput, optFns ...func(*sesv2.Options)) (*sesv2.SendEmailOutput, error) {
+	var errs []error
+	for _, region := range r.ordered(r.clock.Now()) {
+		start := r.clock.Now()
+		output, err := region.sesClient.SendEmail(ctx, params, optFns...)
+		region.observe(r.clock.Now().Sub(start), err, r.clock.Now())
+		if err == nil {
+			return output, nil
+		}
+		if !isSESFailover(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", region.name, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// isSESFailover reports whether the error is a throttling or a failure of the
+// region, rather than a rejection of the email.
+func isSESFailover(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		for _, code := range sesFailoverCodes {
+			if apiErr.ErrorCode() == code {
+				return true
+			}
+		}
+		if apiErr.ErrorFault() == smithy.FaultServer {
+			return true
+		}
+	}
+
+	var resErr *awshttp.ResponseError
+	if errors.As(err, &resErr) && resErr.HTTPStatusCode() >= 500 {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// CreateEmailTemplate creates the template in every region.
+func (r *sesRegions) CreateEmailTemplate(ctx context.Context, params *sesv2.CreateEmailTemplateInput, optFns ...func(*sesv2.Options)) (*sesv2.CreateEmailTemplateOutput, error) {
+	var output *sesv2.CreateEmailTemplateOutput
+	err := r.each(func(region *sesRegion) (err error) {
+		output, err = region.templateClient.CreateEmailTemplate(ctx, params, optFns...)
+		return err
+	})
+	return output, err
+}
+
+// UpdateEmailTemplate updates the template in every region.
+func (r *sesRegions) UpdateEmailTemplate(ctx context.Context, params *sesv2.UpdateEmailTemplateInput, optFns ...func(*sesv2.Options)) (*sesv2.UpdateEmailTemplateOutput, error) {
+	var output *sesv2.UpdateEmailTemplateOutput
+	err := r.each(func(region *sesRegion) (err error) {
+		output, err = region.templateClient.UpdateEmailTemplate(ctx, params, optFns...)
+		return err
+	})
+	return output, err
+}
+
+// DeleteEmailTemplate deletes the template in every region.
+func (r *sesRegions) DeleteEmailTemplate(ctx context.Context, params *sesv2.DeleteEmailTemplateInput, optFns ...func(*sesv2.Options)) (*sesv2.DeleteEmailTemplateOutput, error) {
+	var output *sesv2.DeleteEmailTemplateOutput
+	err := r.each(func(region *sesRegion) (err error) {
+		output, err = region.templateClient.DeleteEmailTemplate(ctx, params, optFns...)
+		return err
+	})
+	return output, err
+}
+
+// GetAccount fetches the SES account of every region, so that Health reports a
+// failover region that would not work.
+func (r *sesRegions) GetAccount(ctx context.Context, params *sesv2.GetAccountInput, optFns ...func(*sesv2.Options)) (*sesv2.GetAccountOutput, error) {
+	var output *sesv2.GetAccountOutput
+	err := r.each(func(region *sesRegion) (err error) {
+		output, err = region.accountClient.GetAccount(ctx, params, optFns...)
+		return err
+	})
+	return output, err
+}
+
+// each calls f with every region, returning the failures together.
+func (r *sesRegions) each(f func(region *sesRegion) error) error {
+	var errs []error
+	for _, region := range r.regions {
+		if err := f(region); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", region.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
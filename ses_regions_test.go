@@ -0,0 +1,89 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/smithy-go"
+)
+
+func TestSESRegions_SendEmail(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)}
+	var calls []string
+	region := func(name string, err error) *sesRegion {
+		return &sesRegion{name: name, sesClient: &mockSESClient{
+			SendEmailFunc: func(ctx context.Context, input *sesv2.SendEmailInput) (*sesv2.SendEmailOutput, error) {
+				calls = append(calls, name)
+				return &sesv2.SendEmailOutput{}, err
+			},
+		}}
+	}
+	throttled := &smithy.GenericAPIError{Code: "TooManyRequestsException"}
+	regions := &sesRegions{
+		regions: []*sesRegion{region("us-east-1", throttled), region("eu-west-1", nil)},
+		clock:   clock,
+	}
+	ses := &sesMailer{sesClient: regions}
+	msg := Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Text: "test"}
+
+	if err := ses.Send(msg); err != nil {
+		t.Fatalf("Expected the email to fail over, got %v", err)
+	}
+	if err := ses.Send(msg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{"us-east-1", "eu-west-1", "eu-west-1"}
+	if len(calls) != len(expected) || calls[0] != expected[0] || calls[1] != expected[1] || calls[2] != expected[2] {
+		t.Errorf("Expected the throttled region to be skipped, got %v", calls)
+	}
+
+	clock.Advance(sesRegionCooldown)
+	calls = nil
+	ses.Send(msg)
+	if len(calls) == 0 || calls[0] != "us-east-1" {
+		t.Errorf("Expected the region to be tried first after the cooldown, got %v", calls)
+	}
+}
+
+func TestSESRegions_Rejected(t *testing.T) {
+	rejected := &smithy.GenericAPIError{Code: "MessageRejected", Fault: smithy.FaultClient}
+	var calls int
+	regions := &sesRegions{clock: systemClock{}}
+	for _, name := range []string{"us-east-1", "eu-west-1"} {
+		regions.regions = append(regions.regions, &sesRegion{name: name, sesClient: &mockSESClient{
+			SendEmailFunc: func(ctx context.Context, input *sesv2.SendEmailInput) (*sesv2.SendEmailOutput, error) {
+				calls++
+				return nil, rejected
+			},
+		}})
+	}
+
+	_, err := regions.SendEmail(context.Background(), &sesv2.SendEmailInput{})
+	if !errors.Is(err, rejected) || calls != 1 {
+		t.Errorf("Expected a rejected email not to fail over, got %v after %d calls", err, calls)
+	}
+}
+
+func TestSESRegions_Latency(t *testing.T) {
+	now := time.Now()
+	slow := &sesRegion{name: "us-east-1", latency: 300 * time.Millisecond}
+	fast := &sesRegion{name: "eu-west-1", latency: 40 * time.Millisecond}
+
+	regions := &sesRegions{regions: []*sesRegion{slow, fast}}
+	if ordered := regions.ordered(now); ordered[0] != slow {
+		t.Errorf("Expected the regions by priority, got %s first", ordered[0].name)
+	}
+
+	regions.selection = SESRegionLatency
+	if ordered := regions.ordered(now); ordered[0] != fast {
+		t.Errorf("Expected the fastest region first, got %s", ordered[0].name)
+	}
+
+	fast.downUntil = now.Add(time.Second)
+	if ordered := regions.ordered(now); ordered[0] != slow {
+		t.Errorf("Expected the region down to come last, got %s first", ordered[0].name)
+	}
+}
@@ -0,0 +1,109 @@
+package mailer
+
+import (
+	"sync"
+	"time"
+)
+
+// ShadowSender sends a copy of the real traffic through a candidate provider,
+// to compare its latency and error rate with the primary provider before
+// switching to it.
+type ShadowSender struct {
+	candidate MailerClient
+	seeds     []string
+
+	wg    sync.WaitGroup
+	mu    sync.Mutex
+	stats ShadowStats
+}
+
+// ShadowStats compares the primary and the candidate providers.
+type ShadowStats struct {
+	Primary   ShadowProviderStats
+	Candidate ShadowProviderStats
+}
+
+// ShadowProviderStats measures the sends of one provider.
+type ShadowProviderStats struct {
+	Sent    int
+	Failed  int
+	Latency time.Duration
+}
+
+// AverageLatency returns the average latency of a send.
+func (s ShadowProviderStats) AverageLatency() time.Duration {
+	if s.Sent+s.Failed == 0 {
+		return 0
+	}
+	return s.Latency / time.Duration(s.Sent+s.Failed)
+}
+
+// ErrorRate returns the ratio of failed sends, between 0 and 1.
+func (s ShadowProviderStats) ErrorRate() float64 {
+	if s.Sent+s.Failed == 0 {
+		return 0
+	}
+	return float64(s.Failed) / float64(s.Sent+s.Failed)
+}
+
+// NewShadowSender creates a shadow sender with the candidate provider configuration.
+// The copies are sent to the seed addresses instead of the real recipients, use
+// the sandbox of the candidate provider e.g. SendGrid sandbox mode for the seeds
+// to not be delivered.
+func NewShadowSender(candidate MailCfg, seeds ...string) *ShadowSender {
+	return &ShadowSender{
+		candidate: getMailerClient(candidate),
+		seeds:     seeds,
+	}
+}
+
+// Middleware returns the Middleware sending the emails through the primary provider,
+// and their copies through the candidate provider in the background.
+func (s *ShadowSender) Middleware() Middleware {
+	return func(next SendFunc) SendFunc {
+		return func(msg Mail) error {
+			start := time.Now()
+			err := next(msg)
+			s.record(&s.stats.Primary, time.Since(start), err)
+
+			shadow := msg
+			shadow.To = toAddresses(s.seeds)
+			shadow.Cc = nil
+			shadow.Bcc = nil
+
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				start := time.Now()
+				err := s.candidate.Send(shadow)
+				s.record(&s.stats.Candidate, time.Since(start), err)
+			}()
+
+			return err
+		}
+	}
+}
+
+func (s *ShadowSender) record(stats *ShadowProviderStats, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats.Latency += latency
+	if err != nil {
+		stats.Failed++
+	} else {
+		stats.Sent++
+	}
+}
+
+// Stats returns the comparison of the primary and the candidate providers.
+func (s *ShadowSender) Stats() ShadowStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Close waits for the copies being sent and closes the candidate client.
+func (s *ShadowSender) Close() {
+	s.wg.Wait()
+	s.candidate.Close()
+}
@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestShadowSender(t *testing.T) {
+	var mu sync.Mutex
+	var copies []Mail
+	shadow := NewShadowSender(MailCfg{
+		APIService: SENDGRID,
+		APIKey:     "candidate-key",
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			mu.Lock()
+			defer mu.Unlock()
+			copies = append(copies, msg)
+			if len(copies) == 2 {
+				return errors.New("candidate failure")
+			}
+			return nil
+		}},
+	}, "seed1@test.com", "seed2@test.com")
+
+	mailer := NewMailer(MailCfg{
+		APIService:   RESEND,
+		APIKey:       MailAPIKey,
+		mailerClient: &mockMailerClient{},
+		Middlewares:  []Middleware{shadow.Middleware()},
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := mailer.Send(Mail{To: Addresses("user@example.com"), Cc: Addresses("cc@example.com"), Subject: "Hello", Text: "Hello"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	mailer.Close()
+	shadow.Close()
+
+	if len(copies) != 2 || joinAddresses(copies[0].To) != "seed1@test.com, seed2@test.com" || joinAddresses(copies[0].Cc) != "" {
+		t.Fatalf("Expected copies to the seeds only, got %+v", copies)
+	}
+
+	stats := shadow.Stats()
+	if stats.Primary.Sent != 2 || stats.Primary.ErrorRate() != 0 {
+		t.Errorf("Unexpected primary stats %+v", stats.Primary)
+	}
+	if stats.Candidate.Sent != 1 || stats.Candidate.ErrorRate() != 0.5 {
+		t.Errorf("Unexpected candidate stats %+v", stats.Candidate)
+	}
+}
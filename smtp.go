@@ -1,62 +1,301 @@
 package mailer
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"log"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	mail "github.com/xhit/go-simple-mail/v2"
 )
 
 type smtpParams struct {
-	Username  string
-	Password  string
-	Host      string
-	Port      string
-	KeepAlive bool
-	Timeout   int
-	useTLS    bool
+	Username   string
+	Password   string
+	Host       string
+	Port       string
+	KeepAlive  bool
+	Timeout    int
+	useTLS     bool
+	encryption mail.Encryption
+	dial       DialOptions
 }
 
 type smtpMailer struct {
-	smtpClient *mail.SMTPClient
+	host       string
+	port       string
+	username   string
+	password   string
+	encryption mail.Encryption
+	tlsConfig  *tls.Config
+	keepAlive  bool
+	timeout    time.Duration
+	dialer     *dialer
+	conns      *connTracker
+
+	mu sync.Mutex
+	// idle holds the sessions waiting for a send, a session being held by a
+	// single send at a time since SMTP clients are not safe for concurrent use.
+	idle []*smtpSession
 }
 
-func newSMTP(params smtpParams) MailerClient {
-	server := mail.NewSMTPClient()
+// smtpSession is a connection to the server, kept with its net.Conn to extend
+// the deadline of every transaction.
+type smtpSession struct {
+	*smtp.Client
+	conn net.Conn
+	// used is whether the session already carried a message.
+	used bool
+}
 
-	server.Host = params.Host
-	server.Port = getPort(params.Port)
-	server.Username = params.Username
-	server.Password = params.Password
-	server.Encryption = mail.EncryptionSTARTTLS
-	server.KeepAlive = params.KeepAlive
-	server.ConnectTimeout = time.Duration(params.Timeout) * time.Second
-	server.SendTimeout = time.Duration(params.Timeout) * time.Second
-	server.TLSConfig = &tls.Config{InsecureSkipVerify: params.useTLS}
+func newSMTP(params smtpParams) MailerClient {
+	timeout := time.Duration(params.Timeout) * time.Second
+	// The session tickets are cached by server name, the reconnections resuming
+	// the TLS session instead of a full handshake.
+	conns := newConnTracker()
 
-	smtpClient, err := server.Connect()
+	m := &smtpMailer{
+		host:       params.Host,
+		port:       strconv.Itoa(getPort(params.Port)),
+		username:   params.Username,
+		password:   params.Password,
+		encryption: params.encryption,
+		tlsConfig:  conns.tlsConfig(&tls.Config{ServerName: params.Host, InsecureSkipVerify: params.useTLS}),
+		keepAlive:  params.KeepAlive,
+		timeout:    timeout,
+		dialer:     newDialer(params.dial, timeout),
+		conns:      conns,
+	}
+	session, err := m.connect()
 
 	if err != nil {
 		log.Fatal(err)
 	}
-	return &smtpMailer{smtpClient: smtpClient}
+	m.idle = append(m.idle, session)
+	return m
+}
+
+// connect opens a session, starting the TLS and authenticating as configured.
+func (m *smtpMailer) connect() (*smtpSession, error) {
+	start := time.Now()
+	ctx := context.Background()
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+	}
+	conn, err := m.dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.host, m.port))
+	if err != nil {
+		return nil, err
+	}
+	if m.encryption == mail.EncryptionSSLTLS || m.encryption == mail.EncryptionSSL {
+		conn = tls.Client(conn, m.tlsConfig)
+	}
+	session := &smtpSession{conn: conn}
+	session.extend(m.timeout)
+
+	if session.Client, err = smtp.NewClient(conn, m.host); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := m.handshake(session.Client); err != nil {
+		session.Close()
+		return nil, err
+	}
+	m.conns.opened(time.Since(start))
+	return session, nil
+}
+
+// handshake starts the TLS when the server offers it and authenticates with the
+// first mechanism advertised of PLAIN, LOGIN and CRAM-MD5, as go-simple-mail did.
+func (m *smtpMailer) handshake(client *smtp.Client) error {
+	if m.encryption == mail.EncryptionSTARTTLS || m.encryption == mail.EncryptionTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(m.tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+	if m.username == "" {
+		return nil
+	}
+	ok, mechanisms := client.Extension("AUTH")
+	if !ok {
+		return nil
+	}
+	var auth smtp.Auth
+	switch {
+	case strings.Contains(mechanisms, "PLAIN"):
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	case strings.Contains(mechanisms, "LOGIN"):
+		auth = &loginAuth{username: m.username, password: m.password, host: m.host}
+	case strings.Contains(mechanisms, "CRAM-MD5"):
+		auth = smtp.CRAMMD5Auth(m.username, m.password)
+	default:
+		return fmt.Errorf("unsupported SMTP authentication mechanisms %s", mechanisms)
+	}
+	return client.Auth(auth)
+}
+
+// extend sets the deadline of the next exchanges, none without a timeout.
+func (s *smtpSession) extend(timeout time.Duration) {
+	if timeout > 0 {
+		s.conn.SetDeadline(time.Now().Add(timeout))
+	} else {
+		s.conn.SetDeadline(time.Time{})
+	}
+}
+
+// session takes an idle session for a send, or opens a new one when every
+// session is busy or they were closed.
+func (m *smtpMailer) session() (*smtpSession, error) {
+	m.mu.Lock()
+	var session *smtpSession
+	if n := len(m.idle); n > 0 {
+		session = m.idle[n-1]
+		m.idle = m.idle[:n-1]
+	}
+	m.mu.Unlock()
+
+	if session == nil {
+		var err error
+		if session, err = m.connect(); err != nil {
+			return nil, err
+		}
+	}
+	if session.used {
+		m.conns.reused()
+	}
+	session.used = true
+	session.extend(m.timeout)
+	return session, nil
+}
+
+// release returns the session to the idle ones after the send when it is kept
+// alive, or closes it. A broken session is closed for the next send to
+// reconnect. A rejected one is reset first, being only reused once the server
+// confirmed that no transaction is left open.
+func (m *smtpMailer) release(session *smtpSession, err error) {
+	var protoErr *textproto.Error
+	if !m.keepAlive || err != nil && !errors.As(err, &protoErr) {
+		session.Close()
+		return
+	}
+	if err != nil {
+		if resetErr := session.Reset(); resetErr != nil {
+			session.Close()
+			return
+		}
+	}
+	m.mu.Lock()
+	m.idle = append(m.idle, session)
+	m.mu.Unlock()
 }
 
 func (m *smtpMailer) Send(msg Mail) error {
-	_, email := buildMessage(msg)
+	return m.sendEnvelopeChunks(msg, m.maxRecipients())
+}
+
+// sendEnvelopeChunks sends the message in transactions of at most limit
+// recipients, each one carrying the same headers.
+func (m *smtpMailer) sendEnvelopeChunks(msg Mail, limit int) error {
+	message, email := buildMessage(msg)
 
 	if email.Error != nil {
 		return email.Error
 	}
 
-	err := email.Send(m.smtpClient)
+	rcpts := email.GetRecipients()
+	if len(msg.EnvelopeTo) > 0 {
+		rcpts = msg.EnvelopeTo
+	}
+	return m.send(email.GetFrom(), rcpts, message, msg.DeliveryNotification, limit)
+}
+
+// SendRaw sends the message as-is over a session of the mailer.
+func (m *smtpMailer) SendRaw(ctx context.Context, from string, rcpts []string, message []byte) error {
+	return m.send(from, rcpts, string(message), nil, m.maxRecipients())
+}
+
+func (m *smtpMailer) send(from string, rcpts []string, message string, dsn *DeliveryNotification, limit int) error {
+	if len(rcpts) == 0 {
+		return errors.New("no recipient specified")
+	}
+	if limit <= 0 {
+		limit = len(rcpts)
+	}
+	n := (len(rcpts) + limit - 1) / limit
+	return eachChunk(n, func(i int) error {
+		return m.transaction(from, rcpts[i*limit:min((i+1)*limit, len(rcpts))], message, dsn)
+	})
+}
+
+func (m *smtpMailer) transaction(from string, rcpts []string, message string, dsn *DeliveryNotification) error {
+	session, err := m.session()
 	if err != nil {
 		return err
 	}
-	return nil
+	err = sendTransaction(session.Client, from, rcpts, message, dsn)
+	m.release(session, err)
+	return err
+}
+
+func (m *smtpMailer) ConnectionStats() ConnectionStats {
+	return m.conns.snapshot()
+}
+
+// Health opens a new connection to verify that the server accepts the EHLO,
+// the TLS handshake and the authentication.
+func (m *smtpMailer) Health(ctx context.Context) error {
+	session, err := m.connect()
+	if err != nil {
+		return err
+	}
+	return session.Quit()
 }
 
 func (m *smtpMailer) Close() {
-	m.smtpClient.Close()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, session := range m.idle {
+		session.Close()
+	}
+	m.idle = nil
+}
+
+// loginAuth implements the LOGIN authentication, which net/smtp does not
+// support, only sending the credentials over TLS or to localhost as PlainAuth.
+type loginAuth struct {
+	username, password, host string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && a.host != "localhost" && a.host != "127.0.0.1" && a.host != "::1" {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
 }
@@ -0,0 +1,146 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const smtp2goBaseURL = "https://api.smtp2go.com"
+
+// SMTP2GOOptions holds the SMTP2GO specific options of an email.
+type SMTP2GOOptions struct {
+	// TemplateID is the id of an SMTP2GO template. When set, the template stored
+	// in SMTP2GO is rendered instead of the Subject, Html and Text of the email.
+	TemplateID string
+	// TemplateData are the values of the template.
+	TemplateData map[string]any
+}
+
+type smtp2goParams struct {
+	apiKey     string
+	userAgent  string
+	httpClient *http.Client
+}
+
+type smtp2goMailer struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	userAgent  string
+}
+
+type smtp2goHeader struct {
+	Header string `json:"header"`
+	Value  string `json:"value"`
+}
+
+type smtp2goAttachment struct {
+	Filename string `json:"filename"`
+	Fileblob string `json:"fileblob"`
+	Mimetype string `json:"mimetype"`
+}
+
+type smtp2goEmail struct {
+	Sender        string              `json:"sender"`
+	To            []string            `json:"to"`
+	Cc            []string            `json:"cc,omitempty"`
+	Bcc           []string            `json:"bcc,omitempty"`
+	Subject       string              `json:"subject,omitempty"`
+	HtmlBody      string              `json:"html_body,omitempty"`
+	TextBody      string              `json:"text_body,omitempty"`
+	CustomHeaders []smtp2goHeader     `json:"custom_headers,omitempty"`
+	Attachments   []smtp2goAttachment `json:"attachments,omitempty"`
+	TemplateID    string              `json:"template_id,omitempty"`
+	TemplateData  map[string]any      `json:"template_data,omitempty"`
+}
+
+func newSMTP2GO(params smtp2goParams) MailerClient {
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+
+	return &smtp2goMailer{
+		httpClient: params.httpClient,
+		baseURL:    smtp2goBaseURL,
+		apiKey:     params.apiKey,
+		userAgent:  params.userAgent,
+	}
+}
+
+func (m *smtp2goMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *smtp2goMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	payload := smtp2goEmail{
+		Sender:   msg.From.String(),
+		To:       formatAddresses(msg.To),
+		Cc:       formatAddresses(msg.Cc),
+		Bcc:      formatAddresses(msg.Bcc),
+		Subject:  msg.Subject,
+		HtmlBody: msg.Html,
+		TextBody: msg.Text,
+	}
+	if msg.ReplyTo.Email != "" {
+		msg = withHeader(msg, "Reply-To", msg.ReplyTo.String())
+	}
+	for _, key := range headerKeys(msg.Headers) {
+		payload.CustomHeaders = append(payload.CustomHeaders, smtp2goHeader{Header: key, Value: msg.Headers[key]})
+	}
+	if options := msg.SMTP2GO; options != nil && options.TemplateID != "" {
+		payload.Subject, payload.HtmlBody, payload.TextBody = "", "", ""
+		payload.TemplateID = options.TemplateID
+		payload.TemplateData = options.TemplateData
+	}
+
+	for _, attachment := range msg.Attachments {
+		content, err := encodeAttachmentBase64(attachment)
+		if err != nil {
+			return nil, err
+		}
+		payload.Attachments = append(payload.Attachments, smtp2goAttachment{
+			Filename: attachment.Name,
+			Fileblob: content,
+			Mimetype: getAttachmentContentType(attachment),
+		})
+	}
+
+	// SMTP2GO answers 200 when some recipients were refused, listing them.
+	var result struct {
+		Data struct {
+			Succeeded int      `json:"succeeded"`
+			Failed    int      `json:"failed"`
+			Failures  []string `json:"failures"`
+		} `json:"data"`
+	}
+	response, err := m.doWithResponse(context.Background(), http.MethodPost, "/v3/email/send", payload, &result)
+	if err == nil && result.Data.Failed > 0 && result.Data.Succeeded == 0 {
+		err = fmt.Errorf("smtp2go refused the email: %s", strings.Join(result.Data.Failures, "; "))
+	}
+	return response, err
+}
+
+func (m *smtp2goMailer) doWithResponse(ctx context.Context, method string, path string, payload any, out any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(method, m.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Smtp2go-Api-Key", m.apiKey)
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, SMTP2GO, req.WithContext(ctx), out)
+}
+
+// Health verifies the API key by fetching the sending statistics, the only
+// read-only endpoint every key may call.
+func (m *smtp2goMailer) Health(ctx context.Context) error {
+	_, err := m.doWithResponse(ctx, http.MethodPost, "/v3/stats/email_summary", struct{}{}, nil)
+	return err
+}
+
+func (m *smtp2goMailer) Close() {
+	// Not implemented because the SMTP2GO API is stateless.
+}
@@ -0,0 +1,78 @@
+package mailer
+
+import (
+	"testing"
+)
+
+func TestSMTP2GO_Send(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	smtp2go := newSMTP2GO(smtp2goParams{apiKey: MailAPIKey}).(*smtp2goMailer)
+	smtp2go.baseURL = server.URL
+
+	err := smtp2go.Send(Mail{
+		From:        Address{Name: "Acme", Email: "info@test.com"},
+		To:          Addresses("a@test.com", "b@test.com"),
+		ReplyTo:     Address{Email: "support@test.com"},
+		Subject:     "test",
+		Html:        "<p>test</p>",
+		Attachments: []Attachment{{Name: "hello.txt", Content: []byte("hello")}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := requests[0]
+	if req.Path != "/v3/email/send" || req.Header.Get("X-Smtp2go-Api-Key") != MailAPIKey {
+		t.Fatalf("Expected an authenticated request to /v3/email/send, got %s %v", req.Path, req.Header)
+	}
+	if req.Body["sender"] != `"Acme" <info@test.com>` || len(req.Body["to"].([]any)) != 2 {
+		t.Errorf("Expected the sender and 2 recipients, got %v", req.Body)
+	}
+	headers := req.Body["custom_headers"].([]any)
+	if header := headers[0].(map[string]any); header["header"] != "Reply-To" || header["value"] != "support@test.com" {
+		t.Errorf("Expected the Reply-To header, got %v", headers)
+	}
+	attachment := req.Body["attachments"].([]any)[0].(map[string]any)
+	if attachment["filename"] != "hello.txt" || attachment["fileblob"] != "aGVsbG8=" {
+		t.Errorf("Expected the attachment, got %v", attachment)
+	}
+}
+
+func TestSMTP2GO_Template(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	smtp2go := newSMTP2GO(smtp2goParams{apiKey: MailAPIKey}).(*smtp2goMailer)
+	smtp2go.baseURL = server.URL
+
+	err := smtp2go.Send(Mail{
+		From:    Address{Email: "info@test.com"},
+		To:      Addresses("a@test.com"),
+		Subject: "ignored",
+		SMTP2GO: &SMTP2GOOptions{TemplateID: "1234", TemplateData: map[string]any{"name": "Ada"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	body := requests[0].Body
+	if body["template_id"] != "1234" || body["template_data"].(map[string]any)["name"] != "Ada" {
+		t.Errorf("Expected the template, got %v", body)
+	}
+	if _, ok := body["subject"]; ok {
+		t.Errorf("Expected no subject with a template, got %v", body["subject"])
+	}
+}
+
+func TestSMTP2GO_Failures(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, map[string]string{
+		"POST /v3/email/send": `{"data":{"succeeded":0,"failed":1,"failures":["a@test.com"]}}`,
+	})
+	smtp2go := newSMTP2GO(smtp2goParams{apiKey: MailAPIKey}).(*smtp2goMailer)
+	smtp2go.baseURL = server.URL
+
+	if err := smtp2go.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com")}); err == nil {
+		t.Error("Expected an error when every recipient failed")
+	}
+}
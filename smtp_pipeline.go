@@ -0,0 +1,130 @@
+package mailer
+
+import (
+	"errors"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// envelope is the MAIL FROM and RCPT TO commands of an SMTP or LMTP transaction.
+type envelope struct {
+	from  string
+	rcpts []string
+	// mailParams and rcptParams are the extension parameters of the commands,
+	// each with its leading space, e.g. " RET=HDRS".
+	mailParams string
+	rcptParams string
+}
+
+// sendEnvelope sends the MAIL FROM, RCPT TO and DATA commands and returns the
+// error of the first one rejected. When the server advertises PIPELINING (RFC
+// 2920) the MAIL FROM and RCPT TO commands are written at once and their
+// replies read afterwards, a transaction costing one round trip instead of one
+// per recipient. DATA is only sent once every recipient is accepted, a
+// rejection resetting the transaction instead, so that the message is never
+// delivered to a part of the recipients. The session is ready for the message
+// on success, and for the next transaction on a rejection.
+func sendEnvelope(text *textproto.Conn, env envelope, pipelining bool) error {
+	commands := []smtpCommand{{250, "MAIL FROM:<" + env.from + ">" + env.mailParams}}
+	for _, rcpt := range env.rcpts {
+		// A recipient is accepted with either a 250 or a 251.
+		commands = append(commands, smtpCommand{25, "RCPT TO:<" + rcpt + ">" + env.rcptParams})
+	}
+
+	var err error
+	if pipelining {
+		err = pipelineCmds(text, commands)
+	} else {
+		for _, command := range commands {
+			if err = smtpCmd(text, command.code, command.line); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		if resetErr := smtpCmd(text, 250, "RSET"); resetErr != nil {
+			return errors.Join(err, resetErr)
+		}
+		return err
+	}
+	return smtpCmd(text, 354, "DATA")
+}
+
+// sendTransaction sends the message through the session of an SMTP client, its
+// envelope pipelined when the server advertises PIPELINING.
+func sendTransaction(client *smtp.Client, from string, rcpts []string, message string, dsn *DeliveryNotification) error {
+	env := envelope{from: from, rcpts: rcpts}
+	if ok, _ := client.Extension("8BITMIME"); ok {
+		env.mailParams += " BODY=8BITMIME"
+	}
+	if ok, _ := client.Extension("SMTPUTF8"); ok {
+		env.mailParams += " SMTPUTF8"
+	}
+	if ok, _ := client.Extension("SIZE"); ok {
+		env.mailParams += " SIZE=" + strconv.Itoa(len(message))
+	}
+	// net/smtp does not support the DSN parameters.
+	if ok, _ := client.Extension("DSN"); ok && dsn != nil {
+		env.mailParams += " RET=" + dsn.ret()
+		env.rcptParams += " NOTIFY=" + strings.Join(dsn.notify(), ",")
+	}
+	pipelining, _ := client.Extension("PIPELINING")
+	if err := sendEnvelope(client.Text, env, pipelining); err != nil {
+		return err
+	}
+
+	w := client.Text.DotWriter()
+	if _, err := w.Write([]byte(message)); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	_, _, err := client.Text.ReadResponse(250)
+	return err
+}
+
+// pipelineCmds writes the commands at once then reads every reply, even after
+// a rejection to keep the session in sync, and returns the first error.
+func pipelineCmds(text *textproto.Conn, commands []smtpCommand) error {
+	ids := make([]uint, len(commands))
+	for i, command := range commands {
+		id, err := text.Cmd("%s", command.line)
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	var first error
+	for i, command := range commands {
+		text.StartResponse(ids[i])
+		_, _, err := text.ReadResponse(command.code)
+		text.EndResponse(ids[i])
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+type smtpCommand struct {
+	code int
+	line string
+}
+
+// smtpCmd sends the command and reads its reply, any code being accepted when
+// expectCode is 0.
+func smtpCmd(text *textproto.Conn, expectCode int, line string) error {
+	id, err := text.Cmd("%s", line)
+	if err != nil {
+		return err
+	}
+	text.StartResponse(id)
+	defer text.EndResponse(id)
+	_, _, err = text.ReadResponse(expectCode)
+	return err
+}
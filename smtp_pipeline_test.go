@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"bufio"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// servePipelined answers the MAIL and RCPT commands only once the whole
+// envelope of n commands is received, which blocks a client waiting for each
+// reply before the next command.
+func servePipelined(t *testing.T, conn net.Conn, n int, reject map[string]bool) <-chan []string {
+	t.Helper()
+	received := make(chan []string, 1)
+	go func() {
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		write := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var lines, replies []string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				received <- lines
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			lines = append(lines, line)
+
+			switch {
+			case strings.HasPrefix(line, "MAIL FROM:"):
+				replies = append(replies, "250 ok")
+			case strings.HasPrefix(line, "RCPT TO:"):
+				if reject[strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>")] {
+					replies = append(replies, "550 no such user")
+				} else {
+					replies = append(replies, "250 ok")
+				}
+			case line == "DATA":
+				write("354 go ahead")
+			default:
+				write("250 ok")
+			}
+			if len(replies) == n {
+				for _, reply := range replies {
+					write(reply)
+				}
+				replies = nil
+			}
+		}
+	}()
+	return received
+}
+
+func TestSendEnvelope_Pipelining(t *testing.T) {
+	testCases := []struct {
+		name     string
+		reject   map[string]bool
+		expected int
+		commands []string
+	}{
+		{
+			name:     "Should send the envelope in a single round trip",
+			commands: []string{"MAIL FROM:<info@test.com>", "RCPT TO:<a@test.com>", "RCPT TO:<b@test.com>", "DATA"},
+		},
+		{
+			name:     "Should reset the transaction instead of sending the data to a part of the recipients",
+			reject:   map[string]bool{"b@test.com": true},
+			expected: 550,
+			commands: []string{"MAIL FROM:<info@test.com>", "RCPT TO:<a@test.com>", "RCPT TO:<b@test.com>", "RSET"},
+		},
+		{
+			name:     "Should reset the transaction when every recipient is rejected",
+			reject:   map[string]bool{"a@test.com": true, "b@test.com": true},
+			expected: 550,
+			commands: []string{"MAIL FROM:<info@test.com>", "RCPT TO:<a@test.com>", "RCPT TO:<b@test.com>", "RSET"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			received := servePipelined(t, server, 3, tc.reject)
+			text := textproto.NewConn(client)
+
+			err := sendEnvelope(text, envelope{from: "info@test.com", rcpts: []string{"a@test.com", "b@test.com"}}, true)
+			switch {
+			case tc.expected == 0 && err != nil:
+				t.Fatalf("Expected no error, got %v", err)
+			case tc.expected != 0:
+				protoErr, ok := err.(*textproto.Error)
+				if !ok || protoErr.Code != tc.expected {
+					t.Fatalf("Expected a %d reply, got %v", tc.expected, err)
+				}
+				// Every reply was read, the session is still in sync.
+				if err := smtpCmd(text, 250, "NOOP"); err != nil {
+					t.Fatalf("Expected the session to be usable, got %v", err)
+				}
+			}
+			text.Close()
+
+			commands := <-received
+			if tc.expected != 0 {
+				commands = commands[:len(commands)-1]
+			}
+			if strings.Join(commands, "\n") != strings.Join(tc.commands, "\n") {
+				t.Errorf("Expected %v, got %v", tc.commands, commands)
+			}
+		})
+	}
+}
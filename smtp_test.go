@@ -0,0 +1,198 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	mail "github.com/xhit/go-simple-mail/v2"
+)
+
+func TestSMTP_ConcurrentSendsHoldTheirSession(t *testing.T) {
+	ln, sessions := serveSMTP(t, nil)
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	m := newSMTP(smtpParams{Host: host, Port: port, KeepAlive: true, Timeout: 2, encryption: mail.EncryptionNone}).(*smtpMailer)
+	defer m.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.SendRaw(context.Background(), "info@test.com", []string{"a@test.com"}, []byte("Subject: test\r\n\r\ntest\r\n")); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every session opened is kept alive for the next sends.
+	if n := atomic.LoadInt32(sessions); int(n) != len(m.idle) {
+		t.Errorf("Expected the %d sessions to be idle, got %d", n, len(m.idle))
+	}
+	stats := m.ConnectionStats()
+	if stats.Reused != 8-stats.Opened {
+		t.Errorf("Unexpected connection stats %+v", stats)
+	}
+}
+
+// servePipeliningSMTP advertises PIPELINING and withholds the replies to the
+// MAIL and RCPT commands until the n commands of an envelope are received,
+// which blocks a client waiting for each reply before the next command.
+func servePipeliningSMTP(t *testing.T, n int, reject map[string]bool) (net.Listener, func() []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	var mu sync.Mutex
+	var lines []string
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				write := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+				write("220 localhost ESMTP ready")
+				var replies []string
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					line = strings.TrimRight(line, "\r\n")
+					mu.Lock()
+					lines = append(lines, line)
+					mu.Unlock()
+
+					switch {
+					case strings.HasPrefix(line, "EHLO"):
+						write("250-localhost")
+						write("250 PIPELINING")
+					case strings.HasPrefix(line, "MAIL FROM:"):
+						replies = append(replies, "250 ok")
+					case strings.HasPrefix(line, "RCPT TO:"):
+						if reject[strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>")] {
+							replies = append(replies, "550 no such user")
+						} else {
+							replies = append(replies, "250 ok")
+						}
+					case line == "DATA":
+						write("354 go ahead")
+						for {
+							data, err := r.ReadString('\n')
+							if err != nil || data == ".\r\n" {
+								break
+							}
+							mu.Lock()
+							lines = append(lines, strings.TrimRight(data, "\r\n"))
+							mu.Unlock()
+						}
+						write("250 queued")
+					default:
+						write("250 ok")
+					}
+					if len(replies) == n {
+						for _, reply := range replies {
+							write(reply)
+						}
+						replies = nil
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return ln, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lines...)
+	}
+}
+
+func TestSMTP_Pipelining(t *testing.T) {
+	ln, received := servePipeliningSMTP(t, 3, map[string]bool{"rejected@test.com": true})
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	m := newSMTP(smtpParams{Host: host, Port: port, KeepAlive: true, Timeout: 2, encryption: mail.EncryptionNone}).(*smtpMailer)
+	defer m.Close()
+
+	err := m.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Cc: Addresses("rejected@test.com"), Subject: "test", Text: "test"})
+	var protoErr *textproto.Error
+	if !errors.As(err, &protoErr) || protoErr.Code != 550 {
+		t.Fatalf("Expected the 550 rejection, got %v", err)
+	}
+	// The envelope would block on a client waiting for each reply.
+	if err := m.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Cc: Addresses("b@test.com"), Subject: "test", Text: "test"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var commands []string
+	for _, line := range received() {
+		if !strings.HasPrefix(line, "EHLO") {
+			commands = append(commands, line)
+		}
+		if line == "DATA" {
+			break
+		}
+	}
+	expected := []string{
+		"MAIL FROM:<info@test.com>", "RCPT TO:<a@test.com>", "RCPT TO:<rejected@test.com>", "RSET",
+		// The rejected session is reset once more before being reused.
+		"RSET",
+		"MAIL FROM:<info@test.com>", "RCPT TO:<a@test.com>", "RCPT TO:<b@test.com>", "DATA",
+	}
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %v, got %v", expected, commands)
+	}
+	if stats := m.ConnectionStats(); stats.Opened != 1 {
+		t.Errorf("Expected the session to be reused, got %+v", stats)
+	}
+}
+
+func TestSMTP_EnvelopeChunks(t *testing.T) {
+	ln, received := servePipeliningSMTP(t, 3, nil)
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	client := newSMTP(smtpParams{Host: host, Port: port, KeepAlive: true, Timeout: 2, encryption: mail.EncryptionNone})
+	mailer := NewMailer(MailCfg{MaxRecipients: 2, mailerClient: client})
+	defer mailer.Close()
+
+	if err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com", "b@test.com"), Cc: Addresses("c@test.com", "d@test.com"), Subject: "test", Text: "test"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var rcpts, to, cc []string
+	for _, line := range received() {
+		switch {
+		case strings.HasPrefix(line, "RCPT TO:"):
+			rcpts = append(rcpts, line)
+		case strings.HasPrefix(line, "To:"):
+			to = append(to, line)
+		case strings.HasPrefix(line, "Cc:"):
+			cc = append(cc, line)
+		}
+	}
+	expected := []string{"RCPT TO:<a@test.com>", "RCPT TO:<b@test.com>", "RCPT TO:<c@test.com>", "RCPT TO:<d@test.com>"}
+	if strings.Join(rcpts, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected the envelope split in two transactions, got %v", rcpts)
+	}
+	// Each transaction carries the same headers, listing every recipient.
+	if len(to) != 2 || to[0] != to[1] || !strings.Contains(to[0], "a@test.com") || !strings.Contains(to[0], "b@test.com") {
+		t.Errorf("Expected the whole To list in both transactions, got %v", to)
+	}
+	if len(cc) != 2 || cc[0] != cc[1] || !strings.Contains(cc[0], "c@test.com") || !strings.Contains(cc[0], "d@test.com") {
+		t.Errorf("Expected the whole Cc list in both transactions, got %v", cc)
+	}
+}
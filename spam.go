@@ -0,0 +1,234 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSpamScoreExceeded is returned when an email is blocked by the spam check.
+var ErrSpamScoreExceeded = errors.New("spam score exceeds the threshold")
+
+// SpamReport is the result of scoring an email.
+type SpamReport struct {
+	// Score is the spam score, higher is spammier.
+	Score float64
+	// Rules are the names of the rules that matched.
+	Rules []string
+}
+
+// SpamScorer scores rendered emails before they are sent.
+type SpamScorer interface {
+	Score(ctx context.Context, msg Mail) (SpamReport, error)
+}
+
+// SpamAction is what the spam check does with emails scoring above the threshold.
+type SpamAction int
+
+const (
+	// SpamWarn logs the email and sends it.
+	SpamWarn SpamAction = iota
+	// SpamTag prefixes the subject and sends the email.
+	SpamTag
+	// SpamBlock rejects the email with ErrSpamScoreExceeded.
+	SpamBlock
+)
+
+// SpamCheck configures SpamCheckMiddleware.
+type SpamCheck struct {
+	// Scorer scores the emails, e.g. a SpamAssassinScorer or a HeuristicSpamScorer.
+	Scorer SpamScorer
+	// Threshold is the score from which the action is taken. Defaults to 5, as SpamAssassin.
+	Threshold float64
+	// Action is the action taken above the threshold.
+	Action SpamAction
+	// TagPrefix is prepended to the subject by SpamTag. Defaults to "[SPAM] ".
+	TagPrefix string
+}
+
+// SpamCheckMiddleware returns a Middleware scoring every email and taking the
+// configured action above the threshold. Scorer failures are logged and the
+// email is sent, so that an unavailable spamd does not stop outgoing mail.
+func SpamCheckMiddleware(check SpamCheck) Middleware {
+	if check.Threshold == 0 {
+		check.Threshold = 5
+	}
+	if check.TagPrefix == "" {
+		check.TagPrefix = "[SPAM] "
+	}
+
+	return func(next SendFunc) SendFunc {
+		return func(msg Mail) error {
+			report, err := check.Scorer.Score(context.Background(), msg)
+			if err != nil {
+				log.Printf("mailer: spam check failed: %v", err)
+				return next(msg)
+			}
+			if report.Score < check.Threshold {
+				return next(msg)
+			}
+
+			switch check.Action {
+			case SpamBlock:
+				return fmt.Errorf("%w: %.1f >= %.1f (%s)", ErrSpamScoreExceeded, report.Score, check.Threshold, strings.Join(report.Rules, ","))
+			case SpamTag:
+				msg.Subject = check.TagPrefix + msg.Subject
+			default:
+				log.Printf("mailer: email %q to %s scored %.1f (%s)", msg.Subject, msg.To, report.Score, strings.Join(report.Rules, ","))
+			}
+			return next(msg)
+		}
+	}
+}
+
+// SpamAssassinScorer scores emails with a spamd daemon, using the spamc protocol.
+type SpamAssassinScorer struct {
+	// Address is the address of spamd. Defaults to localhost:783.
+	Address string
+	// Timeout bounds the whole exchange with spamd. Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+func (s SpamAssassinScorer) Score(ctx context.Context, msg Mail) (SpamReport, error) {
+	address := s.Address
+	if address == "" {
+		address = "localhost:783"
+	}
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	message, _ := buildMessage(msg)
+
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return SpamReport{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	_, err = fmt.Fprintf(conn, "SYMBOLS SPAMC/1.5\r\nContent-length: %d\r\n\r\n%s", len(message), message)
+	if err != nil {
+		return SpamReport{}, err
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.CloseWrite()
+	}
+
+	return parseSpamdResponse(bufio.NewReader(conn))
+}
+
+// parseSpamdResponse parses a SYMBOLS response e.g.
+//
+//	SPAMD/1.1 0 EX_OK
+//	Spam: True ; 15.3 / 5.0
+//
+//	RULE_A,RULE_B
+func parseSpamdResponse(r *bufio.Reader) (SpamReport, error) {
+	text := textproto.NewReader(r)
+
+	status, err := text.ReadLine()
+	if err != nil {
+		return SpamReport{}, err
+	}
+	fields := strings.Fields(status)
+	if len(fields) < 3 || !strings.HasPrefix(fields[0], "SPAMD/") || fields[1] != "0" {
+		return SpamReport{}, fmt.Errorf("spamd error: %s", status)
+	}
+
+	headers, err := text.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return SpamReport{}, err
+	}
+
+	// Spam: True ; 15.3 / 5.0
+	spam := headers.Get("Spam")
+	parts := strings.SplitN(spam, ";", 2)
+	if len(parts) != 2 {
+		return SpamReport{}, fmt.Errorf("invalid spamd Spam header %q", spam)
+	}
+	scores := strings.SplitN(parts[1], "/", 2)
+	score, err := strconv.ParseFloat(strings.TrimSpace(scores[0]), 64)
+	if err != nil {
+		return SpamReport{}, fmt.Errorf("invalid spamd score %q", spam)
+	}
+
+	report := SpamReport{Score: score}
+	body, _ := io.ReadAll(r)
+	for _, rule := range strings.Split(strings.TrimSpace(string(body)), ",") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			report.Rules = append(report.Rules, rule)
+		}
+	}
+	return report, nil
+}
+
+var (
+	spamPhrases = []string{
+		"100% free", "act now", "buy now", "cash bonus", "click here", "double your",
+		"earn money", "free money", "guaranteed", "limited time", "no credit check",
+		"risk free", "winner", "you have been selected",
+	}
+	htmlLinkPattern = regexp.MustCompile(`(?i)<a\s[^>]*href`)
+	htmlTagPattern  = regexp.MustCompile(`<[^>]*>`)
+)
+
+// HeuristicSpamScorer scores emails with a few rules inspired by SpamAssassin,
+// for environments without spamd.
+type HeuristicSpamScorer struct{}
+
+func (HeuristicSpamScorer) Score(ctx context.Context, msg Mail) (SpamReport, error) {
+	var report SpamReport
+	add := func(rule string, score float64) {
+		report.Rules = append(report.Rules, rule)
+		report.Score += score
+	}
+
+	subject := strings.TrimSpace(msg.Subject)
+	if subject == "" {
+		add("MISSING_SUBJECT", 1.5)
+	}
+	if letters := strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' {
+			return r
+		}
+		return -1
+	}, subject); len(letters) >= 8 && letters == strings.ToUpper(letters) {
+		add("SUBJ_ALL_CAPS", 1.5)
+	}
+	if strings.Count(subject, "!") >= 3 {
+		add("SUBJ_EXCESS_EXCLAMATION", 1)
+	}
+
+	if msg.Html != "" && msg.Text == "" {
+		add("MIME_HTML_ONLY", 1)
+	}
+
+	content := strings.ToLower(subject + " " + msg.Text + " " + htmlTagPattern.ReplaceAllString(msg.Html, " "))
+	for _, phrase := range spamPhrases {
+		if strings.Contains(content, phrase) {
+			add("PHRASE_"+strings.ToUpper(strings.NewReplacer(" ", "_", "%", "").Replace(phrase)), 1)
+		}
+	}
+
+	if msg.Html != "" {
+		links := len(htmlLinkPattern.FindAllString(msg.Html, -1))
+		words := len(strings.Fields(htmlTagPattern.ReplaceAllString(msg.Html, " ")))
+		if links > 0 && words/links < 10 {
+			add("HTML_LINK_DENSITY", 1)
+		}
+	}
+
+	return report, nil
+}
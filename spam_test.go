@@ -0,0 +1,139 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSpamCheckMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		score         float64
+		scoreErr      error
+		action        SpamAction
+		expectErr     error
+		expectSubject string
+	}{
+		{name: "Should send emails below the threshold", score: 1, action: SpamBlock, expectSubject: "Hello"},
+		{name: "Should block emails above the threshold", score: 8, action: SpamBlock, expectErr: ErrSpamScoreExceeded},
+		{name: "Should tag emails above the threshold", score: 8, action: SpamTag, expectSubject: "[SPAM] Hello"},
+		{name: "Should warn about emails above the threshold", score: 8, action: SpamWarn, expectSubject: "Hello"},
+		{name: "Should send emails when the scorer fails", scoreErr: errors.New("spamd down"), action: SpamBlock, expectSubject: "Hello"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scorer := mockSpamScorer{report: SpamReport{Score: tc.score, Rules: []string{"TEST"}}, err: tc.scoreErr}
+
+			var sent *Mail
+			send := SpamCheckMiddleware(SpamCheck{Scorer: scorer, Action: tc.action})(func(msg Mail) error {
+				sent = &msg
+				return nil
+			})
+
+			err := send(Mail{To: Addresses("a@test.com"), Subject: "Hello"})
+			if !errors.Is(err, tc.expectErr) {
+				t.Fatalf("Expected %v, got %v", tc.expectErr, err)
+			}
+			if tc.expectErr != nil {
+				if sent != nil {
+					t.Errorf("Expected the email not to be sent")
+				}
+				return
+			}
+			if sent == nil || sent.Subject != tc.expectSubject {
+				t.Errorf("Expected subject %q, got %+v", tc.expectSubject, sent)
+			}
+		})
+	}
+}
+
+func TestHeuristicSpamScorer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		msg      Mail
+		minScore float64
+		maxScore float64
+	}{
+		{
+			name:     "clean transactional email",
+			msg:      Mail{Subject: "Your invoice for May", Text: "Please find your invoice attached.", Html: "<p>Please find your invoice attached.</p>"},
+			maxScore: 0,
+		},
+		{
+			name:     "spammy email",
+			msg:      Mail{Subject: "YOU ARE A WINNER!!!", Html: `<a href="x">Click here</a> for free money`},
+			minScore: 5,
+			maxScore: 100,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := HeuristicSpamScorer{}.Score(context.Background(), tc.msg)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if report.Score < tc.minScore || report.Score > tc.maxScore {
+				t.Errorf("Expected score in [%.1f, %.1f], got %.1f (%v)", tc.minScore, tc.maxScore, report.Score, report.Rules)
+			}
+		})
+	}
+}
+
+func TestSpamAssassinScorer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := textproto.NewReader(bufio.NewReader(conn))
+		command, _ := r.ReadLine()
+		headers, _ := r.ReadMIMEHeader()
+		length, _ := strconv.Atoi(headers.Get("Content-Length"))
+		io.CopyN(io.Discard, r.R, int64(length))
+
+		if !strings.HasPrefix(command, "SYMBOLS SPAMC/") {
+			conn.Write([]byte("SPAMD/1.5 76 EX_PROTOCOL\r\n\r\n"))
+			return
+		}
+		conn.Write([]byte("SPAMD/1.1 0 EX_OK\r\nContent-length: 23\r\nSpam: True ; 15.3 / 5.0\r\n\r\nFREE_MONEY,SUBJ_ALL_CAPS"))
+	}()
+
+	report, err := SpamAssassinScorer{Address: ln.Addr().String()}.Score(context.Background(), Mail{
+		From:    Address{Email: "info@test.com"},
+		To:      Addresses("a@test.com"),
+		Subject: "FREE MONEY",
+		Text:    "test",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Score != 15.3 || len(report.Rules) != 2 || report.Rules[0] != "FREE_MONEY" {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+type mockSpamScorer struct {
+	report SpamReport
+	err    error
+}
+
+func (m mockSpamScorer) Score(ctx context.Context, msg Mail) (SpamReport, error) {
+	return m.report, m.err
+}
@@ -0,0 +1,208 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const (
+	sparkpostBaseURL   = "https://api.sparkpost.com"
+	sparkpostEUBaseURL = "https://api.eu.sparkpost.com"
+)
+
+// SparkPostRegion is the region of a SparkPost account.
+type SparkPostRegion string
+
+const (
+	SparkPostRegionUS SparkPostRegion = "us"
+	SparkPostRegionEU SparkPostRegion = "eu"
+)
+
+// SparkPostOptions holds the SparkPost specific options of an email.
+type SparkPostOptions struct {
+	// TemplateID is the id of a stored SparkPost template. When set, the template
+	// is rendered instead of the Subject, Html and Text of the email.
+	TemplateID string
+	// SubstitutionData are the values of the template shared by every recipient.
+	SubstitutionData map[string]any
+	// RecipientSubstitutionData are the values of the template of each recipient,
+	// keyed by email address, merged over SubstitutionData by SparkPost.
+	RecipientSubstitutionData map[string]map[string]any
+	// CampaignID groups the email with the others of a campaign in the reports.
+	CampaignID string
+	// Sandbox sends from the sandbox domain of the account, e.g. before the sending
+	// domain is verified.
+	Sandbox bool
+}
+
+type sparkpostParams struct {
+	apiKey     string
+	region     SparkPostRegion
+	userAgent  string
+	httpClient *http.Client
+}
+
+type sparkpostMailer struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	userAgent  string
+}
+
+type sparkpostAddress struct {
+	Email    string `json:"email"`
+	Name     string `json:"name,omitempty"`
+	HeaderTo string `json:"header_to,omitempty"`
+}
+
+type sparkpostRecipient struct {
+	Address          sparkpostAddress `json:"address"`
+	SubstitutionData map[string]any   `json:"substitution_data,omitempty"`
+	Tags             []string         `json:"tags,omitempty"`
+}
+
+type sparkpostAttachment struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+type sparkpostContent struct {
+	From        *sparkpostAddress     `json:"from,omitempty"`
+	Subject     string                `json:"subject,omitempty"`
+	Html        string                `json:"html,omitempty"`
+	Text        string                `json:"text,omitempty"`
+	AmpHtml     string                `json:"amp_html,omitempty"`
+	ReplyTo     string                `json:"reply_to,omitempty"`
+	Headers     map[string]string     `json:"headers,omitempty"`
+	Attachments []sparkpostAttachment `json:"attachments,omitempty"`
+	TemplateID  string                `json:"template_id,omitempty"`
+}
+
+type sparkpostTransmission struct {
+	Options *struct {
+		Sandbox bool `json:"sandbox"`
+	} `json:"options,omitempty"`
+	CampaignID       string               `json:"campaign_id,omitempty"`
+	Recipients       []sparkpostRecipient `json:"recipients"`
+	Content          sparkpostContent     `json:"content"`
+	SubstitutionData map[string]any       `json:"substitution_data,omitempty"`
+}
+
+func newSparkPost(params sparkpostParams) MailerClient {
+	baseURL := sparkpostBaseURL
+	if params.region == SparkPostRegionEU {
+		baseURL = sparkpostEUBaseURL
+	}
+	if params.httpClient == nil {
+		params.httpClient = http.DefaultClient
+	}
+
+	return &sparkpostMailer{
+		httpClient: params.httpClient,
+		baseURL:    baseURL,
+		apiKey:     params.apiKey,
+		userAgent:  params.userAgent,
+	}
+}
+
+func (m *sparkpostMailer) Send(msg Mail) error {
+	_, err := m.sendWithResponse(msg)
+	return err
+}
+
+func (m *sparkpostMailer) sendWithResponse(msg Mail) (*ProviderResponse, error) {
+	payload, err := m.buildTransmission(msg)
+	if err != nil {
+		return nil, err
+	}
+	return m.doWithResponse(context.Background(), http.MethodPost, "/api/v1/transmissions", payload, nil)
+}
+
+func (m *sparkpostMailer) buildTransmission(msg Mail) (*sparkpostTransmission, error) {
+	options := msg.SparkPost
+	if options == nil {
+		options = &SparkPostOptions{}
+	}
+
+	payload := &sparkpostTransmission{
+		CampaignID:       options.CampaignID,
+		SubstitutionData: options.SubstitutionData,
+		Content: sparkpostContent{
+			From:    &sparkpostAddress{Email: msg.From.Email, Name: msg.From.Name},
+			Subject: msg.Subject,
+			Html:    msg.Html,
+			Text:    msg.Text,
+			AmpHtml: msg.AmpHtml,
+			ReplyTo: msg.ReplyTo.String(),
+			Headers: msg.Headers,
+		},
+	}
+	if options.Sandbox {
+		payload.Options = &struct {
+			Sandbox bool `json:"sandbox"`
+		}{Sandbox: true}
+	}
+	if options.TemplateID != "" {
+		// The stored template holds the sender and the content.
+		payload.Content = sparkpostContent{TemplateID: options.TemplateID}
+	}
+
+	// SparkPost has no Cc nor Bcc, they are recipients whose header_to is the To
+	// recipients, the Cc ones being listed in the CC header.
+	headerTo := strings.Join(addressEmails(msg.To), ",")
+	add := func(recipients []Address, headerTo string) {
+		for _, addr := range recipients {
+			payload.Recipients = append(payload.Recipients, sparkpostRecipient{
+				Address:          sparkpostAddress{Email: addr.Email, Name: addr.Name, HeaderTo: headerTo},
+				SubstitutionData: options.RecipientSubstitutionData[addr.Email],
+				Tags:             msg.Tags,
+			})
+		}
+	}
+	add(msg.To, "")
+	add(msg.Cc, headerTo)
+	add(msg.Bcc, headerTo)
+	if len(msg.Cc) > 0 && options.TemplateID == "" {
+		payload.Content.Headers = withHeader(Mail{Headers: payload.Content.Headers}, "CC", joinAddresses(msg.Cc)).Headers
+	}
+
+	for _, attachment := range msg.Attachments {
+		content, err := encodeAttachmentBase64(attachment)
+		if err != nil {
+			return nil, err
+		}
+		payload.Content.Attachments = append(payload.Content.Attachments, sparkpostAttachment{
+			Name: attachment.Name,
+			Type: getAttachmentContentType(attachment),
+			Data: content,
+		})
+	}
+	return payload, nil
+}
+
+func (m *sparkpostMailer) do(ctx context.Context, method string, path string, payload any, out any) error {
+	_, err := m.doWithResponse(ctx, method, path, payload, out)
+	return err
+}
+
+func (m *sparkpostMailer) doWithResponse(ctx context.Context, method string, path string, payload any, out any) (*ProviderResponse, error) {
+	req, err := newJSONRequest(method, m.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", m.apiKey)
+	setUserAgent(req, m.userAgent)
+
+	return doJSONRequestWithResponse(m.httpClient, SPARKPOST, req.WithContext(ctx), out)
+}
+
+// Health verifies the API key by fetching the sending domains.
+func (m *sparkpostMailer) Health(ctx context.Context) error {
+	return m.do(ctx, http.MethodGet, "/api/v1/sending-domains", nil, nil)
+}
+
+func (m *sparkpostMailer) Close() {
+	// Not implemented because the SparkPost API is stateless.
+}
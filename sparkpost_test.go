@@ -0,0 +1,79 @@
+package mailer
+
+import (
+	"testing"
+)
+
+func TestSparkPost_Send(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	sparkpost := newSparkPost(sparkpostParams{apiKey: MailAPIKey}).(*sparkpostMailer)
+	sparkpost.baseURL = server.URL
+
+	err := sparkpost.Send(Mail{
+		From:    Address{Name: "Acme", Email: "info@test.com"},
+		To:      Addresses("a@test.com"),
+		Cc:      Addresses("c@test.com"),
+		Bcc:     Addresses("b@test.com"),
+		Subject: "test",
+		Html:    "<p>{{name}}</p>",
+		SparkPost: &SparkPostOptions{
+			SubstitutionData:          map[string]any{"name": "friend"},
+			RecipientSubstitutionData: map[string]map[string]any{"a@test.com": {"name": "Ada"}},
+			Sandbox:                   true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := requests[0]
+	if req.Path != "/api/v1/transmissions" || req.Header.Get("Authorization") != MailAPIKey {
+		t.Fatalf("Expected an authenticated request to /api/v1/transmissions, got %s %v", req.Path, req.Header)
+	}
+	recipients, _ := req.Body["recipients"].([]any)
+	if len(recipients) != 3 {
+		t.Fatalf("Expected 3 recipients, got %v", req.Body["recipients"])
+	}
+	first := recipients[0].(map[string]any)
+	if first["substitution_data"].(map[string]any)["name"] != "Ada" {
+		t.Errorf("Expected the substitution data of the recipient, got %v", first)
+	}
+	for _, recipient := range recipients[1:] {
+		if address := recipient.(map[string]any)["address"].(map[string]any); address["header_to"] != "a@test.com" {
+			t.Errorf("Expected the Cc and Bcc recipients to have the To header, got %v", address)
+		}
+	}
+	content := req.Body["content"].(map[string]any)
+	if content["headers"].(map[string]any)["CC"] != "c@test.com" {
+		t.Errorf("Expected the CC header, got %v", content["headers"])
+	}
+	if options, _ := req.Body["options"].(map[string]any); options["sandbox"] != true {
+		t.Errorf("Expected the sandbox option, got %v", req.Body["options"])
+	}
+}
+
+func TestSparkPost_Region(t *testing.T) {
+	if m := newSparkPost(sparkpostParams{region: SparkPostRegionEU}).(*sparkpostMailer); m.baseURL != sparkpostEUBaseURL {
+		t.Errorf("Expected the EU endpoint, got %s", m.baseURL)
+	}
+	if m := newSparkPost(sparkpostParams{}).(*sparkpostMailer); m.baseURL != sparkpostBaseURL {
+		t.Errorf("Expected the US endpoint, got %s", m.baseURL)
+	}
+}
+
+func TestSparkPost_SendWithTemplate(t *testing.T) {
+	var requests []recordedRequest
+	server := newRecordingServer(t, &requests, nil)
+	sparkpost := newSparkPost(sparkpostParams{apiKey: MailAPIKey}).(*sparkpostMailer)
+	sparkpost.baseURL = server.URL
+
+	err := sparkpost.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "ignored", SparkPost: &SparkPostOptions{TemplateID: "welcome"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	content := requests[0].Body["content"].(map[string]any)
+	if content["template_id"] != "welcome" || content["subject"] != nil || content["from"] != nil {
+		t.Errorf("Expected only the template id, got %v", content)
+	}
+}
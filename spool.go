@@ -0,0 +1,245 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultSpoolReplayInterval = 30 * time.Second
+
+// ErrMessageSpooled is returned for an email the provider could not send because
+// it is down, which was written to the SpoolDir. The mailer sends it once the
+// provider recovers, it must not be sent again.
+var ErrMessageSpooled = errors.New("provider down, message spooled")
+
+// errSpoolReplayLater is returned by the replay of an email the provider is
+// still down for, none of its chunks being sent or, with a *PartialDeliveryError,
+// some of its recipients only.
+var errSpoolReplayLater = errors.New("provider still down")
+
+// spooledMail is an email of the spool, once rendered by the pipeline.
+type spooledMail struct {
+	ID string `json:"id"`
+	// Hash is the content hash of the email for the dedup cache.
+	Hash string `json:"hash,omitempty"`
+	// Attempts is the number of the sends of the email before and since it was
+	// spooled, for the audit trail of the replays to go on numbering them.
+	Attempts  int       `json:"attempts,omitempty"`
+	Mail      Mail      `json:"mail"`
+	Reason    string    `json:"reason"`
+	SpooledAt time.Time `json:"spooled_at"`
+}
+
+// spool keeps the emails in a directory, each as a .json file to be replayed and
+// an .eml file to be read by a person or a mail client. With keys, the .json
+// file is sealed with envelope encryption and there is no .eml file, the
+// bodies and the recipients not being written in the clear.
+type spool struct {
+	dir  string
+	keys KeyProvider
+	// replaying serializes the replays, for an email not to be sent twice.
+	replaying sync.Mutex
+}
+
+// write writes the email, the .json file being renamed into place last so that
+// an interrupted write is not replayed.
+func (s *spool) write(entry spooledMail) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	if s.keys == nil {
+		message, email := buildMessage(entry.Mail)
+		if email.Error != nil {
+			return email.Error
+		}
+		if err := os.WriteFile(filepath.Join(s.dir, entry.ID+".eml"), []byte(message), 0o600); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if s.keys != nil {
+		if data, err = SealEnvelope(context.Background(), s.keys, data); err != nil {
+			return err
+		}
+	}
+	tmp := filepath.Join(s.dir, "."+entry.ID+".json.tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(s.dir, entry.ID+".json"))
+}
+
+// list returns the emails of the spool, oldest first.
+func (s *spool) list() ([]spooledMail, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []spooledMail
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if s.keys != nil {
+			if data, err = OpenEnvelope(context.Background(), s.keys, data); err != nil {
+				return nil, fmt.Errorf("invalid spool file %s: %w", file, err)
+			}
+		}
+		var entry spooledMail
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("invalid spool file %s: %w", file, err)
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].SpooledAt.Before(entries[j].SpooledAt) })
+	return entries, nil
+}
+
+func (s *spool) remove(id string) error {
+	if err := os.Remove(filepath.Join(s.dir, id+".json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.dir, id+".eml")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// isProviderDown reports whether the error means that the provider could not be
+// reached or failed on its side, the email itself not being at fault.
+func isProviderDown(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		strings.Contains(err.Error(), "connection refused")
+}
+
+// spoolMail writes the email to the spool, returning the cause when it cannot.
+func (m *Mailer) spoolMail(id string, hash string, attempts int, msg Mail, cause error) error {
+	entry := spooledMail{ID: id, Hash: hash, Attempts: attempts, Mail: msg, Reason: cause.Error(), SpooledAt: m.clock.Now()}
+	if err := m.spool.write(entry); err != nil {
+		log.Printf("mailer: failed to spool %s: %v", id, err)
+		return cause
+	}
+	return fmt.Errorf("%w: %v", ErrMessageSpooled, cause)
+}
+
+// ReplaySpool sends the emails of the spool, oldest first, returning the number of
+// emails sent. The emails go through the middlewares and the checks of a send,
+// e.g. their expiry and the quotas. It stops at the first email the provider
+// fails to send because it is still down, rate limits the mailer or a quota is
+// reached, the replay being retried later. The emails refused are dead lettered.
+func (m *Mailer) ReplaySpool(ctx context.Context) (int, error) {
+	if m.spool == nil {
+		return 0, nil
+	}
+	m.spool.replaying.Lock()
+	defer m.spool.replaying.Unlock()
+
+	entries, err := m.spool.list()
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) > 0 && !m.spoolProviderUp(ctx) {
+		return 0, nil
+	}
+
+	sent := 0
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+		attempts := entry.Attempts
+		d := delivery{id: entry.ID, hash: entry.Hash, client: m.mailerClient, provider: m.apiService, quotas: m.quotas, replay: true, attempts: &attempts}
+		if entry.Mail.TenantID != "" {
+			t, err := m.tenant(entry.Mail.TenantID)
+			if err != nil {
+				m.deadLetter(entry.ID, entry.Mail, "spool replay: "+err.Error())
+				m.spool.remove(entry.ID)
+				continue
+			}
+			d.client, d.provider = t.client, t.Config.APIService
+			d.quotas = append([]Quota{t.quota()}, m.quotas...)
+		}
+
+		err := m.deliver(d, entry.Mail)
+		var quotaDeferred *quotaDeferral
+		var rateLimited *rateLimitDeferral
+		if errors.As(err, &quotaDeferred) || errors.As(err, &rateLimited) || errors.Is(err, errSpoolReplayLater) {
+			var partial *PartialDeliveryError
+			if errors.As(err, &partial) {
+				entry.Mail.EnvelopeTo = partial.Failed
+			}
+			if partial != nil || attempts != entry.Attempts {
+				entry.Attempts = attempts
+				if err := m.spool.write(entry); err != nil {
+					return sent, err
+				}
+			}
+			return sent, nil
+		}
+
+		switch {
+		case errors.Is(err, ErrMessageExpired):
+			// deliver dead lettered it already.
+			m.statuses.set(entry.ID, MessageFailed, err, m.clock.Now())
+		case err != nil:
+			m.deadLetter(entry.ID, entry.Mail, "spool replay: "+err.Error())
+			m.statuses.set(entry.ID, MessageFailed, err, m.clock.Now())
+		default:
+			m.statuses.set(entry.ID, MessageSent, nil, m.clock.Now())
+			sent++
+		}
+		if err := m.spool.remove(entry.ID); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// spoolProviderUp checks the health of the provider before replaying, the
+// clients that cannot be checked being tried.
+func (m *Mailer) spoolProviderUp(ctx context.Context) bool {
+	return m.Health(ctx) == nil
+}
+
+// replaySpool replays the spool every interval until the mailer is closed.
+func (m *Mailer) replaySpool(interval time.Duration) {
+	ticker := m.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C():
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if _, err := m.ReplaySpool(ctx); err != nil {
+				log.Printf("mailer: failed to replay the spool: %v", err)
+			}
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyClient is a provider whose sends and health checks fail with err.
+type flakyClient struct {
+	mockMailerClient
+	err  error
+	sent []Mail
+}
+
+func (c *flakyClient) Send(msg Mail) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+func (c *flakyClient) Health(ctx context.Context) error { return c.err }
+
+func TestMailer_SpoolWhenProviderDown(t *testing.T) {
+	dir := t.TempDir()
+	client := &flakyClient{err: &APIError{Provider: SENDGRID, StatusCode: 502}}
+	mailer := NewMailer(MailCfg{mailerClient: client, SpoolDir: dir})
+	defer mailer.Close()
+
+	result, err := mailer.SendWithResult(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test", Text: "test"})
+	if !errors.Is(err, ErrMessageSpooled) {
+		t.Fatalf("Expected the email to be spooled, got %v", err)
+	}
+	if status, _ := mailer.Status(result.ID); status.State != MessageSpooled {
+		t.Errorf("Expected the spooled state, got %s", status.State)
+	}
+	for _, ext := range []string{".json", ".eml"} {
+		if _, err := os.Stat(filepath.Join(dir, result.ID+ext)); err != nil {
+			t.Errorf("Expected the %s file of the email, got %v", ext, err)
+		}
+	}
+
+	// Still down, the email stays in the spool.
+	if sent, err := mailer.ReplaySpool(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("Expected nothing to be replayed, got %d, %v", sent, err)
+	}
+
+	client.err = nil
+	if sent, err := mailer.ReplaySpool(context.Background()); err != nil || sent != 1 {
+		t.Fatalf("Expected the email to be replayed, got %d, %v", sent, err)
+	}
+	if len(client.sent) != 1 || client.sent[0].Subject != "test" {
+		t.Errorf("Expected the spooled email to be sent, got %v", client.sent)
+	}
+	if status, _ := mailer.Status(result.ID); status.State != MessageSent {
+		t.Errorf("Expected the sent state, got %s", status.State)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected the spool to be empty, got %d files", len(files))
+	}
+}
+
+func TestMailer_SpoolPartialDelivery(t *testing.T) {
+	down := true
+	var sent []Mail
+	client := &mockSendFuncClient{sendFunc: func(msg Mail) error {
+		sent = append(sent, msg)
+		if down {
+			return &PartialDeliveryError{Failed: []string{"b@test.com"}, Err: &APIError{Provider: SENDGRID, StatusCode: 502}}
+		}
+		return nil
+	}}
+	mailer := NewMailer(MailCfg{mailerClient: client, SpoolDir: t.TempDir()})
+	defer mailer.Close()
+
+	err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com", "b@test.com"), Subject: "test", Text: "test"})
+	if !errors.Is(err, ErrMessageSpooled) {
+		t.Fatalf("Expected the email to be spooled, got %v", err)
+	}
+
+	down = false
+	if n, err := mailer.ReplaySpool(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Expected the email to be replayed, got %d, %v", n, err)
+	}
+	if len(sent) != 2 || joinAddresses(sent[1].To) != "b@test.com" {
+		t.Errorf("Expected the replay to the failed recipient only, got %+v", sent)
+	}
+}
+
+func TestMailer_SpoolWhenUnhealthy(t *testing.T) {
+	client := &flakyClient{err: errors.New("dial tcp: connection refused")}
+	mailer := NewMailer(MailCfg{mailerClient: client, SpoolDir: t.TempDir()})
+	defer mailer.Close()
+
+	mailer.Health(context.Background())
+	client.err = nil
+
+	// The email is spooled without trying the provider whose health check failed.
+	if err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test"}); !errors.Is(err, ErrMessageSpooled) {
+		t.Fatalf("Expected the email to be spooled, got %v", err)
+	}
+	if len(client.sent) != 0 {
+		t.Errorf("Expected no send, got %v", client.sent)
+	}
+}
+
+func TestMailer_SpoolSkipsRejectedEmails(t *testing.T) {
+	dir := t.TempDir()
+	client := &flakyClient{err: &APIError{Provider: SENDGRID, StatusCode: 400}}
+	mailer := NewMailer(MailCfg{mailerClient: client, SpoolDir: dir})
+	defer mailer.Close()
+
+	err := mailer.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "test"})
+	if err == nil || errors.Is(err, ErrMessageSpooled) {
+		t.Fatalf("Expected the rejected email not to be spooled, got %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected the spool to be empty, got %d files", len(files))
+	}
+}
+
+func TestMailer_ReplaySpoolChecks(t *testing.T) {
+	var sendErr error
+	var sent []Mail
+	deadLetters := NewMemoryDeadLetterStore()
+	middlewareRuns := 0
+	clock := &fakeClock{now: time.Now()}
+	mailer := NewMailer(MailCfg{
+		Clock:           clock,
+		SpoolDir:        t.TempDir(),
+		DeadLetterStore: deadLetters,
+		Middlewares: []Middleware{func(next SendFunc) SendFunc {
+			return func(msg Mail) error {
+				middlewareRuns++
+				return next(msg)
+			}
+		}},
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			if sendErr != nil {
+				return sendErr
+			}
+			sent = append(sent, msg)
+			return nil
+		}},
+	})
+	defer mailer.Close()
+
+	now := clock.Now()
+	mailer.spool.write(spooledMail{ID: "expired", Mail: Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "expired", ExpiresAt: now.Add(-time.Minute)}, SpooledAt: now.Add(-time.Hour)})
+	mailer.spool.write(spooledMail{ID: "otp", Mail: Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "otp"}, SpooledAt: now})
+
+	// A rate limit keeps the email in the spool for the next replay.
+	sendErr = &APIError{Provider: RESEND, StatusCode: 429}
+	if n, err := mailer.ReplaySpool(context.Background()); err != nil || n != 0 {
+		t.Fatalf("Expected nothing to be replayed, got %d, %v", n, err)
+	}
+	entries, _ := mailer.spool.list()
+	if len(entries) != 1 || entries[0].ID != "otp" {
+		t.Fatalf("Expected the rate limited email to stay spooled, got %v", entries)
+	}
+	if letters := deadLetters.List(); len(letters) != 1 || letters[0].Reason != "expired" {
+		t.Errorf("Expected the expired email to be dead lettered once, got %v", letters)
+	}
+
+	sendErr = nil
+	clock.Advance(time.Hour)
+	if n, err := mailer.ReplaySpool(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Expected the email to be replayed, got %d, %v", n, err)
+	}
+	if len(sent) != 1 || sent[0].Subject != "otp" {
+		t.Errorf("Expected the spooled email to be sent, got %v", sent)
+	}
+	if middlewareRuns != 3 {
+		t.Errorf("Expected the middlewares to run on every replay, got %d runs", middlewareRuns)
+	}
+}
+
+func TestSpool_SealedWithTheArchiveKeys(t *testing.T) {
+	keys, err := NewStaticKeyProvider(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	mailer := NewMailer(MailCfg{
+		mailerClient: &mockMailerClient{},
+		SpoolDir:     dir,
+		ArchiveStore: NewEncryptedArchiveStore(NewMemoryArchiveStore(), keys),
+	})
+	defer mailer.Close()
+
+	if err := mailer.spool.write(spooledMail{ID: "otp", Mail: Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Subject: "Your code is 123456"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != "otp.json" {
+		t.Fatalf("Expected a single sealed file, got %v", files)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "otp.json"))
+	if strings.Contains(string(data), "123456") || strings.Contains(string(data), "a@test.com") {
+		t.Errorf("Expected the spooled email to be sealed, got %s", data)
+	}
+
+	entries, err := mailer.spool.list()
+	if err != nil || len(entries) != 1 || entries[0].Mail.Subject != "Your code is 123456" {
+		t.Errorf("Expected the sealed email to be read back, got %v, %v", entries, err)
+	}
+}
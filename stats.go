@@ -0,0 +1,162 @@
+package mailer
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats is a snapshot of the activity of the mailer.
+type Stats struct {
+	// Queued is the number of emails waiting to be sent.
+	Queued int
+	// InFlight is the number of emails being sent to the provider.
+	InFlight int
+	// SendsPerMinute is the number of emails sent to the provider during the last minute.
+	SendsPerMinute int
+	// ErrorRate is the ratio of failed sends during the last minute, between 0 and 1.
+	ErrorRate float64
+	// BounceRate is the ratio of the bounces reported during the last minute to
+	// the emails sent during it, capped to 1.
+	BounceRate float64
+	// Providers breaks down the sends since the mailer was created by provider.
+	Providers map[APIServiceType]ProviderStats
+	// Cost is the estimated cost of the emails sent, from MailCfg.Pricing.
+	Cost CostStats
+	// SendQuota is the last sending quota reported by the provider, nil until
+	// CheckSendQuota succeeded.
+	SendQuota *SendQuota
+	// Connections counts the connections to the SMTP server, nil unless the
+	// provider keeps some, see ConnectionReporter.
+	Connections *ConnectionStats
+}
+
+// ProviderStats counts the sends to a provider.
+type ProviderStats struct {
+	Sent   int
+	Failed int
+}
+
+// statsBucket counts the sends during one second.
+type statsBucket struct {
+	second  int64
+	sent    int
+	failed  int
+	bounced int
+}
+
+type statsRecorder struct {
+	mu        sync.Mutex
+	inFlight  int
+	providers map[APIServiceType]ProviderStats
+	// buckets hold the last minute of sends, indexed by second.
+	buckets [60]statsBucket
+	costs   CostStats
+}
+
+func (s *statsRecorder) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight++
+}
+
+func (s *statsRecorder) done(provider APIServiceType, err error, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+
+	if s.providers == nil {
+		s.providers = make(map[APIServiceType]ProviderStats)
+	}
+	p := s.providers[provider]
+
+	bucket := s.bucket(now)
+	if err != nil {
+		p.Failed++
+		bucket.failed++
+	} else {
+		p.Sent++
+		bucket.sent++
+	}
+	s.providers[provider] = p
+}
+
+// bounce counts a bounce reported by the provider.
+func (s *statsRecorder) bounce(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bucket(now).bounced++
+}
+
+// bucket returns the bucket of the second, reset when it held an older second.
+func (s *statsRecorder) bucket(now time.Time) *statsBucket {
+	second := now.Unix()
+	bucket := &s.buckets[second%int64(len(s.buckets))]
+	if bucket.second != second {
+		*bucket = statsBucket{second: second}
+	}
+	return bucket
+}
+
+func (s *statsRecorder) snapshot(now time.Time) Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := Stats{
+		InFlight:  s.inFlight,
+		Providers: make(map[APIServiceType]ProviderStats, len(s.providers)),
+	}
+	for provider, p := range s.providers {
+		stats.Providers[provider] = p
+	}
+
+	stats.Cost = CostStats{
+		Total:     s.costs.Total,
+		Providers: make(map[APIServiceType]float64, len(s.costs.Providers)),
+		Tenants:   make(map[string]float64, len(s.costs.Tenants)),
+		Tags:      make(map[string]float64, len(s.costs.Tags)),
+	}
+	for provider, amount := range s.costs.Providers {
+		stats.Cost.Providers[provider] = amount
+	}
+	for tenant, amount := range s.costs.Tenants {
+		stats.Cost.Tenants[tenant] = amount
+	}
+	for tag, amount := range s.costs.Tags {
+		stats.Cost.Tags[tag] = amount
+	}
+
+	failed, bounced := 0, 0
+	for _, bucket := range s.buckets {
+		if now.Unix()-bucket.second < int64(len(s.buckets)) {
+			stats.SendsPerMinute += bucket.sent + bucket.failed
+			failed += bucket.failed
+			bounced += bucket.bounced
+		}
+	}
+	if stats.SendsPerMinute > 0 {
+		stats.ErrorRate = float64(failed) / float64(stats.SendsPerMinute)
+	}
+	if sent := stats.SendsPerMinute - failed; sent > 0 {
+		stats.BounceRate = min(float64(bounced)/float64(sent), 1)
+	}
+	return stats
+}
+
+// Stats returns the queue length, the in-flight count and the send rates of the
+// mailer, e.g. to alert on a growing backlog.
+func (m *Mailer) Stats() Stats {
+	stats := m.stats.snapshot(m.clock.Now())
+
+	m.pendingMu.Lock()
+	stats.Queued = len(m.pending)
+	m.pendingMu.Unlock()
+
+	stats.SendQuota = m.sendQuota.get()
+
+	if reporter, ok := m.mailerClient.(ConnectionReporter); ok {
+		connections := reporter.ConnectionStats()
+		stats.Connections = &connections
+	}
+
+	return stats
+}
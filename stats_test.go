@@ -0,0 +1,89 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatsRecorder(t *testing.T) {
+	var s statsRecorder
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		s.start()
+		s.done(RESEND, nil, now.Add(-2*time.Minute))
+	}
+	for i := 0; i < 3; i++ {
+		s.start()
+		s.done(RESEND, nil, now)
+	}
+	s.start()
+	s.done(SENDGRID, errors.New("rate limited"), now)
+	s.start()
+	s.bounce(now)
+
+	stats := s.snapshot(now)
+	if stats.InFlight != 1 {
+		t.Errorf("Expected 1 in flight, got %d", stats.InFlight)
+	}
+	if stats.SendsPerMinute != 4 {
+		t.Errorf("Expected 4 sends per minute, got %d", stats.SendsPerMinute)
+	}
+	if stats.ErrorRate != 0.25 {
+		t.Errorf("Expected an error rate of 0.25, got %v", stats.ErrorRate)
+	}
+	if stats.BounceRate != float64(1)/3 {
+		t.Errorf("Expected a bounce rate of 1/3, got %v", stats.BounceRate)
+	}
+	if stats.Providers[RESEND] != (ProviderStats{Sent: 6}) || stats.Providers[SENDGRID] != (ProviderStats{Failed: 1}) {
+		t.Errorf("Unexpected provider stats %+v", stats.Providers)
+	}
+}
+
+func TestMailer_Stats(t *testing.T) {
+	mailer := NewMailer(MailCfg{
+		APIService:   RESEND,
+		APIKey:       MailAPIKey,
+		mailerClient: &mockMailerClient{},
+	})
+	defer mailer.Close()
+
+	mailer.Pause()
+	mailer.Enqueue(Mail{To: Addresses("queued@example.com")})
+	if stats := mailer.Stats(); stats.Queued != 1 {
+		t.Errorf("Expected 1 queued email, got %d", stats.Queued)
+	}
+	mailer.Resume()
+
+	if err := mailer.Send(Mail{To: Addresses("test@example.com")}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stats := mailer.Stats()
+	if stats.Queued != 0 || stats.Providers[RESEND].Sent != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestMailer_StatsCost(t *testing.T) {
+	mailer := NewMailer(MailCfg{
+		APIService:   RESEND,
+		APIKey:       MailAPIKey,
+		mailerClient: &mockMailerClient{},
+		Pricing: map[APIServiceType]Price{
+			RESEND: {PerEmail: 0.5, PerRecipient: 0.25},
+		},
+	})
+	defer mailer.Close()
+
+	mailer.Send(Mail{To: Addresses("a@example.com", "b@example.com"), Tags: []string{"welcome"}})
+	mailer.Send(Mail{To: Addresses("c@example.com"), Bcc: Addresses("d@example.com"), Tags: []string{"welcome", "onboarding"}})
+
+	cost := mailer.Stats().Cost
+	if cost.Total != 2 || cost.Providers[RESEND] != 2 {
+		t.Errorf("Expected a total cost of 2, got %+v", cost)
+	}
+	if cost.Tags["welcome"] != 2 || cost.Tags["onboarding"] != 1 {
+		t.Errorf("Unexpected cost per tag %+v", cost.Tags)
+	}
+}
@@ -0,0 +1,86 @@
+package mailer
+
+import (
+	"sync"
+	"time"
+)
+
+// statusRetention is how long the status of an email is kept once it was sent,
+// failed or was canceled.
+const statusRetention = 24 * time.Hour
+
+// MessageState is the state of an email in the mailer.
+type MessageState string
+
+const (
+	MessageQueued   MessageState = "queued"
+	MessageSent     MessageState = "sent"
+	MessageFailed   MessageState = "failed"
+	MessageCanceled MessageState = "canceled"
+	// MessageSpooled is an email written to the spool while the provider is down.
+	MessageSpooled MessageState = "spooled"
+)
+
+// MessageStatus is the status of an email queued with Send or Enqueue.
+type MessageStatus struct {
+	ID    string
+	State MessageState
+	// Error is the reason of the failure of a failed email.
+	Error     string
+	UpdatedAt time.Time
+	// Responses are the responses of the provider, kept according to MailCfg.ResponseCapture.
+	Responses []ProviderResponse
+}
+
+// statusTracker keeps the status of the emails, forgetting the finished ones after statusRetention.
+type statusTracker struct {
+	mu        sync.Mutex
+	statuses  map[string]MessageStatus
+	lastPrune time.Time
+}
+
+func (t *statusTracker) set(id string, state MessageState, err error, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.statuses == nil {
+		t.statuses = make(map[string]MessageStatus)
+	}
+	if now.Sub(t.lastPrune) > time.Minute {
+		for id, status := range t.statuses {
+			if status.State != MessageQueued && now.Sub(status.UpdatedAt) > statusRetention {
+				delete(t.statuses, id)
+			}
+		}
+		t.lastPrune = now
+	}
+
+	status := MessageStatus{ID: id, State: state, UpdatedAt: now, Responses: t.statuses[id].Responses}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	t.statuses[id] = status
+}
+
+// respond records the responses of the provider to the sending of the email.
+func (t *statusTracker) respond(id string, responses []ProviderResponse) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if status, ok := t.statuses[id]; ok {
+		status.Responses = responses
+		t.statuses[id] = status
+	}
+}
+
+func (t *statusTracker) get(id string) (MessageStatus, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status, ok := t.statuses[id]
+	return status, ok
+}
+
+// Status returns the status of the email with the id returned by Enqueue. The
+// status of the emails sent, failed or canceled is kept for 24 hours.
+func (m *Mailer) Status(id string) (MessageStatus, bool) {
+	return m.statuses.get(id)
+}
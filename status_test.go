@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMailer_Status(t *testing.T) {
+	release := make(chan struct{})
+	mailer := NewMailer(MailCfg{
+		APIService: RESEND,
+		APIKey:     MailAPIKey,
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			<-release
+			if joinAddresses(msg.To) == "bounce@example.com" {
+				return errors.New("mailbox unavailable")
+			}
+			return nil
+		}},
+	})
+	defer mailer.Close()
+
+	sent, _ := mailer.Enqueue(Mail{To: Addresses("ok@example.com")})
+	failed, _ := mailer.Enqueue(Mail{To: Addresses("bounce@example.com")})
+	canceled, _ := mailer.Enqueue(Mail{To: Addresses("canceled@example.com")})
+
+	if status, ok := mailer.Status(canceled); !ok || status.State != MessageQueued {
+		t.Errorf("Expected a queued status, got %+v", status)
+	}
+	mailer.Cancel(canceled)
+	close(release)
+
+	// Send waits for the emails queued before it.
+	mailer.Send(Mail{To: Addresses("last@example.com")})
+
+	expected := map[string]MessageState{sent: MessageSent, failed: MessageFailed, canceled: MessageCanceled}
+	for id, state := range expected {
+		if status, ok := mailer.Status(id); !ok || status.State != state {
+			t.Errorf("Expected %s to be %s, got %+v", id, state, status)
+		}
+	}
+	if status, _ := mailer.Status(failed); status.Error != "mailbox unavailable" {
+		t.Errorf("Expected the failure reason, got %q", status.Error)
+	}
+	if _, ok := mailer.Status("unknown"); ok {
+		t.Errorf("Expected no status for an unknown id")
+	}
+}
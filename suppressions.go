@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrSuppressionSyncUnsupported is returned by SyncSuppressions when the provider
+// does not keep a suppression list of its own.
+var ErrSuppressionSyncUnsupported = errors.New("the provider does not support suppression sync")
+
+// SuppressionReason tells why an address is suppressed.
+type SuppressionReason string
+
+const (
+	SuppressionManual      SuppressionReason = "manual"
+	SuppressionBounce      SuppressionReason = "bounce"
+	SuppressionBlock       SuppressionReason = "block"
+	SuppressionSpamReport  SuppressionReason = "spam_report"
+	SuppressionUnsubscribe SuppressionReason = "unsubscribe"
+)
+
+// Suppression is an address that must not receive emails.
+type Suppression struct {
+	Email  string
+	Reason SuppressionReason
+	// Group is the unsubscribe group the address left, e.g. a SendGrid group.
+	// The address is suppressed from every email when empty.
+	Group     string
+	CreatedAt time.Time
+}
+
+func (s Suppression) key() string {
+	return strings.ToLower(strings.TrimSpace(s.Email)) + "\x00" + s.Group
+}
+
+// SyncableSuppressionList is a SuppressionList that can be listed and added to,
+// so that it can be synchronized with the suppression list of a provider.
+type SyncableSuppressionList interface {
+	SuppressionList
+	Suppress(ctx context.Context, suppression Suppression) error
+	List(ctx context.Context) ([]Suppression, error)
+}
+
+// SuppressionSyncResult counts the suppressions copied by a sync.
+type SuppressionSyncResult struct {
+	// Pulled is the number of suppressions of the provider added to the list.
+	Pulled int
+	// Pushed is the number of suppressions of the list added to the provider.
+	Pushed int
+}
+
+// SuppressionSyncer is implemented by the mailer clients whose provider keeps a
+// suppression list of its own.
+type SuppressionSyncer interface {
+	SyncSuppressions(ctx context.Context, list SyncableSuppressionList) (SuppressionSyncResult, error)
+}
+
+// SyncSuppressions copies the suppressions of the provider missing from the list
+// and the other way around, so that swapping the provider keeps suppressing
+// the same addresses. Removals are not synchronized, an address must be removed
+// from both.
+func (m *Mailer) SyncSuppressions(ctx context.Context, list SyncableSuppressionList) (SuppressionSyncResult, error) {
+	syncer, ok := m.mailerClient.(SuppressionSyncer)
+	if !ok {
+		return SuppressionSyncResult{}, ErrSuppressionSyncUnsupported
+	}
+	return syncer.SyncSuppressions(ctx, list)
+}
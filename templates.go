@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTemplatesNotSupported is returned when the API service does not support stored templates.
+var ErrTemplatesNotSupported = errors.New("the API service does not support stored templates")
+
+// Template is a local template definition synced to the stored templates of a provider.
+type Template struct {
+	// Name identifies the template in the provider.
+	Name string
+	// Subject is the subject of the template.
+	Subject string
+	// Html is the html content of the template.
+	Html string
+	// Text is the text content of the template.
+	Text string
+	// Limits guard the rendering of the template, they are not sent to the provider.
+	Limits RenderLimits
+}
+
+// TemplateManager is implemented by the mailer clients whose provider supports stored templates.
+type TemplateManager interface {
+	CreateTemplate(ctx context.Context, tpl Template) error
+	UpdateTemplate(ctx context.Context, tpl Template) error
+	DeleteTemplate(ctx context.Context, name string) error
+}
+
+// CreateTemplate creates the template in the provider of the chosen API service.
+func (m *Mailer) CreateTemplate(ctx context.Context, tpl Template) error {
+	manager, ok := m.mailerClient.(TemplateManager)
+	if !ok {
+		return ErrTemplatesNotSupported
+	}
+	return manager.CreateTemplate(ctx, tpl)
+}
+
+// UpdateTemplate replaces the content of the template in the provider of the chosen API service.
+func (m *Mailer) UpdateTemplate(ctx context.Context, tpl Template) error {
+	manager, ok := m.mailerClient.(TemplateManager)
+	if !ok {
+		return ErrTemplatesNotSupported
+	}
+	return manager.UpdateTemplate(ctx, tpl)
+}
+
+// DeleteTemplate deletes the template from the provider of the chosen API service.
+func (m *Mailer) DeleteTemplate(ctx context.Context, name string) error {
+	manager, ok := m.mailerClient.(TemplateManager)
+	if !ok {
+		return ErrTemplatesNotSupported
+	}
+	return manager.DeleteTemplate(ctx, name)
+}
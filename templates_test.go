@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMailer_TemplateManagement(t *testing.T) {
+	testCases := []struct {
+		name     string
+		client   MailerClient
+		expected error
+	}{
+		{
+			name:     "Should fail when the client does not support templates",
+			client:   &mockMailerClient{},
+			expected: ErrTemplatesNotSupported,
+		},
+		{
+			name:   "Should delegate to the template manager",
+			client: &mockTemplateClient{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mailer := NewMailer(MailCfg{
+				APIService:   RESEND,
+				APIKey:       MailAPIKey,
+				mailerClient: tc.client,
+			})
+			defer mailer.Close()
+
+			ctx := context.Background()
+			tpl := Template{Name: "welcome", Subject: "Welcome", Html: "<p>Hi</p>"}
+			errs := []error{
+				mailer.CreateTemplate(ctx, tpl),
+				mailer.UpdateTemplate(ctx, tpl),
+				mailer.DeleteTemplate(ctx, tpl.Name),
+			}
+			for _, err := range errs {
+				if !errors.Is(err, tc.expected) {
+					t.Errorf("Expected %v, got %v", tc.expected, err)
+				}
+			}
+
+			if client, ok := tc.client.(*mockTemplateClient); ok {
+				expected := []string{"create:welcome", "update:welcome", "delete:welcome"}
+				if len(client.calls) != len(expected) {
+					t.Fatalf("Expected calls %v, got %v", expected, client.calls)
+				}
+				for i := range expected {
+					if client.calls[i] != expected[i] {
+						t.Errorf("Expected calls %v, got %v", expected, client.calls)
+					}
+				}
+			}
+		})
+	}
+}
+
+type mockTemplateClient struct {
+	mockMailerClient
+	calls []string
+}
+
+func (m *mockTemplateClient) CreateTemplate(ctx context.Context, tpl Template) error {
+	m.calls = append(m.calls, "create:"+tpl.Name)
+	return nil
+}
+
+func (m *mockTemplateClient) UpdateTemplate(ctx context.Context, tpl Template) error {
+	m.calls = append(m.calls, "update:"+tpl.Name)
+	return nil
+}
+
+func (m *mockTemplateClient) DeleteTemplate(ctx context.Context, name string) error {
+	m.calls = append(m.calls, "delete:"+name)
+	return nil
+}
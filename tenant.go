@@ -0,0 +1,175 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownTenant is returned when an email refers to a tenant that was not registered.
+	ErrUnknownTenant = errors.New("unknown tenant")
+	// ErrTenantFromDomain is returned when the sender of an email is not in the From domain of its tenant.
+	ErrTenantFromDomain = errors.New("sender is not in the From domain of the tenant")
+	// ErrQuotaExceeded is returned when sending an email would exceed a quota.
+	ErrQuotaExceeded = errors.New("send quota exceeded")
+	// ErrAllRecipientsSuppressed is returned when every recipient of an email is
+	// on the suppression list of its tenant.
+	ErrAllRecipientsSuppressed = errors.New("all recipients are suppressed")
+)
+
+// SuppressionList holds the addresses that must not receive emails, e.g. after
+// a hard bounce or a complaint.
+type SuppressionList interface {
+	Suppressed(email string) (bool, error)
+}
+
+// MemorySuppressionList is a SuppressionList kept in memory.
+type MemorySuppressionList struct {
+	mu           sync.RWMutex
+	suppressions map[string]Suppression
+}
+
+// NewMemorySuppressionList creates a suppression list holding the emails.
+func NewMemorySuppressionList(emails ...string) *MemorySuppressionList {
+	list := &MemorySuppressionList{suppressions: make(map[string]Suppression)}
+	for _, email := range emails {
+		list.Add(email)
+	}
+	return list
+}
+
+// Add suppresses the email.
+func (l *MemorySuppressionList) Add(email string) {
+	l.Suppress(context.Background(), Suppression{Email: email, Reason: SuppressionManual})
+}
+
+// Remove removes the email from the list.
+func (l *MemorySuppressionList) Remove(email string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.suppressions, Suppression{Email: email}.key())
+}
+
+// Suppressed reports whether the email is suppressed. The unsubscribes from a
+// group do not suppress the email.
+func (l *MemorySuppressionList) Suppressed(email string) (bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.suppressions[Suppression{Email: email}.key()]
+	return ok, nil
+}
+
+// Suppress adds the suppression, keeping the existing one of the email and group.
+func (l *MemorySuppressionList) Suppress(ctx context.Context, suppression Suppression) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	suppression.Email = strings.ToLower(strings.TrimSpace(suppression.Email))
+	if _, ok := l.suppressions[suppression.key()]; !ok {
+		l.suppressions[suppression.key()] = suppression
+	}
+	return nil
+}
+
+// List returns the suppressions sorted by email.
+func (l *MemorySuppressionList) List(ctx context.Context) ([]Suppression, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	suppressions := make([]Suppression, 0, len(l.suppressions))
+	for _, suppression := range l.suppressions {
+		suppressions = append(suppressions, suppression)
+	}
+	sort.Slice(suppressions, func(i, j int) bool { return suppressions[i].key() < suppressions[j].key() })
+	return suppressions, nil
+}
+
+// Tenant is a customer on whose behalf emails are sent, with its own sender
+// domain, provider and limits. Emails select their tenant with Mail.TenantID.
+type Tenant struct {
+	// ID identifies the tenant.
+	ID string
+	// FromDomain is the only domain the tenant may send from, when set.
+	FromDomain string
+	// From is the default sender of the emails of the tenant.
+	From string
+	// Config holds the provider and credentials of the tenant, only the provider
+	// fields are used.
+	Config MailCfg
+	// DailyQuota is the maximum number of emails sent per UTC day, unlimited when zero.
+	DailyQuota int
+	// Suppressions are the addresses the tenant must not send to.
+	Suppressions SuppressionList
+}
+
+type tenant struct {
+	Tenant
+	client MailerClient
+}
+
+// quota returns the daily quota of the tenant.
+func (t *tenant) quota() Quota {
+	return Quota{Tenant: t.ID, Period: QuotaDaily, Limit: int64(t.DailyQuota)}
+}
+
+// RegisterTenant adds or replaces a tenant.
+func (m *Mailer) RegisterTenant(t Tenant) error {
+	if t.ID == "" {
+		return errors.New("tenant id is missing")
+	}
+	if err := validateMailerRequiredFields(t.Config); err != nil {
+		return fmt.Errorf("tenant %s: %w", t.ID, err)
+	}
+
+	registered := &tenant{Tenant: t, client: getMailerClient(t.Config)}
+
+	m.tenantsMu.Lock()
+	previous := m.tenants[t.ID]
+	m.tenants[t.ID] = registered
+	m.tenantsMu.Unlock()
+
+	if previous != nil {
+		previous.client.Close()
+	}
+	return nil
+}
+
+func (m *Mailer) tenant(id string) (*tenant, error) {
+	m.tenantsMu.RLock()
+	defer m.tenantsMu.RUnlock()
+	t, ok := m.tenants[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, id)
+	}
+	return t, nil
+}
+
+// prepare applies the sender and the suppression list of the tenant to the email.
+func (t *tenant) prepare(msg Mail) (Mail, error) {
+	if msg.From.Email == "" {
+		msg.From = toAddress(t.From)
+	}
+	if t.FromDomain != "" {
+		domain := msg.From.Email[strings.LastIndex(msg.From.Email, "@")+1:]
+		if !strings.EqualFold(domain, t.FromDomain) {
+			return msg, fmt.Errorf("%w: %s", ErrTenantFromDomain, msg.From)
+		}
+	}
+
+	if t.Suppressions != nil {
+		checker := PreferenceCheckerFunc(func(recipient string, category string) (bool, error) {
+			suppressed, err := t.Suppressions.Suppressed(recipient)
+			return !suppressed, err
+		})
+		var err error
+		if msg, err = applyPreferences(checker, msg); err != nil {
+			if errors.Is(err, ErrAllRecipientsOptedOut) {
+				return msg, ErrAllRecipientsSuppressed
+			}
+			return msg, err
+		}
+	}
+	return msg, nil
+}
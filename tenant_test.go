@@ -0,0 +1,109 @@
+package mailer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMailer_SendWithTenant(t *testing.T) {
+	var defaultSent, tenantSent []Mail
+	audits := NewMemoryAuditStore()
+	mailer := NewMailer(MailCfg{
+		APIService: RESEND,
+		APIKey:     MailAPIKey,
+		AuditStore: audits,
+		mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+			defaultSent = append(defaultSent, msg)
+			return nil
+		}},
+		Tenants: []Tenant{{
+			ID:           "acme",
+			FromDomain:   "acme.com",
+			From:         "noreply@acme.com",
+			DailyQuota:   2,
+			Suppressions: NewMemorySuppressionList("Bounced@example.com"),
+			Config: MailCfg{
+				APIService: SENDGRID,
+				APIKey:     "acme-key",
+				mailerClient: &mockSendFuncClient{sendFunc: func(msg Mail) error {
+					tenantSent = append(tenantSent, msg)
+					return nil
+				}},
+			},
+		}},
+	})
+	defer mailer.Close()
+
+	testCases := []struct {
+		name string
+		msg  Mail
+		err  error
+	}{
+		{
+			name: "uses the tenant provider and sender",
+			msg:  Mail{TenantID: "acme", To: Addresses("a@example.com", "bounced@example.com")},
+		},
+		{
+			name: "rejects senders outside the tenant domain",
+			msg:  Mail{TenantID: "acme", From: Address{Email: "ceo@other.com"}, To: Addresses("a@example.com")},
+			err:  ErrTenantFromDomain,
+		},
+		{
+			name: "rejects emails to suppressed recipients only",
+			msg:  Mail{TenantID: "acme", To: Addresses("bounced@example.com")},
+			err:  ErrAllRecipientsSuppressed,
+		},
+		{
+			name: "rejects unknown tenants",
+			msg:  Mail{TenantID: "unknown", To: Addresses("a@example.com")},
+			err:  ErrUnknownTenant,
+		},
+		{
+			name: "sends within the daily quota",
+			msg:  Mail{TenantID: "acme", From: Address{Email: "billing@acme.com"}, To: Addresses("b@example.com")},
+		},
+		{
+			name: "rejects emails over the daily quota",
+			msg:  Mail{TenantID: "acme", To: Addresses("c@example.com")},
+			err:  ErrQuotaExceeded,
+		},
+		{
+			name: "sends emails without tenant with the default provider",
+			msg:  Mail{To: Addresses("d@example.com")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := mailer.Send(tc.msg)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+
+	if len(tenantSent) != 2 || len(defaultSent) != 1 {
+		t.Fatalf("Expected 2 tenant and 1 default emails, got %d and %d", len(tenantSent), len(defaultSent))
+	}
+	if tenantSent[0].From.String() != "noreply@acme.com" || joinAddresses(tenantSent[0].To) != "a@example.com" {
+		t.Errorf("Expected the tenant sender and suppressions to be applied, got %+v", tenantSent[0])
+	}
+	if stats := mailer.Stats(); stats.Providers[SENDGRID].Sent != 2 {
+		t.Errorf("Expected the tenant sends to be counted for its provider, got %+v", stats.Providers)
+	}
+	if records := audits.Records(); len(records) != 3 || records[0].Provider != SENDGRID || records[2].Provider != RESEND {
+		t.Errorf("Expected the tenant sends to be audited with its provider, got %+v", records)
+	}
+}
+
+func TestMailer_RegisterTenant(t *testing.T) {
+	mailer := NewMailer(MailCfg{APIService: RESEND, APIKey: MailAPIKey, mailerClient: &mockMailerClient{}})
+	defer mailer.Close()
+
+	if err := mailer.RegisterTenant(Tenant{Config: MailCfg{APIService: RESEND, APIKey: "key"}}); err == nil {
+		t.Errorf("Expected an error for a tenant without id")
+	}
+	if err := mailer.RegisterTenant(Tenant{ID: "acme", Config: MailCfg{APIService: SENDGRID}}); err == nil {
+		t.Errorf("Expected an error for a tenant without API key")
+	}
+}
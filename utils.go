@@ -1,10 +1,13 @@
 package mailer
 
 import (
+	"bytes"
 	"fmt"
 	"log"
+	"mime"
+	"os"
+	"path/filepath"
 	"strconv"
-	"strings"
 
 	mail "github.com/xhit/go-simple-mail/v2"
 )
@@ -19,27 +22,157 @@ func getMailerClient(cfg MailCfg) MailerClient {
 	}
 	switch cfg.APIService {
 	case SMTP:
+		encryption := mail.EncryptionSTARTTLS
+		if cfg.UseSSL {
+			encryption = mail.EncryptionSSLTLS
+		}
 		return newSMTP(smtpParams{
-			Host:      cfg.Host,
-			Port:      cfg.Port,
-			Username:  cfg.HostUser,
-			Password:  cfg.HostPassword,
-			KeepAlive: cfg.KeepAlive,
-			Timeout:   cfg.Timeout,
-			useTLS:    cfg.UseTLS,
+			Host:       cfg.Host,
+			Port:       cfg.Port,
+			Username:   cfg.HostUser,
+			Password:   cfg.HostPassword,
+			KeepAlive:  cfg.KeepAlive,
+			Timeout:    cfg.Timeout,
+			useTLS:     cfg.UseTLS,
+			encryption: encryption,
+			dial:       cfg.Dial,
 		})
+	case DEV:
+		return newSMTP(getDevSMTPParams(cfg))
+	case OVH:
+		return newSMTP(getOVHSMTPParams(cfg))
+	case FILE:
+		return newFile(fileParams{dir: cfg.FileDir, clock: cfg.Clock})
 	case RESEND:
 		return newResend(resendParams{
-			apiKey: cfg.APIKey,
+			apiKey:     cfg.APIKey,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, RESEND),
+		})
+	case SENDGRID:
+		return newSendGrid(sendgridParams{
+			apiKey:     cfg.APIKey,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, SENDGRID),
+		})
+	case MAILGUN:
+		return newMailgun(mailgunParams{
+			apiKey:     cfg.APIKey,
+			domain:     cfg.MailgunDomain,
+			region:     cfg.MailgunRegion,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, MAILGUN),
+			clock:      cfg.Clock,
+		})
+	case POSTMARK:
+		return newPostmark(postmarkParams{
+			serverToken: cfg.APIKey,
+			userAgent:   userAgent(cfg),
+			httpClient:  providerHTTPClient(cfg, POSTMARK),
+		})
+	case BREVO:
+		return newBrevo(brevoParams{
+			apiKey:         cfg.APIKey,
+			templateSender: cfg.BrevoTemplateSender,
+			userAgent:      userAgent(cfg),
+			httpClient:     providerHTTPClient(cfg, BREVO),
+		})
+	case SPARKPOST:
+		return newSparkPost(sparkpostParams{
+			apiKey:     cfg.APIKey,
+			region:     cfg.SparkPostRegion,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, SPARKPOST),
+		})
+	case MAILJET:
+		return newMailjet(mailjetParams{
+			apiKey:     cfg.APIKey,
+			apiSecret:  cfg.APISecret,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, MAILJET),
+		})
+	case SMTP2GO:
+		return newSMTP2GO(smtp2goParams{
+			apiKey:     cfg.APIKey,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, SMTP2GO),
+		})
+	case ELASTIC_EMAIL:
+		return newElasticEmail(elasticEmailParams{
+			apiKey:     cfg.APIKey,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, ELASTIC_EMAIL),
+		})
+	case MANDRILL:
+		return newMandrill(mandrillParams{
+			apiKey:     cfg.APIKey,
+			subaccount: cfg.MandrillSubaccount,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, MANDRILL),
+		})
+	case LOOPS:
+		return newLoops(loopsParams{
+			apiKey:     cfg.APIKey,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, LOOPS),
+		})
+	case PLUNK:
+		return newPlunk(plunkParams{
+			apiKey:     cfg.APIKey,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, PLUNK),
 		})
-	case SENDGRID, MAILGUN:
-		return nil
+	case SCALEWAY:
+		return newScaleway(scalewayParams{
+			secretKey:  cfg.APIKey,
+			projectID:  cfg.ScalewayProjectID,
+			region:     cfg.ScalewayRegion,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, SCALEWAY),
+		})
+	case JSON_API:
+		return newJSONAPI(jsonAPIParams{
+			config:     *cfg.JSONAPI,
+			apiKey:     cfg.APIKey,
+			userAgent:  userAgent(cfg),
+			httpClient: providerHTTPClient(cfg, JSON_API),
+		})
+	case LMTP:
+		return newLMTP(lmtpParams{
+			Host:    cfg.Host,
+			Port:    cfg.Port,
+			Timeout: cfg.Timeout,
+			dial:    cfg.Dial,
+		})
+	case SENDMAIL:
+		return newSendmail(sendmailParams{
+			path: cfg.SendmailPath,
+			args: cfg.SendmailArgs,
+		})
+	case MX:
+		return newMX(mxParams{
+			helloName: cfg.MXHelloName,
+			port:      cfg.Port,
+			timeout:   cfg.Timeout,
+			retries:   cfg.MXRetries,
+			tlsPolicy: cfg.MXTLSPolicy,
+			clock:     cfg.Clock,
+			dial:      cfg.Dial,
+		})
+	case NULL:
+		return newNull()
 	case AMAZON_SES:
 		return newSES(
 			sesParams{
-				Region: cfg.Region,
-				Key:    cfg.APIKey,
-				Secret: cfg.APISecret,
+				Region:               cfg.Region,
+				Key:                  cfg.APIKey,
+				Secret:               cfg.APISecret,
+				ConfigurationSetName: cfg.SESConfigurationSetName,
+				AppID:                sesAppID(cfg),
+				HTTPClient:           providerHTTPClient(cfg, AMAZON_SES),
+				Regions:              cfg.SESRegions,
+				RegionSelection:      cfg.SESRegionSelection,
+				Clock:                cfg.Clock,
 			},
 		)
 	default:
@@ -47,6 +180,27 @@ func getMailerClient(cfg MailCfg) MailerClient {
 	}
 }
 
+func getDevSMTPParams(cfg MailCfg) smtpParams {
+	params := smtpParams{
+		Host:       cfg.Host,
+		Port:       cfg.Port,
+		KeepAlive:  cfg.KeepAlive,
+		Timeout:    cfg.Timeout,
+		encryption: mail.EncryptionNone,
+		dial:       cfg.Dial,
+	}
+	if params.Host == "" {
+		params.Host = defaultDevHost
+	}
+	if params.Port == "" {
+		params.Port = defaultDevPort
+	}
+	if params.Timeout == 0 {
+		params.Timeout = 10
+	}
+	return params
+}
+
 func getPort(port string) int {
 	p, err := strconv.Atoi(port)
 
@@ -63,12 +217,36 @@ func validateMailerRequiredFields(cfg MailCfg) error {
 		if cfg.Host == "" || cfg.Port == "" || cfg.HostUser == "" || cfg.HostPassword == "" {
 			return fmt.Errorf("missing required fields for SMTP i.e host, port, username, password")
 		}
-	case SENDGRID, MAILGUN, RESEND:
+	case LMTP:
+		if cfg.Host == "" {
+			return fmt.Errorf("missing required fields for LMTP i.e host")
+		}
+	case MAILGUN:
+		if cfg.APIKey == "" || cfg.MailgunDomain == "" {
+			return fmt.Errorf("missing required fields for Mailgun i.e key, domain")
+		}
+	case SENDGRID, RESEND, POSTMARK, BREVO, SPARKPOST, SMTP2GO, ELASTIC_EMAIL, MANDRILL, LOOPS, PLUNK:
 		if cfg.APIKey == "" {
 			return fmt.Errorf("API key is missing")
 		}
+	case MAILJET:
+		if cfg.APIKey == "" || cfg.APISecret == "" {
+			return fmt.Errorf("missing required fields for Mailjet i.e key, secret")
+		}
+	case SCALEWAY:
+		if cfg.APIKey == "" || cfg.ScalewayProjectID == "" {
+			return fmt.Errorf("missing required fields for Scaleway i.e key, project id")
+		}
+	case OVH:
+		if cfg.HostUser == "" || cfg.HostPassword == "" {
+			return fmt.Errorf("missing required fields for OVH i.e username, password")
+		}
+	case JSON_API:
+		if cfg.JSONAPI == nil || cfg.JSONAPI.Endpoint == "" || cfg.JSONAPI.Fields.To == "" {
+			return fmt.Errorf("missing required fields for the JSON API i.e endpoint, to field")
+		}
 	case AMAZON_SES:
-		if cfg.APIKey == "" || cfg.APISecret == "" || cfg.Region == "" {
+		if cfg.APIKey == "" || cfg.APISecret == "" || cfg.Region == "" && len(cfg.SESRegions) == 0 {
 			return fmt.Errorf("missing required fields for Amazon SES i.e region, key, secret")
 		}
 	}
@@ -79,41 +257,101 @@ func getSplitEmails(emails string) []string {
 	if emails == "" {
 		return []string{}
 	}
-	return strings.Split(emails, ",")
+	return splitAddressList(emails)
+}
+
+func getAttachmentContent(attachment Attachment) ([]byte, error) {
+	if attachment.Content != nil {
+		return attachment.Content, nil
+	}
+	content, err := os.ReadFile(attachment.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment %s: %w", attachment.Name, err)
+	}
+	return content, nil
+}
+
+func getAttachmentContentType(attachment Attachment) string {
+	if attachment.ContentType != "" {
+		return attachment.ContentType
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(attachment.Name))
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(attachment.Path))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return contentType
 }
 
 func buildMessage(msg Mail) (string, *mail.Email) {
 	email := mail.NewMSG()
-	email.SetFrom(msg.From).
-		AddTo(msg.To).
+	// go-simple-mail parses each argument as a single address.
+	email.SetFrom(msg.From.String()).
+		AddTo(formatAddresses(msg.To)...).
 		SetSubject(msg.Subject)
 
 	if msg.Text != "" {
 		email.SetBody(mail.TextPlain, msg.Text)
 	}
 
+	// Clients display the last alternative they support, AMP must come before html.
+	if msg.AmpHtml != "" {
+		email.AddAlternative(mail.TextAMP, msg.AmpHtml)
+	}
 	if msg.Html != "" {
 		email.AddAlternative(mail.TextHTML, msg.Html)
 	}
 
-	if msg.ReplyTo != "" {
-		email.SetReplyTo(msg.ReplyTo)
+	if msg.ReplyTo.Email != "" {
+		email.SetReplyTo(msg.ReplyTo.String())
 	}
-	if msg.Cc != "" {
-		email.AddCc(msg.Cc)
+	if len(msg.Cc) > 0 {
+		email.AddCc(formatAddresses(msg.Cc)...)
 	}
-	if msg.Bcc != "" {
-		email.AddBcc(msg.Bcc)
+	if len(msg.Bcc) > 0 {
+		email.AddBcc(formatAddresses(msg.Bcc)...)
+	}
+	for _, key := range headerKeys(msg.Headers) {
+		email.AddHeader(key, msg.Headers[key])
+	}
+	if n := msg.DeliveryNotification; n != nil {
+		var dsn []mail.DSN
+		for _, notify := range n.notify() {
+			dsn = append(dsn, map[string]mail.DSN{"SUCCESS": mail.SUCCESS, "FAILURE": mail.FAILURE, "DELAY": mail.DELAY, "NEVER": mail.NEVER}[notify])
+		}
+		email.SetDSN(dsn, false)
 	}
 
-	if len(msg.Attachments) > 0 {
-		for _, attachment := range msg.Attachments {
-			email.Attach(&mail.File{
-				FilePath: attachment.Path,
-				Name:     attachment.Name,
-				Inline:   true,
-			})
+	// The files are read into pooled buffers, released once the message is
+	// encoded, instead of go-simple-mail reading each file into a new slice.
+	var buffers []*bytes.Buffer
+	defer func() {
+		for _, buf := range buffers {
+			putBuffer(buf)
+		}
+	}()
+	for _, attachment := range msg.Attachments {
+		file := &mail.File{
+			Name:     attachment.Name,
+			MimeType: attachment.ContentType,
+			Data:     attachment.Content,
+			Inline:   true,
+		}
+		if attachment.Content == nil {
+			buf := getBuffer()
+			buffers = append(buffers, buf)
+			if err := copyAttachment(buf, attachment); err != nil {
+				email.Error = err
+				break
+			}
+			file.Data = buf.Bytes()
+			if file.Name == "" {
+				file.Name = filepath.Base(attachment.Path)
+			}
 		}
+		email.Attach(file)
 	}
 
 	return email.GetMessage(), email
@@ -52,9 +52,10 @@ func TestGetMailer(t *testing.T) {
 			name:       "get mailgun",
 			apiService: "mailgun",
 			cfg: MailCfg{
-				APIService:   MAILGUN,
-				APIKey:       MailAPIKey,
-				mailerClient: mockClient,
+				APIService:    MAILGUN,
+				APIKey:        MailAPIKey,
+				MailgunDomain: "mg.test.com",
+				mailerClient:  mockClient,
 			},
 			success: true,
 		},
@@ -172,11 +173,20 @@ func TestValidateMailerRequiredFields(t *testing.T) {
 		},
 		{
 			name: "validate mailgun",
+			cfg: MailCfg{
+				APIService:    MAILGUN,
+				APIKey:        MailAPIKey,
+				MailgunDomain: "mg.test.com",
+			},
+			success: true,
+		},
+		{
+			name: "validate mailgun without domain",
 			cfg: MailCfg{
 				APIService: MAILGUN,
 				APIKey:     MailAPIKey,
 			},
-			success: true,
+			success: false,
 		},
 		{
 			name: "validate amazon_ses",
@@ -252,13 +262,13 @@ func TestAWSSes_buildMessage(t *testing.T) {
 			name: "Should send email successfully",
 			payload: Mail{
 				Subject: "test",
-				From:    "info@test.com",
-				To:      "test@gmail.com",
+				From:    Address{Email: "info@test.com"},
+				To:      Addresses("test@gmail.com"),
 				Html:    "<p>test</p>",
 				Text:    "test",
-				ReplyTo: "info@test.com",
-				Cc:      "info@test.com,info@test.com",
-				Bcc:     "info@test.com,info@test.com",
+				ReplyTo: Address{Email: "info@test.com"},
+				Cc:      Addresses("info@test.com", "info@test.com"),
+				Bcc:     Addresses("info@test.com", "info@test.com"),
 				Attachments: []Attachment{
 					{
 						Name: "test",
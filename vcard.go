@@ -0,0 +1,67 @@
+package mailer
+
+import "strings"
+
+// VCard is a contact card (vCard 3.0, RFC 2426), e.g. to let recipients save the
+// sender of onboarding emails.
+type VCard struct {
+	// FormattedName is the display name of the contact, required.
+	FormattedName string
+	GivenName     string
+	FamilyName    string
+	Organization  string
+	Title         string
+	Email         string
+	Phone         string
+	URL           string
+}
+
+// Attachment returns the card as a text/vcard attachment.
+func (c VCard) Attachment(name string) Attachment {
+	var b contentLines
+	b.line("BEGIN:VCARD")
+	b.line("VERSION:3.0")
+	b.line("FN:" + escapeContentValue(c.FormattedName))
+	b.line("N:" + escapeContentValue(c.FamilyName) + ";" + escapeContentValue(c.GivenName) + ";;;")
+	b.optional("ORG:", c.Organization)
+	b.optional("TITLE:", c.Title)
+	b.optional("EMAIL;TYPE=INTERNET:", c.Email)
+	b.optional("TEL:", c.Phone)
+	if c.URL != "" {
+		b.line("URL:" + c.URL)
+	}
+	b.line("END:VCARD")
+
+	return Attachment{Name: name, Content: []byte(b.String()), ContentType: "text/vcard; charset=utf-8"}
+}
+
+// contentLines builds vCard and iCalendar content, whose lines end with CRLF and
+// are folded at 75 octets.
+type contentLines struct {
+	strings.Builder
+}
+
+func (b *contentLines) line(line string) {
+	// Continuation lines start with a space.
+	for limit := 75; len(line) > limit; limit = 74 {
+		cut := limit
+		// Do not split multi-byte characters.
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line + "\r\n")
+}
+
+func (b *contentLines) optional(prefix string, value string) {
+	if value != "" {
+		b.line(prefix + escapeContentValue(value))
+	}
+}
+
+// escapeContentValue escapes a text value of a vCard or iCalendar property.
+func escapeContentValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
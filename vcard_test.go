@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVCard_Attachment(t *testing.T) {
+	attachment := VCard{
+		FormattedName: "Ada Lovelace",
+		GivenName:     "Ada",
+		FamilyName:    "Lovelace",
+		Organization:  "Analytical Engines, Ltd",
+		Email:         "ada@example.com",
+	}.Attachment("ada.vcf")
+
+	expected := "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Ada Lovelace\r\nN:Lovelace;Ada;;;\r\nORG:Analytical Engines\\, Ltd\r\nEMAIL;TYPE=INTERNET:ada@example.com\r\nEND:VCARD\r\n"
+	if string(attachment.Content) != expected {
+		t.Errorf("Unexpected vCard %q", attachment.Content)
+	}
+	if getAttachmentContentType(attachment) != "text/vcard; charset=utf-8" {
+		t.Errorf("Unexpected content type %s", getAttachmentContentType(attachment))
+	}
+}
+
+func TestContentLines_Fold(t *testing.T) {
+	var b contentLines
+	b.line("DESCRIPTION:" + strings.Repeat("é", 100))
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) < 3 {
+		t.Fatalf("Expected the line to be folded, got %q", lines)
+	}
+	unfolded := lines[0]
+	for _, line := range lines[1:] {
+		if !strings.HasPrefix(line, " ") {
+			t.Errorf("Expected continuation lines to start with a space, got %q", line)
+		}
+		unfolded += line[1:]
+	}
+	for _, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("Expected lines of at most 75 octets, got %d", len(line))
+		}
+	}
+	if unfolded != "DESCRIPTION:"+strings.Repeat("é", 100) {
+		t.Errorf("Expected folding to keep the characters, got %q", unfolded)
+	}
+}
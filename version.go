@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Version is the version of the package, sent in the X-Mailer header and the
+// User-Agent of the API requests unless configured otherwise.
+const Version = "0.2.0"
+
+const defaultIdentification = "caesar-rocks/mail/" + Version
+
+// xMailer returns the X-Mailer header of the configuration, empty when suppressed.
+func xMailer(cfg MailCfg) string {
+	if cfg.NoXMailer {
+		return ""
+	}
+	if cfg.XMailer != "" {
+		return cfg.XMailer
+	}
+	return defaultIdentification
+}
+
+// userAgent returns the User-Agent of the configuration, empty when suppressed.
+func userAgent(cfg MailCfg) string {
+	if cfg.NoUserAgent {
+		return ""
+	}
+	if cfg.UserAgent != "" {
+		return cfg.UserAgent
+	}
+	return defaultIdentification
+}
+
+// setUserAgent sets the User-Agent of the request, net/http sending none rather
+// than its default when it is empty.
+func setUserAgent(req *http.Request, userAgent string) {
+	req.Header["User-Agent"] = []string{userAgent}
+}
+
+// applyXMailer sets the X-Mailer header of the email, unless set by the caller.
+func applyXMailer(msg Mail, xMailer string) Mail {
+	if xMailer == "" {
+		return msg
+	}
+	if _, ok := msg.Headers["X-Mailer"]; ok {
+		return msg
+	}
+	return withHeader(msg, "X-Mailer", xMailer)
+}
+
+// sesAppID returns the app id appended to the user agent of the AWS SDK, which
+// only accepts the characters of a user agent token.
+func sesAppID(cfg MailCfg) string {
+	if cfg.NoUserAgent {
+		return ""
+	}
+	return strings.NewReplacer("/", "-", " ", "_").Replace(userAgent(cfg))
+}
@@ -0,0 +1,68 @@
+package mailer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMailer_SendXMailer(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      MailCfg
+		headers  map[string]string
+		expected string
+	}{
+		{name: "default", expected: "caesar-rocks/mail/" + Version},
+		{name: "configured", cfg: MailCfg{XMailer: "fleet-42"}, expected: "fleet-42"},
+		{name: "suppressed", cfg: MailCfg{NoXMailer: true}, expected: ""},
+		{name: "set by the caller", headers: map[string]string{"X-Mailer": "app"}, expected: "app"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sent := make(chan Mail, 1)
+			test.cfg.mailerClient = &mockSendFuncClient{sendFunc: func(msg Mail) error {
+				sent <- msg
+				return nil
+			}}
+			mailer := NewMailer(test.cfg)
+			defer mailer.Close()
+
+			mailer.Send(Mail{To: Addresses("a@test.com"), Headers: test.headers})
+			if msg := <-sent; msg.Headers["X-Mailer"] != test.expected {
+				t.Errorf("Expected X-Mailer %q, got %q", test.expected, msg.Headers["X-Mailer"])
+			}
+		})
+	}
+}
+
+func TestSendGrid_UserAgent(t *testing.T) {
+	tests := []struct {
+		cfg      MailCfg
+		expected string
+	}{
+		{cfg: MailCfg{}, expected: "caesar-rocks/mail/" + Version},
+		{cfg: MailCfg{UserAgent: "fleet-42"}, expected: "fleet-42"},
+		{cfg: MailCfg{NoUserAgent: true}, expected: ""},
+	}
+
+	for _, test := range tests {
+		var got []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Values("User-Agent")
+			w.WriteHeader(http.StatusAccepted)
+		}))
+
+		sendgrid := newSendGrid(sendgridParams{apiKey: "key", userAgent: userAgent(test.cfg)}).(*sendgridMailer)
+		sendgrid.baseURL = server.URL
+		if err := sendgrid.Send(Mail{From: Address{Email: "info@test.com"}, To: Addresses("a@test.com"), Text: "test"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		server.Close()
+
+		if test.expected == "" && len(got) != 0 || test.expected != "" && (len(got) != 1 || got[0] != test.expected) {
+			t.Errorf("Expected User-Agent %q, got %q", test.expected, got)
+		}
+	}
+}
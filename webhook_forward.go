@@ -0,0 +1,198 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWebhookMaxAttempts = 5
+	// webhookQueueSize is the number of events buffered per webhook, the events
+	// raised while it is full are dropped.
+	webhookQueueSize = 1000
+	webhookTimeout   = 10 * time.Second
+	// webhookSignatureTolerance is the maximum age of a forwarded event accepted
+	// by VerifyForwardedEvent.
+	webhookSignatureTolerance = 5 * time.Minute
+)
+
+// WebhookEndpoint is a downstream webhook the events of the mailer are forwarded
+// to, so that other systems consume them without talking to the provider.
+type WebhookEndpoint struct {
+	// URL receives the events as JSON POST requests.
+	URL string
+	// Secret signs the requests with HMAC-SHA256 in the X-Mailer-Signature header,
+	// see VerifyForwardedEvent. The requests are not signed when empty.
+	Secret string
+	// Events are the types of the events forwarded, every event when empty.
+	Events []EventType
+}
+
+// forwardedEvent is the JSON body of the requests of the webhooks.
+type forwardedEvent struct {
+	Type      EventType      `json:"type"`
+	Provider  APIServiceType `json:"provider,omitempty"`
+	MessageID string         `json:"message_id,omitempty"`
+	Recipient string         `json:"recipient,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+	Reason    string         `json:"reason,omitempty"`
+	Permanent bool           `json:"permanent,omitempty"`
+}
+
+// webhookForwarder delivers the events to a webhook in order, retrying the failed
+// requests with an exponential backoff.
+type webhookForwarder struct {
+	endpoint    WebhookEndpoint
+	httpClient  *http.Client
+	clock       Clock
+	maxAttempts int
+	events      chan Event
+}
+
+func newWebhookForwarders(cfg MailCfg, clock Clock) []*webhookForwarder {
+	maxAttempts := cfg.WebhookMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookMaxAttempts
+	}
+	httpClient := &http.Client{Transport: cfg.HTTPTransport, Timeout: webhookTimeout}
+
+	forwarders := make([]*webhookForwarder, len(cfg.Webhooks))
+	for i, endpoint := range cfg.Webhooks {
+		forwarders[i] = &webhookForwarder{
+			endpoint:    endpoint,
+			httpClient:  httpClient,
+			clock:       clock,
+			maxAttempts: maxAttempts,
+			events:      make(chan Event, webhookQueueSize),
+		}
+	}
+	return forwarders
+}
+
+// forward queues the event, unless the webhook does not receive its type.
+func (f *webhookForwarder) forward(event Event) {
+	if len(f.endpoint.Events) > 0 && !slices.Contains(f.endpoint.Events, event.Type) {
+		return
+	}
+	select {
+	case f.events <- event:
+	default:
+		log.Printf("mailer: webhook %s is too slow, dropping %s event of %s", f.endpoint.URL, event.Type, event.MessageID)
+	}
+}
+
+// run delivers the queued events until done is closed.
+func (f *webhookForwarder) run(done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case event := <-f.events:
+			if err := f.deliver(event); err != nil {
+				log.Printf("mailer: failed to forward %s event of %s to %s: %v", event.Type, event.MessageID, f.endpoint.URL, err)
+			}
+		}
+	}
+}
+
+func (f *webhookForwarder) deliver(event Event) error {
+	body, err := json.Marshal(forwardedEvent(event))
+	if err != nil {
+		return err
+	}
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		retry, err := f.post(body)
+		if err == nil || !retry || attempt == f.maxAttempts {
+			return err
+		}
+		f.clock.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post sends the event, reporting whether a failure may be retried.
+func (f *webhookForwarder) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, f.endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if f.endpoint.Secret != "" {
+		timestamp := strconv.FormatInt(f.clock.Now().Unix(), 10)
+		req.Header.Set("X-Mailer-Timestamp", timestamp)
+		req.Header.Set("X-Mailer-Signature", signForwardedEvent(f.endpoint.Secret, timestamp, body))
+	}
+
+	res, err := f.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	retry := res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusRequestTimeout
+	return retry, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+}
+
+// signForwardedEvent returns the v1= signature of the body of a forwarded event.
+func signForwardedEvent(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyForwardedEvent verifies the X-Mailer-Signature and X-Mailer-Timestamp
+// headers of a request of a WebhookEndpoint and returns its event. Requests
+// older than 5 minutes are rejected, so that a captured request cannot be
+// replayed later.
+func VerifyForwardedEvent(secret string, r *http.Request) (Event, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return Event{}, err
+	}
+
+	timestamp := r.Header.Get("X-Mailer-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return Event{}, ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > webhookSignatureTolerance || age < -webhookSignatureTolerance {
+		return Event{}, fmt.Errorf("%w: timestamp outside the tolerance", ErrInvalidSignature)
+	}
+	signature := strings.TrimSpace(r.Header.Get("X-Mailer-Signature"))
+	if !hmac.Equal([]byte(signature), []byte(signForwardedEvent(secret, timestamp, body))) {
+		return Event{}, ErrInvalidSignature
+	}
+
+	var event forwardedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return Event{}, fmt.Errorf("invalid forwarded event: %w", err)
+	}
+	return Event(event), nil
+}
+
+// HandleEvents passes events received from a provider, e.g. parsed from its
+// webhook with ParseSESNotification, to the EventHandler and the Webhooks of the
+// mailer, like the events it raises itself.
+func (m *Mailer) HandleEvents(events ...Event) {
+	for _, event := range events {
+		m.emit(event)
+	}
+}
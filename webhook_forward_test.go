@@ -0,0 +1,134 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMailer_Webhooks(t *testing.T) {
+	var mu sync.Mutex
+	var received []Event
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		event, err := VerifyForwardedEvent("secret", r)
+		if err != nil {
+			t.Errorf("Expected a valid signature, got %v", err)
+		}
+		received = append(received, event)
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Now().Truncate(time.Second)}
+	mailer := NewMailer(MailCfg{
+		APIService:   RESEND,
+		APIKey:       MailAPIKey,
+		Clock:        clock,
+		Webhooks:     []WebhookEndpoint{{URL: server.URL, Secret: "secret", Events: []EventType{EventBounced}}},
+		mailerClient: &mockMailerClient{},
+	})
+	defer mailer.Close()
+
+	bounce := Event{
+		Type:      EventBounced,
+		Provider:  SENDGRID,
+		MessageID: "abc",
+		Recipient: "a@test.com",
+		Timestamp: clock.Now().UTC(),
+		Reason:    "550 mailbox unavailable",
+		Permanent: true,
+	}
+	mailer.HandleEvents(Event{Type: EventDelivered, MessageID: "abc"}, bounce)
+
+	for i := 0; i < 200; i++ {
+		mu.Lock()
+		n := len(received)
+		mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || received[0] != bounce {
+		t.Fatalf("Expected only the bounce to be forwarded, got %v", received)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected the failed request to be retried, got %d attempts", attempts)
+	}
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != time.Second {
+		t.Errorf("Expected a backoff before the retry, got %v", clock.sleeps)
+	}
+}
+
+func TestWebhookForwarder_NoRetryOnClientError(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	forwarders := newWebhookForwarders(MailCfg{Webhooks: []WebhookEndpoint{{URL: server.URL}}}, &fakeClock{})
+	if err := forwarders[0].deliver(Event{Type: EventBounced}); err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a client error not to be retried, got %d attempts", attempts)
+	}
+}
+
+func TestVerifyForwardedEvent(t *testing.T) {
+	body := []byte(`{"type":"bounced","timestamp":"2024-05-01T10:00:00Z"}`)
+	newRequest := func(timestamp time.Time, secret string) *http.Request {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/events", bytes.NewReader(body))
+		req.Header.Set("X-Mailer-Timestamp", ts)
+		req.Header.Set("X-Mailer-Signature", signForwardedEvent(secret, ts, body))
+		return req
+	}
+
+	testCases := []struct {
+		name    string
+		req     *http.Request
+		wantErr bool
+	}{
+		{name: "Should accept a signed request", req: newRequest(time.Now(), "secret")},
+		{name: "Should reject another secret", req: newRequest(time.Now(), "other"), wantErr: true},
+		{name: "Should reject an old request", req: newRequest(time.Now().Add(-time.Hour), "secret"), wantErr: true},
+		{name: "Should reject an unsigned request", req: httptest.NewRequest(http.MethodPost, "/events", io.NopCloser(bytes.NewReader(body))), wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			event, err := VerifyForwardedEvent("secret", tc.req)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidSignature) {
+					t.Errorf("Expected ErrInvalidSignature, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if event.Type != EventBounced {
+				t.Errorf("Expected the bounce, got %v", event)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerifySendGridSignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := base64.StdEncoding.EncodeToString(der)
+
+	body := []byte(`[{"event":"delivered"}]`)
+	timestamp := "1700000000"
+	digest := sha256.Sum256(append([]byte(timestamp), body...))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := base64.StdEncoding.EncodeToString(sig)
+
+	if err := VerifySendGridSignature(publicKey, signature, timestamp, body); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+	if err := VerifySendGridSignature(publicKey, signature, timestamp, []byte(`[]`)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for a tampered body, got %v", err)
+	}
+}
+
+func TestVerifyMailgunSignature(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("signing-key"))
+	mac.Write([]byte("1700000000" + "token"))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	if err := VerifyMailgunSignature("signing-key", "1700000000", "token", signature); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+	if err := VerifyMailgunSignature("other-key", "1700000000", "token", signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestVerifyPostmarkBasicAuth(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/webhooks/postmark", nil)
+	r.SetBasicAuth("postmark", "secret")
+
+	if err := VerifyPostmarkBasicAuth(r, "postmark", "secret"); err != nil {
+		t.Errorf("Expected valid credentials, got %v", err)
+	}
+	if err := VerifyPostmarkBasicAuth(r, "postmark", "other"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+// newTestSNSSigner returns a function signing SNS messages and a verifier
+// downloading the matching certificate.
+func newTestSNSSigner(t *testing.T) (func(msg *snsMessage), *SNSVerifier) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	certURL := "https://sns.us-east-1.amazonaws.com/SimpleNotificationService-test.pem"
+	sign := func(msg *snsMessage) {
+		msg.SignatureVersion = "2"
+		msg.SigningCertURL = certURL
+		digest := sha256.Sum256([]byte(snsStringToSign(*msg)))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg.Signature = base64.StdEncoding.EncodeToString(sig)
+	}
+
+	verifier := &SNSVerifier{HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != certURL {
+			t.Errorf("Unexpected certificate URL %s", req.URL)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(certPEM))}, nil
+	})}}
+	return sign, verifier
+}
+
+func TestSNSVerifier_Verify(t *testing.T) {
+	sign, verifier := newTestSNSSigner(t)
+
+	msg := snsMessage{
+		Type:      "Notification",
+		MessageId: "1",
+		TopicArn:  "arn:aws:sns:us-east-1:123456789012:ses",
+		Message:   `{"notificationType":"Delivery"}`,
+		Timestamp: "2024-01-01T00:00:00.000Z",
+	}
+	sign(&msg)
+	body, _ := json.Marshal(msg)
+	if err := verifier.Verify(context.Background(), body); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+
+	msg.Message = `{"notificationType":"Bounce"}`
+	body, _ = json.Marshal(msg)
+	if err := verifier.Verify(context.Background(), body); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for a tampered message, got %v", err)
+	}
+
+	msg.SigningCertURL = "https://evil.example.com/cert.pem"
+	body, _ = json.Marshal(msg)
+	if err := verifier.Verify(context.Background(), body); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for an untrusted certificate, got %v", err)
+	}
+}